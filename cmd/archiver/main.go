@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/kyma-project/control-plane/components/schema-migrator/cleaner"
+	"github.com/kyma-project/kyma-environment-broker/internal/archiving"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/vrischmann/envconfig"
+)
+
+type Config struct {
+	Database  storage.Config
+	Archiving archiving.Config
+}
+
+func main() {
+	logs := logrus.New()
+	logs.SetFormatter(&logrus.JSONFormatter{})
+	logs.Info("Starting operations archiving job")
+
+	// create and fill config
+	var cfg Config
+	err := envconfig.InitWithPrefix(&cfg, "APP")
+	fatalOnError(err)
+
+	if cfg.Archiving.DryRun {
+		logs.Info("Dry run only - no changes")
+	}
+	logs.Infof("Retention period: %s", cfg.Archiving.RetentionPeriod)
+
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err)
+	db, conn, err := storage.NewFromConfig(cfg.Database, events.Config{}, cipher, logs.WithField("service", "storage"))
+	fatalOnError(err)
+
+	svc := archiving.NewService(cfg.Archiving, db.Operations(), logs.WithField("service", "archiving"))
+	result, err := svc.Run()
+	if cfg.Archiving.DryRun {
+		logs.Infof("Instances to archive in the first batch: %d", result.Archived)
+	} else {
+		logs.Infof("Instances archived: %d, failures: %d", result.Archived, result.Failed)
+	}
+	fatalOnError(err)
+
+	// do not use defer, close must be done before halting
+	err = conn.Close()
+	fatalOnError(err)
+
+	cleaner.HaltIstioSidecar()
+	err = cleaner.Halt()
+	fatalOnError(err)
+
+	logs.Info("Operations archiving job finished")
+}
+
+func fatalOnError(err error) {
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"os/signal"
 	"regexp"
 	gruntime "runtime"
 	"runtime/pprof"
 	"sort"
+	"syscall"
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
@@ -25,8 +27,10 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler"
 	orchestrationExt "github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/admission"
 	"github.com/kyma-project/kyma-environment-broker/internal/appinfo"
 	"github.com/kyma-project/kyma-environment-broker/internal/avs"
+	"github.com/kyma-project/kyma-environment-broker/internal/binding"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	kebConfig "github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
@@ -41,6 +45,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/metrics"
 	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/kyma-environment-broker/internal/notification"
+	"github.com/kyma-project/kyma-environment-broker/internal/operations"
 	"github.com/kyma-project/kyma-environment-broker/internal/orchestration"
 	orchestrate "github.com/kyma-project/kyma-environment-broker/internal/orchestration/handlers"
 	"github.com/kyma-project/kyma-environment-broker/internal/orchestration/manager"
@@ -54,6 +59,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/process/upgrade_kyma"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider"
 	"github.com/kyma-project/kyma-environment-broker/internal/provisioner"
+	"github.com/kyma-project/kyma-environment-broker/internal/quota"
 	"github.com/kyma-project/kyma-environment-broker/internal/reconciler"
 	"github.com/kyma-project/kyma-environment-broker/internal/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/runtime/components"
@@ -61,6 +67,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/runtimeversion"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/bolt"
 	"github.com/kyma-project/kyma-environment-broker/internal/suspension"
 	"github.com/kyma-project/kyma-environment-broker/internal/swagger"
 	"github.com/prometheus/client_golang/prometheus"
@@ -88,6 +95,11 @@ type Config struct {
 	// Suitable for development purposes.
 	DbInMemory bool `envconfig:"default=false"`
 
+	// DbFilePath allows to use the storage kept in the embedded database file instead of the postgres one.
+	// Data is not lost on restart, but the file can be used only by one instance of the broker.
+	// Suitable for development purposes.
+	DbFilePath string `envconfig:"optional"`
+
 	// DisableProcessOperationsInProgress allows to disable processing operations
 	// which are in progress on starting application. Set to true if you are
 	// running in a separate testing deployment but with the production DB.
@@ -154,6 +166,10 @@ type Config struct {
 	EuAccessWhitelistedGlobalAccountsFilePath string
 	EuAccessRejectionMessage                  string `envconfig:"default=Due to limited availability you need to open support ticket before attempting to provision Kyma clusters in EU Access only regions"`
 
+	QuotasFilePath         string
+	AdmissionRulesFilePath string
+	StepPoliciesFilePath   string
+
 	MaxPaginationPage int `envconfig:"default=100"`
 
 	LogLevel string `envconfig:"default=info"`
@@ -173,6 +189,10 @@ type Config struct {
 	Provisioning   process.StagedManagerConfiguration
 	Deprovisioning process.StagedManagerConfiguration
 	Update         process.StagedManagerConfiguration
+	Leases         process.LeaseConfig
+
+	QueuePriorities process.PriorityConfig
+	AccountLimits   process.AccountLimitsConfig
 }
 
 type ProfilerConfig struct {
@@ -186,6 +206,8 @@ const (
 	checkKymaStageName          = "check_kyma"
 	createKymaResourceStageName = "create_kyma_resource"
 	startStageName              = "start"
+
+	shutdownTimeout = 10 * time.Second
 )
 
 func periodicProfile(logger lager.Logger, profiler ProfilerConfig) {
@@ -225,6 +247,8 @@ func main() {
 
 	cfg.OrchestrationConfig.KymaVersion = cfg.KymaVersion
 	cfg.OrchestrationConfig.KubernetesVersion = cfg.Provisioner.KubernetesVersion
+	cfg.Broker.KymaVersion = cfg.KymaVersion
+	cfg.Broker.KubernetesVersion = cfg.Provisioner.KubernetesVersion
 
 	// create logger
 	logger := lager.NewLogger("kyma-env-broker")
@@ -254,11 +278,20 @@ func main() {
 	fatalOnError(err)
 
 	// create storage
-	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err)
 	var db storage.BrokerStorage
-	if cfg.DbInMemory {
+	// journal is set only for the embedded storage, it must be closed on shutdown to release the database file
+	var journal *bolt.Journal
+	switch {
+	case cfg.DbInMemory:
 		db = storage.NewMemoryStorage()
-	} else {
+	case cfg.DbFilePath != "":
+		store, j, err := storage.NewEmbeddedStorage(cfg.DbFilePath, cfg.Events, cipher, logs.WithField("service", "storage"))
+		fatalOnError(err)
+		db = store
+		journal = j
+	default:
 		store, conn, err := storage.NewFromConfig(cfg.Database, cfg.Events, cipher, logs.WithField("service", "storage"))
 		fatalOnError(err)
 		db = store
@@ -282,6 +315,7 @@ func main() {
 		components.Tracing: runtime.NewGenericComponentDisabler(components.Tracing),
 	}
 	optComponentsSvc := runtime.NewOptionalComponentsService(optionalComponentsDisablers)
+	cfg.Broker.OptionalComponents = optComponentsSvc.GetAllOptionalComponentsNames()
 
 	disabledComponentsProvider := runtime.NewDisabledComponentsProvider()
 
@@ -350,20 +384,52 @@ func main() {
 	accountVersionMapping := runtimeversion.NewAccountVersionMapping(ctx, cli, cfg.VersionConfig.Namespace, cfg.VersionConfig.Name, logs)
 	runtimeVerConfigurator := runtimeversion.NewRuntimeVersionConfigurator(cfg.KymaVersion, accountVersionMapping, db.RuntimeStates())
 
+	bindingsManager := binding.NewServiceAccountBindingsManager(provisionerClient, k8sClientProvider)
+	if cfg.Broker.Binding.Enabled {
+		bindingsCleaner := binding.NewExpiredBindingsCleaner(db.Bindings(), db.Instances(), bindingsManager, eventBroker,
+			cfg.Broker.Binding.CleanupInterval, cfg.Broker.Binding.CleanupDryRun, logs)
+		go bindingsCleaner.Run(ctx)
+	}
+
+	stepPolicies, err := process.ReadStepPoliciesFromFile(cfg.StepPoliciesFilePath)
+	fatalOnError(err)
+	accountLimiter, err := process.NewAccountLimiter(cfg.AccountLimits)
+	fatalOnError(err)
+
 	// run queues
 	provisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.OperationTimeout, cfg.Provisioning, logs.WithField("provisioning", "manager"))
+	provisionManager.UseStepPolicies(stepPolicies)
+	provisionManager.UseAccountLimiter(accountLimiter)
+	provisionManager.UseCompensation(db.Instances())
 	provisionQueue := NewProvisioningProcessingQueue(ctx, provisionManager, cfg.Provisioning.WorkersAmount, &cfg, db, provisionerClient, inputFactory,
 		avsDel, internalEvalAssistant, externalEvalCreator, runtimeVerConfigurator,
-		runtimeOverrides, edpClient, accountProvider, reconcilerClient, k8sClientProvider, cli, logs)
+		runtimeOverrides, edpClient, accountProvider, dynamicGardener, gardenerNamespace, reconcilerClient, k8sClientProvider, cli, logs)
 
 	deprovisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.OperationTimeout, cfg.Deprovisioning, logs.WithField("deprovisioning", "manager"))
+	deprovisionManager.UseStepPolicies(stepPolicies)
+	deprovisionManager.UseAccountLimiter(accountLimiter)
 	deprovisionQueue := NewDeprovisioningProcessingQueue(ctx, cfg.Deprovisioning.WorkersAmount, deprovisionManager, &cfg, db, eventBroker, provisionerClient,
 		avsDel, internalEvalAssistant, externalEvalAssistant, bundleBuilder, edpClient, accountProvider, reconcilerClient,
-		k8sClientProvider, cli, configProvider, logs)
+		k8sClientProvider, cli, configProvider, bindingsManager, logs)
 
 	updateManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.OperationTimeout, cfg.Update, logs.WithField("update", "manager"))
+	updateManager.UseStepPolicies(stepPolicies)
+	updateManager.UseAccountLimiter(accountLimiter)
 	updateQueue := NewUpdateProcessingQueue(ctx, updateManager, cfg.Update.WorkersAmount, db, inputFactory, provisionerClient, eventBroker,
-		runtimeVerConfigurator, db.RuntimeStates(), componentsProvider, reconcilerClient, cfg, k8sClientProvider, cli, logs)
+		runtimeVerConfigurator, db.RuntimeStates(), componentsProvider, reconcilerClient, dynamicGardener, gardenerNamespace,
+		cfg, k8sClientProvider, cli, logs)
+	prometheus.MustRegister(metrics.NewQueueCollector(map[string]metrics.QueueStatsGetter{
+		string(internal.OperationTypeProvision):   provisionQueue,
+		string(internal.OperationTypeDeprovision): deprovisionQueue,
+		string(internal.OperationTypeUpdate):      updateQueue,
+	}))
+
+	runtimeLister := orchestration.NewRuntimeLister(db.Instances(), db.Operations(), runtime.NewConverter(cfg.DefaultRequestRegion), logs)
+	runtimeResolver := orchestrationExt.NewGardenerRuntimeResolver(dynamicGardener, gardenerNamespace, runtimeLister, logs)
+
+	kymaQueue := NewKymaOrchestrationProcessingQueue(ctx, db, runtimeOverrides, provisionerClient, eventBroker, inputFactory, nil, time.Minute, runtimeVerConfigurator, runtimeResolver, upgradeEvalManager, &cfg, internalEvalAssistant, reconcilerClient, notificationBuilder, logs, cli, 1)
+	clusterQueue := NewClusterOrchestrationProcessingQueue(ctx, db, provisionerClient, eventBroker, inputFactory,
+		nil, time.Minute, runtimeResolver, upgradeEvalManager, notificationBuilder, logs, cli, cfg, 1)
 	/***/
 	servicesConfig, err := broker.NewServicesConfigFromFile(cfg.CatalogFilePath)
 	fatalOnError(err)
@@ -371,7 +437,7 @@ func main() {
 	// create server
 	router := mux.NewRouter()
 
-	createAPI(router, servicesConfig, inputFactory, &cfg, db, provisionQueue, deprovisionQueue, updateQueue, logger, logs, inputFactory.GetPlanDefaults)
+	createAPI(router, servicesConfig, inputFactory, &cfg, db, provisionQueue, deprovisionQueue, updateQueue, kymaQueue, clusterQueue, bindingsManager, logger, logs, inputFactory.GetPlanDefaults)
 
 	// create metrics endpoint
 	router.Handle("/metrics", promhttp.Handler())
@@ -381,13 +447,6 @@ func main() {
 	kcHandler := kubeconfig.NewHandler(db, kcBuilder, cfg.Kubeconfig.AllowOrigins, logs.WithField("service", "kubeconfigHandle"))
 	kcHandler.AttachRoutes(router)
 
-	runtimeLister := orchestration.NewRuntimeLister(db.Instances(), db.Operations(), runtime.NewConverter(cfg.DefaultRequestRegion), logs)
-	runtimeResolver := orchestrationExt.NewGardenerRuntimeResolver(dynamicGardener, gardenerNamespace, runtimeLister, logs)
-
-	kymaQueue := NewKymaOrchestrationProcessingQueue(ctx, db, runtimeOverrides, provisionerClient, eventBroker, inputFactory, nil, time.Minute, runtimeVerConfigurator, runtimeResolver, upgradeEvalManager, &cfg, internalEvalAssistant, reconcilerClient, notificationBuilder, logs, cli, 1)
-	clusterQueue := NewClusterOrchestrationProcessingQueue(ctx, db, provisionerClient, eventBroker, inputFactory,
-		nil, time.Minute, runtimeResolver, upgradeEvalManager, notificationBuilder, logs, cli, cfg, 1)
-
 	// TODO: in case of cluster upgrade the same Azure Zones must be send to the Provisioner
 	orchestrationHandler := orchestrate.NewOrchestrationHandler(db, kymaQueue, clusterQueue, cfg.MaxPaginationPage, logs)
 
@@ -420,12 +479,39 @@ func main() {
 	runtimeHandler := runtime.NewHandler(db.Instances(), db.Operations(), db.RuntimeStates(), cfg.MaxPaginationPage, cfg.DefaultRequestRegion)
 	runtimeHandler.AttachRoutes(router)
 
+	// create operations endpoints
+	operationsHandler := operations.NewHandler(db.Operations(), map[internal.OperationType]operations.RetryTarget{
+		internal.OperationTypeProvision: {Queue: provisionQueue, Manager: provisionManager},
+		internal.OperationTypeUpdate:    {Queue: updateQueue, Manager: updateManager},
+	}, stepPolicies, logs)
+	operationsHandler.AttachRoutes(router)
+
 	router.StrictSlash(true).PathPrefix("/").Handler(http.StripPrefix("/", http.FileServer(http.Dir("/swagger"))))
 	svr := handlers.CustomLoggingHandler(os.Stdout, router, func(writer io.Writer, params handlers.LogFormatterParams) {
 		logs.Infof("Call handled: method=%s url=%s statusCode=%d size=%d", params.Request.Method, params.URL.Path, params.StatusCode, params.Size)
 	})
 
-	fatalOnError(http.ListenAndServe(cfg.Host+":"+cfg.Port, svr))
+	server := &http.Server{Addr: cfg.Host + ":" + cfg.Port, Handler: svr}
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+
+		logs.Info("Shutting down the broker")
+		cancel()
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logs.Errorf("while shutting down the server: %s", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		fatalOnError(err)
+	}
+	if journal != nil {
+		fatalOnError(journal.Close())
+	}
 }
 
 func k8sClientProvider(kcfg string) (client.Client, error) {
@@ -457,7 +543,7 @@ func isVersionFollowingSemanticVersioning(version string) bool {
 	return false
 }
 
-func createAPI(router *mux.Router, servicesConfig broker.ServicesConfig, planValidator broker.PlanValidator, cfg *Config, db storage.BrokerStorage, provisionQueue, deprovisionQueue, updateQueue *process.Queue, logger lager.Logger, logs logrus.FieldLogger, planDefaults broker.PlanDefaults) {
+func createAPI(router *mux.Router, servicesConfig broker.ServicesConfig, planValidator broker.PlanValidator, cfg *Config, db storage.BrokerStorage, provisionQueue, deprovisionQueue, updateQueue, kymaQueue, clusterQueue *process.Queue, bindingsManager broker.BindingsManager, logger lager.Logger, logs logrus.FieldLogger, planDefaults broker.PlanDefaults) {
 	suspensionCtxHandler := suspension.NewContextUpdateHandler(db.Operations(), provisionQueue, deprovisionQueue, logs)
 
 	defaultPlansConfig, err := servicesConfig.DefaultPlansConfig()
@@ -475,22 +561,30 @@ func createAPI(router *mux.Router, servicesConfig broker.ServicesConfig, planVal
 	fatalOnError(err)
 	logs.Infof("Number of globalAccountIds for EU Access: %d\n", len(whitelistedGlobalAccountIds))
 
+	quotas, err := quota.ReadQuotasFromFile(cfg.QuotasFilePath)
+	fatalOnError(err)
+
+	admissionPolicy, err := admission.ReadPolicyFromFile(cfg.AdmissionRulesFilePath)
+	fatalOnError(err)
+
 	// create KymaEnvironmentBroker endpoints
 	kymaEnvBroker := &broker.KymaEnvironmentBroker{
 		ServicesEndpoint: broker.NewServices(cfg.Broker, servicesConfig, logs),
 		ProvisionEndpoint: broker.NewProvision(cfg.Broker, cfg.Gardener, db.Operations(), db.Instances(),
 			provisionQueue, planValidator, defaultPlansConfig, cfg.EnableOnDemandVersion,
-			planDefaults, whitelistedGlobalAccountIds, cfg.EuAccessRejectionMessage, logs, cfg.KymaDashboardConfig),
-		DeprovisionEndpoint: broker.NewDeprovision(db.Instances(), db.Operations(), deprovisionQueue, logs),
+			planDefaults, whitelistedGlobalAccountIds, cfg.EuAccessRejectionMessage, quotas, admissionPolicy, logs, cfg.KymaDashboardConfig),
+		DeprovisionEndpoint: broker.NewDeprovision(cfg.Broker, db.Instances(), db.Operations(), deprovisionQueue, logs),
 		UpdateEndpoint: broker.NewUpdate(cfg.Broker, db.Instances(), db.RuntimeStates(), db.Operations(),
-			suspensionCtxHandler, cfg.UpdateProcessingEnabled, cfg.UpdateSubAccountMovementEnabled, updateQueue, defaultPlansConfig,
+			suspensionCtxHandler, cfg.UpdateProcessingEnabled, cfg.UpdateSubAccountMovementEnabled, updateQueue,
+			broker.NewOrchestrationInstanceUpgrader(db.Orchestrations(), kymaQueue, clusterQueue, logs), admissionPolicy, defaultPlansConfig,
 			planDefaults, logs, cfg.KymaDashboardConfig),
-		GetInstanceEndpoint:          broker.NewGetInstance(cfg.Broker, db.Instances(), db.Operations(), logs),
-		LastOperationEndpoint:        broker.NewLastOperation(db.Operations(), logs),
-		BindEndpoint:                 broker.NewBind(logs),
-		UnbindEndpoint:               broker.NewUnbind(logs),
-		GetBindingEndpoint:           broker.NewGetBinding(logs),
-		LastBindingOperationEndpoint: broker.NewLastBindingOperation(logs),
+		GetInstanceEndpoint:   broker.NewGetInstance(cfg.Broker, db.Instances(), db.Operations(), logs),
+		LastOperationEndpoint: broker.NewLastOperation(db.Operations(), logs),
+		BindEndpoint: broker.NewBind(cfg.Broker.Binding, db.Instances(), db.Operations(), db.Bindings(),
+			bindingsManager, logs),
+		UnbindEndpoint:               broker.NewUnbind(cfg.Broker.Binding, db.Instances(), db.Bindings(), bindingsManager, logs),
+		GetBindingEndpoint:           broker.NewGetBinding(cfg.Broker.Binding, db.Bindings(), logs),
+		LastBindingOperationEndpoint: broker.NewLastBindingOperation(cfg.Broker.Binding, db.Bindings(), logs),
 	}
 
 	router.Use(middleware.AddRegionToContext(cfg.DefaultRequestRegion))
@@ -507,6 +601,12 @@ func createAPI(router *mux.Router, servicesConfig broker.ServicesConfig, planVal
 	runtimesInfoHandler := appinfo.NewRuntimeInfoHandler(db.Instances(), db.Operations(), defaultPlansConfig, cfg.DefaultRequestRegion, respWriter)
 	router.Handle("/info/runtimes", runtimesInfoHandler)
 	router.Handle("/events", eventshandler.NewHandler(db.Events(), db.Instances()))
+
+	quotaHandler := quota.NewHandler(db.Instances(), quotas, broker.PlanNamesMapping, logs)
+	quotaHandler.AttachRoutes(router)
+
+	admissionHandler := admission.NewHandler(admissionPolicy, broker.PlanNamesMapping, logs)
+	admissionHandler.AttachRoutes(router)
 }
 
 // queues all in progress operations by type
@@ -632,6 +732,7 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 	internalEvalAssistant *avs.InternalEvalAssistant, externalEvalCreator *provisioning.ExternalEvalCreator,
 	runtimeVerConfigurator *runtimeversion.RuntimeVersionConfigurator,
 	runtimeOverrides provisioning.RuntimeOverridesAppender, edpClient provisioning.EDPClient, accountProvider hyperscaler.AccountProvider,
+	gardenerClient dynamic.Interface, gardenerNamespace string,
 	reconcilerClient reconciler.Client, k8sClientProvider func(kcfg string) (client.Client, error), cli client.Client, logs logrus.FieldLogger) *process.Queue {
 
 	const postActionsStageName = "post_actions"
@@ -649,6 +750,11 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 			Once the stage is done it will never be retried.
 	*/
 
+	postActionSteps := []process.Step{provisioning.NewExternalEvalStep(externalEvalCreator)}
+	if !cfg.Avs.Disabled {
+		postActionSteps = append(postActionSteps, provisioning.NewInternalEvaluationStep(avsDel, internalEvalAssistant))
+	}
+
 	provisioningSteps := []struct {
 		disabled  bool
 		stage     string
@@ -669,7 +775,7 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 		},
 		{
 			stage:     createRuntimeStageName,
-			step:      provisioning.NewResolveCredentialsStep(db.Operations(), accountProvider),
+			step:      provisioning.NewResolveCredentialsStep(db.Operations(), db.Instances(), accountProvider),
 			condition: provisioning.SkipForOwnClusterPlan,
 		},
 		{
@@ -704,6 +810,11 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 			step:      provisioning.NewCheckRuntimeStep(db.Operations(), provisionerClient, cfg.Provisioner.ProvisioningTimeout),
 			condition: provisioning.SkipForOwnClusterPlan,
 		},
+		{
+			stage:     createRuntimeStageName,
+			step:      steps.NewApplyAdditionalWorkerNodePoolsStep(db.Operations(), gardenerClient, gardenerNamespace),
+			condition: provisioning.WhenAdditionalWorkerNodePoolsProvided,
+		},
 		{
 			stage: createRuntimeStageName,
 			step:  provisioning.NewGetKubeconfigStep(db.Operations(), provisionerClient, k8sClientProvider),
@@ -735,15 +846,10 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 			stage:    createKymaResourceStageName,
 			step:     provisioning.NewApplyKymaStep(db.Operations(), cli),
 		},
-		// post actions
+		// post actions are independent, so they run in parallel
 		{
 			stage: postActionsStageName,
-			step:  provisioning.NewExternalEvalStep(externalEvalCreator),
-		},
-		{
-			stage:    postActionsStageName,
-			step:     provisioning.NewInternalEvaluationStep(avsDel, internalEvalAssistant),
-			disabled: cfg.Avs.Disabled,
+			step:  process.NewParallelGroup("Post_Actions", postActionSteps...),
 		},
 	}
 	for _, step := range provisioningSteps {
@@ -756,6 +862,10 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 	}
 
 	queue := process.NewQueue(provisionManager, logs)
+	if cfg.Leases.Enabled {
+		queue.UseLeases(db.Operations(), string(internal.OperationTypeProvision), cfg.Leases)
+	}
+	queue.UseClassifier(process.OperationClassifier(db.Operations(), cfg.QueuePriorities), cfg.QueuePriorities)
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
@@ -763,7 +873,8 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 
 func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManager, workersAmount int, db storage.BrokerStorage, inputFactory input.CreatorForPlan,
 	provisionerClient provisioner.Client, publisher event.Publisher, runtimeVerConfigurator *runtimeversion.RuntimeVersionConfigurator, runtimeStatesDb storage.RuntimeStates,
-	runtimeProvider input.ComponentListProvider, reconcilerClient reconciler.Client, cfg Config, k8sClientProvider func(kcfg string) (client.Client, error), cli client.Client, logs logrus.FieldLogger) *process.Queue {
+	runtimeProvider input.ComponentListProvider, reconcilerClient reconciler.Client, gardenerClient dynamic.Interface, gardenerNamespace string,
+	cfg Config, k8sClientProvider func(kcfg string) (client.Client, error), cli client.Client, logs logrus.FieldLogger) *process.Queue {
 
 	requiresReconcilerUpdate := update.RequiresReconcilerUpdate
 	if cfg.ReconcilerIntegrationDisabled {
@@ -798,6 +909,11 @@ func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManage
 			step:      update.NewBTPOperatorOverridesStep(db.Operations(), runtimeProvider),
 			condition: update.RequiresBTPOperatorCredentials,
 		},
+		{
+			stage:     "btp-operator",
+			step:      update.NewOptionalComponentsStep(db.Operations(), runtimeProvider, cfg.Broker.OptionalComponents),
+			condition: update.ForOptionalComponentsChange,
+		},
 		{
 			stage:     "btp-operator",
 			step:      update.NewApplyReconcilerConfigurationStep(db.Operations(), db.RuntimeStates(), reconcilerClient),
@@ -813,6 +929,16 @@ func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManage
 			step:      update.NewCheckStep(db.Operations(), provisionerClient, 40*time.Minute),
 			condition: update.SkipForOwnClusterPlan,
 		},
+		{
+			stage:     "check",
+			step:      steps.NewApplyAdditionalWorkerNodePoolsStep(db.Operations(), gardenerClient, gardenerNamespace),
+			condition: update.ForAdditionalWorkerNodePoolsChange,
+		},
+		{
+			stage:     "check",
+			step:      update.NewUpdateInstancePlanStep(db.Instances(), db.Operations()),
+			condition: update.ForPlanChange,
+		},
 	}
 
 	for _, step := range updateSteps {
@@ -822,6 +948,10 @@ func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManage
 		}
 	}
 	queue := process.NewQueue(manager, logs)
+	if cfg.Leases.Enabled {
+		queue.UseLeases(db.Operations(), string(internal.OperationTypeUpdate), cfg.Leases)
+	}
+	queue.UseClassifier(process.OperationClassifier(db.Operations(), cfg.QueuePriorities), cfg.QueuePriorities)
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
@@ -832,7 +962,16 @@ func NewDeprovisioningProcessingQueue(ctx context.Context, workersAmount int, de
 	provisionerClient provisioner.Client, avsDel *avs.Delegator, internalEvalAssistant *avs.InternalEvalAssistant,
 	externalEvalAssistant *avs.ExternalEvalAssistant, bundleBuilder ias.BundleBuilder,
 	edpClient deprovisioning.EDPClient, accountProvider hyperscaler.AccountProvider, reconcilerClient reconciler.Client,
-	k8sClientProvider func(kcfg string) (client.Client, error), cli client.Client, configProvider input.ConfigurationProvider, logs logrus.FieldLogger) *process.Queue {
+	k8sClientProvider func(kcfg string) (client.Client, error), cli client.Client, configProvider input.ConfigurationProvider,
+	bindingsManager broker.BindingsManager, logs logrus.FieldLogger) *process.Queue {
+
+	deregistrationSteps := []process.Step{deprovisioning.NewAvsEvaluationsRemovalStep(avsDel, db.Operations(), externalEvalAssistant, internalEvalAssistant)}
+	if !cfg.EDP.Disabled {
+		deregistrationSteps = append(deregistrationSteps, deprovisioning.NewEDPDeregistrationStep(db.Operations(), edpClient, cfg.EDP))
+	}
+	if !cfg.IAS.Disabled {
+		deregistrationSteps = append(deregistrationSteps, deprovisioning.NewIASDeregistrationStep(db.Operations(), bundleBuilder))
+	}
 
 	deprovisioningSteps := []struct {
 		disabled bool
@@ -842,18 +981,14 @@ func NewDeprovisioningProcessingQueue(ctx context.Context, workersAmount int, de
 			step: deprovisioning.NewInitStep(db.Operations(), db.Instances(), 12*time.Hour),
 		},
 		{
-			step: deprovisioning.NewBTPOperatorCleanupStep(db.Operations(), provisionerClient, k8sClientProvider),
+			step: deprovisioning.NewDeleteBindingsStep(db.Operations(), db.Instances(), db.Bindings(), bindingsManager),
 		},
 		{
-			step: deprovisioning.NewAvsEvaluationsRemovalStep(avsDel, db.Operations(), externalEvalAssistant, internalEvalAssistant),
-		},
-		{
-			step:     deprovisioning.NewEDPDeregistrationStep(db.Operations(), edpClient, cfg.EDP),
-			disabled: cfg.EDP.Disabled,
+			step: deprovisioning.NewBTPOperatorCleanupStep(db.Operations(), provisionerClient, k8sClientProvider),
 		},
 		{
-			step:     deprovisioning.NewIASDeregistrationStep(db.Operations(), bundleBuilder),
-			disabled: cfg.IAS.Disabled,
+			// deregistration from external services is independent, so it runs in parallel
+			step: process.NewParallelGroup("External_Services_Deregistration", deregistrationSteps...),
 		},
 		{
 			disabled: cfg.LifecycleManagerIntegrationDisabled,
@@ -902,6 +1037,10 @@ func NewDeprovisioningProcessingQueue(ctx context.Context, workersAmount int, de
 	}
 
 	queue := process.NewQueue(deprovisionManager, logs)
+	if cfg.Leases.Enabled {
+		queue.UseLeases(db.Operations(), string(internal.OperationTypeDeprovision), cfg.Leases)
+	}
+	queue.UseClassifier(process.OperationClassifier(db.Operations(), cfg.QueuePriorities), cfg.QueuePriorities)
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
@@ -909,67 +1048,83 @@ func NewDeprovisioningProcessingQueue(ctx context.Context, workersAmount int, de
 
 func NewKymaOrchestrationProcessingQueue(ctx context.Context, db storage.BrokerStorage, runtimeOverrides upgrade_kyma.RuntimeOverridesAppender, provisionerClient provisioner.Client, pub event.Publisher, inputFactory input.CreatorForPlan, icfg *upgrade_kyma.TimeSchedule, pollingInterval time.Duration, runtimeVerConfigurator *runtimeversion.RuntimeVersionConfigurator, runtimeResolver orchestrationExt.RuntimeResolver, upgradeEvalManager *avs.EvaluationManager, cfg *Config, internalEvalAssistant *avs.InternalEvalAssistant, reconcilerClient reconciler.Client, notificationBuilder notification.BundleBuilder, logs logrus.FieldLogger, cli client.Client, speedFactor int) *process.Queue {
 
-	upgradeKymaManager := upgrade_kyma.NewManager(db.Operations(), pub, logs.WithField("upgradeKyma", "manager"))
-	upgradeKymaInit := upgrade_kyma.NewInitialisationStep(db.Operations(), db.Orchestrations(), db.Instances(),
-		provisionerClient, inputFactory, upgradeEvalManager, icfg, runtimeVerConfigurator, notificationBuilder)
+	const (
+		upgradeKymaStageName = "upgrade_kyma"
+		checkKymaStageName   = "check_kyma"
+	)
+	// upgrade operations have no time limit, pending operations wait for the maintenance window and steps have their own limits
+	upgradeKymaManager := process.NewStagedManager(db.Operations(), pub, 0, process.StagedManagerConfiguration{}, logs.WithField("upgradeKyma", "manager"))
+	upgradeKymaManager.SpeedUp(int64(speedFactor))
+	upgradeKymaManager.DefineStages([]string{upgradeKymaStageName, checkKymaStageName})
+	/*
+		The upgrade Kyma process contains the following stages:
+		1. "upgrade_kyma" - changes the state from pending to in progress, prepares overrides and applies the cluster configuration.
+		The InputCreator is not persisted, that's why all steps which require it must be in the same stage as the initialisation step.
+		2. "check_kyma" - checks if the cluster configuration is applied
 
-	upgradeKymaManager.InitStep(upgradeKymaInit)
+		Once the stage is done it will never be retried.
+	*/
 	upgradeKymaSteps := []struct {
 		disabled bool
-		weight   int
-		step     upgrade_kyma.Step
-		cnd      upgrade_kyma.StepCondition
+		stage    string
+		step     process.Step
+		cnd      process.StepCondition
 	}{
-		// check cluster configuration is the first step - to not execute other steps, when cluster configuration was applied
-		// this should be moved to the end when we introduce stages like in the provisioning process
-		// (also return operation, 0, nil at the end of apply_cluster_configuration)
 		{
-			weight:   1,
-			disabled: cfg.ReconcilerIntegrationDisabled,
-			step:     upgrade_kyma.NewCheckClusterConfigurationStep(db.Operations(), reconcilerClient, upgradeEvalManager, cfg.Reconciler.ProvisioningTimeout),
-			cnd:      upgrade_kyma.SkipForPreviewPlan,
+			stage: upgradeKymaStageName,
+			step: upgrade_kyma.NewInitialisationStep(db.Operations(), db.Orchestrations(), db.Instances(),
+				provisionerClient, inputFactory, upgradeEvalManager, icfg, runtimeVerConfigurator, notificationBuilder),
 		},
 		{
-			weight: 1,
-			step:   steps.InitKymaTemplateUpgradeKyma(db.Operations()),
+			stage: upgradeKymaStageName,
+			step:  steps.NewInitKymaTemplate(db.Operations()),
 		},
 		{
-			weight: 2,
-			step:   upgrade_kyma.NewGetKubeconfigStep(db.Operations(), provisionerClient),
+			stage: upgradeKymaStageName,
+			step:  upgrade_kyma.NewGetKubeconfigStep(db.Operations(), provisionerClient),
 		},
 		{
-			weight:   2,
 			disabled: cfg.LifecycleManagerIntegrationDisabled,
-			step:     steps.SyncKubeconfigUpgradeKyma(db.Operations(), cli),
+			stage:    upgradeKymaStageName,
+			step:     steps.SyncKubeconfig(db.Operations(), cli),
 		},
 		{
-			weight:   2,
 			disabled: cfg.LifecycleManagerIntegrationDisabled,
-			step:     upgrade_kyma.NewApplyKymaStep(db.Operations(), cli),
+			stage:    upgradeKymaStageName,
+			step:     provisioning.NewApplyKymaStep(db.Operations(), cli),
 		},
 		{
-			weight: 3,
-			cnd:    upgrade_kyma.WhenBTPOperatorCredentialsProvided,
-			step:   upgrade_kyma.NewBTPOperatorOverridesStep(db.Operations()),
+			stage: upgradeKymaStageName,
+			cnd:   upgrade_kyma.WhenBTPOperatorCredentialsProvided,
+			step:  upgrade_kyma.NewBTPOperatorOverridesStep(db.Operations()),
 		},
 		{
-			weight: 4,
-			step:   upgrade_kyma.NewOverridesFromSecretsAndConfigStep(db.Operations(), runtimeOverrides, runtimeVerConfigurator),
+			stage: upgradeKymaStageName,
+			step:  upgrade_kyma.NewOverridesFromSecretsAndConfigStep(db.Operations(), runtimeOverrides, runtimeVerConfigurator),
 		},
 		{
-			weight: 8,
-			step:   upgrade_kyma.NewSendNotificationStep(db.Operations(), notificationBuilder),
+			stage: upgradeKymaStageName,
+			step:  upgrade_kyma.NewSendNotificationStep(db.Operations(), notificationBuilder),
 		},
 		{
-			weight:   10,
 			disabled: cfg.ReconcilerIntegrationDisabled,
+			stage:    upgradeKymaStageName,
 			step:     upgrade_kyma.NewApplyClusterConfigurationStep(db.Operations(), db.RuntimeStates(), reconcilerClient),
 			cnd:      upgrade_kyma.SkipForPreviewPlan,
 		},
+		{
+			disabled: cfg.ReconcilerIntegrationDisabled,
+			stage:    checkKymaStageName,
+			step:     upgrade_kyma.NewCheckClusterConfigurationStep(db.Operations(), reconcilerClient, upgradeEvalManager, cfg.Reconciler.ProvisioningTimeout),
+			cnd:      upgrade_kyma.SkipForPreviewPlan,
+		},
 	}
 	for _, step := range upgradeKymaSteps {
 		if !step.disabled {
-			upgradeKymaManager.AddStep(step.weight, step.step, step.cnd)
+			err := upgradeKymaManager.AddStep(step.stage, step.step, step.cnd)
+			if err != nil {
+				fatalOnError(err)
+			}
 		}
 	}
 
@@ -977,6 +1132,9 @@ func NewKymaOrchestrationProcessingQueue(ctx context.Context, db storage.BrokerS
 		upgradeKymaManager, runtimeResolver, pollingInterval, logs.WithField("upgradeKyma", "orchestration"),
 		cli, &cfg.OrchestrationConfig, notificationBuilder, speedFactor)
 	queue := process.NewQueue(orchestrateKymaManager, logs)
+	if cfg.Leases.Enabled {
+		queue.UseLeases(db.Operations(), string(orchestrationExt.UpgradeKymaOrchestration), cfg.Leases)
+	}
 
 	queue.Run(ctx.Done(), 3)
 
@@ -988,36 +1146,59 @@ func NewClusterOrchestrationProcessingQueue(ctx context.Context, db storage.Brok
 	runtimeResolver orchestrationExt.RuntimeResolver, upgradeEvalManager *avs.EvaluationManager, notificationBuilder notification.BundleBuilder, logs logrus.FieldLogger,
 	cli client.Client, cfg Config, speedFactor int) *process.Queue {
 
-	upgradeClusterManager := upgrade_cluster.NewManager(db.Operations(), pub, logs.WithField("upgradeCluster", "manager"))
-	upgradeClusterInit := upgrade_cluster.NewInitialisationStep(db.Operations(), db.Orchestrations(), provisionerClient, inputFactory, upgradeEvalManager, icfg, notificationBuilder)
-	upgradeClusterManager.InitStep(upgradeClusterInit)
+	const (
+		upgradeClusterStageName      = "upgrade_cluster"
+		checkClusterUpgradeStageName = "check_cluster_upgrade"
+	)
+	// upgrade operations have no time limit, pending operations wait for the maintenance window and steps have their own limits
+	upgradeClusterManager := process.NewStagedManager(db.Operations(), pub, 0, process.StagedManagerConfiguration{}, logs.WithField("upgradeCluster", "manager"))
+	upgradeClusterManager.SpeedUp(int64(speedFactor))
+	upgradeClusterManager.DefineStages([]string{upgradeClusterStageName, checkClusterUpgradeStageName})
+	/*
+		The upgrade cluster process contains the following stages:
+		1. "upgrade_cluster" - changes the state from pending to in progress and triggers the upgrade in the Provisioner.
+		The InputCreator is not persisted, that's why all steps which require it must be in the same stage as the initialisation step.
+		2. "check_cluster_upgrade" - waits until the Provisioner finishes the upgrade
 
+		Once the stage is done it will never be retried.
+	*/
 	upgradeClusterSteps := []struct {
 		disabled  bool
-		weight    int
-		step      upgrade_cluster.Step
-		condition upgrade_cluster.StepCondition
+		stage     string
+		step      process.Step
+		condition process.StepCondition
 	}{
 		{
-			weight:    1,
+			stage: upgradeClusterStageName,
+			step:  upgrade_cluster.NewInitialisationStep(db.Operations(), db.Orchestrations(), inputFactory, icfg),
+		},
+		{
+			stage:     upgradeClusterStageName,
 			step:      upgrade_cluster.NewLogSkippingUpgradeStep(db.Operations()),
 			condition: provisioning.DoForOwnClusterPlanOnly,
 		},
 		{
-			weight:    10,
+			stage:     upgradeClusterStageName,
 			step:      upgrade_cluster.NewSendNotificationStep(db.Operations(), notificationBuilder),
 			condition: provisioning.SkipForOwnClusterPlan,
 		},
 		{
-			weight:    10,
+			stage:     upgradeClusterStageName,
 			step:      upgrade_cluster.NewUpgradeClusterStep(db.Operations(), db.RuntimeStates(), provisionerClient, icfg),
 			condition: provisioning.SkipForOwnClusterPlan,
 		},
+		{
+			stage:     checkClusterUpgradeStageName,
+			step:      upgrade_cluster.NewCheckClusterUpgradeStep(db.Operations(), provisionerClient, upgradeEvalManager, icfg, notificationBuilder),
+			condition: provisioning.SkipForOwnClusterPlan,
+		},
 	}
-
 	for _, step := range upgradeClusterSteps {
 		if !step.disabled {
-			upgradeClusterManager.AddStep(step.weight, step.step, step.condition)
+			err := upgradeClusterManager.AddStep(step.stage, step.step, step.condition)
+			if err != nil {
+				fatalOnError(err)
+			}
 		}
 	}
 
@@ -1025,6 +1206,9 @@ func NewClusterOrchestrationProcessingQueue(ctx context.Context, db storage.Brok
 		upgradeClusterManager, runtimeResolver, pollingInterval, logs.WithField("upgradeCluster", "orchestration"),
 		cli, cfg.OrchestrationConfig, notificationBuilder, speedFactor)
 	queue := process.NewQueue(orchestrateClusterManager, logs)
+	if cfg.Leases.Enabled {
+		queue.UseLeases(db.Operations(), string(orchestrationExt.UpgradeClusterOrchestration), cfg.Leases)
+	}
 
 	queue.Run(ctx.Done(), 3)
 
@@ -639,7 +639,7 @@ func NewProvisioningSuite(t *testing.T, multiZoneCluster bool, controlPlaneFailu
 	provisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.OperationTimeout, cfg.Provisioning, logs.WithField("provisioning", "manager"))
 	provisioningQueue := NewProvisioningProcessingQueue(ctx, provisionManager, workersAmount, cfg, db, provisionerClient, inputFactory, avsDel,
 		internalEvalAssistant, externalEvalCreator, runtimeVerConfigurator, runtimeOverrides, edpClient, accountProvider,
-		reconcilerClient, fakeK8sClientProvider(cli), cli, logs)
+		gardener.NewDynamicFakeClient(), "garden-kyma", reconcilerClient, fakeK8sClientProvider(cli), cli, logs)
 
 	provisioningQueue.SpeedUp(10000)
 	provisionManager.SpeedUp(10000)
@@ -1016,6 +1016,8 @@ func fixConfig() *Config {
 		FreemiumProviders:                         []string{"aws", "azure"},
 		EuAccessWhitelistedGlobalAccountsFilePath: "testdata/eu_access_whitelist.yaml",
 		EuAccessRejectionMessage:                  "EU Access Rejection Message - see: http://google.pl",
+		QuotasFilePath:                            "testdata/quotas.yaml",
+		AdmissionRulesFilePath:                    "testdata/admission_rules.yaml",
 
 		Provisioning:   process.StagedManagerConfiguration{MaxStepProcessingTime: time.Minute},
 		Deprovisioning: process.StagedManagerConfiguration{MaxStepProcessingTime: time.Minute},
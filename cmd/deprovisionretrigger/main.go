@@ -52,7 +52,8 @@ func main() {
 	brokerClient := broker.NewClient(ctx, cfg.Broker)
 
 	// create storage connection
-	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err)
 	db, conn, err := storage.NewFromConfig(cfg.Database, events.Config{}, cipher, log.WithField("service", "storage"))
 	fatalOnError(err)
 	svc := newDeprovisionRetriggerService(cfg, brokerClient, db.Instances())
@@ -90,6 +91,15 @@ func (s *DeprovisionRetriggerService) PerformCleanup() error {
 		return err
 	}
 
+	// instances which side effects of the failed provisioning were not undone are deprovisioned too
+	compensationFailedFilter := dbmodel.InstanceFilter{CompensationFailed: &[]bool{true}[0], DeletionAttempted: &[]bool{false}[0]}
+	instancesNotCompensated, _, _, err := s.instanceStorage.List(compensationFailedFilter)
+	if err != nil {
+		log.Error(fmt.Sprintf("while getting instances with failed compensation: %s", err))
+		return err
+	}
+	instancesToDeprovisionAgain = append(instancesToDeprovisionAgain, instancesNotCompensated...)
+
 	if s.cfg.DryRun {
 		s.logInstances(instancesToDeprovisionAgain)
 		log.Infof("Instances to retrigger deprovisioning: %d", len(instancesToDeprovisionAgain))
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/kyma-project/control-plane/components/schema-migrator/cleaner"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/vrischmann/envconfig"
+)
+
+type Config struct {
+	Database  storage.Config
+	BatchSize int  `envconfig:"default=100"`
+	DryRun    bool `envconfig:"default=true"`
+}
+
+func main() {
+	logs := logrus.New()
+	logs.SetFormatter(&logrus.JSONFormatter{})
+	logs.Info("Starting re-encryption job")
+
+	// create and fill config
+	var cfg Config
+	err := envconfig.InitWithPrefix(&cfg, "APP")
+	fatalOnError(err)
+
+	if cfg.DryRun {
+		logs.Info("Dry run only - no changes")
+	}
+
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err)
+	logs.Infof("Encrypting data with the key %s", cipher.ActiveKeyID())
+
+	reencryption, conn, err := storage.NewReencryptionFromConfig(cfg.Database, cipher, cfg.BatchSize, cfg.DryRun, logs.WithField("service", "reencryption"))
+	fatalOnError(err)
+
+	stats, err := reencryption.Run()
+	for _, s := range stats {
+		logs.Infof("Table %s: scanned rows = %d, re-encrypted rows = %d, rows changed in the meantime = %d, failed rows = %d",
+			s.Table, s.Scanned, s.Reencrypted, s.Changed, s.Failed)
+	}
+	fatalOnError(err)
+	for _, s := range stats {
+		if s.Failed > 0 {
+			logs.Errorf("Some rows of the %s table are not encrypted with the key %s, keys used to encrypt them must not be removed", s.Table, cipher.ActiveKeyID())
+		}
+	}
+
+	// do not use defer, close must be done before halting
+	err = conn.Close()
+	fatalOnError(err)
+
+	cleaner.HaltIstioSidecar()
+	err = cleaner.Halt()
+	fatalOnError(err)
+
+	logs.Info("Re-encryption job finished")
+}
+
+func fatalOnError(err error) {
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
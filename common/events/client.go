@@ -6,6 +6,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,8 +15,19 @@ import (
 type EventLevel string
 
 const (
-	InfoEventLevel  EventLevel = "info"
-	ErrorEventLevel EventLevel = "error"
+	InfoEventLevel    EventLevel = "info"
+	WarningEventLevel EventLevel = "warning"
+	ErrorEventLevel   EventLevel = "error"
+)
+
+// Fields are structured data of the event, for example the name of the processed step
+type Fields map[string]interface{}
+
+// Keys of fields set by KEB
+const (
+	StepField       = "step"
+	RetryCountField = "retryCount"
+	BackoffField    = "backoff"
 )
 
 type EventDTO struct {
@@ -23,17 +36,62 @@ type EventDTO struct {
 	InstanceID  *string
 	OperationID *string
 	Message     string
+	Fields      Fields
 	CreatedAt   time.Time
 }
 
+// EventFilter selects events, every non-empty criterion must be met. Events are ordered by the creation time.
 type EventFilter struct {
 	InstanceIDs  []string
 	OperationIDs []string
+	Levels       []EventLevel
+	// From and To limit events to the ones created in the [From, To) range
+	From time.Time
+	To   time.Time
+	// MessageContains limits events to the ones which message contains the text, letter case is ignored
+	MessageContains string
+	// After limits events to the ones following the cursor
+	After *Cursor
+	// Limit is the maximum number of returned events, all matching events are returned if it is not set
+	Limit int
+}
+
+// EventQuery is the query of the /events API, the API resolves runtime IDs to instance IDs
+type EventQuery struct {
+	EventFilter
+	RuntimeIDs []string
+}
+
+// EventPage is the response of the /events API, NextCursor is set if there are more events
+type EventPage struct {
+	Data       []EventDTO `json:"data"`
+	Count      int        `json:"count"`
+	NextCursor *Cursor    `json:"nextCursor,omitempty"`
 }
 
+// DefaultLimit is the number of events in the page if the cursor is set without the limit
+const DefaultLimit = 100
+
+// Query parameters of the /events API, IDs and levels are comma-separated, times are in the RFC 3339 format.
+// The API returns the EventPage if the limit or the cursor is set, otherwise it returns the array of all matching events.
+const (
+	InstanceIDsParam  = "instance_ids"
+	RuntimeIDsParam   = "runtime_ids"
+	OperationIDsParam = "operation_ids"
+	LevelsParam       = "levels"
+	FromParam         = "from"
+	ToParam           = "to"
+	MessageParam      = "message"
+	LimitParam        = "limit"
+	CursorParam       = "cursor"
+)
+
 // Client is the interface to interact with the KEB /events API as an HTTP client using OIDC ID token in JWT format.
 type Client interface {
+	// ListEvents returns all events of the instances, it reads all pages
 	ListEvents(instanceIDs []string) ([]EventDTO, error)
+	// QueryEvents returns one page of events, set the NextCursor of the page in the query to get the next one
+	QueryEvents(query EventQuery) (EventPage, error)
 }
 
 type client struct {
@@ -54,17 +112,31 @@ func NewClient(url string, httpClient *http.Client) Client {
 
 // ListEvents
 func (c *client) ListEvents(instanceIDs []string) ([]EventDTO, error) {
-	var events []EventDTO
+	events := make([]EventDTO, 0)
+	query := EventQuery{EventFilter: EventFilter{InstanceIDs: instanceIDs}}
+	for {
+		page, err := c.QueryEvents(query)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, page.Data...)
+		if page.NextCursor == nil {
+			return events, nil
+		}
+		query.After = page.NextCursor
+	}
+}
+
+// QueryEvents
+func (c *client) QueryEvents(query EventQuery) (page EventPage, err error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/events", c.url), nil)
 	if err != nil {
-		return events, fmt.Errorf("while creating request: %v", err)
+		return page, fmt.Errorf("while creating request: %v", err)
 	}
-	q := req.URL.Query()
-	q.Add("instance_ids", strings.Join(instanceIDs, ","))
-	req.URL.RawQuery = q.Encode()
+	req.URL.RawQuery = query.values().Encode()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return events, fmt.Errorf("while calling %s: %v", req.URL.String(), err)
+		return page, fmt.Errorf("while calling %s: %v", req.URL.String(), err)
 	}
 
 	// Drain response body and close, return error to context if there isn't any.
@@ -80,15 +152,51 @@ func (c *client) ListEvents(instanceIDs []string) ([]EventDTO, error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return events, fmt.Errorf("calling %s returned %d (%s) status", req.URL.String(), resp.StatusCode, resp.Status)
+		return page, fmt.Errorf("calling %s returned %d (%s) status", req.URL.String(), resp.StatusCode, resp.Status)
 	}
 
 	decoder := json.NewDecoder(resp.Body)
-	err = decoder.Decode(&events)
+	err = decoder.Decode(&page)
 	if err != nil {
-		return events, fmt.Errorf("while decoding response body: %v", err)
+		return page, fmt.Errorf("while decoding response body: %v", err)
+	}
+	return page, nil
+}
+
+func (q EventQuery) values() url.Values {
+	values := url.Values{}
+	setList := func(key string, list []string) {
+		if len(list) > 0 {
+			values.Set(key, strings.Join(list, ","))
+		}
+	}
+	setList(InstanceIDsParam, q.InstanceIDs)
+	setList(RuntimeIDsParam, q.RuntimeIDs)
+	setList(OperationIDsParam, q.OperationIDs)
+	levels := make([]string, 0, len(q.Levels))
+	for _, level := range q.Levels {
+		levels = append(levels, string(level))
+	}
+	setList(LevelsParam, levels)
+	if !q.From.IsZero() {
+		values.Set(FromParam, q.From.Format(time.RFC3339Nano))
+	}
+	if !q.To.IsZero() {
+		values.Set(ToParam, q.To.Format(time.RFC3339Nano))
+	}
+	if q.MessageContains != "" {
+		values.Set(MessageParam, q.MessageContains)
+	}
+	// the limit is always set, so the API returns the page
+	limit := q.Limit
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+	values.Set(LimitParam, strconv.Itoa(limit))
+	if q.After != nil {
+		values.Set(CursorParam, q.After.String())
 	}
-	return events, nil
+	return values
 }
 
 func drainResponseBody(body io.Reader) error {
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_QueryEvents(t *testing.T) {
+	t.Run("test request URL and response are correct", func(t *testing.T) {
+		// given
+		from := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+		cursor := &Cursor{CreatedAt: from.Add(time.Minute), ID: "ev-1"}
+		query := EventQuery{
+			EventFilter: EventFilter{
+				InstanceIDs:     []string{"inst-1", "inst-2"},
+				OperationIDs:    []string{"op-1"},
+				Levels:          []EventLevel{WarningEventLevel, ErrorEventLevel},
+				From:            from,
+				To:              from.Add(time.Hour),
+				MessageContains: "failed",
+				After:           cursor,
+				Limit:           10,
+			},
+			RuntimeIDs: []string{"runtime-1"},
+		}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/events", r.URL.Path)
+			params := r.URL.Query()
+			assert.Equal(t, "inst-1,inst-2", params.Get(InstanceIDsParam))
+			assert.Equal(t, "runtime-1", params.Get(RuntimeIDsParam))
+			assert.Equal(t, "op-1", params.Get(OperationIDsParam))
+			assert.Equal(t, "warning,error", params.Get(LevelsParam))
+			assert.Equal(t, "2023-01-01T10:00:00Z", params.Get(FromParam))
+			assert.Equal(t, "2023-01-01T11:00:00Z", params.Get(ToParam))
+			assert.Equal(t, "failed", params.Get(MessageParam))
+			assert.Equal(t, "10", params.Get(LimitParam))
+			assert.Equal(t, cursor.String(), params.Get(CursorParam))
+
+			respondEvents(t, w, []EventDTO{fixEvent("ev-2", from)}, CursorOf(fixEvent("ev-2", from)))
+		}))
+		defer ts.Close()
+		client := NewClient(ts.URL, http.DefaultClient)
+
+		// when
+		page, err := client.QueryEvents(query)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 1, page.Count)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, "ev-2", page.Data[0].ID)
+		assert.Equal(t, WarningEventLevel, page.Data[0].Level)
+		assert.Equal(t, "Create_Runtime", page.Data[0].Fields[StepField])
+		require.NotNil(t, page.NextCursor)
+		assert.Equal(t, "ev-2", page.NextCursor.ID)
+		assert.True(t, from.Equal(page.NextCursor.CreatedAt))
+	})
+
+	t.Run("should return error when server fails", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+		client := NewClient(ts.URL, http.DefaultClient)
+
+		// when
+		_, err := client.QueryEvents(EventQuery{})
+
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_ListEvents(t *testing.T) {
+	// given
+	createdAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	all := []EventDTO{fixEvent("ev-1", createdAt), fixEvent("ev-2", createdAt), fixEvent("ev-3", createdAt.Add(time.Second))}
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "inst-1", r.URL.Query().Get(InstanceIDsParam))
+		assert.Equal(t, strconv.Itoa(DefaultLimit), r.URL.Query().Get(LimitParam))
+		switch r.URL.Query().Get(CursorParam) {
+		case "":
+			respondEvents(t, w, all[:2], CursorOf(all[1]))
+		case CursorOf(all[1]).String():
+			respondEvents(t, w, all[2:], nil)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+	client := NewClient(ts.URL, http.DefaultClient)
+
+	// when
+	events, err := client.ListEvents([]string{"inst-1"})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	require.Len(t, events, 3)
+	for i, ev := range events {
+		assert.Equal(t, all[i].ID, ev.ID)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	// given
+	cursor := Cursor{CreatedAt: time.Date(2023, 1, 1, 10, 0, 0, 123456000, time.UTC), ID: "ev-1"}
+
+	// when
+	parsed, err := ParseCursor(cursor.String())
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, cursor.ID, parsed.ID)
+	assert.True(t, parsed.Precedes(fixEvent("ev-2", cursor.CreatedAt)))
+	assert.True(t, parsed.Precedes(fixEvent("ev-0", cursor.CreatedAt.Add(time.Microsecond))))
+	assert.False(t, parsed.Precedes(fixEvent("ev-1", cursor.CreatedAt)))
+	assert.False(t, parsed.Precedes(fixEvent("ev-2", cursor.CreatedAt.Add(-time.Microsecond))))
+
+	_, err = ParseCursor("invalid")
+	assert.Error(t, err)
+}
+
+func fixEvent(id string, createdAt time.Time) EventDTO {
+	instanceID := "inst-1"
+	return EventDTO{
+		ID:         id,
+		Level:      WarningEventLevel,
+		InstanceID: &instanceID,
+		Message:    "step Create_Runtime failed retries",
+		Fields:     Fields{StepField: "Create_Runtime"},
+		CreatedAt:  createdAt,
+	}
+}
+
+func respondEvents(t *testing.T, w http.ResponseWriter, events []EventDTO, next *Cursor) {
+	data, err := json.Marshal(EventPage{Data: events, Count: len(events), NextCursor: next})
+	require.NoError(t, err)
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	require.NoError(t, err)
+}
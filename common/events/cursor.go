@@ -0,0 +1,64 @@
+package events
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cursor points to the last event of the page, the next page starts with the event following it.
+// Events are ordered by the creation time and then by the ID, so the cursor is stable when new events are added.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorOf returns the cursor pointing to the given event
+func CursorOf(event EventDTO) *Cursor {
+	return &Cursor{CreatedAt: event.CreatedAt, ID: event.ID}
+}
+
+// ParseCursor parses the value returned by the String method
+func ParseCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	createdAt, id, found := strings.Cut(string(data), "/")
+	if !found || id == "" {
+		return nil, fmt.Errorf("invalid cursor: the event ID is missing")
+	}
+	c := &Cursor{ID: id}
+	c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return c, nil
+}
+
+// Precedes returns true if the event is after the cursor
+func (c Cursor) Precedes(event EventDTO) bool {
+	if event.CreatedAt.Equal(c.CreatedAt) {
+		return event.ID > c.ID
+	}
+	return event.CreatedAt.After(c.CreatedAt)
+}
+
+// String returns the opaque value of the cursor which can be used in URLs
+func (c Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "/" + c.ID))
+}
+
+func (c Cursor) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Cursor) UnmarshalText(text []byte) error {
+	parsed, err := ParseCursor(string(text))
+	if err != nil {
+		return err
+	}
+	*c = *parsed
+	return nil
+}
@@ -83,6 +83,8 @@ type RuntimeTarget struct {
 	Shoot string `json:"shoot,omitempty"`
 	// InstanceID is used to identify an instance by it's instance ID
 	InstanceID string `json:"instanceID,omitempty"`
+	// Labels is used to match runtimes having all given instance labels. E.g. {"team": "payments"}
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 type Type string
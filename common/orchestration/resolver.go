@@ -195,6 +195,11 @@ func (resolver *GardenerRuntimeResolver) resolveRuntimeTarget(rt RuntimeTarget,
 			}
 		}
 
+		// Perform match against instance labels
+		if !runtime.MatchLabels(r.Labels, rt.Labels) {
+			continue
+		}
+
 		// Perform match against GlobalAccount regexp
 		if rt.GlobalAccount != "" {
 			matched, err := regexp.MatchString(rt.GlobalAccount, shoot.GetLabels()[globalAccountLabel])
@@ -184,6 +184,32 @@ func TestResolver_Resolve(t *testing.T) {
 			},
 			ExpectedRuntimes: []expectedRuntime{expectedRuntime1},
 		},
+		"IncludeLabels": {
+			Target: TargetSpec{
+				Include: []RuntimeTarget{
+					{
+						Labels: map[string]string{"team": "payments"},
+					},
+				},
+				Exclude: nil,
+			},
+			ExpectedRuntimes: []expectedRuntime{expectedRuntime2, expectedRuntime3},
+		},
+		"IncludeLabelsExcludeLabel": {
+			Target: TargetSpec{
+				Include: []RuntimeTarget{
+					{
+						Labels: map[string]string{"team": "payments"},
+					},
+				},
+				Exclude: []RuntimeTarget{
+					{
+						Labels: map[string]string{"env": "staging"},
+					},
+				},
+			},
+			ExpectedRuntimes: []expectedRuntime{expectedRuntime2},
+		},
 	} {
 		t.Run(tn, func(t *testing.T) {
 			// when
@@ -270,8 +296,8 @@ var (
 	shoot11 = fixShoot(11, globalAccountID1, region1)
 
 	runtime1  = fixRuntimeDTO(1, globalAccountID1, plan2, runtimeOpState{provision: string(brokerapi.Succeeded)})
-	runtime2  = fixRuntimeDTO(2, globalAccountID1, plan1, runtimeOpState{provision: string(brokerapi.Succeeded)})
-	runtime3  = fixRuntimeDTO(3, globalAccountID2, plan1, runtimeOpState{provision: string(brokerapi.Succeeded)})
+	runtime2  = withLabels(fixRuntimeDTO(2, globalAccountID1, plan1, runtimeOpState{provision: string(brokerapi.Succeeded)}), map[string]string{"team": "payments"})
+	runtime3  = withLabels(fixRuntimeDTO(3, globalAccountID2, plan1, runtimeOpState{provision: string(brokerapi.Succeeded)}), map[string]string{"team": "payments", "env": "staging"})
 	runtime4  = fixRuntimeDTO(4, globalAccountID3, plan1, runtimeOpState{provision: string(brokerapi.Succeeded), deprovision: string(brokerapi.InProgress)})
 	runtime5  = fixRuntimeDTO(5, globalAccountID3, plan1, runtimeOpState{provision: string(brokerapi.Failed)})
 	runtime6  = fixRuntimeDTO(6, globalAccountID3, plan2, runtimeOpState{provision: string(brokerapi.InProgress)})
@@ -318,6 +344,11 @@ type runtimeOpState struct {
 	unsuspension string
 }
 
+func withLabels(rt runtime.RuntimeDTO, labels map[string]string) runtime.RuntimeDTO {
+	rt.Labels = labels
+	return rt
+}
+
 func fixRuntimeDTO(id int, globalAccountID, planName string, state runtimeOpState) runtime.RuntimeDTO {
 	rt := runtime.RuntimeDTO{
 		InstanceID:      fmt.Sprintf("instance-id-%d", id),
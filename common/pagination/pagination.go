@@ -30,7 +30,7 @@ func ConvertPageAndPageSizeToOffset(pageSize, page int) int {
 	if page < 2 {
 		return 0
 	} else {
-		return page*pageSize - 1
+		return (page - 1) * pageSize
 	}
 }
 
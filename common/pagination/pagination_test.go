@@ -0,0 +1,48 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertPageAndPageSizeToOffset(t *testing.T) {
+	for name, tc := range map[string]struct {
+		pageSize       int
+		page           int
+		expectedOffset int
+	}{
+		"first page":            {pageSize: 10, page: 1, expectedOffset: 0},
+		"page smaller than one": {pageSize: 10, page: 0, expectedOffset: 0},
+		"second page":           {pageSize: 4, page: 2, expectedOffset: 4},
+		"third page":            {pageSize: 10, page: 3, expectedOffset: 20},
+		"page size of one":      {pageSize: 1, page: 5, expectedOffset: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			offset := ConvertPageAndPageSizeToOffset(tc.pageSize, tc.page)
+
+			// then
+			assert.Equal(t, tc.expectedOffset, offset)
+		})
+	}
+}
+
+func TestConvertPageSizeAndOrderedColumnToSQL(t *testing.T) {
+	t.Run("should skip the rows of previous pages", func(t *testing.T) {
+		// when
+		sql, err := ConvertPageSizeAndOrderedColumnToSQL(10, 3, "created_at")
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, "ORDER BY created_at LIMIT 10 OFFSET 20", sql)
+	})
+
+	t.Run("should return error for page smaller than one", func(t *testing.T) {
+		// when
+		_, err := ConvertPageSizeAndOrderedColumnToSQL(10, 0, "created_at")
+
+		// then
+		assert.Error(t, err)
+	})
+}
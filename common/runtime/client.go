@@ -122,6 +122,9 @@ func setQuery(url *url.URL, params ListParameters) {
 	for _, s := range params.States {
 		query.Add(StateParam, string(s))
 	}
+	for key, value := range params.Labels {
+		query.Add(LabelParam, key+"="+value)
+	}
 	url.RawQuery = query.Encode()
 }
 
@@ -1,6 +1,8 @@
 package runtime
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
@@ -30,6 +32,8 @@ const (
 	StateUpdating State = "updating"
 	// StateSuspended means that the trial runtime is suspended (i.e. deprovisioned).
 	StateSuspended State = "suspended"
+	// StatePaused means that the last runtime operation is in progress, but its processing is paused by an operator.
+	StatePaused State = "paused"
 	// AllState is a virtual state only used as query parameter in ListParameters to indicate "include all runtimes, which are excluded by default without state filters".
 	AllState State = "all"
 )
@@ -54,6 +58,7 @@ type RuntimeDTO struct {
 	KymaVersion                 string                         `json:"kymaVersion,omitempty"`
 	KymaConfig                  *gqlschema.KymaConfigInput     `json:"kymaConfig,omitempty"`
 	ClusterConfig               *gqlschema.GardenerConfigInput `json:"clusterConfig,omitempty"`
+	Labels                      map[string]string              `json:"labels,omitempty"`
 }
 
 type RuntimeStatus struct {
@@ -100,6 +105,7 @@ type Operation struct {
 	FinishedStages               []string      `json:"finishedStages"`
 	ExecutedButNotCompletedSteps []string      `json:"executedButNotCompletedSteps,omitempty"`
 	RuntimeVersion               string        `json:"runtimeVersion"`
+	Paused                       bool          `json:"paused,omitempty"`
 }
 
 type RuntimesPage struct {
@@ -121,6 +127,7 @@ const (
 	KymaConfigParam      = "kyma_config"
 	ClusterConfigParam   = "cluster_config"
 	ExpiredParam         = "expired"
+	LabelParam           = "label"
 )
 
 type OperationDetail string
@@ -159,10 +166,38 @@ type ListParameters struct {
 	States []State
 	// Expired parameter filters runtimes to show only expired ones.
 	Expired bool
+	// Labels parameter filters runtimes having all specified labels
+	Labels map[string]string
 	// Events parameter fetches tracing events per instance
 	Events string
 }
 
+// ParseLabelSelectors converts label query parameters in the key=value format to a map
+func ParseLabelSelectors(selectors []string) (map[string]string, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(selectors))
+	for _, selector := range selectors {
+		key, value, found := strings.Cut(selector, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("label selector %q must have the key=value format", selector)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// MatchLabels returns true if the labels contain all key-value pairs of the selector
+func MatchLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, found := labels[key]; !found || v != value {
+			return false
+		}
+	}
+	return true
+}
+
 func (rt RuntimeDTO) LastOperation() Operation {
 	op := Operation{}
 
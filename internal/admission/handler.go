@@ -0,0 +1,84 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/sirupsen/logrus"
+)
+
+// DryRunRequest contains the request payload evaluated against the admission rules
+type DryRunRequest struct {
+	Operation      string                             `json:"operation"`
+	PlanID         string                             `json:"plan_id"`
+	PlatformRegion string                             `json:"platform_region"`
+	Parameters     internal.ProvisioningParametersDTO `json:"parameters"`
+	Context        internal.ERSContext                `json:"context"`
+}
+
+type DryRunResponse struct {
+	Allowed bool     `json:"allowed"`
+	Rules   []Result `json:"rules"`
+}
+
+type Handler struct {
+	policy    Policy
+	planNames map[string]string
+	log       logrus.FieldLogger
+}
+
+// NewHandler creates the dry-run handler, planNames maps plan IDs to plan names used in the rules
+func NewHandler(policy Policy, planNames map[string]string, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		policy:    policy,
+		planNames: planNames,
+		log:       log.WithField("service", "AdmissionHandler"),
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/admission/dry-run", h.dryRun).Methods(http.MethodPost)
+}
+
+func (h *Handler) dryRun(w http.ResponseWriter, r *http.Request) {
+	request := DryRunRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.log.Errorf("while decoding request body: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while decoding request body: %w", err))
+		return
+	}
+	switch request.Operation {
+	case "":
+		request.Operation = OperationProvision
+	case OperationProvision, OperationUpdate:
+	default:
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("operation must be %s or %s", OperationProvision, OperationUpdate))
+		return
+	}
+
+	results, err := h.policy.Evaluate(Input{
+		Operation:      request.Operation,
+		PlanID:         request.PlanID,
+		PlanName:       h.planNames[request.PlanID],
+		PlatformRegion: request.PlatformRegion,
+		Parameters:     request.Parameters,
+		ERSContext:     request.Context,
+	})
+	if err != nil {
+		h.log.Errorf("while evaluating admission rules: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while evaluating admission rules: %w", err))
+		return
+	}
+
+	response := DryRunResponse{Allowed: true, Rules: results}
+	for _, result := range results {
+		if h.policy.Rejects(result) {
+			response.Allowed = false
+		}
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,40 @@
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_DryRun(t *testing.T) {
+	// given
+	policy, err := ReadPolicyFromFile("testdata/rules.yaml")
+	require.NoError(t, err)
+	handler := NewHandler(policy, map[string]string{"azure-lite-plan-id": "azure_lite"}, logrus.New())
+	router := mux.NewRouter()
+	handler.AttachRoutes(router)
+
+	// when
+	body := `{"plan_id": "azure-lite-plan-id", "platform_region": "cf-eu20", "parameters": {"name": "cluster"}, "context": {"globalaccount_id": "global-account-id"}}`
+	req, err := http.NewRequest(http.MethodPost, "/admission/dry-run", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// then
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response DryRunResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.False(t, response.Allowed)
+	assert.Equal(t, []Result{
+		{Rule: "big-machines-for-premium-accounts"},
+		{Rule: "no-azure-lite-in-eu20", Fired: true, Message: "The azure_lite plan is not available in the cf-eu20 region"},
+	}, response.Rules)
+}
@@ -0,0 +1,191 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	OperationProvision = "provision"
+	OperationUpdate    = "update"
+)
+
+// Rule denies the request when the CEL expression evaluates to true. The expression can use the following variables:
+// operation (provision or update), planID, plan (plan name), platformRegion, parameters (provisioning parameters)
+// and context (ERS context). Parameters and context fields use the names from the OSB request, e.g. parameters.machineType.
+type Rule struct {
+	Name       string `yaml:"name" json:"name"`
+	Expression string `yaml:"expression" json:"expression"`
+	Message    string `yaml:"message" json:"message"`
+}
+
+// Input is the request evaluated against the rules
+type Input struct {
+	Operation      string
+	PlanID         string
+	PlanName       string
+	PlatformRegion string
+	Parameters     internal.ProvisioningParametersDTO
+	ERSContext     internal.ERSContext
+}
+
+// Result describes the evaluation of a single rule
+type Result struct {
+	Rule    string `json:"rule"`
+	Fired   bool   `json:"fired"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type compiledRule struct {
+	Rule
+	program cel.Program
+}
+
+// Policy is a set of compiled rules, the zero value admits all requests.
+// A rule which cannot be evaluated rejects the request, unless the policy fails open.
+type Policy struct {
+	rules    []compiledRule
+	failOpen bool
+}
+
+func ReadPolicyFromFile(filename string) (Policy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Policy{}, fmt.Errorf("while reading %s file with admission rules: %w", filename, err)
+	}
+	var config struct {
+		Rules    []Rule `yaml:"rules"`
+		FailOpen bool   `yaml:"failOpen"`
+	}
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return Policy{}, fmt.Errorf("while unmarshalling a file with admission rules: %w", err)
+	}
+	policy, err := NewPolicy(config.Rules)
+	if err != nil {
+		return Policy{}, err
+	}
+	return policy.WithFailOpen(config.FailOpen), nil
+}
+
+func NewPolicy(rules []Rule) (Policy, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("operation", cel.StringType),
+		cel.Variable("planID", cel.StringType),
+		cel.Variable("plan", cel.StringType),
+		cel.Variable("platformRegion", cel.StringType),
+		cel.Variable("parameters", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("context", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return Policy{}, fmt.Errorf("while creating CEL environment: %w", err)
+	}
+
+	policy := Policy{}
+	for _, rule := range rules {
+		ast, issues := env.Compile(rule.Expression)
+		if issues != nil && issues.Err() != nil {
+			return Policy{}, fmt.Errorf("while compiling rule %s: %w", rule.Name, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return Policy{}, fmt.Errorf("rule %s must evaluate to bool, got %s", rule.Name, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return Policy{}, fmt.Errorf("while creating program for rule %s: %w", rule.Name, err)
+		}
+		policy.rules = append(policy.rules, compiledRule{Rule: rule, program: program})
+	}
+	return policy, nil
+}
+
+// WithFailOpen returns the policy which admits requests that cannot be evaluated by a rule
+func (p Policy) WithFailOpen(failOpen bool) Policy {
+	p.failOpen = failOpen
+	return p
+}
+
+// Evaluate runs all rules. A rule which cannot be evaluated, e.g. uses a parameter not present in the request, does not fire, the error is set instead.
+func (p Policy) Evaluate(input Input) ([]Result, error) {
+	activation, err := input.activation()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(p.rules))
+	for _, rule := range p.rules {
+		result := Result{Rule: rule.Name}
+		out, _, err := rule.program.Eval(activation)
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case out.Value() == true:
+			result.Fired = true
+			result.Message = rule.Message
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Rejects returns true if the result of the rule rejects the request
+func (p Policy) Rejects(result Result) bool {
+	return result.Fired || (result.Error != "" && !p.failOpen)
+}
+
+// Admit returns an error with messages of all rules rejecting the request
+func (p Policy) Admit(input Input) error {
+	results, err := p.Evaluate(input)
+	if err != nil {
+		return fmt.Errorf("while evaluating admission rules: %w", err)
+	}
+	var messages []string
+	for _, result := range results {
+		switch {
+		case result.Fired:
+			messages = append(messages, result.Message)
+		case p.Rejects(result):
+			messages = append(messages, fmt.Sprintf("the request cannot be checked by the admission rule %s", result.Rule))
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+func (i Input) activation() (map[string]interface{}, error) {
+	parameters, err := toMap(i.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("while converting parameters: %w", err)
+	}
+	ersContext, err := toMap(i.ERSContext)
+	if err != nil {
+		return nil, fmt.Errorf("while converting context: %w", err)
+	}
+	return map[string]interface{}{
+		"operation":      i.Operation,
+		"planID":         i.PlanID,
+		"plan":           i.PlanName,
+		"platformRegion": i.PlatformRegion,
+		"parameters":     parameters,
+		"context":        ersContext,
+	}, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
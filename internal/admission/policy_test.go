@@ -0,0 +1,128 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Admit(t *testing.T) {
+	policy, err := ReadPolicyFromFile("testdata/rules.yaml")
+	require.NoError(t, err)
+
+	for tn, tc := range map[string]struct {
+		input         Input
+		expectedError string
+	}{
+		"big machine for premium global account": {
+			input: Input{
+				Operation:  OperationProvision,
+				PlanName:   "aws",
+				Parameters: internal.ProvisioningParametersDTO{MachineType: ptr.String("m5.8xlarge")},
+				ERSContext: internal.ERSContext{GlobalAccountID: "premium-global-account-id"},
+			},
+		},
+		"big machine for other global account": {
+			input: Input{
+				Operation:  OperationUpdate,
+				PlanName:   "aws",
+				Parameters: internal.ProvisioningParametersDTO{MachineType: ptr.String("m5.8xlarge")},
+				ERSContext: internal.ERSContext{GlobalAccountID: "global-account-id"},
+			},
+			expectedError: "The m5.8xlarge machine type is available only for premium global accounts",
+		},
+		"machine type not provided": {
+			input: Input{
+				Operation:  OperationProvision,
+				PlanName:   "aws",
+				ERSContext: internal.ERSContext{GlobalAccountID: "global-account-id"},
+			},
+		},
+		"azure_lite in restricted region": {
+			input: Input{
+				Operation:      OperationProvision,
+				PlanName:       "azure_lite",
+				PlatformRegion: "cf-eu20",
+			},
+			expectedError: "The azure_lite plan is not available in the cf-eu20 region",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			err := policy.Admit(tc.input)
+
+			// then
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	// given
+	policy, err := NewPolicy([]Rule{
+		{Name: "fired", Expression: `operation == "provision"`, Message: "rejected"},
+		{Name: "not-fired", Expression: `plan == "trial"`, Message: "trial rejected"},
+		{Name: "failed", Expression: `parameters.region == "westeurope"`, Message: "region rejected"},
+	})
+	require.NoError(t, err)
+
+	// when
+	results, err := policy.Evaluate(Input{Operation: OperationProvision, PlanName: "azure"})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, Result{Rule: "fired", Fired: true, Message: "rejected"}, results[0])
+	assert.Equal(t, Result{Rule: "not-fired"}, results[1])
+	assert.False(t, results[2].Fired)
+	assert.NotEmpty(t, results[2].Error)
+}
+
+func TestPolicy_AdmitFailedEvaluation(t *testing.T) {
+	rules := []Rule{{Name: "westeurope-only", Expression: `parameters.region != "westeurope"`, Message: "Only the westeurope region is allowed"}}
+	input := Input{Operation: OperationProvision, PlanName: "azure"}
+
+	t.Run("should reject request which cannot be evaluated", func(t *testing.T) {
+		// given
+		policy, err := NewPolicy(rules)
+		require.NoError(t, err)
+
+		// when
+		err = policy.Admit(input)
+
+		// then
+		assert.EqualError(t, err, "the request cannot be checked by the admission rule westeurope-only")
+	})
+
+	t.Run("should admit request which cannot be evaluated when the policy fails open", func(t *testing.T) {
+		// given
+		policy, err := ReadPolicyFromFile("testdata/rules-fail-open.yaml")
+		require.NoError(t, err)
+
+		// when
+		err = policy.Admit(input)
+
+		// then
+		assert.NoError(t, err)
+		assert.EqualError(t, policy.Admit(Input{Parameters: internal.ProvisioningParametersDTO{Region: ptr.String("northeurope")}}), "Only the westeurope region is allowed")
+	})
+}
+
+func TestNewPolicy(t *testing.T) {
+	t.Run("should reject invalid expression", func(t *testing.T) {
+		_, err := NewPolicy([]Rule{{Name: "invalid", Expression: `plan ==`}})
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject expression not evaluated to bool", func(t *testing.T) {
+		_, err := NewPolicy([]Rule{{Name: "string", Expression: `plan`}})
+		assert.EqualError(t, err, "rule string must evaluate to bool, got string")
+	})
+}
@@ -37,7 +37,7 @@ func TestRuntimeInfoHandlerSuccess(t *testing.T) {
 		},
 		"instances without operations": {
 			instances: []internal.Instance{
-				fixInstance(1), fixInstance(2), fixInstance(2),
+				fixInstance(1), fixInstance(2),
 			},
 		},
 		"instances without service and plan name should have defaults": {
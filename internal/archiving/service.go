@@ -0,0 +1,113 @@
+package archiving
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	// RetentionPeriod is the time after the deprovisioning after which operations of the instance are archived
+	RetentionPeriod time.Duration `envconfig:"default=2160h"`
+	BatchSize       int           `envconfig:"default=100"`
+	DryRun          bool          `envconfig:"default=true"`
+}
+
+// Result describes instances processed by the archiving
+type Result struct {
+	Archived int
+	Failed   int
+}
+
+// Service moves operations of instances deprovisioned longer ago than the retention period to the archive.
+// The last deprovisioning operation of the instance stays in the operations table as the summary of the instance.
+type Service struct {
+	cfg        Config
+	operations storage.Operations
+	log        logrus.FieldLogger
+}
+
+func NewService(cfg Config, operations storage.Operations, log logrus.FieldLogger) *Service {
+	return &Service{
+		cfg:        cfg,
+		operations: operations,
+		log:        log,
+	}
+}
+
+func (s *Service) Run() (Result, error) {
+	result := Result{}
+	finishedBefore := time.Now().Add(-s.cfg.RetentionPeriod)
+	failed := make(map[string]bool)
+	for {
+		instanceIDs, err := s.operations.ListInstancesToArchive(finishedBefore, s.cfg.BatchSize+len(failed))
+		if err != nil {
+			return result, fmt.Errorf("while listing instances to archive: %w", err)
+		}
+		if s.cfg.DryRun {
+			// nothing is archived, so the next batch would contain the same instances
+			for _, instanceID := range instanceIDs {
+				s.log.Infof("operations of instance %s would be archived", instanceID)
+			}
+			result.Archived = len(instanceIDs)
+			return result, nil
+		}
+
+		processed := 0
+		for _, instanceID := range instanceIDs {
+			if failed[instanceID] {
+				continue
+			}
+			processed++
+			if err := s.archive(instanceID); err != nil {
+				s.log.Errorf("unable to archive operations of instance %s: %s", instanceID, err)
+				failed[instanceID] = true
+				result.Failed++
+				continue
+			}
+			result.Archived++
+		}
+		if processed == 0 {
+			return result, nil
+		}
+		s.log.Infof("archived operations of %d instances", result.Archived)
+	}
+}
+
+func (s *Service) archive(instanceID string) error {
+	operations, err := s.operations.ListOperationsByInstanceID(instanceID)
+	if err != nil {
+		return fmt.Errorf("while listing operations: %w", err)
+	}
+	summary := SummaryOperation(operations)
+	if summary == nil {
+		return fmt.Errorf("instance has no operations")
+	}
+	return s.operations.ArchiveOperations(instanceID, summary.ID)
+}
+
+// SummaryOperation returns the last succeeded deprovisioning operation which removed the instance,
+// or the last operation if there is no such operation
+func SummaryOperation(operations []internal.Operation) *internal.Operation {
+	var summary, last *internal.Operation
+	for i := range operations {
+		op := &operations[i]
+		if last == nil || op.CreatedAt.After(last.CreatedAt) {
+			last = op
+		}
+		if op.Type != internal.OperationTypeDeprovision || op.State != domain.Succeeded || op.Temporary {
+			continue
+		}
+		if summary == nil || op.CreatedAt.After(summary.CreatedAt) {
+			summary = op
+		}
+	}
+	if summary == nil {
+		return last
+	}
+	return summary
+}
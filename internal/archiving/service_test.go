@@ -0,0 +1,99 @@
+package archiving
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Run(t *testing.T) {
+	old := time.Now().Add(-100 * 24 * time.Hour)
+
+	fixOperations := func(t *testing.T, operations storage.Operations, instanceID string, deprovisionedAt time.Time) {
+		provisioning := fixture.FixProvisioningOperation(instanceID+"-provisioning", instanceID)
+		provisioning.CreatedAt, provisioning.UpdatedAt = deprovisionedAt.Add(-2*time.Hour), deprovisionedAt.Add(-2*time.Hour)
+		require.NoError(t, operations.InsertOperation(provisioning))
+		update := fixture.FixUpdatingOperation(instanceID+"-update", instanceID)
+		update.CreatedAt, update.UpdatedAt = deprovisionedAt.Add(-time.Hour), deprovisionedAt.Add(-time.Hour)
+		update.State = domain.Succeeded
+		require.NoError(t, operations.InsertUpdatingOperation(update))
+		deprovisioning := fixture.FixDeprovisioningOperation(instanceID+"-deprovisioning", instanceID)
+		deprovisioning.CreatedAt, deprovisioning.UpdatedAt = deprovisionedAt, deprovisionedAt
+		deprovisioning.State = domain.Succeeded
+		require.NoError(t, operations.InsertDeprovisioningOperation(deprovisioning))
+	}
+
+	t.Run("should archive operations of instances deprovisioned before the retention period", func(t *testing.T) {
+		// given
+		operations := storage.NewMemoryStorage().Operations()
+		fixOperations(t, operations, "old-1", old)
+		fixOperations(t, operations, "old-2", old.Add(time.Minute))
+		fixOperations(t, operations, "recent", time.Now().Add(-time.Hour))
+		svc := NewService(Config{RetentionPeriod: 90 * 24 * time.Hour, BatchSize: 1}, operations, logrus.New())
+
+		// when
+		result, err := svc.Run()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Result{Archived: 2}, result)
+
+		remaining, err := operations.ListOperationsByInstanceID("old-1")
+		require.NoError(t, err)
+		require.Len(t, remaining, 1)
+		assert.Equal(t, "old-1-deprovisioning", remaining[0].ID)
+		archived, err := operations.ListArchivedOperationsByInstanceIDs([]string{"old-1", "old-2", "recent"})
+		require.NoError(t, err)
+		assert.Len(t, archived, 6)
+		// all operations of the recent instance stay in the operations table, the update operation included
+		remaining, err = operations.ListOperationsByInstanceID("recent")
+		require.NoError(t, err)
+		var remainingIDs []string
+		for _, op := range remaining {
+			remainingIDs = append(remainingIDs, op.ID)
+		}
+		assert.Equal(t, []string{"recent-deprovisioning", "recent-update", "recent-provisioning"}, remainingIDs)
+
+		// when
+		result, err = svc.Run()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Result{}, result)
+	})
+
+	t.Run("should not archive operations in the dry run mode", func(t *testing.T) {
+		// given
+		operations := storage.NewMemoryStorage().Operations()
+		fixOperations(t, operations, "old-1", old)
+		svc := NewService(Config{RetentionPeriod: 90 * 24 * time.Hour, BatchSize: 10, DryRun: true}, operations, logrus.New())
+
+		// when
+		result, err := svc.Run()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Result{Archived: 1}, result)
+		archived, err := operations.ListArchivedOperationsByInstanceIDs([]string{"old-1"})
+		require.NoError(t, err)
+		assert.Empty(t, archived)
+	})
+}
+
+func TestSummaryOperation(t *testing.T) {
+	now := time.Now()
+	suspension := internal.Operation{ID: "suspension", Type: internal.OperationTypeDeprovision, State: domain.Succeeded, Temporary: true, CreatedAt: now.Add(-time.Hour)}
+	deprovisioning := internal.Operation{ID: "deprovisioning", Type: internal.OperationTypeDeprovision, State: domain.Succeeded, CreatedAt: now.Add(-2 * time.Hour)}
+	provisioning := internal.Operation{ID: "provisioning", Type: internal.OperationTypeProvision, State: domain.Failed, CreatedAt: now.Add(-3 * time.Hour)}
+
+	assert.Equal(t, "deprovisioning", SummaryOperation([]internal.Operation{provisioning, deprovisioning, suspension}).ID)
+	assert.Equal(t, "suspension", SummaryOperation([]internal.Operation{provisioning, suspension}).ID)
+	assert.Nil(t, SummaryOperation(nil))
+}
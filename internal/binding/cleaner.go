@@ -0,0 +1,118 @@
+package binding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExpiredBindingsCleaned is published after every run of the ExpiredBindingsCleaner
+type ExpiredBindingsCleaned struct {
+	Expired int
+	Removed int
+	Failed  int
+	DryRun  bool
+}
+
+type credentialsRevoker interface {
+	Delete(ctx context.Context, instance *internal.Instance, bindingID string) error
+}
+
+// ExpiredBindingsCleaner periodically removes bindings which credentials expired. The service account of the binding
+// is removed from the SKR and the binding is removed from the storage. In the dry run mode expired bindings are only logged.
+type ExpiredBindingsCleaner struct {
+	bindings  storage.Bindings
+	instances storage.Instances
+	revoker   credentialsRevoker
+	publisher event.Publisher
+
+	interval time.Duration
+	dryRun   bool
+	log      logrus.FieldLogger
+}
+
+func NewExpiredBindingsCleaner(bindings storage.Bindings, instances storage.Instances, revoker credentialsRevoker, publisher event.Publisher,
+	interval time.Duration, dryRun bool, log logrus.FieldLogger) *ExpiredBindingsCleaner {
+	return &ExpiredBindingsCleaner{
+		bindings:  bindings,
+		instances: instances,
+		revoker:   revoker,
+		publisher: publisher,
+		interval:  interval,
+		dryRun:    dryRun,
+		log:       log.WithField("service", "ExpiredBindingsCleaner"),
+	}
+}
+
+// Run starts the cleanup loop, it returns when the context is done
+func (c *ExpiredBindingsCleaner) Run(ctx context.Context) {
+	if c.dryRun {
+		c.log.Info("Dry run only - expired bindings are not removed")
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Cleanup(ctx); err != nil {
+			c.log.Errorf("while cleaning expired bindings: %s", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Cleanup removes all bindings expired until now
+func (c *ExpiredBindingsCleaner) Cleanup(ctx context.Context) (ExpiredBindingsCleaned, error) {
+	expired, err := c.bindings.ListExpired(time.Now())
+	if err != nil {
+		return ExpiredBindingsCleaned{}, fmt.Errorf("while listing expired bindings: %w", err)
+	}
+
+	result := ExpiredBindingsCleaned{Expired: len(expired), DryRun: c.dryRun}
+	for _, binding := range expired {
+		logger := c.log.WithFields(logrus.Fields{"instanceID": binding.InstanceID, "bindingID": binding.ID})
+		if c.dryRun {
+			logger.Infof("binding expired at %s", binding.ExpiresAt)
+			continue
+		}
+		if err := c.remove(ctx, binding); err != nil {
+			logger.Errorf("unable to remove expired binding: %s", err)
+			result.Failed++
+			continue
+		}
+		logger.Infof("expired binding removed")
+		result.Removed++
+	}
+	c.log.Infof("Expired bindings: %d, removed: %d, failures: %d", result.Expired, result.Removed, result.Failed)
+	c.publisher.Publish(ctx, result)
+
+	return result, nil
+}
+
+func (c *ExpiredBindingsCleaner) remove(ctx context.Context, binding internal.Binding) error {
+	instance, err := c.instances.GetByID(binding.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		// the runtime is gone together with the service account
+	case err != nil:
+		return fmt.Errorf("while getting instance: %w", err)
+	case instance.RuntimeID != "":
+		if err := c.revoker.Delete(ctx, instance, binding.ID); err != nil {
+			return fmt.Errorf("while revoking credentials: %w", err)
+		}
+	}
+
+	if err := c.bindings.Delete(binding.InstanceID, binding.ID); err != nil {
+		return fmt.Errorf("while deleting binding from the storage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package binding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiredBindingsCleaner(t *testing.T) {
+	t.Run("should remove expired bindings", func(t *testing.T) {
+		// given
+		st := fixStorageWithBindings(t)
+		revoker := &fakeRevoker{}
+		publisher := &fakePublisher{}
+		cleaner := NewExpiredBindingsCleaner(st.Bindings(), st.Instances(), revoker, publisher, time.Minute, false, logrus.New())
+
+		// when
+		result, err := cleaner.Cleanup(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, ExpiredBindingsCleaned{Expired: 2, Removed: 2}, result)
+		assert.Equal(t, []interface{}{result}, publisher.events)
+		assert.Equal(t, []string{"expired"}, revoker.deleted)
+
+		_, err = st.Bindings().Get("instance-id", "expired")
+		assert.True(t, dberr.IsNotFound(err))
+		_, err = st.Bindings().Get("removed-instance-id", "expired")
+		assert.True(t, dberr.IsNotFound(err))
+		_, err = st.Bindings().Get("instance-id", "valid")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should only count expired bindings in dry run", func(t *testing.T) {
+		// given
+		st := fixStorageWithBindings(t)
+		revoker := &fakeRevoker{}
+		cleaner := NewExpiredBindingsCleaner(st.Bindings(), st.Instances(), revoker, &fakePublisher{}, time.Minute, true, logrus.New())
+
+		// when
+		result, err := cleaner.Cleanup(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, ExpiredBindingsCleaned{Expired: 2, DryRun: true}, result)
+		assert.Empty(t, revoker.deleted)
+		bindings, err := st.Bindings().ListExpired(time.Now())
+		require.NoError(t, err)
+		assert.Len(t, bindings, 2)
+	})
+
+	t.Run("should keep binding which credentials cannot be revoked", func(t *testing.T) {
+		// given
+		st := fixStorageWithBindings(t)
+		revoker := &fakeRevoker{err: fmt.Errorf("runtime not reachable")}
+		cleaner := NewExpiredBindingsCleaner(st.Bindings(), st.Instances(), revoker, &fakePublisher{}, time.Minute, false, logrus.New())
+
+		// when
+		result, err := cleaner.Cleanup(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, ExpiredBindingsCleaned{Expired: 2, Removed: 1, Failed: 1}, result)
+		_, err = st.Bindings().Get("instance-id", "expired")
+		assert.NoError(t, err)
+	})
+}
+
+func fixStorageWithBindings(t *testing.T) storage.BrokerStorage {
+	st := storage.NewMemoryStorage()
+	require.NoError(t, st.Instances().Insert(fixture.FixInstance("instance-id")))
+
+	for _, binding := range []internal.Binding{
+		{ID: "expired", InstanceID: "instance-id", ExpiresAt: time.Now().Add(-time.Minute)},
+		{ID: "valid", InstanceID: "instance-id", ExpiresAt: time.Now().Add(time.Hour)},
+		{ID: "expired", InstanceID: "removed-instance-id", ExpiresAt: time.Now().Add(-time.Hour)},
+	} {
+		require.NoError(t, st.Bindings().Insert(binding))
+	}
+	return st
+}
+
+type fakeRevoker struct {
+	err     error
+	deleted []string
+}
+
+func (r *fakeRevoker) Delete(_ context.Context, _ *internal.Instance, bindingID string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.deleted = append(r.deleted, bindingID)
+	return nil
+}
+
+type fakePublisher struct {
+	mu     sync.Mutex
+	events []interface{}
+}
+
+func (p *fakePublisher) Publish(_ context.Context, ev interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, ev)
+}
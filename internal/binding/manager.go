@@ -0,0 +1,219 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/provisioner"
+
+	"gopkg.in/yaml.v2"
+	authv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	Namespace          = "kyma-system"
+	DefaultClusterRole = "cluster-admin"
+	ManagedByLabelKey  = "app.kubernetes.io/managed-by"
+	ManagedByLabel     = "kcp-kyma-environment-broker"
+	BindingIDLabelKey  = "operator.kyma-project.io/binding-id"
+	resourceNamePrefix = "kyma-binding-"
+)
+
+// ServiceAccountBindingsManager issues SKR credentials for service bindings. Every binding gets a dedicated service account
+// bound to the requested cluster role, the returned kubeconfig contains a token of the service account requested with the
+// TokenRequest API, so the token expires after the requested number of seconds.
+type ServiceAccountBindingsManager struct {
+	provisionerClient provisioner.Client
+	k8sClientProvider func(kcfg string) (client.Client, error)
+}
+
+func NewServiceAccountBindingsManager(provisionerClient provisioner.Client, k8sClientProvider func(kcfg string) (client.Client, error)) *ServiceAccountBindingsManager {
+	return &ServiceAccountBindingsManager{
+		provisionerClient: provisionerClient,
+		k8sClientProvider: k8sClientProvider,
+	}
+}
+
+func (m *ServiceAccountBindingsManager) Create(ctx context.Context, instance *internal.Instance, bindingID, clusterRole string, expirationSeconds int64) (string, time.Time, error) {
+	adminKubeconfig, err := m.adminKubeconfig(instance)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	k8sClient, err := m.k8sClientProvider(adminKubeconfig)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("while creating k8s client for the runtime %s: %w", instance.RuntimeID, err)
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: objectMeta(bindingID, Namespace),
+	}
+	if err := k8sClient.Create(ctx, serviceAccount); err != nil && !errors.IsAlreadyExists(err) {
+		return "", time.Time{}, fmt.Errorf("while creating service account %s: %w", serviceAccount.Name, err)
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: objectMeta(bindingID, ""),
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount.Name,
+				Namespace: serviceAccount.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		},
+	}
+	if err := k8sClient.Create(ctx, clusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		return "", time.Time{}, fmt.Errorf("while creating cluster role binding %s: %w", clusterRoleBinding.Name, err)
+	}
+
+	tokenRequest := &authv1.TokenRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccount.Name,
+			Namespace: serviceAccount.Namespace,
+		},
+		Spec: authv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	if err := k8sClient.SubResource("token").Create(ctx, serviceAccount, tokenRequest); err != nil {
+		return "", time.Time{}, fmt.Errorf("while requesting token for service account %s: %w", serviceAccount.Name, err)
+	}
+
+	kubeconfig, err := buildKubeconfig(adminKubeconfig, serviceAccount.Name, tokenRequest.Status.Token)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return kubeconfig, tokenRequest.Status.ExpirationTimestamp.Time, nil
+}
+
+func (m *ServiceAccountBindingsManager) Delete(ctx context.Context, instance *internal.Instance, bindingID string) error {
+	adminKubeconfig, err := m.adminKubeconfig(instance)
+	if err != nil {
+		return err
+	}
+	k8sClient, err := m.k8sClientProvider(adminKubeconfig)
+	if err != nil {
+		return fmt.Errorf("while creating k8s client for the runtime %s: %w", instance.RuntimeID, err)
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: objectMeta(bindingID, "")}
+	if err := k8sClient.Delete(ctx, clusterRoleBinding); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("while deleting cluster role binding %s: %w", clusterRoleBinding.Name, err)
+	}
+	// removing the service account invalidates all tokens issued for it
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: objectMeta(bindingID, Namespace)}
+	if err := k8sClient.Delete(ctx, serviceAccount); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("while deleting service account %s: %w", serviceAccount.Name, err)
+	}
+	return nil
+}
+
+func (m *ServiceAccountBindingsManager) adminKubeconfig(instance *internal.Instance) (string, error) {
+	// own cluster plan instances are provisioned with the kubeconfig given in the parameters
+	if instance.Parameters.Parameters.Kubeconfig != "" {
+		return instance.Parameters.Parameters.Kubeconfig, nil
+	}
+	status, err := m.provisionerClient.RuntimeStatus(instance.GlobalAccountID, instance.RuntimeID)
+	if err != nil {
+		return "", fmt.Errorf("while fetching runtime status from provisioner: %w", err)
+	}
+	if status.RuntimeConfiguration == nil || status.RuntimeConfiguration.Kubeconfig == nil {
+		return "", fmt.Errorf("kubeconfig is nil (nil response from Provisioner)")
+	}
+	return *status.RuntimeConfiguration.Kubeconfig, nil
+}
+
+func ResourceName(bindingID string) string {
+	return resourceNamePrefix + bindingID
+}
+
+func objectMeta(bindingID, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      ResourceName(bindingID),
+		Namespace: namespace,
+		Labels: map[string]string{
+			ManagedByLabelKey: ManagedByLabel,
+			BindingIDLabelKey: bindingID,
+		},
+	}
+}
+
+type adminKubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			Server                   string `yaml:"server"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+}
+
+type kubeconfigData struct {
+	ContextName string
+	CAData      string
+	ServerURL   string
+	UserName    string
+	Token       string
+}
+
+func buildKubeconfig(admin, userName, token string) (string, error) {
+	var kubeCfg adminKubeconfig
+	if err := yaml.Unmarshal([]byte(admin), &kubeCfg); err != nil {
+		return "", fmt.Errorf("while unmarshaling kubeconfig: %w", err)
+	}
+	if kubeCfg.CurrentContext == "" || len(kubeCfg.Clusters) == 0 {
+		return "", fmt.Errorf("kubeconfig does not contain cluster info")
+	}
+
+	var result bytes.Buffer
+	t, err := template.New("bindingKubeconfig").Parse(kubeconfigTemplate)
+	if err != nil {
+		return "", fmt.Errorf("while parsing kubeconfig template: %w", err)
+	}
+	err = t.Execute(&result, kubeconfigData{
+		ContextName: kubeCfg.CurrentContext,
+		CAData:      kubeCfg.Clusters[0].Cluster.CertificateAuthorityData,
+		ServerURL:   kubeCfg.Clusters[0].Cluster.Server,
+		UserName:    userName,
+		Token:       token,
+	})
+	if err != nil {
+		return "", fmt.Errorf("while executing kubeconfig template: %w", err)
+	}
+	return result.String(), nil
+}
+
+const kubeconfigTemplate = `
+---
+apiVersion: v1
+kind: Config
+current-context: {{ .ContextName }}
+clusters:
+- name: {{ .ContextName }}
+  cluster:
+    certificate-authority-data: {{ .CAData }}
+    server: {{ .ServerURL }}
+contexts:
+- name: {{ .ContextName }}
+  context:
+    cluster: {{ .ContextName }}
+    user: {{ .UserName }}
+users:
+- name: {{ .UserName }}
+  user:
+    token: {{ .Token }}
+`
@@ -0,0 +1,142 @@
+package binding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/provisioner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+	authv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	bindingID  = "binding-id"
+	fixToken   = "service-account-token"
+	kubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: shoot--kyma--c-1234
+clusters:
+- name: shoot--kyma--c-1234
+  cluster:
+    certificate-authority-data: Y2VydGlmaWNhdGUK
+    server: https://api.c-1234.kyma.sap.com
+users:
+- name: admin
+  user:
+    token: admin-token
+`
+)
+
+func TestServiceAccountBindingsManager(t *testing.T) {
+	t.Run("should create service account with token and delete it", func(t *testing.T) {
+		// given
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		k8sClient := &tokenRequestClient{Client: fake.NewClientBuilder().Build(), expiresAt: expiresAt}
+		manager := NewServiceAccountBindingsManager(nil, func(string) (client.Client, error) { return k8sClient, nil })
+		instance := fixOwnClusterInstance()
+
+		// when
+		credentials, gotExpiresAt, err := manager.Create(context.Background(), &instance, bindingID, "view", 3600)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, expiresAt, gotExpiresAt)
+		assert.Equal(t, int64(3600), k8sClient.expirationSeconds)
+
+		var generated struct {
+			Users []struct {
+				Name string `yaml:"name"`
+				User struct {
+					Token string `yaml:"token"`
+				} `yaml:"user"`
+			} `yaml:"users"`
+		}
+		require.NoError(t, yaml.Unmarshal([]byte(credentials), &generated))
+		require.Len(t, generated.Users, 1)
+		assert.Equal(t, ResourceName(bindingID), generated.Users[0].Name)
+		assert.Equal(t, fixToken, generated.Users[0].User.Token)
+		assert.Contains(t, credentials, "server: https://api.c-1234.kyma.sap.com")
+
+		sa := &corev1.ServiceAccount{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: ResourceName(bindingID)}, sa))
+		assert.Equal(t, bindingID, sa.Labels[BindingIDLabelKey])
+		crb := &rbacv1.ClusterRoleBinding{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Name: ResourceName(bindingID)}, crb))
+		assert.Equal(t, "view", crb.RoleRef.Name)
+
+		// when
+		err = manager.Delete(context.Background(), &instance, bindingID)
+
+		// then
+		require.NoError(t, err)
+		err = k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: ResourceName(bindingID)}, sa)
+		assert.True(t, errors.IsNotFound(err))
+		err = k8sClient.Get(context.Background(), client.ObjectKey{Name: ResourceName(bindingID)}, crb)
+		assert.True(t, errors.IsNotFound(err))
+
+		// deleting not existing resources is not an error
+		assert.NoError(t, manager.Delete(context.Background(), &instance, bindingID))
+	})
+
+	t.Run("should fail when provisioner does not know the runtime", func(t *testing.T) {
+		// given
+		provisionerClient := provisioner.NewFakeClient()
+		instance := fixture.FixInstance("instance-id")
+		manager := NewServiceAccountBindingsManager(provisionerClient, func(string) (client.Client, error) {
+			return fake.NewClientBuilder().Build(), nil
+		})
+
+		// when
+		_, _, err := manager.Create(context.Background(), &instance, bindingID, DefaultClusterRole, 600)
+
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func fixOwnClusterInstance() internal.Instance {
+	instance := fixture.FixInstance("instance-id")
+	instance.Parameters.Parameters.Kubeconfig = kubeconfig
+	return instance
+}
+
+// tokenRequestClient handles TokenRequests, which are not supported by the fake client
+type tokenRequestClient struct {
+	client.Client
+
+	expiresAt         time.Time
+	expirationSeconds int64
+}
+
+func (c *tokenRequestClient) SubResource(subResource string) client.SubResourceClient {
+	return &tokenSubResourceClient{SubResourceClient: c.Client.SubResource(subResource), parent: c}
+}
+
+type tokenSubResourceClient struct {
+	client.SubResourceClient
+
+	parent *tokenRequestClient
+}
+
+func (c *tokenSubResourceClient) Create(_ context.Context, _ client.Object, subResource client.Object, _ ...client.SubResourceCreateOption) error {
+	tokenRequest := subResource.(*authv1.TokenRequest)
+	c.parent.expirationSeconds = *tokenRequest.Spec.ExpirationSeconds
+	tokenRequest.Status = authv1.TokenRequestStatus{
+		Token:               fixToken,
+		ExpirationTimestamp: metav1.NewTime(c.parent.expiresAt),
+	}
+	return nil
+}
@@ -2,28 +2,171 @@ package broker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 )
 
+//go:generate mockery --name=BindingsManager --output=automock --outpkg=automock --case=underscore
+
+// BindingsManager creates and revokes credentials to the SKR issued for a service binding
+type BindingsManager interface {
+	Create(ctx context.Context, instance *internal.Instance, bindingID, clusterRole string, expirationSeconds int64) (string, time.Time, error)
+	Delete(ctx context.Context, instance *internal.Instance, bindingID string) error
+}
+
 type BindEndpoint struct {
+	config            BindingConfig
+	instancesStorage  storage.Instances
+	operationsStorage storage.Provisioning
+	bindingsStorage   storage.Bindings
+	bindingsManager   BindingsManager
+
 	log logrus.FieldLogger
 }
 
-func NewBind(log logrus.FieldLogger) *BindEndpoint {
-	return &BindEndpoint{log: log.WithField("service", "BindEndpoint")}
+type BindingParams struct {
+	ExpirationSeconds int64  `json:"expiration_seconds,omitempty"`
+	ClusterRole       string `json:"cluster_role,omitempty"`
+}
+
+type Credentials struct {
+	Kubeconfig string `json:"kubeconfig"`
+}
+
+func NewBind(cfg BindingConfig, instancesStorage storage.Instances, operationsStorage storage.Provisioning, bindingsStorage storage.Bindings,
+	bindingsManager BindingsManager, log logrus.FieldLogger) *BindEndpoint {
+	return &BindEndpoint{
+		config:            cfg,
+		instancesStorage:  instancesStorage,
+		operationsStorage: operationsStorage,
+		bindingsStorage:   bindingsStorage,
+		bindingsManager:   bindingsManager,
+		log:               log.WithField("service", "BindEndpoint"),
+	}
 }
 
 // Bind creates a new service binding
 //
 //	PUT /v2/service_instances/{instance_id}/service_bindings/{binding_id}
 func (b *BindEndpoint) Bind(ctx context.Context, instanceID, bindingID string, details domain.BindDetails, asyncAllowed bool) (domain.Binding, error) {
-	b.log.Infof("Bind instanceID:", instanceID)
-	b.log.Infof("Bind parameters: %s", string(details.RawParameters))
-	b.log.Infof("Bind context: %s", string(details.RawContext))
-	b.log.Infof("Bind asyncAllowed:", asyncAllowed)
+	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "bindingID": bindingID})
+	logger.Infof("Bind parameters: %s", string(details.RawParameters))
+	logger.Infof("Bind asyncAllowed: %v", asyncAllowed)
+
+	if !b.config.Enabled {
+		return domain.Binding{}, fmt.Errorf("not supported")
+	}
+
+	instance, err := b.instancesStorage.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return domain.Binding{}, apiresponses.NewFailureResponse(fmt.Errorf("instance with instanceID %s does not exist", instanceID), http.StatusNotFound, "binding")
+	case err != nil:
+		logger.Errorf("unable to get instance from the storage: %s", err)
+		return domain.Binding{}, fmt.Errorf("unable to get instance %s", instanceID)
+	}
+	if err := b.validateInstance(instance); err != nil {
+		return domain.Binding{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, "binding")
+	}
+
+	params, err := b.parseParameters(details.RawParameters)
+	if err != nil {
+		return domain.Binding{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "binding")
+	}
+
+	existing, err := b.bindingsStorage.Get(instanceID, bindingID)
+	switch {
+	case err == nil:
+		if existing.ExpirationSeconds != params.ExpirationSeconds || existing.ClusterRole != params.ClusterRole {
+			return domain.Binding{}, apiresponses.ErrBindingAlreadyExists
+		}
+		logger.Info("binding already exists")
+		return domain.Binding{
+			AlreadyExists: true,
+			Credentials:   Credentials{Kubeconfig: existing.Kubeconfig},
+		}, nil
+	case !dberr.IsNotFound(err):
+		logger.Errorf("unable to get binding from the storage: %s", err)
+		return domain.Binding{}, fmt.Errorf("unable to get binding %s", bindingID)
+	}
+
+	kubeconfig, expiresAt, err := b.bindingsManager.Create(ctx, instance, bindingID, params.ClusterRole, params.ExpirationSeconds)
+	if err != nil {
+		logger.Errorf("unable to create credentials for the binding: %s", err)
+		return domain.Binding{}, fmt.Errorf("unable to create credentials for the binding %s", bindingID)
+	}
+
+	binding := internal.Binding{
+		ID:                bindingID,
+		InstanceID:        instanceID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		ExpiresAt:         expiresAt,
+		Kubeconfig:        kubeconfig,
+		ExpirationSeconds: params.ExpirationSeconds,
+		ClusterRole:       params.ClusterRole,
+	}
+	if err := b.bindingsStorage.Insert(binding); err != nil {
+		logger.Errorf("unable to save the binding: %s", err)
+		if err := b.bindingsManager.Delete(ctx, instance, bindingID); err != nil {
+			logger.Errorf("unable to revoke credentials of the not saved binding: %s", err)
+		}
+		return domain.Binding{}, fmt.Errorf("unable to save the binding %s", bindingID)
+	}
+	logger.Infof("binding created, expires at %s", expiresAt)
+
+	return domain.Binding{
+		IsAsync:     false,
+		Credentials: Credentials{Kubeconfig: kubeconfig},
+	}, nil
+}
+
+func (b *BindEndpoint) validateInstance(instance *internal.Instance) error {
+	if !instance.DeletedAt.IsZero() {
+		return fmt.Errorf("instance %s is being deprovisioned", instance.InstanceID)
+	}
+	if instance.RuntimeID == "" {
+		return fmt.Errorf("instance %s has no runtime, provisioning could be in progress", instance.InstanceID)
+	}
+	operation, err := b.operationsStorage.GetProvisioningOperationByInstanceID(instance.InstanceID)
+	if err != nil {
+		return fmt.Errorf("unable to get provisioning operation for instance %s", instance.InstanceID)
+	}
+	if operation.State != domain.Succeeded {
+		return fmt.Errorf("provisioning of instance %s is %s", instance.InstanceID, operation.State)
+	}
+	return nil
+}
 
-	return domain.Binding{}, fmt.Errorf("not supported")
+func (b *BindEndpoint) parseParameters(raw json.RawMessage) (BindingParams, error) {
+	params := BindingParams{}
+	if len(raw) != 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return BindingParams{}, fmt.Errorf("while unmarshalling parameters: %w", err)
+		}
+	}
+	if params.ExpirationSeconds == 0 {
+		params.ExpirationSeconds = b.config.ExpirationSeconds
+	}
+	if params.ExpirationSeconds < b.config.MinExpirationSeconds || params.ExpirationSeconds > b.config.MaxExpirationSeconds {
+		return BindingParams{}, fmt.Errorf("expiration_seconds must be between %d and %d", b.config.MinExpirationSeconds, b.config.MaxExpirationSeconds)
+	}
+	if params.ClusterRole == "" {
+		params.ClusterRole = b.config.ClusterRole
+	} else if !slices.Contains(b.config.AllowedClusterRoles, params.ClusterRole) {
+		return BindingParams{}, fmt.Errorf("cluster_role must be one of: %s", strings.Join(b.config.AllowedClusterRoles, ", "))
+	}
+	return params, nil
 }
@@ -0,0 +1,341 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	bindingID      = "binding-id"
+	bindKubeconfig = "binding kubeconfig"
+)
+
+func TestBind(t *testing.T) {
+	t.Run("should create binding with default expiration", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		expiresAt := time.Now().Add(10 * time.Minute)
+		manager := automock.NewBindingsManager(t)
+		manager.On("Create", mock.Anything, mock.AnythingOfType("*internal.Instance"), bindingID, "cluster-admin", int64(600)).
+			Return(bindKubeconfig, expiresAt, nil).Once()
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), manager, logrus.New())
+
+		// when
+		response, err := svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{}, false)
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, response.IsAsync)
+		assert.Equal(t, broker.Credentials{Kubeconfig: bindKubeconfig}, response.Credentials)
+
+		binding, err := st.Bindings().Get(instanceID, bindingID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(600), binding.ExpirationSeconds)
+		assert.Equal(t, bindKubeconfig, binding.Kubeconfig)
+		assert.WithinDuration(t, expiresAt, binding.ExpiresAt, time.Second)
+	})
+
+	t.Run("should return existing binding for the same parameters", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		manager := automock.NewBindingsManager(t)
+		manager.On("Create", mock.Anything, mock.Anything, bindingID, "cluster-admin", int64(900)).
+			Return(bindKubeconfig, time.Now().Add(15*time.Minute), nil).Once()
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), manager, logrus.New())
+		details := domain.BindDetails{RawParameters: json.RawMessage(`{"expiration_seconds": 900}`)}
+
+		_, err := svc.Bind(context.Background(), instanceID, bindingID, details, false)
+		require.NoError(t, err)
+
+		// when
+		response, err := svc.Bind(context.Background(), instanceID, bindingID, details, false)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.AlreadyExists)
+		assert.Equal(t, broker.Credentials{Kubeconfig: bindKubeconfig}, response.Credentials)
+	})
+
+	t.Run("should return conflict for existing binding with different parameters", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		err := st.Bindings().Insert(internal.Binding{ID: bindingID, InstanceID: instanceID, ExpirationSeconds: 900})
+		require.NoError(t, err)
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), automock.NewBindingsManager(t), logrus.New())
+
+		// when
+		_, err = svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{}, false)
+
+		// then
+		assert.Equal(t, apiresponses.ErrBindingAlreadyExists, err)
+	})
+
+	t.Run("should create binding with requested cluster role", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		manager := automock.NewBindingsManager(t)
+		manager.On("Create", mock.Anything, mock.Anything, bindingID, "view", int64(600)).
+			Return(bindKubeconfig, time.Now().Add(10*time.Minute), nil).Once()
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), manager, logrus.New())
+
+		// when
+		_, err := svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{
+			RawParameters: json.RawMessage(`{"cluster_role": "view"}`),
+		}, false)
+
+		// then
+		require.NoError(t, err)
+		binding, err := st.Bindings().Get(instanceID, bindingID)
+		require.NoError(t, err)
+		assert.Equal(t, "view", binding.ClusterRole)
+
+		// when
+		_, err = svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{}, false)
+
+		// then
+		assert.Equal(t, apiresponses.ErrBindingAlreadyExists, err)
+	})
+
+	t.Run("should reject cluster role which is not allowed", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), automock.NewBindingsManager(t), logrus.New())
+
+		// when
+		_, err := svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{
+			RawParameters: json.RawMessage(`{"cluster_role": "edit"}`),
+		}, false)
+
+		// then
+		assertFailureResponse(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("should reject expiration out of range", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), automock.NewBindingsManager(t), logrus.New())
+
+		for _, expiration := range []int64{60, 7201} {
+			// when
+			_, err := svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{
+				RawParameters: json.RawMessage(fmt.Sprintf(`{"expiration_seconds": %d}`, expiration)),
+			}, false)
+
+			// then
+			assertFailureResponse(t, err, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("should return not found for not existing instance", func(t *testing.T) {
+		// given
+		st := storage.NewMemoryStorage()
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), automock.NewBindingsManager(t), logrus.New())
+
+		// when
+		_, err := svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{}, false)
+
+		// then
+		assertFailureResponse(t, err, http.StatusNotFound)
+	})
+
+	t.Run("should reject instance which is not provisioned", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		operation, err := st.Operations().GetProvisioningOperationByInstanceID(instanceID)
+		require.NoError(t, err)
+		operation.State = domain.InProgress
+		_, err = st.Operations().UpdateProvisioningOperation(*operation)
+		require.NoError(t, err)
+		svc := broker.NewBind(fixBindingConfig(), st.Instances(), st.Operations(), st.Bindings(), automock.NewBindingsManager(t), logrus.New())
+
+		// when
+		_, err = svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{}, false)
+
+		// then
+		assertFailureResponse(t, err, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("should not support bindings when disabled", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		svc := broker.NewBind(broker.BindingConfig{}, st.Instances(), st.Operations(), st.Bindings(), automock.NewBindingsManager(t), logrus.New())
+
+		// when
+		_, err := svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{}, false)
+
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func TestGetBinding(t *testing.T) {
+	t.Run("should return binding", func(t *testing.T) {
+		// given
+		st := storage.NewMemoryStorage()
+		err := st.Bindings().Insert(fixBinding(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		svc := broker.NewGetBinding(fixBindingConfig(), st.Bindings(), logrus.New())
+
+		// when
+		response, err := svc.GetBinding(context.Background(), instanceID, bindingID, domain.FetchBindingDetails{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, broker.Credentials{Kubeconfig: bindKubeconfig}, response.Credentials)
+		assert.Equal(t, broker.BindingParams{ExpirationSeconds: 600}, response.Parameters)
+	})
+
+	t.Run("should not return expired binding", func(t *testing.T) {
+		// given
+		st := storage.NewMemoryStorage()
+		err := st.Bindings().Insert(fixBinding(time.Now().Add(-time.Minute)))
+		require.NoError(t, err)
+		svc := broker.NewGetBinding(fixBindingConfig(), st.Bindings(), logrus.New())
+
+		// when
+		_, err = svc.GetBinding(context.Background(), instanceID, bindingID, domain.FetchBindingDetails{})
+
+		// then
+		assert.Equal(t, apiresponses.ErrBindingNotFound, err)
+	})
+
+	t.Run("should return not found for not existing binding", func(t *testing.T) {
+		// given
+		st := storage.NewMemoryStorage()
+		svc := broker.NewGetBinding(fixBindingConfig(), st.Bindings(), logrus.New())
+
+		// when
+		_, err := svc.GetBinding(context.Background(), instanceID, bindingID, domain.FetchBindingDetails{})
+
+		// then
+		assert.Equal(t, apiresponses.ErrBindingNotFound, err)
+	})
+}
+
+func TestUnbind(t *testing.T) {
+	t.Run("should revoke credentials and delete binding", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		err := st.Bindings().Insert(fixBinding(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		manager := automock.NewBindingsManager(t)
+		manager.On("Delete", mock.Anything, mock.AnythingOfType("*internal.Instance"), bindingID).Return(nil).Once()
+		svc := broker.NewUnbind(fixBindingConfig(), st.Instances(), st.Bindings(), manager, logrus.New())
+
+		// when
+		_, err = svc.Unbind(context.Background(), instanceID, bindingID, domain.UnbindDetails{}, false)
+
+		// then
+		require.NoError(t, err)
+		_, err = st.Bindings().Get(instanceID, bindingID)
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should keep binding when credentials cannot be revoked", func(t *testing.T) {
+		// given
+		st := fixStorageWithInstance(t)
+		err := st.Bindings().Insert(fixBinding(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		manager := automock.NewBindingsManager(t)
+		manager.On("Delete", mock.Anything, mock.Anything, bindingID).Return(fmt.Errorf("unreachable")).Once()
+		svc := broker.NewUnbind(fixBindingConfig(), st.Instances(), st.Bindings(), manager, logrus.New())
+
+		// when
+		_, err = svc.Unbind(context.Background(), instanceID, bindingID, domain.UnbindDetails{}, false)
+
+		// then
+		assert.Error(t, err)
+		_, err = st.Bindings().Get(instanceID, bindingID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return gone for not existing binding", func(t *testing.T) {
+		// given
+		st := storage.NewMemoryStorage()
+		svc := broker.NewUnbind(fixBindingConfig(), st.Instances(), st.Bindings(), automock.NewBindingsManager(t), logrus.New())
+
+		// when
+		_, err := svc.Unbind(context.Background(), instanceID, bindingID, domain.UnbindDetails{}, false)
+
+		// then
+		assert.Equal(t, apiresponses.ErrBindingDoesNotExist, err)
+	})
+}
+
+func TestLastBindingOperation(t *testing.T) {
+	// given
+	st := storage.NewMemoryStorage()
+	svc := broker.NewLastBindingOperation(fixBindingConfig(), st.Bindings(), logrus.New())
+
+	// when
+	_, err := svc.LastBindingOperation(context.Background(), instanceID, bindingID, domain.PollDetails{})
+
+	// then
+	assert.Equal(t, apiresponses.ErrBindingDoesNotExist, err)
+
+	// given
+	err = st.Bindings().Insert(fixBinding(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	// when
+	response, err := svc.LastBindingOperation(context.Background(), instanceID, bindingID, domain.PollDetails{})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, domain.Succeeded, response.State)
+}
+
+func fixBindingConfig() broker.BindingConfig {
+	return broker.BindingConfig{
+		Enabled:              true,
+		ExpirationSeconds:    600,
+		MinExpirationSeconds: 600,
+		MaxExpirationSeconds: 7200,
+		ClusterRole:          "cluster-admin",
+		AllowedClusterRoles:  []string{"cluster-admin", "view"},
+	}
+}
+
+func fixBinding(expiresAt time.Time) internal.Binding {
+	return internal.Binding{
+		ID:                bindingID,
+		InstanceID:        instanceID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		ExpiresAt:         expiresAt,
+		Kubeconfig:        bindKubeconfig,
+		ExpirationSeconds: 600,
+	}
+}
+
+func fixStorageWithInstance(t *testing.T) storage.BrokerStorage {
+	st := storage.NewMemoryStorage()
+	err := st.Instances().Insert(fixture.FixInstance(instanceID))
+	require.NoError(t, err)
+	err = st.Operations().InsertOperation(fixture.FixProvisioningOperation("provisioning-op", instanceID))
+	require.NoError(t, err)
+	return st
+}
+
+func assertFailureResponse(t *testing.T, err error, statusCode int) {
+	require.IsType(t, &apiresponses.FailureResponse{}, err)
+	apierr := err.(*apiresponses.FailureResponse)
+	assert.Equal(t, statusCode, apierr.ValidatedStatusCode(nil))
+}
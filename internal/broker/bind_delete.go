@@ -4,25 +4,75 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 	"github.com/sirupsen/logrus"
 )
 
 type UnbindEndpoint struct {
+	config           BindingConfig
+	instancesStorage storage.Instances
+	bindingsStorage  storage.Bindings
+	bindingsManager  BindingsManager
+
 	log logrus.FieldLogger
 }
 
-func NewUnbind(log logrus.FieldLogger) *UnbindEndpoint {
-	return &UnbindEndpoint{log: log.WithField("service", "UnbindEndpoint")}
+func NewUnbind(cfg BindingConfig, instancesStorage storage.Instances, bindingsStorage storage.Bindings, bindingsManager BindingsManager, log logrus.FieldLogger) *UnbindEndpoint {
+	return &UnbindEndpoint{
+		config:           cfg,
+		instancesStorage: instancesStorage,
+		bindingsStorage:  bindingsStorage,
+		bindingsManager:  bindingsManager,
+		log:              log.WithField("service", "UnbindEndpoint"),
+	}
 }
 
 // Unbind deletes an existing service binding
 //
 //	DELETE /v2/service_instances/{instance_id}/service_bindings/{binding_id}
 func (b *UnbindEndpoint) Unbind(ctx context.Context, instanceID, bindingID string, details domain.UnbindDetails, asyncAllowed bool) (domain.UnbindSpec, error) {
-	b.log.Infof("Unbind instanceID: %s", instanceID)
-	b.log.Infof("Unbind details: %+v", details)
-	b.log.Infof("Unbind asyncAllowed: %v", asyncAllowed)
+	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "bindingID": bindingID})
+	logger.Infof("Unbind details: %+v", details)
+	logger.Infof("Unbind asyncAllowed: %v", asyncAllowed)
+
+	if !b.config.Enabled {
+		return domain.UnbindSpec{}, fmt.Errorf("not supported")
+	}
+
+	_, err := b.bindingsStorage.Get(instanceID, bindingID)
+	switch {
+	case dberr.IsNotFound(err):
+		return domain.UnbindSpec{}, apiresponses.ErrBindingDoesNotExist
+	case err != nil:
+		logger.Errorf("unable to get binding from the storage: %s", err)
+		return domain.UnbindSpec{}, fmt.Errorf("unable to get binding %s", bindingID)
+	}
+
+	instance, err := b.instancesStorage.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		logger.Warn("instance does not exist, credentials are gone together with the runtime")
+	case err != nil:
+		logger.Errorf("unable to get instance from the storage: %s", err)
+		return domain.UnbindSpec{}, fmt.Errorf("unable to get instance %s", instanceID)
+	default:
+		if err := b.bindingsManager.Delete(ctx, instance, bindingID); err != nil {
+			logger.Errorf("unable to revoke credentials of the binding: %s", err)
+			return domain.UnbindSpec{}, fmt.Errorf("unable to revoke credentials of the binding %s", bindingID)
+		}
+	}
+
+	if err := b.bindingsStorage.Delete(instanceID, bindingID); err != nil {
+		logger.Errorf("unable to delete the binding from the storage: %s", err)
+		return domain.UnbindSpec{}, fmt.Errorf("unable to delete the binding %s", bindingID)
+	}
+	logger.Info("binding deleted")
 
-	return domain.UnbindSpec{}, fmt.Errorf("not supported")
+	return domain.UnbindSpec{
+		IsAsync: false,
+	}, nil
 }
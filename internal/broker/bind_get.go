@@ -4,24 +4,55 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 	"github.com/sirupsen/logrus"
 )
 
 type GetBindingEndpoint struct {
+	config          BindingConfig
+	bindingsStorage storage.Bindings
+
 	log logrus.FieldLogger
 }
 
-func NewGetBinding(log logrus.FieldLogger) *GetBindingEndpoint {
-	return &GetBindingEndpoint{log: log.WithField("service", "GetBindingEndpoint")}
+func NewGetBinding(cfg BindingConfig, bindingsStorage storage.Bindings, log logrus.FieldLogger) *GetBindingEndpoint {
+	return &GetBindingEndpoint{
+		config:          cfg,
+		bindingsStorage: bindingsStorage,
+		log:             log.WithField("service", "GetBindingEndpoint"),
+	}
 }
 
 // GetBinding fetches an existing service binding
 //
 //	GET /v2/service_instances/{instance_id}/service_bindings/{binding_id}
 func (b *GetBindingEndpoint) GetBinding(_ context.Context, instanceID, bindingID string, _ domain.FetchBindingDetails) (domain.GetBindingSpec, error) {
-	b.log.Infof("GetBinding instanceID: %s", instanceID)
-	b.log.Infof("GetBinding bindingID: %s", bindingID)
+	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "bindingID": bindingID})
+	logger.Infof("GetBinding called")
+
+	if !b.config.Enabled {
+		return domain.GetBindingSpec{}, fmt.Errorf("not supported")
+	}
+
+	binding, err := b.bindingsStorage.Get(instanceID, bindingID)
+	switch {
+	case dberr.IsNotFound(err):
+		return domain.GetBindingSpec{}, apiresponses.ErrBindingNotFound
+	case err != nil:
+		logger.Errorf("unable to get binding from the storage: %s", err)
+		return domain.GetBindingSpec{}, fmt.Errorf("unable to get binding %s", bindingID)
+	}
+	if binding.IsExpired() {
+		logger.Infof("binding expired at %s", binding.ExpiresAt)
+		return domain.GetBindingSpec{}, apiresponses.ErrBindingNotFound
+	}
 
-	return domain.GetBindingSpec{}, fmt.Errorf("not supported")
+	return domain.GetBindingSpec{
+		Credentials: Credentials{Kubeconfig: binding.Kubeconfig},
+		Parameters:  BindingParams{ExpirationSeconds: binding.ExpirationSeconds},
+	}, nil
 }
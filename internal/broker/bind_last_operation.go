@@ -4,25 +4,53 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 	"github.com/sirupsen/logrus"
 )
 
 type LastBindingOperationEndpoint struct {
+	config          BindingConfig
+	bindingsStorage storage.Bindings
+
 	log logrus.FieldLogger
 }
 
-func NewLastBindingOperation(log logrus.FieldLogger) *LastBindingOperationEndpoint {
-	return &LastBindingOperationEndpoint{log: log.WithField("service", "LastBindingOperationEndpoint")}
+func NewLastBindingOperation(cfg BindingConfig, bindingsStorage storage.Bindings, log logrus.FieldLogger) *LastBindingOperationEndpoint {
+	return &LastBindingOperationEndpoint{
+		config:          cfg,
+		bindingsStorage: bindingsStorage,
+		log:             log.WithField("service", "LastBindingOperationEndpoint"),
+	}
 }
 
 // LastBindingOperation fetches last operation state for a service binding
 //
 //	GET /v2/service_instances/{instance_id}/service_bindings/{binding_id}/last_operation
+//
+// Bindings are created and deleted synchronously, the last operation is succeeded as long as the binding exists.
 func (b *LastBindingOperationEndpoint) LastBindingOperation(ctx context.Context, instanceID, bindingID string, details domain.PollDetails) (domain.LastOperation, error) {
-	b.log.Infof("LastBindingOperation instanceID: %s", instanceID)
-	b.log.Infof("LastBindingOperation bindingID: %s", bindingID)
-	b.log.Infof("LastBindingOperation details: %+v", details)
+	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "bindingID": bindingID})
+	logger.Infof("LastBindingOperation details: %+v", details)
+
+	if !b.config.Enabled {
+		return domain.LastOperation{}, fmt.Errorf("not supported")
+	}
+
+	_, err := b.bindingsStorage.Get(instanceID, bindingID)
+	switch {
+	case dberr.IsNotFound(err):
+		return domain.LastOperation{}, apiresponses.ErrBindingDoesNotExist
+	case err != nil:
+		logger.Errorf("unable to get binding from the storage: %s", err)
+		return domain.LastOperation{}, fmt.Errorf("unable to get binding %s", bindingID)
+	}
 
-	return domain.LastOperation{}, fmt.Errorf("not supported")
+	return domain.LastOperation{
+		State:       domain.Succeeded,
+		Description: "binding created",
+	}, nil
 }
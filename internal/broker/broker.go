@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
@@ -43,6 +44,38 @@ type Config struct {
 	TrialDocsURL                            string `envconfig:"default="`
 
 	AllowNetworkingParameters bool `envconfig:"default=false"`
+	EnablePlanUpgrades        bool `envconfig:"default=false"`
+
+	// EnableMaintenanceInfo publishes maintenance_info in the catalog and allows upgrades triggered by the update request
+	EnableMaintenanceInfo bool   `envconfig:"default=false"`
+	KymaVersion           string `envconfig:"-"`
+	KubernetesVersion     string `envconfig:"-"`
+
+	// OptionalComponents lists Kyma components which can be enabled with the components parameter
+	OptionalComponents []string `envconfig:"-"`
+
+	// RequestIdentityWindow defines how long the response of the OSB request is returned for repeated requests
+	// with the same X-Broker-API-Request-Identity header
+	RequestIdentityWindow time.Duration `envconfig:"default=24h"`
+
+	Binding BindingConfig
+}
+
+// BindingConfig represents configuration of OSB service bindings
+type BindingConfig struct {
+	Enabled bool `envconfig:"default=false"`
+	// ExpirationSeconds is used when the bind request does not specify expiration_seconds parameter
+	ExpirationSeconds    int64 `envconfig:"default=600"`
+	MinExpirationSeconds int64 `envconfig:"default=600"`
+	MaxExpirationSeconds int64 `envconfig:"default=7200"`
+	// ClusterRole is bound to the service account of the binding when the bind request does not specify cluster_role parameter
+	ClusterRole string `envconfig:"default=cluster-admin"`
+	// AllowedClusterRoles lists cluster roles which can be requested with cluster_role parameter
+	AllowedClusterRoles []string `envconfig:"default=cluster-admin"`
+	// CleanupInterval defines how often expired bindings are removed from the runtimes and the storage
+	CleanupInterval time.Duration `envconfig:"default=10m"`
+	// CleanupDryRun only logs expired bindings without removing them
+	CleanupDryRun bool `envconfig:"default=false"`
 }
 
 type ServicesConfig map[string]Service
@@ -111,3 +144,13 @@ func (m *EnablePlans) Unmarshal(in string) error {
 	*m = plans
 	return nil
 }
+
+// Contains checks if the plan with the given name is enabled
+func (m EnablePlans) Contains(name string) bool {
+	for _, plan := range m {
+		if plan == name {
+			return true
+		}
+	}
+	return false
+}
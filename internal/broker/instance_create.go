@@ -22,9 +22,11 @@ import (
 	"github.com/kyma-incubator/compass/components/director/pkg/jsonschema"
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/admission"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
 	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/quota"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
@@ -65,6 +67,9 @@ type ProvisionEndpoint struct {
 	euAccessWhitelist        euaccess.WhitelistSet
 	euAccessRejectionMessage string
 
+	quotas          quota.Quotas
+	admissionPolicy admission.Policy
+
 	log logrus.FieldLogger
 }
 
@@ -79,6 +84,8 @@ func NewProvision(cfg Config,
 	planDefaults PlanDefaults,
 	euAccessWhitelist euaccess.WhitelistSet,
 	euRejectMessage string,
+	quotas quota.Quotas,
+	admissionPolicy admission.Policy,
 	log logrus.FieldLogger,
 	dashboardConfig dashboard.Config,
 ) *ProvisionEndpoint {
@@ -104,6 +111,8 @@ func NewProvision(cfg Config,
 		planDefaults:             planDefaults,
 		euAccessWhitelist:        euAccessWhitelist,
 		euAccessRejectionMessage: euRejectMessage,
+		quotas:                   quotas,
+		admissionPolicy:          admissionPolicy,
 		dashboardConfig:          dashboardConfig,
 	}
 }
@@ -116,6 +125,14 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID, "operationID": operationID, "planID": details.PlanID})
 	logger.Infof("Provision called with context: %s", marshallRawContext(hideSensitiveDataFromRawContext(details.RawContext)))
 
+	requestIdentity, previousOperation, err := operationForRequestIdentity(ctx, b.operationsStorage, b.config.RequestIdentityWindow, instanceID, internal.OperationTypeProvision, logger)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, err
+	}
+	if previousOperation != nil {
+		return b.responseForOperation(*previousOperation), nil
+	}
+
 	region, found := middleware.RegionFromContext(ctx)
 	if !found {
 		err := fmt.Errorf("No region specified in request.")
@@ -153,10 +170,16 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 	case errStorage != nil && !dberr.IsNotFound(errStorage):
 		logger.Errorf("cannot get existing operation from storage %s", errStorage)
 		return domain.ProvisionedServiceSpec{}, fmt.Errorf("cannot get existing operation from storage")
+	case existingOperation != nil && requestIdentity != "" && existingOperation.RequestIdentity == requestIdentity:
+		return b.responseForOperation(existingOperation.Operation), nil
 	case existingOperation != nil && !dberr.IsNotFound(errStorage):
 		return b.handleExistingOperation(existingOperation, provisioningParameters)
 	}
 
+	if err := b.checkQuota(ersContext.GlobalAccountID, details.PlanID, logger); err != nil {
+		return domain.ProvisionedServiceSpec{}, err
+	}
+
 	shootName := gardener.CreateShootName()
 	shootDomainSuffix := strings.Trim(b.shootDomain, ".")
 
@@ -173,6 +196,7 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 	operation.ShootDomain = fmt.Sprintf("%s.%s", shootName, shootDomainSuffix)
 	operation.ShootDNSProviders = b.shootDnsProviders
 	operation.DashboardURL = dashboardURL
+	operation.RequestIdentity = requestIdentity
 	// for own cluster plan - KEB uses provided shoot name and shoot domain
 	if IsOwnClusterPlan(provisioningParameters.PlanID) {
 		operation.ShootName = provisioningParameters.Parameters.ShootName
@@ -181,6 +205,10 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 	logger.Infof("Runtime ShootDomain: %s", operation.ShootDomain)
 
 	err = b.operationsStorage.InsertOperation(operation.Operation)
+	if duplicated, found := operationForDuplicatedRequest(b.operationsStorage, requestIdentity, err, instanceID, internal.OperationTypeProvision); found {
+		logger.Infof("operation %s already created by the request with identity %s", duplicated.ID, requestIdentity)
+		return b.responseForOperation(*duplicated), nil
+	}
 	if err != nil {
 		logger.Errorf("cannot save operation: %s", err)
 		return domain.ProvisionedServiceSpec{}, fmt.Errorf("cannot save operation")
@@ -196,11 +224,11 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		ServicePlanName: PlanNamesMapping[provisioningParameters.PlanID],
 		DashboardURL:    dashboardURL,
 		Parameters:      operation.ProvisioningParameters,
+		Labels:          parameters.Labels,
 	}
-	err = b.instanceStorage.Insert(instance)
+	err = b.insertInstance(instance, operation.Operation, logger)
 	if err != nil {
-		logger.Errorf("cannot save instance in storage: %s", err)
-		return domain.ProvisionedServiceSpec{}, fmt.Errorf("cannot save instance")
+		return domain.ProvisionedServiceSpec{}, err
 	}
 
 	logger.Info("Adding operation to provisioning queue")
@@ -275,6 +303,12 @@ func (b *ProvisionEndpoint) validateAndExtract(details domain.ProvisionDetails,
 			return ersContext, parameters, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
 		}
 	}
+	if err := validateAdditionalWorkerNodePools(details.PlanID, b.config.IncludeAdditionalParamsInSchema, parameters.AdditionalWorkerNodePools); err != nil {
+		return ersContext, parameters, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	if err := internal.ValidateLabels(parameters.Labels); err != nil {
+		return ersContext, parameters, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
 
 	planValidator, err := b.validator(&details, provider, ctx)
 	if err != nil {
@@ -341,9 +375,78 @@ func (b *ProvisionEndpoint) validateAndExtract(details domain.ProvisionDetails,
 		}
 	}
 
+	platformRegion, _ := middleware.RegionFromContext(ctx)
+	err = b.admissionPolicy.Admit(admission.Input{
+		Operation:      admission.OperationProvision,
+		PlanID:         details.PlanID,
+		PlanName:       PlanNamesMapping[details.PlanID],
+		PlatformRegion: platformRegion,
+		Parameters:     parameters,
+		ERSContext:     ersContext,
+	})
+	if err != nil {
+		logger.Infof("Provisioning rejected by admission rules: %s", err)
+		return ersContext, parameters, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "provisioning")
+	}
+
 	return ersContext, parameters, nil
 }
 
+// checkQuota rejects the request if the global account reached the limit of instances of the plan.
+// The check is repeated when the instance is inserted, this one only avoids creating operations of rejected requests.
+func (b *ProvisionEndpoint) checkQuota(globalAccountID, planID string, logger logrus.FieldLogger) error {
+	planName := PlanNamesMapping[planID]
+	limit, limited := b.quotas.Limit(globalAccountID, planName)
+	if !limited {
+		return nil
+	}
+	instancesByPlan, err := b.instanceStorage.GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID)
+	if err != nil {
+		logger.Errorf("cannot get number of instances for the global account: %s", err)
+		return fmt.Errorf("cannot check quota of the global account")
+	}
+	if instancesByPlan[planID] >= limit {
+		logger.Infof("Provisioning rejected, the global account reached the quota of %d %s instances", limit, planName)
+		return quotaExceededError(globalAccountID, planName, limit)
+	}
+	return nil
+}
+
+// insertInstance saves the instance, the quota of the global account is checked in the same storage call, so concurrent requests cannot exceed it.
+// If the quota is exceeded, the already saved operation is marked as failed.
+func (b *ProvisionEndpoint) insertInstance(instance internal.Instance, operation internal.Operation, logger logrus.FieldLogger) error {
+	limit, limited := b.quotas.Limit(instance.GlobalAccountID, instance.ServicePlanName)
+	if !limited {
+		if err := b.instanceStorage.Insert(instance); err != nil {
+			logger.Errorf("cannot save instance in storage: %s", err)
+			return fmt.Errorf("cannot save instance")
+		}
+		return nil
+	}
+
+	inserted, err := b.instanceStorage.InsertWithinQuota(instance, limit)
+	if err != nil {
+		logger.Errorf("cannot save instance in storage: %s", err)
+		return fmt.Errorf("cannot save instance")
+	}
+	if inserted {
+		return nil
+	}
+
+	logger.Infof("Provisioning rejected, the global account reached the quota of %d %s instances", limit, instance.ServicePlanName)
+	operation.State = domain.Failed
+	operation.Description = fmt.Sprintf("quota of %d %s instances exceeded", limit, instance.ServicePlanName)
+	if _, err := b.operationsStorage.UpdateOperation(operation); err != nil {
+		logger.Warnf("cannot mark operation of the rejected instance as failed: %s", err)
+	}
+	return quotaExceededError(instance.GlobalAccountID, instance.ServicePlanName, limit)
+}
+
+func quotaExceededError(globalAccountID, planName string, limit int) error {
+	err := fmt.Errorf("quota exceeded: the global account %s can have at most %d instances of the %s plan", globalAccountID, limit, planName)
+	return apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+}
+
 func isEuRestrictedAccess(ctx context.Context) bool {
 	platformRegion, _ := middleware.RegionFromContext(ctx)
 	return euaccess.IsEURestrictedAccess(platformRegion)
@@ -418,6 +521,22 @@ func (b *ProvisionEndpoint) handleExistingOperation(operation *internal.Provisio
 	}, nil
 }
 
+// responseForOperation returns the response of the request which created the provisioning operation
+func (b *ProvisionEndpoint) responseForOperation(operation internal.Operation) domain.ProvisionedServiceSpec {
+	instance := internal.Instance{
+		InstanceID:    operation.InstanceID,
+		ServicePlanID: operation.ProvisioningParameters.PlanID,
+	}
+	return domain.ProvisionedServiceSpec{
+		IsAsync:       true,
+		OperationData: operation.ID,
+		DashboardURL:  operation.DashboardURL,
+		Metadata: domain.InstanceMetadata{
+			Labels: ResponseLabels(internal.ProvisioningOperation{Operation: operation}, instance, b.config.URL, b.config.EnableKubeconfigURLLabel),
+		},
+	}
+}
+
 func (b *ProvisionEndpoint) determineLicenceType(planId string) *string {
 	if planId == AzureLitePlanID || IsTrialPlan(planId) {
 		return ptr.String(internal.LicenceTypeLite)
@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/admission"
 	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
+	"github.com/kyma-project/kyma-environment-broker/internal/quota"
 
 	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+	"github.com/pivotal-cf/brokerapi/v8/middlewares"
 
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
@@ -36,16 +41,17 @@ const (
 	subAccountID               = "3cb65e5b-e455-4799-bf35-be46e8f5a533"
 	userID                     = "test@test.pl"
 
-	instanceID           = "d3d5dca4-5dc8-44ee-a825-755c2a3fb839"
-	otherInstanceID      = "87bfaeaa-48eb-40d6-84f3-3d5368eed3eb"
-	existOperationID     = "920cbfd9-24e9-4aa2-aa77-879e9aabe140"
-	clusterName          = "cluster-testing"
-	region               = "eu"
-	brokerURL            = "example.com"
-	notEncodedKubeconfig = "apiVersion: v1\\nkind: Config"
-	encodedKubeconfig    = "YXBpVmVyc2lvbjogdjEKa2luZDogQ29uZmlnCmN1cnJlbnQtY29udGV4dDogc2hvb3QtLWt5bWEtZGV2LS1jbHVzdGVyLW5hbWUKY29udGV4dHM6CiAgLSBuYW1lOiBzaG9vdC0ta3ltYS1kZXYtLWNsdXN0ZXItbmFtZQogICAgY29udGV4dDoKICAgICAgY2x1c3Rlcjogc2hvb3QtLWt5bWEtZGV2LS1jbHVzdGVyLW5hbWUKICAgICAgdXNlcjogc2hvb3QtLWt5bWEtZGV2LS1jbHVzdGVyLW5hbWUtdG9rZW4KY2x1c3RlcnM6CiAgLSBuYW1lOiBzaG9vdC0ta3ltYS1kZXYtLWNsdXN0ZXItbmFtZQogICAgY2x1c3RlcjoKICAgICAgc2VydmVyOiBodHRwczovL2FwaS5jbHVzdGVyLW5hbWUua3ltYS1kZXYuc2hvb3QuY2FuYXJ5Lms4cy1oYW5hLm9uZGVtYW5kLmNvbQogICAgICBjZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YTogPi0KICAgICAgICBMUzB0TFMxQ1JVZEpUaUJEUlZKVVNVWkpRMEZVUlMwdExTMHQKdXNlcnM6CiAgLSBuYW1lOiBzaG9vdC0ta3ltYS1kZXYtLWNsdXN0ZXItbmFtZS10b2tlbgogICAgdXNlcjoKICAgICAgdG9rZW46ID4tCiAgICAgICAgdE9rRW4K"
-	shootName            = "own-cluster-name"
-	shootDomain          = "kyma-dev.shoot.canary.k8s-hana.ondemand.com"
+	instanceID                = "d3d5dca4-5dc8-44ee-a825-755c2a3fb839"
+	otherInstanceID           = "87bfaeaa-48eb-40d6-84f3-3d5368eed3eb"
+	otherGlobalAccountTrialID = "6b7c1b55-7e2b-4b8f-9d5e-0f3f8e2c4a11"
+	existOperationID          = "920cbfd9-24e9-4aa2-aa77-879e9aabe140"
+	clusterName               = "cluster-testing"
+	region                    = "eu"
+	brokerURL                 = "example.com"
+	notEncodedKubeconfig      = "apiVersion: v1\\nkind: Config"
+	encodedKubeconfig         = "YXBpVmVyc2lvbjogdjEKa2luZDogQ29uZmlnCmN1cnJlbnQtY29udGV4dDogc2hvb3QtLWt5bWEtZGV2LS1jbHVzdGVyLW5hbWUKY29udGV4dHM6CiAgLSBuYW1lOiBzaG9vdC0ta3ltYS1kZXYtLWNsdXN0ZXItbmFtZQogICAgY29udGV4dDoKICAgICAgY2x1c3Rlcjogc2hvb3QtLWt5bWEtZGV2LS1jbHVzdGVyLW5hbWUKICAgICAgdXNlcjogc2hvb3QtLWt5bWEtZGV2LS1jbHVzdGVyLW5hbWUtdG9rZW4KY2x1c3RlcnM6CiAgLSBuYW1lOiBzaG9vdC0ta3ltYS1kZXYtLWNsdXN0ZXItbmFtZQogICAgY2x1c3RlcjoKICAgICAgc2VydmVyOiBodHRwczovL2FwaS5jbHVzdGVyLW5hbWUua3ltYS1kZXYuc2hvb3QuY2FuYXJ5Lms4cy1oYW5hLm9uZGVtYW5kLmNvbQogICAgICBjZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YTogPi0KICAgICAgICBMUzB0TFMxQ1JVZEpUaUJEUlZKVVNVWkpRMEZVUlMwdExTMHQKdXNlcnM6CiAgLSBuYW1lOiBzaG9vdC0ta3ltYS1kZXYtLWNsdXN0ZXItbmFtZS10b2tlbgogICAgdXNlcjoKICAgICAgdG9rZW46ID4tCiAgICAgICAgdE9rRW4K"
+	shootName                 = "own-cluster-name"
+	shootDomain               = "kyma-dev.shoot.canary.k8s-hana.ondemand.com"
 )
 
 var dashboardConfig = dashboard.Config{LandscapeURL: "https://dashboard.example.com"}
@@ -83,6 +89,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -156,6 +164,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -233,6 +243,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -282,6 +294,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -355,6 +369,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -430,6 +446,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -479,6 +497,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -495,6 +515,167 @@ func TestProvision_Provision(t *testing.T) {
 		assert.EqualError(t, err, "trial Kyma was created for the global account, but there is only one allowed")
 	})
 
+	t.Run("provisioning over the quota of the global account is not allowed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Operations().InsertOperation(fixExistOperation())
+		assert.NoError(t, err)
+		err = memoryStorage.Instances().Insert(internal.Instance{
+			InstanceID:      instanceID,
+			GlobalAccountID: globalAccountID,
+			ServiceID:       serviceID,
+			ServicePlanID:   broker.AzurePlanID,
+		})
+		assert.NoError(t, err)
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", broker.AzurePlanID).Return(true)
+
+		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+			return &gqlschema.ClusterConfigInput{}, nil
+		}
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			nil,
+			factoryBuilder,
+			broker.PlansConfig{},
+			false,
+			planDefaults,
+			euaccess.WhitelistSet{},
+			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{
+				Default:        quota.Limits{broker.AzurePlanName: 5},
+				GlobalAccounts: map[string]quota.Limits{globalAccountID: {broker.AzurePlanName: 1}},
+			},
+			admission.Policy{},
+			logrus.StandardLogger(),
+			dashboardConfig,
+		)
+
+		// when
+		_, err = provisionEndpoint.Provision(fixRequestContext(t, "dummy"), "new-instance-id", domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        broker.AzurePlanID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, userID)),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+		assert.EqualError(t, err, fmt.Sprintf("quota exceeded: the global account %s can have at most 1 instances of the azure plan", globalAccountID))
+		_, err = memoryStorage.Instances().GetByID("new-instance-id")
+		assert.Error(t, err)
+	})
+
+	t.Run("provisioning racing with another provisioning over the quota is not allowed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Operations().InsertOperation(fixExistOperation())
+		assert.NoError(t, err)
+		err = memoryStorage.Instances().Insert(internal.Instance{
+			InstanceID:      instanceID,
+			GlobalAccountID: globalAccountID,
+			ServiceID:       serviceID,
+			ServicePlanID:   broker.AzurePlanID,
+		})
+		assert.NoError(t, err)
+		// the concurrent provisioning is not visible yet when the quota is checked for the first time
+		instances := staleCountInstances{Instances: memoryStorage.Instances()}
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", broker.AzurePlanID).Return(true)
+
+		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+			return &gqlschema.ClusterConfigInput{}, nil
+		}
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+			memoryStorage.Operations(),
+			instances,
+			nil,
+			factoryBuilder,
+			broker.PlansConfig{},
+			false,
+			planDefaults,
+			euaccess.WhitelistSet{},
+			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{Default: quota.Limits{broker.AzurePlanName: 1}},
+			admission.Policy{},
+			logrus.StandardLogger(),
+			dashboardConfig,
+		)
+
+		// when
+		_, err = provisionEndpoint.Provision(fixRequestContext(t, "dummy"), "new-instance-id", domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        broker.AzurePlanID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, userID)),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+		_, err = memoryStorage.Instances().GetByID("new-instance-id")
+		assert.Error(t, err)
+		operation, err := memoryStorage.Operations().GetProvisioningOperationByInstanceID("new-instance-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Failed, operation.State)
+	})
+
+	t.Run("provisioning rejected by admission rules is not allowed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", broker.AzurePlanID).Return(true)
+
+		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+			return &gqlschema.ClusterConfigInput{}, nil
+		}
+		policy, err := admission.NewPolicy([]admission.Rule{{
+			Name:       "big-machines",
+			Expression: `plan == "azure" && parameters.machineType == "Standard_D48_v3" && context.globalaccount_id != "premium"`,
+			Message:    "machine type Standard_D48_v3 is not allowed",
+		}})
+		require.NoError(t, err)
+		provisionEndpoint := broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}},
+			gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			nil,
+			factoryBuilder,
+			broker.PlansConfig{},
+			false,
+			planDefaults,
+			euaccess.WhitelistSet{},
+			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			policy,
+			logrus.StandardLogger(),
+			dashboardConfig,
+		)
+
+		// when
+		_, err = provisionEndpoint.Provision(fixRequestContext(t, "dummy"), "new-instance-id", domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        broker.AzurePlanID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "machineType": "Standard_D48_v3"}`, clusterName)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, userID)),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+		assert.EqualError(t, err, "machine type Standard_D48_v3 is not allowed")
+	})
+
 	t.Run("more than one trial is allowed", func(t *testing.T) {
 		// given
 		memoryStorage := storage.NewMemoryStorage()
@@ -529,6 +710,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -564,12 +747,13 @@ func TestProvision_Provision(t *testing.T) {
 	t.Run("provision trial", func(t *testing.T) {
 		// given
 		memoryStorage := storage.NewMemoryStorage()
-		memoryStorage.Instances().Insert(internal.Instance{
-			InstanceID:      instanceID,
+		err := memoryStorage.Instances().Insert(internal.Instance{
+			InstanceID:      otherGlobalAccountTrialID,
 			GlobalAccountID: "other-global-account",
 			ServiceID:       serviceID,
 			ServicePlanID:   broker.TrialPlanID,
 		})
+		require.NoError(t, err)
 
 		queue := &automock.Queue{}
 		queue.On("Add", mock.AnythingOfType("string"))
@@ -592,6 +776,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -655,6 +841,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -699,6 +887,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -741,6 +931,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -784,6 +976,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -825,6 +1019,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -873,6 +1069,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -918,6 +1116,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -969,6 +1169,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -1026,6 +1228,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -1083,6 +1287,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -1140,6 +1346,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{whitelistedGlobalAccountID: struct{}{}},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -1190,6 +1398,8 @@ func TestProvision_Provision(t *testing.T) {
 			planDefaults,
 			euaccess.WhitelistSet{},
 			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
 			logrus.StandardLogger(),
 			dashboardConfig,
 		)
@@ -1299,6 +1509,8 @@ func TestNetworkingValidation(t *testing.T) {
 				planDefaults,
 				euaccess.WhitelistSet{},
 				"request rejected, your globalAccountId is not whitelisted",
+				quota.Quotas{},
+				admission.Policy{},
 				logrus.StandardLogger(),
 				dashboardConfig,
 			)
@@ -1319,6 +1531,287 @@ func TestNetworkingValidation(t *testing.T) {
 
 }
 
+func TestAdditionalWorkerNodePoolsValidation(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		planID                  string
+		includeAdditionalParams bool
+		givenPools              string
+
+		expectedError bool
+	}{
+		"Valid pools": {
+			planID:                  broker.AzurePlanID,
+			includeAdditionalParams: true,
+			givenPools:              `[{"name": "memory", "machineType": "Standard_D8_v3", "autoScalerMin": 1, "autoScalerMax": 5}, {"name": "compute", "machineType": "Standard_D16_v3", "autoScalerMin": 0, "autoScalerMax": 3}]`,
+			expectedError:           false,
+		},
+		"Machine type not available for the provider": {
+			planID:                  broker.AzurePlanID,
+			includeAdditionalParams: true,
+			givenPools:              `[{"name": "memory", "machineType": "m5.xlarge", "autoScalerMin": 1, "autoScalerMax": 5}]`,
+			expectedError:           true,
+		},
+		"Duplicated names": {
+			planID:                  broker.AzurePlanID,
+			includeAdditionalParams: true,
+			givenPools:              `[{"name": "memory", "machineType": "Standard_D8_v3", "autoScalerMin": 1, "autoScalerMax": 5}, {"name": "memory", "machineType": "Standard_D16_v3", "autoScalerMin": 1, "autoScalerMax": 3}]`,
+			expectedError:           true,
+		},
+		"Name of the default pool": {
+			planID:                  broker.AzurePlanID,
+			includeAdditionalParams: true,
+			givenPools:              `[{"name": "cpu-worker-0", "machineType": "Standard_D8_v3", "autoScalerMin": 1, "autoScalerMax": 5}]`,
+			expectedError:           true,
+		},
+		"AutoScalerMin larger than AutoScalerMax": {
+			planID:                  broker.AzurePlanID,
+			includeAdditionalParams: true,
+			givenPools:              `[{"name": "memory", "machineType": "Standard_D8_v3", "autoScalerMin": 5, "autoScalerMax": 3}]`,
+			expectedError:           true,
+		},
+		"Additional parameters not enabled": {
+			planID:                  broker.AzurePlanID,
+			includeAdditionalParams: false,
+			givenPools:              `[{"name": "memory", "machineType": "Standard_D8_v3", "autoScalerMin": 1, "autoScalerMax": 5}]`,
+			expectedError:           true,
+		},
+		"Plan without additional pools": {
+			planID:                  broker.FreemiumPlanID,
+			includeAdditionalParams: true,
+			givenPools:              `[{"name": "memory", "machineType": "Standard_D8_v3", "autoScalerMin": 1, "autoScalerMax": 5}]`,
+			expectedError:           true,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+
+			queue := &automock.Queue{}
+			queue.On("Add", mock.AnythingOfType("string"))
+
+			factoryBuilder := &automock.PlanValidator{}
+			factoryBuilder.On("IsPlanSupport", mock.AnythingOfType("string")).Return(true)
+
+			planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+				return &gqlschema.ClusterConfigInput{}, nil
+			}
+			provisionEndpoint := broker.NewProvision(
+				broker.Config{EnablePlans: []string{"gcp", "azure", "free"}, IncludeAdditionalParamsInSchema: tc.includeAdditionalParams},
+				gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+				memoryStorage.Operations(),
+				memoryStorage.Instances(),
+				queue,
+				factoryBuilder,
+				broker.PlansConfig{},
+				false,
+				planDefaults,
+				euaccess.WhitelistSet{},
+				"request rejected, your globalAccountId is not whitelisted",
+				quota.Quotas{},
+				admission.Policy{},
+				logrus.StandardLogger(),
+				dashboardConfig,
+			)
+
+			// when
+			response, err := provisionEndpoint.Provision(fixRequestContextWithProvider(t, "cf-eu10", "azure"), instanceID,
+				domain.ProvisionDetails{
+					ServiceID:     serviceID,
+					PlanID:        tc.planID,
+					RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "cluster-name", "additionalWorkerNodePools": %s}`, tc.givenPools)),
+					RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, userID)),
+				}, true)
+
+			// then
+			assert.Equal(t, tc.expectedError, err != nil)
+			if err == nil {
+				operation, err := memoryStorage.Operations().GetProvisioningOperationByID(response.OperationData)
+				require.NoError(t, err)
+				assert.Len(t, operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools, 2)
+			}
+		})
+	}
+}
+
+func TestLabelsValidation(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		givenLabels string
+
+		expectedError bool
+	}{
+		"Valid labels": {
+			givenLabels:   `{"team": "payments", "example.com/env": "staging"}`,
+			expectedError: false,
+		},
+		"Invalid key": {
+			givenLabels:   `{"not a key": "staging"}`,
+			expectedError: true,
+		},
+		"Invalid value": {
+			givenLabels:   `{"team": "-payments"}`,
+			expectedError: true,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+
+			queue := &automock.Queue{}
+			queue.On("Add", mock.AnythingOfType("string"))
+
+			factoryBuilder := &automock.PlanValidator{}
+			factoryBuilder.On("IsPlanSupport", mock.AnythingOfType("string")).Return(true)
+
+			planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+				return &gqlschema.ClusterConfigInput{}, nil
+			}
+			provisionEndpoint := broker.NewProvision(
+				broker.Config{EnablePlans: []string{"gcp", "azure"}},
+				gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+				memoryStorage.Operations(),
+				memoryStorage.Instances(),
+				queue,
+				factoryBuilder,
+				broker.PlansConfig{},
+				false,
+				planDefaults,
+				euaccess.WhitelistSet{},
+				"request rejected, your globalAccountId is not whitelisted",
+				quota.Quotas{},
+				admission.Policy{},
+				logrus.StandardLogger(),
+				dashboardConfig,
+			)
+
+			// when
+			_, err := provisionEndpoint.Provision(fixRequestContextWithProvider(t, "cf-eu10", "azure"), instanceID,
+				domain.ProvisionDetails{
+					ServiceID:     serviceID,
+					PlanID:        broker.AzurePlanID,
+					RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "cluster-name", "labels": %s}`, tc.givenLabels)),
+					RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, userID)),
+				}, true)
+
+			// then
+			assert.Equal(t, tc.expectedError, err != nil)
+			if err == nil {
+				instance, err := memoryStorage.Instances().GetByID(instanceID)
+				require.NoError(t, err)
+				assert.Equal(t, map[string]string{"team": "payments", "example.com/env": "staging"}, instance.Labels)
+			}
+		})
+	}
+}
+
+func TestProvision_RequestIdentity(t *testing.T) {
+	newProvisionEndpoint := func(memoryStorage storage.BrokerStorage, queue broker.Queue) *broker.ProvisionEndpoint {
+		factoryBuilder := &automock.PlanValidator{}
+		factoryBuilder.On("IsPlanSupport", mock.AnythingOfType("string")).Return(true)
+		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+			return &gqlschema.ClusterConfigInput{}, nil
+		}
+		return broker.NewProvision(
+			broker.Config{EnablePlans: []string{"gcp", "azure"}, RequestIdentityWindow: time.Hour},
+			gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+			memoryStorage.Operations(),
+			memoryStorage.Instances(),
+			queue,
+			factoryBuilder,
+			broker.PlansConfig{},
+			false,
+			planDefaults,
+			euaccess.WhitelistSet{},
+			"request rejected, your globalAccountId is not whitelisted",
+			quota.Quotas{},
+			admission.Policy{},
+			logrus.StandardLogger(),
+			dashboardConfig,
+		)
+	}
+	details := domain.ProvisionDetails{
+		ServiceID:     serviceID,
+		PlanID:        broker.AzurePlanID,
+		RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s"}`, clusterName)),
+		RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, userID)),
+	}
+	requestContext := func(requestIdentity string) context.Context {
+		return context.WithValue(fixRequestContextWithProvider(t, "cf-eu10", "azure"), middlewares.RequestIdentityKey, requestIdentity)
+	}
+
+	t.Run("should return the original response for the repeated request", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+		provisionEndpoint := newProvisionEndpoint(memoryStorage, queue)
+
+		first, err := provisionEndpoint.Provision(requestContext("request-1"), instanceID, details, true)
+		require.NoError(t, err)
+
+		// when
+		second, err := provisionEndpoint.Provision(requestContext("request-1"), instanceID, details, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+		operation, err := memoryStorage.Operations().GetOperationByRequestIdentity("request-1")
+		require.NoError(t, err)
+		assert.Equal(t, first.OperationData, operation.ID)
+		queue.AssertNumberOfCalls(t, "Add", 1)
+	})
+
+	t.Run("should create one operation for concurrent duplicated requests", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+		provisionEndpoint := newProvisionEndpoint(memoryStorage, queue)
+
+		const requests = 10
+		responses := make([]domain.ProvisionedServiceSpec, requests)
+		errs := make([]error, requests)
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i], errs[i] = provisionEndpoint.Provision(requestContext("request-1"), instanceID, details, true)
+			}(i)
+		}
+		wg.Wait()
+
+		// then
+		for i := 0; i < requests; i++ {
+			require.NoError(t, errs[i])
+			assert.Equal(t, responses[0].OperationData, responses[i].OperationData)
+		}
+		operations, err := memoryStorage.Operations().ListProvisioningOperationsByInstanceID(instanceID)
+		require.NoError(t, err)
+		assert.Len(t, operations, 1)
+		queue.AssertNumberOfCalls(t, "Add", 1)
+	})
+
+	t.Run("should reject request identity used for another instance", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+		provisionEndpoint := newProvisionEndpoint(memoryStorage, queue)
+
+		_, err := provisionEndpoint.Provision(requestContext("request-1"), instanceID, details, true)
+		require.NoError(t, err)
+
+		// when
+		_, err = provisionEndpoint.Provision(requestContext("request-1"), otherInstanceID, details, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+	})
+}
+
 func TestRegionValidation(t *testing.T) {
 
 	for tn, tc := range map[string]struct {
@@ -1395,6 +1888,8 @@ func TestRegionValidation(t *testing.T) {
 				planDefaults,
 				euaccess.WhitelistSet{},
 				"request rejected, your globalAccountId is not whitelisted",
+				quota.Quotas{},
+				admission.Policy{},
 				logrus.StandardLogger(),
 				dashboardConfig,
 			)
@@ -1470,3 +1965,12 @@ func fixDNSProviders() gardener.DNSProvidersData {
 		},
 	}
 }
+
+// staleCountInstances returns no instances when the quota is checked before the operation is created
+type staleCountInstances struct {
+	storage.Instances
+}
+
+func (s staleCountInstances) GetNumberOfInstancesByPlanForGlobalAccountID(string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
@@ -17,16 +17,18 @@ import (
 )
 
 type DeprovisionEndpoint struct {
-	log logrus.FieldLogger
+	config Config
+	log    logrus.FieldLogger
 
 	instancesStorage  storage.Instances
-	operationsStorage storage.Deprovisioning
+	operationsStorage storage.Operations
 
 	queue Queue
 }
 
-func NewDeprovision(instancesStorage storage.Instances, operationsStorage storage.Operations, q Queue, log logrus.FieldLogger) *DeprovisionEndpoint {
+func NewDeprovision(cfg Config, instancesStorage storage.Instances, operationsStorage storage.Operations, q Queue, log logrus.FieldLogger) *DeprovisionEndpoint {
 	return &DeprovisionEndpoint{
+		config:            cfg,
 		log:               log.WithField("service", "DeprovisionEndpoint"),
 		instancesStorage:  instancesStorage,
 		operationsStorage: operationsStorage,
@@ -42,6 +44,17 @@ func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string
 	logger := b.log.WithFields(logrus.Fields{"instanceID": instanceID})
 	logger.Infof("Deprovisioning triggered, details: %+v", details)
 
+	requestIdentity, previousOperation, err := operationForRequestIdentity(ctx, b.operationsStorage, b.config.RequestIdentityWindow, instanceID, internal.OperationTypeDeprovision, logger)
+	if err != nil {
+		return domain.DeprovisionServiceSpec{}, err
+	}
+	if previousOperation != nil {
+		return domain.DeprovisionServiceSpec{
+			IsAsync:       true,
+			OperationData: previousOperation.ID,
+		}, nil
+	}
+
 	instance, err := b.instancesStorage.GetByID(instanceID)
 	switch {
 	case err == nil:
@@ -95,7 +108,15 @@ func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string
 	if v := ctx.Value("User-Agent"); v != nil {
 		operation.UserAgent = v.(string)
 	}
+	operation.RequestIdentity = requestIdentity
 	err = b.operationsStorage.InsertDeprovisioningOperation(operation)
+	if duplicated, found := operationForDuplicatedRequest(b.operationsStorage, requestIdentity, err, instanceID, internal.OperationTypeDeprovision); found {
+		logger.Infof("operation %s already created by the request with identity %s", duplicated.ID, requestIdentity)
+		return domain.DeprovisionServiceSpec{
+			IsAsync:       true,
+			OperationData: duplicated.ID,
+		}, nil
+	}
 	if err != nil {
 		logger.Errorf("cannot save operation: %s", err)
 		return domain.DeprovisionServiceSpec{}, fmt.Errorf("cannot save operation")
@@ -2,13 +2,16 @@ package broker
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/middlewares"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -27,7 +30,7 @@ func TestDeprovisionEndpoint_DeprovisionNotExistingInstance(t *testing.T) {
 	queue := &automock.Queue{}
 	queue.On("Add", mock.AnythingOfType("string"))
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
+	svc := NewDeprovision(Config{}, memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
 
 	// when
 	_, err := svc.Deprovision(context.TODO(), "inst-0001", domain.DeprovisionDetails{}, true)
@@ -45,7 +48,7 @@ func TestDeprovisionEndpoint_DeprovisionExistingInstance(t *testing.T) {
 	queue := &automock.Queue{}
 	queue.On("Add", mock.AnythingOfType("string"))
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
+	svc := NewDeprovision(Config{}, memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
 
 	// when
 	_, err = svc.Deprovision(context.TODO(), instanceID, domain.DeprovisionDetails{}, true)
@@ -69,7 +72,7 @@ func TestDeprovisionEndpoint_DeprovisionExistingOperationInProgress(t *testing.T
 	queue := &automock.Queue{}
 	queue.On("Add", mock.AnythingOfType("string"))
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
+	svc := NewDeprovision(Config{}, memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
 
 	// when
 	res, err := svc.Deprovision(context.TODO(), instanceID, domain.DeprovisionDetails{}, true)
@@ -96,7 +99,7 @@ func TestDeprovisionEndpoint_DeprovisionExistingOperationFailed(t *testing.T) {
 	queue := &automock.Queue{}
 	queue.On("Add", mock.Anything)
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
+	svc := NewDeprovision(Config{}, memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
 
 	// when
 	res, err := svc.Deprovision(context.TODO(), instanceID, domain.DeprovisionDetails{}, true)
@@ -110,6 +113,98 @@ func TestDeprovisionEndpoint_DeprovisionExistingOperationFailed(t *testing.T) {
 	assert.Equal(t, domain.LastOperationState("pending"), operation.State)
 }
 
+func TestDeprovisionEndpoint_RequestIdentity(t *testing.T) {
+	requestContext := func(requestIdentity string) context.Context {
+		return context.WithValue(context.TODO(), middlewares.RequestIdentityKey, requestIdentity)
+	}
+	completeWithNotCompletedSteps := func(t *testing.T, memoryStorage storage.BrokerStorage, operationID string) {
+		operation, err := memoryStorage.Operations().GetDeprovisioningOperationByID(operationID)
+		require.NoError(t, err)
+		operation.State = domain.Succeeded
+		operation.ExcutedButNotCompleted = []string{"Remove_Runtime"}
+		_, err = memoryStorage.Operations().UpdateDeprovisioningOperation(*operation)
+		require.NoError(t, err)
+	}
+
+	t.Run("should return the original operation for the repeated request", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		require.NoError(t, memoryStorage.Instances().Insert(fixInstance()))
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+		svc := NewDeprovision(Config{RequestIdentityWindow: time.Hour}, memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
+
+		first, err := svc.Deprovision(requestContext("request-1"), instanceID, domain.DeprovisionDetails{}, true)
+		require.NoError(t, err)
+		completeWithNotCompletedSteps(t, memoryStorage, first.OperationData)
+
+		// when
+		second, err := svc.Deprovision(requestContext("request-1"), instanceID, domain.DeprovisionDetails{}, true)
+		require.NoError(t, err)
+		third, err := svc.Deprovision(requestContext("request-2"), instanceID, domain.DeprovisionDetails{}, true)
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first.OperationData, third.OperationData)
+		queue.AssertNumberOfCalls(t, "Add", 2)
+	})
+
+	t.Run("should process the repeated request as a new one after the window", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		require.NoError(t, memoryStorage.Instances().Insert(fixInstance()))
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+		svc := NewDeprovision(Config{RequestIdentityWindow: time.Nanosecond}, memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
+
+		first, err := svc.Deprovision(requestContext("request-1"), instanceID, domain.DeprovisionDetails{}, true)
+		require.NoError(t, err)
+		completeWithNotCompletedSteps(t, memoryStorage, first.OperationData)
+
+		// when
+		second, err := svc.Deprovision(requestContext("request-1"), instanceID, domain.DeprovisionDetails{}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.NotEqual(t, first.OperationData, second.OperationData)
+	})
+
+	t.Run("should create one operation for concurrent duplicated requests", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		require.NoError(t, memoryStorage.Instances().Insert(fixInstance()))
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+		svc := NewDeprovision(Config{RequestIdentityWindow: time.Hour}, memoryStorage.Instances(), memoryStorage.Operations(), queue, logrus.StandardLogger())
+
+		const requests = 10
+		responses := make([]domain.DeprovisionServiceSpec, requests)
+		errs := make([]error, requests)
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i], errs[i] = svc.Deprovision(requestContext("request-1"), instanceID, domain.DeprovisionDetails{}, true)
+			}(i)
+		}
+		wg.Wait()
+
+		// then
+		for i := 0; i < requests; i++ {
+			require.NoError(t, errs[i])
+			assert.Equal(t, responses[0].OperationData, responses[i].OperationData)
+		}
+		operations, err := memoryStorage.Operations().ListDeprovisioningOperationsByInstanceID(instanceID)
+		require.NoError(t, err)
+		assert.Len(t, operations, 1)
+		queue.AssertNumberOfCalls(t, "Add", 1)
+	})
+}
+
 func fixDeprovisioningOperation(state domain.LastOperationState) internal.DeprovisioningOperation {
 	deprovisioningOperation := fixture.FixDeprovisioningOperation(operationID, instanceID)
 	deprovisioningOperation.State = state
@@ -8,7 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/admission"
 	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
+	"github.com/kyma-project/kyma-environment-broker/internal/quota"
 
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
@@ -63,6 +65,8 @@ func TestGetEndpoint_GetProvisioningInstance(t *testing.T) {
 		planDefaults,
 		euaccess.WhitelistSet{},
 		"request rejected, your globalAccountId is not whitelisted",
+		quota.Quotas{},
+		admission.Policy{},
 		logrus.StandardLogger(),
 		dashboardConfig,
 	)
@@ -3,6 +3,7 @@ package broker_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/kyma-environment-broker/internal"
@@ -154,6 +155,12 @@ func TestLastOperation_LastOperation(t *testing.T) {
 		updateOp.State = orchestration.Canceled
 		err := memoryStorage.Operations().InsertUpdatingOperation(updateOp)
 		assert.NoError(t, err)
+		cancelingOp := fixture.FixUpdatingOperation("canceling-op-id", instID)
+		cancelingOp.State = orchestration.Canceling
+		cancelingOp.Description = "canceling"
+		cancelingOp.CreatedAt = updateOp.CreatedAt.Add(time.Minute)
+		err = memoryStorage.Operations().InsertUpdatingOperation(cancelingOp)
+		assert.NoError(t, err)
 
 		lastOperationEndpoint := broker.NewLastOperation(memoryStorage.Operations(), logrus.StandardLogger())
 
@@ -164,7 +171,7 @@ func TestLastOperation_LastOperation(t *testing.T) {
 		// then
 		assert.Equal(t, domain.LastOperation{
 			State:       domain.Succeeded,
-			Description: updateOp.Description,
+			Description: cancelingOp.Description,
 		}, response)
 
 		// when
@@ -178,6 +185,31 @@ func TestLastOperation_LastOperation(t *testing.T) {
 			Description: updateOp.Description,
 		}, response)
 	})
+	t.Run("Should skip canceled operations when getting the last operation", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		provisioningOp := fixOperation()
+		provisioningOp.ID = "provisioning-op-id"
+		provisioningOp.CreatedAt = time.Now().Add(-time.Hour)
+		err := memoryStorage.Operations().InsertOperation(provisioningOp)
+		assert.NoError(t, err)
+		updateOp := fixture.FixUpdatingOperation(operationID, instID)
+		updateOp.State = orchestration.Canceled
+		err = memoryStorage.Operations().InsertUpdatingOperation(updateOp)
+		assert.NoError(t, err)
+
+		lastOperationEndpoint := broker.NewLastOperation(memoryStorage.Operations(), logrus.StandardLogger())
+
+		// when
+		response, err := lastOperationEndpoint.LastOperation(context.TODO(), instID, domain.PollDetails{OperationData: ""})
+		assert.NoError(t, err)
+
+		// then
+		assert.Equal(t, domain.LastOperation{
+			State:       domain.Succeeded,
+			Description: provisioningOp.Description,
+		}, response)
+	})
 }
 
 func fixOperation() internal.Operation {
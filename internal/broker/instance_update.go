@@ -17,7 +17,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/admission"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
@@ -41,7 +43,9 @@ type UpdateEndpoint struct {
 
 	operationStorage storage.Operations
 
-	updatingQueue Queue
+	updatingQueue    Queue
+	instanceUpgrader InstanceUpgrader
+	admissionPolicy  admission.Policy
 
 	plansConfig  PlansConfig
 	planDefaults PlanDefaults
@@ -57,6 +61,8 @@ func NewUpdate(cfg Config,
 	processingEnabled bool,
 	subAccountMovementEnabled bool,
 	queue Queue,
+	instanceUpgrader InstanceUpgrader,
+	admissionPolicy admission.Policy,
 	plansConfig PlansConfig,
 	planDefaults PlanDefaults,
 	log logrus.FieldLogger,
@@ -72,6 +78,8 @@ func NewUpdate(cfg Config,
 		processingEnabled:         processingEnabled,
 		subAccountMovementEnabled: subAccountMovementEnabled,
 		updatingQueue:             queue,
+		instanceUpgrader:          instanceUpgrader,
+		admissionPolicy:           admissionPolicy,
 		plansConfig:               plansConfig,
 		planDefaults:              planDefaults,
 		dashboardConfig:           dashboardConfig,
@@ -81,7 +89,7 @@ func NewUpdate(cfg Config,
 // Update modifies an existing service instance
 //
 //	PATCH /v2/service_instances/{instance_id}
-func (b *UpdateEndpoint) Update(_ context.Context, instanceID string, details domain.UpdateDetails, asyncAllowed bool) (domain.UpdateServiceSpec, error) {
+func (b *UpdateEndpoint) Update(ctx context.Context, instanceID string, details domain.UpdateDetails, asyncAllowed bool) (domain.UpdateServiceSpec, error) {
 	logger := b.log.WithField("instanceID", instanceID)
 	logger.Infof("Updating instanceID: %s", instanceID)
 	logger.Infof("Updating asyncAllowed: %v", asyncAllowed)
@@ -97,6 +105,14 @@ func (b *UpdateEndpoint) Update(_ context.Context, instanceID string, details do
 	}
 	logger.Infof("Plan ID/Name: %s/%s", instance.ServicePlanID, PlanNamesMapping[instance.ServicePlanID])
 
+	requestIdentity, previousOperation, err := operationForRequestIdentity(ctx, b.operationStorage, b.config.RequestIdentityWindow, instanceID, internal.OperationTypeUpdate, logger)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+	if previousOperation != nil {
+		return b.responseForOperation(instance, *previousOperation), nil
+	}
+
 	var ersContext internal.ERSContext
 	err = json.Unmarshal(details.RawContext, &ersContext)
 	if err != nil {
@@ -106,6 +122,15 @@ func (b *UpdateEndpoint) Update(_ context.Context, instanceID string, details do
 	logger.Infof("Global account ID: %s active: %s", instance.GlobalAccountID, ptr.BoolAsString(ersContext.Active))
 	logger.Infof("Received context: %s", marshallRawContext(hideSensitiveDataFromRawContext(details.RawContext)))
 
+	if err := b.validatePlanChange(instance, details, ersContext, logger); err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+
+	maintenanceUpgrades, err := b.maintenanceUpgrades(instance, details, logger)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+
 	// validation of incoming input
 	if err := b.validateWithJsonSchemaValidator(details, instance); err != nil {
 		return domain.UpdateServiceSpec{}, err
@@ -140,6 +165,10 @@ func (b *UpdateEndpoint) Update(_ context.Context, instanceID string, details do
 		}
 	}
 
+	if len(maintenanceUpgrades) > 0 {
+		return b.processMaintenanceInfoUpgrade(instance, details, maintenanceUpgrades, lastProvisioningOperation, logger)
+	}
+
 	dashboardURL := instance.DashboardURL
 	if b.dashboardConfig.LandscapeURL != "" {
 		dashboardURL = fmt.Sprintf("%s/?kubeconfigID=%s", b.dashboardConfig.LandscapeURL, instanceID)
@@ -155,7 +184,7 @@ func (b *UpdateEndpoint) Update(_ context.Context, instanceID string, details do
 		// NOTE: KEB currently can't process update parameters in one call along with context update
 		// this block makes it that KEB ignores any parameters updates if context update changed suspension state
 		if !suspendStatusChange && !instance.IsExpired() {
-			return b.processUpdateParameters(instance, details, lastProvisioningOperation, asyncAllowed, ersContext, logger)
+			return b.processUpdateParameters(instance, details, lastProvisioningOperation, asyncAllowed, ersContext, requestIdentity, logger)
 		}
 	}
 
@@ -169,9 +198,130 @@ func (b *UpdateEndpoint) Update(_ context.Context, instanceID string, details do
 	}, nil
 }
 
+// validatePlanChange rejects plan changes which cannot be processed, so the plan change is never silently dropped.
+// The plan change is processed by the update operation, which is not created if update processing is disabled,
+// for an expired instance, or if the request changes the suspension.
+func (b *UpdateEndpoint) validatePlanChange(instance *internal.Instance, details domain.UpdateDetails, ersContext internal.ERSContext, logger logrus.FieldLogger) error {
+	if !isPlanChange(instance, details) {
+		return nil
+	}
+	logger.Infof("Plan change requested from %s to %s", PlanNamesMapping[instance.ServicePlanID], PlanNamesMapping[details.PlanID])
+	if !b.config.EnablePlanUpgrades || !b.processingEnabled {
+		return apiresponses.NewFailureResponse(fmt.Errorf("plan change is not supported"), http.StatusUnprocessableEntity, "")
+	}
+	if instance.IsExpired() {
+		return apiresponses.NewFailureResponse(fmt.Errorf("plan change of an expired instance is not supported"), http.StatusUnprocessableEntity, "")
+	}
+	suspended := instance.Parameters.ErsContext.Active != nil && !*instance.Parameters.ErsContext.Active
+	if suspended || (ersContext.Active != nil && !*ersContext.Active) {
+		return apiresponses.NewFailureResponse(fmt.Errorf("plan change of a suspended instance is not supported"), http.StatusUnprocessableEntity, "")
+	}
+	if !b.config.EnablePlans.Contains(PlanNamesMapping[details.PlanID]) {
+		return apiresponses.NewFailureResponse(fmt.Errorf("plan ID %q is not recognized", details.PlanID), http.StatusBadRequest, "")
+	}
+	if err := ValidatePlanUpgrade(instance.ServicePlanID, details.PlanID, instance.Provider); err != nil {
+		logger.Warnf("invalid plan change: %s", err)
+		return apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	return nil
+}
+
+// maintenanceUpgrades validates the requested maintenance_info and returns upgrades needed to move the instance to it.
+// A maintenance_info matching the versions installed on the instance is ignored, so it can be sent along with other changes.
+func (b *UpdateEndpoint) maintenanceUpgrades(instance *internal.Instance, details domain.UpdateDetails, logger logrus.FieldLogger) ([]orchestration.Type, error) {
+	if details.MaintenanceInfo == nil {
+		return nil, nil
+	}
+	current := b.config.MaintenanceInfo()
+	if current == nil || !current.Equals(*details.MaintenanceInfo) {
+		logger.Warnf("maintenance_info version %q does not match the catalog", details.MaintenanceInfo.Version)
+		return nil, apiresponses.ErrMaintenanceInfoConflict
+	}
+	installedKyma, installedKubernetes, err := b.installedVersions(instance, details)
+	if err != nil {
+		logger.Errorf("unable to get versions installed on the instance: %s", err.Error())
+		return nil, fmt.Errorf("unable to process the update")
+	}
+	upgrades := maintenanceUpgradeTypes(installedKyma, installedKubernetes, details.MaintenanceInfo)
+	if len(upgrades) == 0 {
+		logger.Infof("Instance is already on maintenance_info version %s", details.MaintenanceInfo.Version)
+		return nil, nil
+	}
+	if isPlanChange(instance, details) || len(details.RawParameters) != 0 {
+		return nil, apiresponses.NewFailureResponse(fmt.Errorf("maintenance_info upgrade cannot be combined with other changes"), http.StatusUnprocessableEntity, "")
+	}
+	if instance.Parameters.ErsContext.Active != nil && !*instance.Parameters.ErsContext.Active {
+		return nil, apiresponses.NewFailureResponse(fmt.Errorf("upgrade of a suspended instance is not supported"), http.StatusUnprocessableEntity, "")
+	}
+	return upgrades, nil
+}
+
+// installedVersions returns Kyma and Kubernetes versions stored in the runtime states of the instance.
+// Versions not found in the runtime states are taken from the previous maintenance_info sent by the platform.
+func (b *UpdateEndpoint) installedVersions(instance *internal.Instance, details domain.UpdateDetails) (string, string, error) {
+	var kymaVersion, kubernetesVersion string
+	if details.PreviousValues.MaintenanceInfo != nil {
+		kymaVersion, kubernetesVersion = parseMaintenanceInfoVersion(details.PreviousValues.MaintenanceInfo.Version)
+	}
+	if instance.RuntimeID == "" {
+		return kymaVersion, kubernetesVersion, nil
+	}
+
+	state, err := b.runtimeStates.GetLatestWithKymaVersionByRuntimeID(instance.RuntimeID)
+	switch {
+	case err == nil:
+		kymaVersion = state.GetKymaVersion()
+	case !dberr.IsNotFound(err):
+		return "", "", err
+	}
+
+	states, err := b.runtimeStates.ListByRuntimeID(instance.RuntimeID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return "", "", err
+	}
+	var latest time.Time
+	for _, s := range states {
+		if s.ClusterConfig.KubernetesVersion != "" && s.CreatedAt.After(latest) {
+			kubernetesVersion = s.ClusterConfig.KubernetesVersion
+			latest = s.CreatedAt
+		}
+	}
+	return kymaVersion, kubernetesVersion, nil
+}
+
+// processMaintenanceInfoUpgrade schedules upgrades of the instance to the versions published in the catalog.
+// The upgrades are processed in the background by the orchestration queues, that's why the response is synchronous.
+func (b *UpdateEndpoint) processMaintenanceInfoUpgrade(instance *internal.Instance, details domain.UpdateDetails, upgrades []orchestration.Type, lastProvisioningOperation *internal.ProvisioningOperation, logger logrus.FieldLogger) (domain.UpdateServiceSpec, error) {
+	for _, upgradeType := range upgrades {
+		orchestrationID, err := b.instanceUpgrader.Upgrade(*instance, upgradeType)
+		if err != nil {
+			logger.Errorf("unable to create %s orchestration: %s", upgradeType, err.Error())
+			return domain.UpdateServiceSpec{}, fmt.Errorf("unable to process the upgrade")
+		}
+		logger.Infof("Upgrade to maintenance_info version %s scheduled with the %s orchestration %s", details.MaintenanceInfo.Version, upgradeType, orchestrationID)
+	}
+
+	return domain.UpdateServiceSpec{
+		IsAsync:       false,
+		DashboardURL:  instance.DashboardURL,
+		OperationData: "",
+		Metadata: domain.InstanceMetadata{
+			Labels: ResponseLabels(*lastProvisioningOperation, *instance, b.config.URL, b.config.EnableKubeconfigURLLabel),
+		},
+	}, nil
+}
+
+func isPlanChange(instance *internal.Instance, details domain.UpdateDetails) bool {
+	return details.PlanID != "" && details.PlanID != instance.ServicePlanID
+}
+
 func (b *UpdateEndpoint) validateWithJsonSchemaValidator(details domain.UpdateDetails, instance *internal.Instance) error {
 	if len(details.RawParameters) > 0 {
-		planValidator, err := b.getJsonSchemaValidator(instance.Provider, instance.ServicePlanID, instance.ProviderRegion)
+		planID := instance.ServicePlanID
+		if isPlanChange(instance, details) {
+			planID = details.PlanID
+		}
+		planValidator, err := b.getJsonSchemaValidator(instance.Provider, planID, instance.ProviderRegion)
 		if err != nil {
 			return fmt.Errorf("while creating plan validator: %w", err)
 		}
@@ -187,13 +337,13 @@ func (b *UpdateEndpoint) validateWithJsonSchemaValidator(details domain.UpdateDe
 }
 
 func shouldUpdate(instance *internal.Instance, details domain.UpdateDetails, ersContext internal.ERSContext) bool {
-	if len(details.RawParameters) != 0 {
+	if len(details.RawParameters) != 0 || isPlanChange(instance, details) {
 		return true
 	}
 	return ersContext.ERSUpdate()
 }
 
-func (b *UpdateEndpoint) processUpdateParameters(instance *internal.Instance, details domain.UpdateDetails, lastProvisioningOperation *internal.ProvisioningOperation, asyncAllowed bool, ersContext internal.ERSContext, logger logrus.FieldLogger) (domain.UpdateServiceSpec, error) {
+func (b *UpdateEndpoint) processUpdateParameters(instance *internal.Instance, details domain.UpdateDetails, lastProvisioningOperation *internal.ProvisioningOperation, asyncAllowed bool, ersContext internal.ERSContext, requestIdentity string, logger logrus.FieldLogger) (domain.UpdateServiceSpec, error) {
 	if !shouldUpdate(instance, details, ersContext) {
 		logger.Debugf("Parameters not provided, skipping processing update parameters")
 		return domain.UpdateServiceSpec{
@@ -229,12 +379,28 @@ func (b *UpdateEndpoint) processUpdateParameters(instance *internal.Instance, de
 	operationID := uuid.New().String()
 	logger = logger.WithField("operationID", operationID)
 
-	logger.Debugf("creating update operation %v", params)
-	operation := internal.NewUpdateOperation(operationID, instance, params)
+	planChange := isPlanChange(instance, details)
 	planID := instance.Parameters.PlanID
 	if len(details.PlanID) != 0 {
 		planID = details.PlanID
 	}
+	if err := internal.ValidateLabels(params.Labels); err != nil {
+		logger.Errorf("invalid labels: %s", err.Error())
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if params.AdditionalWorkerNodePools != nil {
+		if err := validateAdditionalWorkerNodePools(planID, b.config.IncludeAdditionalParamsInSchema, *params.AdditionalWorkerNodePools); err != nil {
+			logger.Errorf("invalid additional worker node pools: %s", err.Error())
+			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+		}
+	}
+	if params.OptionalComponentsToInstall != nil {
+		if err := validateOptionalComponents(b.config.OptionalComponents, *params.OptionalComponentsToInstall); err != nil {
+			logger.Errorf("invalid optional components: %s", err.Error())
+			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+		}
+	}
 	defaults, err := b.planDefaults(planID, instance.Provider, &instance.Provider)
 	if err != nil {
 		logger.Errorf("unable to obtain plan defaults: %s", err.Error())
@@ -244,16 +410,38 @@ func (b *UpdateEndpoint) processUpdateParameters(instance *internal.Instance, de
 	if defaults.GardenerConfig != nil {
 		p := defaults.GardenerConfig
 		autoscalerMin, autoscalerMax = p.AutoScalerMin, p.AutoScalerMax
+		if planChange {
+			// the cluster is upgraded to the new plan defaults unless the parameters say otherwise
+			params.ApplyPlanDefaults(p.MachineType, p.AutoScalerMin, p.AutoScalerMax)
+		}
+	}
+
+	if err := b.admit(instance, planID, params); err != nil {
+		logger.Infof("Update rejected by admission rules: %s", err)
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "update")
+	}
+
+	logger.Debugf("creating update operation %v", params)
+	operation := internal.NewUpdateOperation(operationID, instance, params)
+	if planChange {
+		operation.ProvisioningParameters.PlanID = planID
+		operation.PreviousPlanID = instance.ServicePlanID
 	}
 	if err := operation.ProvisioningParameters.Parameters.AutoScalerParameters.Validate(autoscalerMin, autoscalerMax); err != nil {
 		logger.Errorf("invalid autoscaler parameters: %s", err.Error())
 		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
 	}
+	operation.RequestIdentity = requestIdentity
 	err = b.operationStorage.InsertOperation(operation)
+	if duplicated, found := operationForDuplicatedRequest(b.operationStorage, requestIdentity, err, instance.InstanceID, internal.OperationTypeUpdate); found {
+		logger.Infof("operation %s already created by the request with identity %s", duplicated.ID, requestIdentity)
+		return b.responseForOperation(instance, *duplicated), nil
+	}
 	if err != nil {
 		return domain.UpdateServiceSpec{}, err
 	}
 
+	// the new plan is saved in the instance by the update operation when the cluster is upgraded
 	var updateStorage []string
 	if params.OIDC.IsProvided() {
 		instance.Parameters.Parameters.OIDC = params.OIDC
@@ -270,6 +458,17 @@ func (b *UpdateEndpoint) processUpdateParameters(instance *internal.Instance, de
 	if params.UpdateAutoScaler(&instance.Parameters.Parameters) {
 		updateStorage = append(updateStorage, "Auto Scaler parameters")
 	}
+	if params.UpdateAdditionalWorkerNodePools(&instance.Parameters.Parameters) {
+		updateStorage = append(updateStorage, "Additional Worker Node Pools")
+	}
+	if params.UpdateOptionalComponents(&instance.Parameters.Parameters) {
+		updateStorage = append(updateStorage, "Optional Components")
+	}
+	if params.Labels != nil {
+		instance.Parameters.Parameters.Labels = params.Labels
+		instance.Labels = params.Labels
+		updateStorage = append(updateStorage, "Labels")
+	}
 	if params.MachineType != nil && *params.MachineType != "" {
 		instance.Parameters.Parameters.MachineType = params.MachineType
 	}
@@ -300,6 +499,47 @@ func (b *UpdateEndpoint) processUpdateParameters(instance *internal.Instance, de
 	}, nil
 }
 
+// responseForOperation returns the response of the request which created the update operation
+func (b *UpdateEndpoint) responseForOperation(instance *internal.Instance, operation internal.Operation) domain.UpdateServiceSpec {
+	return domain.UpdateServiceSpec{
+		IsAsync:       true,
+		DashboardURL:  instance.DashboardURL,
+		OperationData: operation.ID,
+		Metadata: domain.InstanceMetadata{
+			Labels: ResponseLabels(internal.ProvisioningOperation{Operation: operation}, *instance, b.config.URL, b.config.EnableKubeconfigURLLabel),
+		},
+	}
+}
+
+// admit evaluates admission rules against the instance parameters with the requested changes applied
+func (b *UpdateEndpoint) admit(instance *internal.Instance, planID string, params internal.UpdatingParametersDTO) error {
+	parameters := instance.Parameters.Parameters
+	params.UpdateAutoScaler(&parameters)
+	params.UpdateAdditionalWorkerNodePools(&parameters)
+	params.UpdateOptionalComponents(&parameters)
+	if params.Labels != nil {
+		parameters.Labels = params.Labels
+	}
+	if params.MachineType != nil && *params.MachineType != "" {
+		parameters.MachineType = params.MachineType
+	}
+	if params.OIDC.IsProvided() {
+		parameters.OIDC = params.OIDC
+	}
+	if len(params.RuntimeAdministrators) != 0 {
+		parameters.RuntimeAdministrators = params.RuntimeAdministrators
+	}
+
+	return b.admissionPolicy.Admit(admission.Input{
+		Operation:      admission.OperationUpdate,
+		PlanID:         planID,
+		PlanName:       PlanNamesMapping[planID],
+		PlatformRegion: instance.Parameters.PlatformRegion,
+		Parameters:     parameters,
+		ERSContext:     instance.Parameters.ErsContext,
+	})
+}
+
 func (b *UpdateEndpoint) processContext(instance *internal.Instance, details domain.UpdateDetails, lastProvisioningOperation *internal.ProvisioningOperation, logger logrus.FieldLogger) (*internal.Instance, bool, error) {
 	var ersContext internal.ERSContext
 	err := json.Unmarshal(details.RawContext, &ersContext)
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/admission"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 
 	"github.com/stretchr/testify/mock"
 
@@ -21,6 +25,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+	"github.com/pivotal-cf/brokerapi/v8/middlewares"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -75,6 +80,8 @@ func TestUpdateEndpoint_UpdateSuspension(t *testing.T) {
 		true,
 		false,
 		q,
+		nil,
+		admission.Policy{},
 		PlansConfig{},
 		planDefaults,
 		logrus.New(),
@@ -130,7 +137,7 @@ func TestUpdateEndpoint_UpdateExpirationOfTrial(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -185,7 +192,7 @@ func TestUpdateEndpoint_UpdateExpirationOfExpiredTrial(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -238,7 +245,7 @@ func TestUpdateEndpoint_UpdateOfExpiredTrial(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -281,7 +288,7 @@ func TestUpdateEndpoint_UpdateAutoscalerParams(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	t.Run("Should fail on invalid (too low) autoScalerMin and autoScalerMax", func(t *testing.T) {
@@ -362,7 +369,7 @@ func TestUpdateEndpoint_UpdateUnsuspension(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -409,7 +416,7 @@ func TestUpdateEndpoint_UpdateInstanceWithWrongActiveValue(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -439,7 +446,7 @@ func TestUpdateEndpoint_UpdateNonExistingInstance(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -500,7 +507,7 @@ func TestUpdateEndpoint_UpdateGlobalAccountID(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, true, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, true, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -542,7 +549,7 @@ func TestUpdateEndpoint_UpdateParameters(t *testing.T) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
 
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, true, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, true, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	t.Run("Should fail on invalid OIDC params", func(t *testing.T) {
@@ -670,7 +677,7 @@ func TestUpdateEndpoint_UpdateWithEnabledDashboard(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -724,7 +731,7 @@ func TestUpdateEndpoint_UpdateExpirationOfNonTrial(t *testing.T) {
 	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 		return &gqlschema.ClusterConfigInput{}, nil
 	}
-	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 		planDefaults, logrus.New(), dashboardConfig)
 
 	// when
@@ -782,7 +789,7 @@ func TestUpdateEndpoint_ExpiryOnFailedOperations(t *testing.T) {
 		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 			return &gqlschema.ClusterConfigInput{}, nil
 		}
-		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 			planDefaults, logrus.New(), dashboardConfig)
 
 		// when
@@ -841,7 +848,7 @@ func TestUpdateEndpoint_ExpiryOnFailedOperations(t *testing.T) {
 		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 			return &gqlschema.ClusterConfigInput{}, nil
 		}
-		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 			planDefaults, logrus.New(), dashboardConfig)
 
 		// when
@@ -903,7 +910,7 @@ func TestUpdateEndpoint_ExpiryOnFailedOperations(t *testing.T) {
 		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 			return &gqlschema.ClusterConfigInput{}, nil
 		}
-		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 			planDefaults, logrus.New(), dashboardConfig)
 
 		// when
@@ -967,7 +974,7 @@ func TestUpdateEndpoint_ExpiryOnFailedOperations(t *testing.T) {
 		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
 			return &gqlschema.ClusterConfigInput{}, nil
 		}
-		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, PlansConfig{},
+		svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), handler, true, false, q, nil, admission.Policy{}, PlansConfig{},
 			planDefaults, logrus.New(), dashboardConfig)
 
 		// when
@@ -993,3 +1000,738 @@ func TestUpdateEndpoint_ExpiryOnFailedOperations(t *testing.T) {
 		assert.False(t, inst.IsExpired())
 	})
 }
+
+func TestUpdateEndpoint_UpdatePlan(t *testing.T) {
+	// given
+	fixInstance := func(planID string, provider internal.CloudProvider) internal.Instance {
+		return internal.Instance{
+			InstanceID:      instanceID,
+			ServicePlanID:   planID,
+			ServicePlanName: PlanNamesMapping[planID],
+			Provider:        provider,
+			Parameters: internal.ProvisioningParameters{
+				PlanID: planID,
+				ErsContext: internal.ERSContext{
+					Active: ptr.Bool(true),
+				},
+			},
+		}
+	}
+	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+		return &gqlschema.ClusterConfigInput{
+			GardenerConfig: &gqlschema.GardenerConfigInput{
+				MachineType:   "m5.xlarge",
+				AutoScalerMin: 3,
+				AutoScalerMax: 20,
+			},
+		}, nil
+	}
+	newServiceWithProcessing := func(t *testing.T, cfg Config, instance internal.Instance, processingEnabled bool) (*UpdateEndpoint, storage.BrokerStorage) {
+		st := storage.NewMemoryStorage()
+		require.NoError(t, st.Instances().Insert(instance))
+		require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("01")))
+		q := &automock.Queue{}
+		q.On("Add", mock.AnythingOfType("string"))
+		svc := NewUpdate(cfg, st.Instances(), st.RuntimeStates(), st.Operations(), &handler{}, processingEnabled, false, q, nil, admission.Policy{}, PlansConfig{},
+			planDefaults, logrus.New(), dashboardConfig)
+		return svc, st
+	}
+	newService := func(t *testing.T, cfg Config, instance internal.Instance) (*UpdateEndpoint, storage.BrokerStorage) {
+		return newServiceWithProcessing(t, cfg, instance, true)
+	}
+	cfg := Config{EnablePlanUpgrades: true, EnablePlans: EnablePlans{FreemiumPlanName, TrialPlanName, AWSPlanName, AzurePlanName, AzureLitePlanName}}
+	assertRejected := func(t *testing.T, st storage.BrokerStorage, planID string, err error) {
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+		instance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Equal(t, planID, instance.ServicePlanID)
+	}
+
+	t.Run("should change free plan to aws on the same provider", func(t *testing.T) {
+		svc, st := newService(t, cfg, fixInstance(FreemiumPlanID, internal.AWS))
+
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AWSPlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+
+		// the plan is saved in the instance when the update operation succeeds
+		instance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Equal(t, FreemiumPlanID, instance.ServicePlanID)
+		assert.Equal(t, FreemiumPlanID, instance.Parameters.PlanID)
+
+		operation, err := st.Operations().GetOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, AWSPlanID, operation.ProvisioningParameters.PlanID)
+		assert.Equal(t, FreemiumPlanID, operation.PreviousPlanID)
+		assert.Equal(t, "m5.xlarge", *operation.UpdatingParameters.MachineType)
+		assert.Equal(t, 3, *operation.UpdatingParameters.AutoScalerMin)
+		assert.Equal(t, 20, *operation.UpdatingParameters.AutoScalerMax)
+	})
+
+	t.Run("should keep autoscaler parameters given in the request", func(t *testing.T) {
+		svc, st := newService(t, cfg, fixInstance(AzureLitePlanID, internal.Azure))
+
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:        AzurePlanID,
+			RawParameters: json.RawMessage(`{"autoScalerMin": 5, "autoScalerMax": 10}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		operation, err := st.Operations().GetOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, AzurePlanID, operation.ProvisioningParameters.PlanID)
+		assert.Equal(t, AzureLitePlanID, operation.PreviousPlanID)
+		assert.Equal(t, 5, *operation.UpdatingParameters.AutoScalerMin)
+		assert.Equal(t, 10, *operation.UpdatingParameters.AutoScalerMax)
+	})
+
+	t.Run("should reject plan running on a different provider", func(t *testing.T) {
+		svc, _ := newService(t, cfg, fixInstance(FreemiumPlanID, internal.Azure))
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AWSPlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+	})
+
+	t.Run("should reject plan change not defined in the matrix", func(t *testing.T) {
+		svc, _ := newService(t, cfg, fixInstance(AWSPlanID, internal.AWS))
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     TrialPlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+	})
+
+	t.Run("should reject plan change when disabled", func(t *testing.T) {
+		svc, st := newService(t, Config{EnablePlans: cfg.EnablePlans}, fixInstance(FreemiumPlanID, internal.AWS))
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AWSPlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		assertRejected(t, st, FreemiumPlanID, err)
+	})
+
+	t.Run("should reject plan change of trial instance", func(t *testing.T) {
+		svc, st := newService(t, cfg, fixInstance(TrialPlanID, internal.Azure))
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AzurePlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		assertRejected(t, st, TrialPlanID, err)
+	})
+
+	t.Run("should reject plan change when update processing is disabled", func(t *testing.T) {
+		svc, st := newServiceWithProcessing(t, cfg, fixInstance(FreemiumPlanID, internal.AWS), false)
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AWSPlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		assertRejected(t, st, FreemiumPlanID, err)
+	})
+
+	t.Run("should reject plan change combined with suspension", func(t *testing.T) {
+		svc, st := newService(t, cfg, fixInstance(FreemiumPlanID, internal.AWS))
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AWSPlanID,
+			RawContext: json.RawMessage(`{"active": false}`),
+		}, true)
+
+		// then
+		assertRejected(t, st, FreemiumPlanID, err)
+	})
+
+	t.Run("should reject plan change of suspended instance", func(t *testing.T) {
+		instance := fixInstance(FreemiumPlanID, internal.AWS)
+		instance.Parameters.ErsContext.Active = ptr.Bool(false)
+		svc, st := newService(t, cfg, instance)
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AWSPlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		assertRejected(t, st, FreemiumPlanID, err)
+	})
+
+	t.Run("should reject plan change of expired instance", func(t *testing.T) {
+		instance := fixInstance(FreemiumPlanID, internal.AWS)
+		instance.ExpiredAt = ptr.Time(time.Now())
+		svc, st := newService(t, cfg, instance)
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:     AWSPlanID,
+			RawContext: json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		assertRejected(t, st, FreemiumPlanID, err)
+	})
+}
+
+func TestParseMaintenanceInfoVersion(t *testing.T) {
+	for version, expected := range map[string][2]string{
+		"2.10.0-k8s.1.25.4":     {"2.10.0", "1.25.4"},
+		"2.10.0-rc1.k8s.1.25.4": {"2.10.0-rc1", "1.25.4"},
+		"2.10.0":                {"2.10.0", ""},
+	} {
+		kymaVersion, kubernetesVersion := parseMaintenanceInfoVersion(version)
+		assert.Equal(t, expected[0], kymaVersion, version)
+		assert.Equal(t, expected[1], kubernetesVersion, version)
+	}
+	assert.Equal(t, "2.10.0-rc1.k8s.1.25.4", maintenanceInfoVersion("2.10.0-rc1", "1.25.4"))
+}
+
+func TestUpdateEndpoint_UpdateMaintenanceInfo(t *testing.T) {
+	// given
+	cfg := Config{EnableMaintenanceInfo: true, KymaVersion: "2.10.0", KubernetesVersion: "1.25.4"}
+	newService := func(t *testing.T) (*UpdateEndpoint, storage.BrokerStorage, *automock.Queue, *automock.Queue) {
+		st := storage.NewMemoryStorage()
+		require.NoError(t, st.Instances().Insert(internal.Instance{
+			InstanceID:    instanceID,
+			RuntimeID:     "runtime-01",
+			ServicePlanID: AzurePlanID,
+			Parameters: internal.ProvisioningParameters{
+				PlanID:     AzurePlanID,
+				ErsContext: internal.ERSContext{Active: ptr.Bool(true)},
+			},
+		}))
+		require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("01")))
+		kymaQueue := &automock.Queue{}
+		clusterQueue := &automock.Queue{}
+		upgrader := NewOrchestrationInstanceUpgrader(st.Orchestrations(), kymaQueue, clusterQueue, logrus.New())
+		updateQueue := &automock.Queue{}
+		updateQueue.On("Add", mock.AnythingOfType("string"))
+		planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+			return &gqlschema.ClusterConfigInput{}, nil
+		}
+		svc := NewUpdate(cfg, st.Instances(), st.RuntimeStates(), st.Operations(), &handler{}, true, false, updateQueue, upgrader, admission.Policy{}, PlansConfig{},
+			planDefaults, logrus.New(), dashboardConfig)
+		return svc, st, kymaQueue, clusterQueue
+	}
+
+	t.Run("should upgrade Kyma when Kyma version changed", func(t *testing.T) {
+		svc, st, kymaQueue, clusterQueue := newService(t)
+		kymaQueue.On("Add", mock.AnythingOfType("string")).Once()
+
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:          AzurePlanID,
+			RawContext:      json.RawMessage(`{"active": true}`),
+			MaintenanceInfo: cfg.MaintenanceInfo(),
+			PreviousValues:  domain.PreviousValues{MaintenanceInfo: &domain.MaintenanceInfo{Version: "2.9.0-k8s.1.25.4"}},
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, response.IsAsync)
+		kymaQueue.AssertExpectations(t)
+		clusterQueue.AssertNotCalled(t, "Add", mock.Anything)
+
+		orchestrations, count, _, err := st.Orchestrations().List(dbmodel.OrchestrationFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		assert.Equal(t, orchestration.UpgradeKymaOrchestration, orchestrations[0].Type)
+		assert.Equal(t, []orchestration.RuntimeTarget{{InstanceID: instanceID}}, orchestrations[0].Parameters.Targets.Include)
+	})
+
+	t.Run("should upgrade Kyma and cluster when previous maintenance info is not known", func(t *testing.T) {
+		svc, st, kymaQueue, clusterQueue := newService(t)
+		kymaQueue.On("Add", mock.AnythingOfType("string")).Once()
+		clusterQueue.On("Add", mock.AnythingOfType("string")).Once()
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:          AzurePlanID,
+			RawContext:      json.RawMessage(`{"active": true}`),
+			MaintenanceInfo: cfg.MaintenanceInfo(),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		kymaQueue.AssertExpectations(t)
+		clusterQueue.AssertExpectations(t)
+		_, count, _, err := st.Orchestrations().List(dbmodel.OrchestrationFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("should reject maintenance info not matching the catalog", func(t *testing.T) {
+		svc, st, _, _ := newService(t)
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:          AzurePlanID,
+			RawContext:      json.RawMessage(`{"active": true}`),
+			MaintenanceInfo: &domain.MaintenanceInfo{Version: "2.11.0-k8s.1.25.4"},
+		}, true)
+
+		// then
+		assert.Equal(t, apiresponses.ErrMaintenanceInfoConflict, err)
+		_, count, _, err := st.Orchestrations().List(dbmodel.OrchestrationFilter{})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("should compare maintenance info with versions installed on the instance", func(t *testing.T) {
+		svc, st, kymaQueue, clusterQueue := newService(t)
+		require.NoError(t, st.RuntimeStates().Insert(fixRuntimeStateWithVersions("rs-01", "runtime-01", "2.10.0", "1.24.8")))
+		clusterQueue.On("Add", mock.AnythingOfType("string")).Once()
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:          AzurePlanID,
+			RawContext:      json.RawMessage(`{"active": true}`),
+			MaintenanceInfo: cfg.MaintenanceInfo(),
+			PreviousValues:  domain.PreviousValues{MaintenanceInfo: &domain.MaintenanceInfo{Version: "2.9.0-k8s.1.25.4"}},
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		clusterQueue.AssertExpectations(t)
+		kymaQueue.AssertNotCalled(t, "Add", mock.Anything)
+		orchestrations, count, _, err := st.Orchestrations().List(dbmodel.OrchestrationFilter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		assert.Equal(t, orchestration.UpgradeClusterOrchestration, orchestrations[0].Type)
+	})
+
+	t.Run("should ignore maintenance info matching versions installed on the instance", func(t *testing.T) {
+		svc, st, kymaQueue, clusterQueue := newService(t)
+		require.NoError(t, st.RuntimeStates().Insert(fixRuntimeStateWithVersions("rs-01", "runtime-01", "2.10.0", "1.25.4")))
+
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:          AzurePlanID,
+			RawContext:      json.RawMessage(`{"active": true}`),
+			RawParameters:   json.RawMessage(`{"autoScalerMin": 3, "autoScalerMax": 10}`),
+			MaintenanceInfo: cfg.MaintenanceInfo(),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+		kymaQueue.AssertNotCalled(t, "Add", mock.Anything)
+		clusterQueue.AssertNotCalled(t, "Add", mock.Anything)
+		_, count, _, err := st.Orchestrations().List(dbmodel.OrchestrationFilter{})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("should reuse upgrade in progress for the instance", func(t *testing.T) {
+		svc, st, kymaQueue, _ := newService(t)
+		require.NoError(t, st.RuntimeStates().Insert(fixRuntimeStateWithVersions("rs-01", "runtime-01", "2.9.0", "1.25.4")))
+		kymaQueue.On("Add", mock.AnythingOfType("string")).Once()
+		details := domain.UpdateDetails{
+			PlanID:          AzurePlanID,
+			RawContext:      json.RawMessage(`{"active": true}`),
+			MaintenanceInfo: cfg.MaintenanceInfo(),
+		}
+
+		// when
+		_, err := svc.Update(context.Background(), instanceID, details, true)
+		require.NoError(t, err)
+		_, err = svc.Update(context.Background(), instanceID, details, true)
+
+		// then
+		require.NoError(t, err)
+		kymaQueue.AssertExpectations(t)
+		_, count, _, err := st.Orchestrations().List(dbmodel.OrchestrationFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func fixRuntimeStateWithVersions(id, runtimeID, kymaVersion, kubernetesVersion string) internal.RuntimeState {
+	return internal.RuntimeState{
+		ID:            id,
+		RuntimeID:     runtimeID,
+		OperationID:   "01",
+		CreatedAt:     time.Now(),
+		KymaVersion:   kymaVersion,
+		ClusterConfig: gqlschema.GardenerConfigInput{KubernetesVersion: kubernetesVersion},
+	}
+}
+
+func TestUpdateEndpoint_UpdateWithAdmissionRules(t *testing.T) {
+	// given
+	st := storage.NewMemoryStorage()
+	require.NoError(t, st.Instances().Insert(internal.Instance{
+		InstanceID:    instanceID,
+		ServicePlanID: AzurePlanID,
+		Parameters: internal.ProvisioningParameters{
+			PlanID:         AzurePlanID,
+			PlatformRegion: "cf-eu10",
+			ErsContext:     internal.ERSContext{Active: ptr.Bool(true), GlobalAccountID: "global-account-id"},
+		},
+	}))
+	require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("01")))
+	q := &automock.Queue{}
+	q.On("Add", mock.AnythingOfType("string"))
+	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+		return &gqlschema.ClusterConfigInput{}, nil
+	}
+	policy, err := admission.NewPolicy([]admission.Rule{{
+		Name:       "autoscaler-limit",
+		Expression: `operation == "update" && platformRegion == "cf-eu10" && has(parameters.autoScalerMax) && parameters.autoScalerMax > 40`,
+		Message:    "at most 40 worker nodes are allowed in cf-eu10",
+	}})
+	require.NoError(t, err)
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), &handler{}, true, false, q, nil, policy, PlansConfig{},
+		planDefaults, logrus.New(), dashboardConfig)
+
+	t.Run("should reject update denied by admission rules", func(t *testing.T) {
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMin": 3, "autoScalerMax": 50}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+		assert.EqualError(t, err, "at most 40 worker nodes are allowed in cf-eu10")
+	})
+
+	t.Run("should accept update allowed by admission rules", func(t *testing.T) {
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMin": 3, "autoScalerMax": 40}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+	})
+}
+
+func TestUpdateEndpoint_UpdateAdditionalWorkerNodePools(t *testing.T) {
+	// given
+	st := storage.NewMemoryStorage()
+	require.NoError(t, st.Instances().Insert(internal.Instance{
+		InstanceID:    instanceID,
+		ServicePlanID: AzurePlanID,
+		Parameters: internal.ProvisioningParameters{
+			PlanID:     AzurePlanID,
+			ErsContext: internal.ERSContext{Active: ptr.Bool(true)},
+			Parameters: internal.ProvisioningParametersDTO{
+				AdditionalWorkerNodePools: []internal.AdditionalWorkerNodePool{
+					{Name: "memory", MachineType: "Standard_D8_v3", AutoScalerMin: 1, AutoScalerMax: 3},
+				},
+			},
+		},
+	}))
+	require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("01")))
+	q := &automock.Queue{}
+	q.On("Add", mock.AnythingOfType("string"))
+	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+		return &gqlschema.ClusterConfigInput{}, nil
+	}
+	svc := NewUpdate(Config{IncludeAdditionalParamsInSchema: true}, st.Instances(), st.RuntimeStates(), st.Operations(), &handler{}, true, false, q, nil, admission.Policy{}, PlansConfig{},
+		planDefaults, logrus.New(), dashboardConfig)
+
+	t.Run("should keep pools when the parameter is not provided", func(t *testing.T) {
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMax": 20}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		op, err := st.Operations().GetOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Len(t, op.ProvisioningParameters.Parameters.AdditionalWorkerNodePools, 1)
+	})
+
+	t.Run("should resize and add pools", func(t *testing.T) {
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"additionalWorkerNodePools": [{"name": "memory", "machineType": "Standard_D8_v3", "autoScalerMin": 2, "autoScalerMax": 6}, {"name": "compute", "machineType": "Standard_D16_v3", "autoScalerMin": 1, "autoScalerMax": 2}]}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		expected := []internal.AdditionalWorkerNodePool{
+			{Name: "memory", MachineType: "Standard_D8_v3", AutoScalerMin: 2, AutoScalerMax: 6},
+			{Name: "compute", MachineType: "Standard_D16_v3", AutoScalerMin: 1, AutoScalerMax: 2},
+		}
+		op, err := st.Operations().GetOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, expected, op.ProvisioningParameters.Parameters.AdditionalWorkerNodePools)
+		instance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, instance.Parameters.Parameters.AdditionalWorkerNodePools)
+	})
+
+	t.Run("should reject pools with duplicated names", func(t *testing.T) {
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"additionalWorkerNodePools": [{"name": "memory", "machineType": "Standard_D8_v3", "autoScalerMin": 2, "autoScalerMax": 6}, {"name": "memory", "machineType": "Standard_D16_v3", "autoScalerMin": 1, "autoScalerMax": 2}]}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+	})
+
+	t.Run("should remove all pools", func(t *testing.T) {
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"additionalWorkerNodePools": []}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		instance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Empty(t, instance.Parameters.Parameters.AdditionalWorkerNodePools)
+	})
+}
+
+func TestUpdateEndpoint_UpdateLabels(t *testing.T) {
+	// given
+	st := storage.NewMemoryStorage()
+	require.NoError(t, st.Instances().Insert(internal.Instance{
+		InstanceID:    instanceID,
+		ServicePlanID: AzurePlanID,
+		Labels:        map[string]string{"team": "payments"},
+		Parameters: internal.ProvisioningParameters{
+			PlanID:     AzurePlanID,
+			ErsContext: internal.ERSContext{Active: ptr.Bool(true)},
+			Parameters: internal.ProvisioningParametersDTO{
+				Labels: map[string]string{"team": "payments"},
+			},
+		},
+	}))
+	require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("01")))
+	q := &automock.Queue{}
+	q.On("Add", mock.AnythingOfType("string"))
+	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+		return &gqlschema.ClusterConfigInput{}, nil
+	}
+	svc := NewUpdate(Config{}, st.Instances(), st.RuntimeStates(), st.Operations(), &handler{}, true, false, q, nil, admission.Policy{}, PlansConfig{},
+		planDefaults, logrus.New(), dashboardConfig)
+
+	t.Run("should keep labels when the parameter is not provided", func(t *testing.T) {
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"autoScalerMax": 20}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		instance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"team": "payments"}, instance.Labels)
+	})
+
+	t.Run("should replace labels", func(t *testing.T) {
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"labels": {"team": "billing", "env": "staging"}}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		expected := map[string]string{"team": "billing", "env": "staging"}
+		op, err := st.Operations().GetOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, expected, op.ProvisioningParameters.Parameters.Labels)
+		instance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, instance.Labels)
+		assert.Equal(t, expected, instance.Parameters.Parameters.Labels)
+	})
+
+	t.Run("should reject invalid label key", func(t *testing.T) {
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"labels": {"not a key": "value"}}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+	})
+}
+
+func TestUpdateEndpoint_UpdateOptionalComponents(t *testing.T) {
+	// given
+	st := storage.NewMemoryStorage()
+	require.NoError(t, st.Instances().Insert(internal.Instance{
+		InstanceID:    instanceID,
+		ServicePlanID: AzurePlanID,
+		Parameters: internal.ProvisioningParameters{
+			PlanID:     AzurePlanID,
+			ErsContext: internal.ERSContext{Active: ptr.Bool(true)},
+			Parameters: internal.ProvisioningParametersDTO{
+				OptionalComponentsToInstall: []string{"tracing"},
+			},
+		},
+	}))
+	require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("01")))
+	q := &automock.Queue{}
+	q.On("Add", mock.AnythingOfType("string"))
+	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+		return &gqlschema.ClusterConfigInput{}, nil
+	}
+	svc := NewUpdate(Config{OptionalComponents: []string{"tracing", "kiali"}}, st.Instances(), st.RuntimeStates(), st.Operations(), &handler{}, true, false, q, nil,
+		admission.Policy{}, PlansConfig{}, planDefaults, logrus.New(), dashboardConfig)
+
+	t.Run("should replace optional components", func(t *testing.T) {
+		// when
+		response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"components": ["kiali"]}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		op, err := st.Operations().GetOperationByID(response.OperationData)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kiali"}, *op.UpdatingParameters.OptionalComponentsToInstall)
+		assert.Equal(t, []string{"kiali"}, op.ProvisioningParameters.Parameters.OptionalComponentsToInstall)
+		instance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kiali"}, instance.Parameters.Parameters.OptionalComponentsToInstall)
+	})
+
+	t.Run("should reject unknown optional components", func(t *testing.T) {
+		// when
+		_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+			RawParameters: json.RawMessage(`{"components": ["kiali", "unknown"]}`),
+			RawContext:    json.RawMessage(`{"active": true}`),
+		}, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+		assert.EqualError(t, err, "unknown optional components: unknown, allowed components: kiali, tracing")
+	})
+}
+
+func TestUpdateEndpoint_RequestIdentity(t *testing.T) {
+	// given
+	st := storage.NewMemoryStorage()
+	require.NoError(t, st.Instances().Insert(internal.Instance{
+		InstanceID:    instanceID,
+		ServicePlanID: AzurePlanID,
+		Parameters: internal.ProvisioningParameters{
+			PlanID:     AzurePlanID,
+			ErsContext: internal.ERSContext{Active: ptr.Bool(true)},
+		},
+	}))
+	require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("01")))
+	q := &automock.Queue{}
+	q.On("Add", mock.AnythingOfType("string"))
+	planDefaults := func(planID string, platformProvider internal.CloudProvider, provider *internal.CloudProvider) (*gqlschema.ClusterConfigInput, error) {
+		return &gqlschema.ClusterConfigInput{}, nil
+	}
+	svc := NewUpdate(Config{RequestIdentityWindow: time.Hour}, st.Instances(), st.RuntimeStates(), st.Operations(), &handler{}, true, false, q, nil, admission.Policy{}, PlansConfig{},
+		planDefaults, logrus.New(), dashboardConfig)
+	details := domain.UpdateDetails{
+		RawParameters: json.RawMessage(`{"autoScalerMax": 20}`),
+		RawContext:    json.RawMessage(`{"active": true}`),
+	}
+	requestContext := func(requestIdentity string) context.Context {
+		return context.WithValue(context.Background(), middlewares.RequestIdentityKey, requestIdentity)
+	}
+
+	t.Run("should create one operation for concurrent duplicated requests", func(t *testing.T) {
+		const requests = 10
+		responses := make([]domain.UpdateServiceSpec, requests)
+		errs := make([]error, requests)
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i], errs[i] = svc.Update(requestContext("request-1"), instanceID, details, true)
+			}(i)
+		}
+		wg.Wait()
+
+		// then
+		for i := 0; i < requests; i++ {
+			require.NoError(t, errs[i])
+			assert.Equal(t, responses[0].OperationData, responses[i].OperationData)
+		}
+		q.AssertNumberOfCalls(t, "Add", 1)
+	})
+
+	t.Run("should return the original operation for the repeated request", func(t *testing.T) {
+		// when
+		first, err := svc.Update(requestContext("request-2"), instanceID, details, true)
+		require.NoError(t, err)
+		second, err := svc.Update(requestContext("request-2"), instanceID, details, true)
+		require.NoError(t, err)
+		third, err := svc.Update(requestContext("request-3"), instanceID, details, true)
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, first.OperationData, second.OperationData)
+		assert.NotEqual(t, first.OperationData, third.OperationData)
+	})
+
+	t.Run("should reject request identity used for another operation type", func(t *testing.T) {
+		// given
+		op := fixture.FixDeprovisioningOperation("deprovisioning-01", instanceID)
+		op.RequestIdentity = "request-4"
+		require.NoError(t, st.Operations().InsertDeprovisioningOperation(op))
+
+		// when
+		_, err := svc.Update(requestContext("request-4"), instanceID, details, true)
+
+		// then
+		require.IsType(t, &apiresponses.FailureResponse{}, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil))
+	})
+}
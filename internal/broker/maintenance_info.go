@@ -0,0 +1,148 @@
+package broker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const maintenanceInfoKubernetesPrefix = "k8s."
+
+// MaintenanceInfo returns the maintenance_info published for every plan in the catalog.
+// The version is built from the default Kyma version and the Kubernetes version passed as the pre-release, e.g. 2.10.0-k8s.1.25.4.
+// Platforms ignore the build metadata when comparing versions, the pre-release numbers are compared, so a Kubernetes upgrade makes the version newer.
+func (c Config) MaintenanceInfo() *domain.MaintenanceInfo {
+	if !c.EnableMaintenanceInfo {
+		return nil
+	}
+	return &domain.MaintenanceInfo{
+		Version:     maintenanceInfoVersion(c.KymaVersion, c.KubernetesVersion),
+		Description: fmt.Sprintf("Kyma %s, Kubernetes %s", c.KymaVersion, c.KubernetesVersion),
+	}
+}
+
+// maintenanceInfoVersion appends the Kubernetes version to the Kyma version pre-release, or starts the pre-release if Kyma has none
+func maintenanceInfoVersion(kymaVersion, kubernetesVersion string) string {
+	separator := "-"
+	if strings.Contains(kymaVersion, "-") {
+		separator = "."
+	}
+	return kymaVersion + separator + maintenanceInfoKubernetesPrefix + kubernetesVersion
+}
+
+// parseMaintenanceInfoVersion returns Kyma and Kubernetes versions encoded in the maintenance_info version
+func parseMaintenanceInfoVersion(version string) (string, string) {
+	idx := strings.LastIndex(version, maintenanceInfoKubernetesPrefix)
+	if idx < 1 || (version[idx-1] != '-' && version[idx-1] != '.') {
+		return version, ""
+	}
+	return version[:idx-1], version[idx+len(maintenanceInfoKubernetesPrefix):]
+}
+
+// InstanceUpgrader schedules upgrades of a single instance
+type InstanceUpgrader interface {
+	Upgrade(instance internal.Instance, upgradeType orchestration.Type) (string, error)
+}
+
+// maintenanceUpgradeTypes returns upgrades needed to move the instance from the installed versions to the ones of the given maintenance_info.
+// An unknown installed version is always upgraded.
+func maintenanceUpgradeTypes(installedKyma, installedKubernetes string, current *domain.MaintenanceInfo) []orchestration.Type {
+	var upgrades []orchestration.Type
+	currentKyma, currentKubernetes := parseMaintenanceInfoVersion(current.Version)
+	if installedKyma == "" || installedKyma != currentKyma {
+		upgrades = append(upgrades, orchestration.UpgradeKymaOrchestration)
+	}
+	if installedKubernetes == "" || installedKubernetes != currentKubernetes {
+		upgrades = append(upgrades, orchestration.UpgradeClusterOrchestration)
+	}
+	return upgrades
+}
+
+// OrchestrationInstanceUpgrader creates immediate orchestrations targeting only one instance
+type OrchestrationInstanceUpgrader struct {
+	orchestrations storage.Orchestrations
+	queues         map[orchestration.Type]Queue
+	log            logrus.FieldLogger
+}
+
+func NewOrchestrationInstanceUpgrader(orchestrations storage.Orchestrations, kymaQueue, clusterQueue Queue, log logrus.FieldLogger) *OrchestrationInstanceUpgrader {
+	return &OrchestrationInstanceUpgrader{
+		orchestrations: orchestrations,
+		queues: map[orchestration.Type]Queue{
+			orchestration.UpgradeKymaOrchestration:    kymaQueue,
+			orchestration.UpgradeClusterOrchestration: clusterQueue,
+		},
+		log: log.WithField("service", "OrchestrationInstanceUpgrader"),
+	}
+}
+
+// Upgrade creates and queues the orchestration of the given type, the orchestration manager uses the default versions.
+// If an orchestration of the given type targeting the instance is still pending or in progress, its ID is returned instead.
+func (u *OrchestrationInstanceUpgrader) Upgrade(instance internal.Instance, upgradeType orchestration.Type) (string, error) {
+	queue, found := u.queues[upgradeType]
+	if !found {
+		return "", fmt.Errorf("unsupported upgrade type %s", upgradeType)
+	}
+
+	existingID, err := u.unfinishedUpgrade(instance.InstanceID, upgradeType)
+	if err != nil {
+		return "", err
+	}
+	if existingID != "" {
+		u.log.Infof("Reusing %s orchestration %s for instance %s", upgradeType, existingID, instance.InstanceID)
+		return existingID, nil
+	}
+
+	now := time.Now()
+	o := internal.Orchestration{
+		OrchestrationID: uuid.New().String(),
+		Type:            upgradeType,
+		State:           orchestration.Pending,
+		Description:     "queued for processing",
+		Parameters: orchestration.Parameters{
+			Targets: orchestration.TargetSpec{
+				Include: []orchestration.RuntimeTarget{{InstanceID: instance.InstanceID}},
+			},
+			Strategy: orchestration.StrategySpec{
+				Type:     orchestration.ParallelStrategy,
+				Schedule: string(orchestration.Immediate),
+				Parallel: orchestration.ParallelStrategySpec{Workers: 1},
+			},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := u.orchestrations.Insert(o); err != nil {
+		return "", fmt.Errorf("while inserting orchestration to storage: %w", err)
+	}
+	u.log.Infof("Created %s orchestration %s for instance %s", upgradeType, o.OrchestrationID, instance.InstanceID)
+
+	queue.Add(o.OrchestrationID)
+	return o.OrchestrationID, nil
+}
+
+// unfinishedUpgrade returns the ID of the not finished orchestration of the given type targeting only the given instance
+func (u *OrchestrationInstanceUpgrader) unfinishedUpgrade(instanceID string, upgradeType orchestration.Type) (string, error) {
+	orchestrations, _, _, err := u.orchestrations.List(dbmodel.OrchestrationFilter{
+		Types:  []string{string(upgradeType)},
+		States: []string{orchestration.Pending, orchestration.InProgress, orchestration.Retrying},
+	})
+	if err != nil {
+		return "", fmt.Errorf("while listing orchestrations: %w", err)
+	}
+	for _, o := range orchestrations {
+		include := o.Parameters.Targets.Include
+		if len(include) == 1 && include[0].InstanceID == instanceID {
+			return o.OrchestrationID, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,29 @@
+package broker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// validateOptionalComponents checks if all requested components are known optional components. Names are compared case insensitive.
+func validateOptionalComponents(known, requested []string) error {
+	allowed := make(map[string]struct{}, len(known))
+	for _, name := range known {
+		allowed[strings.ToLower(name)] = struct{}{}
+	}
+
+	var unknown []string
+	for _, name := range requested {
+		if _, found := allowed[strings.ToLower(name)]; !found {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	names := append([]string{}, known...)
+	sort.Strings(names)
+	return fmt.Errorf("unknown optional components: %s, allowed components: %s", strings.Join(unknown, ", "), strings.Join(names, ", "))
+}
@@ -0,0 +1,43 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+)
+
+// planUpgrades defines allowed plan changes. The instance keeps its cluster, that's why the target plan must run
+// on the same hyperscaler as the instance. Trial clusters run in the shared hyperscaler accounts and cannot be moved
+// to the account of the global account, so the trial plan cannot be changed.
+var planUpgrades = map[string][]string{
+	FreemiumPlanID:  {AWSPlanID, AzurePlanID},
+	AzureLitePlanID: {AzurePlanID},
+}
+
+var planProviders = map[string]internal.CloudProvider{
+	AWSPlanID:       internal.AWS,
+	AzurePlanID:     internal.Azure,
+	AzureLitePlanID: internal.Azure,
+	GCPPlanID:       internal.GCP,
+}
+
+// ValidatePlanUpgrade checks if the instance running on the given provider can be moved from one plan to another
+func ValidatePlanUpgrade(fromPlanID, toPlanID string, provider internal.CloudProvider) error {
+	allowed := false
+	for _, planID := range planUpgrades[fromPlanID] {
+		if planID == toPlanID {
+			allowed = true
+			break
+		}
+	}
+	if IsTrialPlan(fromPlanID) {
+		return fmt.Errorf("plan change of a trial instance is not supported, provision a new instance of the %s plan instead", PlanNamesMapping[toPlanID])
+	}
+	if !allowed {
+		return fmt.Errorf("plan change from %s to %s is not supported", PlanNamesMapping[fromPlanID], PlanNamesMapping[toPlanID])
+	}
+	if planProviders[toPlanID] != provider {
+		return fmt.Errorf("plan %s is not available for the instance running on %s", PlanNamesMapping[toPlanID], provider)
+	}
+	return nil
+}
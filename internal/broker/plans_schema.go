@@ -37,11 +37,18 @@ type UpdateProperties struct {
 	OIDC           *OIDCType `json:"oidc,omitempty"`
 	Administrators *Type     `json:"administrators,omitempty"`
 	MachineType    *Type     `json:"machineType,omitempty"`
+	Labels         *Type     `json:"labels,omitempty"`
+
+	AdditionalWorkerNodePools *AdditionalWorkerNodePoolsType `json:"additionalWorkerNodePools,omitempty"`
 }
 
 func (up *UpdateProperties) IncludeAdditional() {
 	up.OIDC = NewOIDCSchema()
 	up.Administrators = AdministratorsProperty()
+	up.Labels = LabelsProperty()
+	if up.MachineType != nil && up.AutoScalerMax != nil {
+		up.AdditionalWorkerNodePools = NewAdditionalWorkerNodePoolsSchema(*up.MachineType, up.AutoScalerMax.Maximum)
+	}
 }
 
 type NetworkingProperties struct {
@@ -69,6 +76,24 @@ type OIDCType struct {
 	Required   []string       `json:"required"`
 }
 
+type AdditionalWorkerNodePoolProperties struct {
+	Name          Type `json:"name"`
+	MachineType   Type `json:"machineType"`
+	AutoScalerMin Type `json:"autoScalerMin"`
+	AutoScalerMax Type `json:"autoScalerMax"`
+}
+
+type AdditionalWorkerNodePoolType struct {
+	Type
+	Properties AdditionalWorkerNodePoolProperties `json:"properties"`
+	Required   []string                           `json:"required"`
+}
+
+type AdditionalWorkerNodePoolsType struct {
+	Type
+	Items AdditionalWorkerNodePoolType `json:"items"`
+}
+
 type Type struct {
 	Type        string `json:"type"`
 	Title       string `json:"title,omitempty"`
@@ -88,6 +113,8 @@ type Type struct {
 	Items           *Type             `json:"items,omitempty"`
 	AdditionalItems *bool             `json:"additionalItems,omitempty"`
 	UniqueItems     *bool             `json:"uniqueItems,omitempty"`
+
+	AdditionalProperties *Type `json:"additionalProperties,omitempty"`
 }
 
 type NameType struct {
@@ -214,6 +241,42 @@ func NewOIDCSchema() *OIDCType {
 	}
 }
 
+// NewAdditionalWorkerNodePoolsSchema creates a schema of worker node pools using the same machine types as the default worker node pool
+func NewAdditionalWorkerNodePoolsSchema(machineType Type, autoScalerMaximum int) *AdditionalWorkerNodePoolsType {
+	return &AdditionalWorkerNodePoolsType{
+		Type: Type{Type: "array", Description: "Additional worker node pools, each with its own machine type and autoscaler limits"},
+		Items: AdditionalWorkerNodePoolType{
+			Type: Type{Type: "object"},
+			Properties: AdditionalWorkerNodePoolProperties{
+				Name: Type{
+					Type:        "string",
+					Description: "Unique name of the worker node pool",
+					Pattern:     "^[a-z0-9]([-a-z0-9]*[a-z0-9])?$",
+					MinLength:   1,
+					MaxLength:   15,
+				},
+				MachineType: Type{
+					Type:            "string",
+					Enum:            machineType.Enum,
+					EnumDisplayName: machineType.EnumDisplayName,
+				},
+				AutoScalerMin: Type{
+					Type:        "integer",
+					Maximum:     autoScalerMaximum,
+					Description: "Specifies the minimum number of virtual machines to create",
+				},
+				AutoScalerMax: Type{
+					Type:        "integer",
+					Minimum:     1,
+					Maximum:     autoScalerMaximum,
+					Description: "Specifies the maximum number of virtual machines to create",
+				},
+			},
+			Required: []string{"name", "machineType", "autoScalerMin", "autoScalerMax"},
+		},
+	}
+}
+
 func NewSchemaWithOnlyNameRequired(properties interface{}, update bool) *RootSchema {
 	return NewSchemaForOwnCluster(properties, update, []string{"name"})
 }
@@ -248,7 +311,7 @@ func unmarshalOrPanic(from, to interface{}) interface{} {
 }
 
 func DefaultControlsOrder() []string {
-	return []string{"name", "kubeconfig", "shootName", "shootDomain", "region", "machineType", "autoScalerMin", "autoScalerMax", "zonesCount", "networking", "oidc", "administrators"}
+	return []string{"name", "kubeconfig", "shootName", "shootDomain", "region", "machineType", "autoScalerMin", "autoScalerMax", "additionalWorkerNodePools", "zonesCount", "networking", "oidc", "administrators", "labels"}
 }
 
 func ToInterfaceSlice(input []string) []interface{} {
@@ -269,3 +332,14 @@ func AdministratorsProperty() *Type {
 		},
 	}
 }
+
+func LabelsProperty() *Type {
+	return &Type{
+		Type:        "object",
+		Title:       "Labels",
+		Description: "Specifies the labels of the instance, which can be used to filter runtimes",
+		AdditionalProperties: &Type{
+			Type: "string",
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+	"github.com/pivotal-cf/brokerapi/v8/middlewares"
+	"github.com/sirupsen/logrus"
+)
+
+// requestIdentityFromContext returns the X-Broker-API-Request-Identity header of the OSB request
+func requestIdentityFromContext(ctx context.Context) string {
+	requestIdentity, _ := ctx.Value(middlewares.RequestIdentityKey).(string)
+	return requestIdentity
+}
+
+// operationForRequestIdentity returns the operation created by the previous request with the same identity.
+// The returned identity must be stored with a new operation. It is empty if the request has no identity
+// or the previous request is older than the window, then the request is processed as a new one.
+func operationForRequestIdentity(ctx context.Context, operations storage.Operations, window time.Duration, instanceID string, operationType internal.OperationType, logger logrus.FieldLogger) (string, *internal.Operation, error) {
+	requestIdentity := requestIdentityFromContext(ctx)
+	if requestIdentity == "" {
+		return "", nil, nil
+	}
+
+	operation, err := operations.GetOperationByRequestIdentity(requestIdentity)
+	switch {
+	case dberr.IsNotFound(err):
+		return requestIdentity, nil, nil
+	case err != nil:
+		logger.Errorf("unable to get operation by request identity %s: %s", requestIdentity, err)
+		return "", nil, fmt.Errorf("unable to get operation by request identity")
+	}
+
+	if operation.InstanceID != instanceID || operation.Type != operationType {
+		err := fmt.Errorf("request identity %s was used by the %s operation of the instance %s", requestIdentity, operation.Type, operation.InstanceID)
+		return "", nil, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+	}
+	if time.Since(operation.CreatedAt) > window {
+		logger.Infof("request identity %s of the operation %s expired, processing the request as a new one", requestIdentity, operation.ID)
+		return "", nil, nil
+	}
+	logger.Infof("repeated request with identity %s, returning the operation %s", requestIdentity, operation.ID)
+	return requestIdentity, operation, nil
+}
+
+// operationForDuplicatedRequest returns the operation inserted by a concurrent request with the same identity
+func operationForDuplicatedRequest(operations storage.Operations, requestIdentity string, insertErr error, instanceID string, operationType internal.OperationType) (*internal.Operation, bool) {
+	if requestIdentity == "" || !dberr.IsAlreadyExists(insertErr) {
+		return nil, false
+	}
+	operation, err := operations.GetOperationByRequestIdentity(requestIdentity)
+	if err != nil || operation.InstanceID != instanceID || operation.Type != operationType {
+		return nil, false
+	}
+	return operation, true
+}
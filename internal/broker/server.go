@@ -36,6 +36,7 @@ func AttachRoutes(router *mux.Router, serviceBroker domain.ServiceBroker, logger
 	apiVersionMiddleware := middlewares.APIVersionMiddleware{LoggerFactory: logger}
 
 	router.Use(middlewares.AddOriginatingIdentityToContext)
+	router.Use(middlewares.AddRequestIdentityToContext)
 	router.Use(apiVersionMiddleware.ValidateAPIVersionHdr)
 	router.Use(middlewares.AddInfoLocationToContext)
 
@@ -60,6 +60,7 @@ func (b *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, erro
 			continue
 		}
 		// p := plan.PlanDefinition
+		plan.MaintenanceInfo = b.cfg.MaintenanceInfo()
 
 		availableServicePlans = append(availableServicePlans, plan)
 	}
@@ -69,8 +70,10 @@ func (b *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, erro
 			ID:                   KymaServiceID,
 			Name:                 KymaServiceName,
 			Description:          class.Description,
-			Bindable:             false,
+			Bindable:             b.cfg.Binding.Enabled,
 			InstancesRetrievable: true,
+			BindingsRetrievable:  b.cfg.Binding.Enabled,
+			PlanUpdatable:        b.cfg.EnablePlanUpgrades,
 			Tags: []string{
 				"SAP",
 				"Kyma",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/Masterminds/semver"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/sirupsen/logrus"
@@ -115,6 +116,58 @@ func TestServices_Services(t *testing.T) {
 		assertPlansContainPropertyInSchemas(t, services[0], "oidc")
 		assertPlansContainPropertyInSchemas(t, services[0], "administrators")
 	})
+
+	t.Run("should publish maintenance info", func(t *testing.T) {
+		// given
+		cfg := broker.Config{
+			EnablePlans:           []string{"gcp", "azure"},
+			EnableMaintenanceInfo: true,
+			KymaVersion:           "2.10.0",
+			KubernetesVersion:     "1.25.4",
+		}
+		servicesConfig := map[string]broker.Service{
+			broker.KymaServiceName: {},
+		}
+		servicesEndpoint := broker.NewServices(cfg, servicesConfig, logrus.StandardLogger())
+
+		// when
+		services, err := servicesEndpoint.Services(context.TODO())
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, services[0].Plans, 2)
+		for _, plan := range services[0].Plans {
+			require.NotNil(t, plan.MaintenanceInfo)
+			assert.Equal(t, "2.10.0-k8s.1.25.4", plan.MaintenanceInfo.Version)
+		}
+	})
+
+	t.Run("should publish newer maintenance info version for newer Kubernetes version", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			kymaVersion string
+			older       string
+			newer       string
+		}{
+			"patch":                    {kymaVersion: "2.10.0", older: "1.25.4", newer: "1.25.10"},
+			"minor":                    {kymaVersion: "2.10.0", older: "1.25.4", newer: "1.26.0"},
+			"kyma pre-release version": {kymaVersion: "2.10.0-rc1", older: "1.25.4", newer: "1.26.0"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// given
+				older := broker.Config{EnableMaintenanceInfo: true, KymaVersion: tc.kymaVersion, KubernetesVersion: tc.older}
+				newer := broker.Config{EnableMaintenanceInfo: true, KymaVersion: tc.kymaVersion, KubernetesVersion: tc.newer}
+
+				// when
+				olderVersion, err := semver.NewVersion(older.MaintenanceInfo().Version)
+				require.NoError(t, err)
+				newerVersion, err := semver.NewVersion(newer.MaintenanceInfo().Version)
+				require.NoError(t, err)
+
+				// then
+				assert.True(t, olderVersion.LessThan(newerVersion), "%s must be older than %s", olderVersion, newerVersion)
+			})
+		}
+	})
 }
 
 func assertPlansContainPropertyInSchemas(t *testing.T, service domain.Service, property string) {
@@ -0,0 +1,22 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+)
+
+// validateAdditionalWorkerNodePools checks additional worker node pools. Machine types are validated by the plan schema,
+// which contains the additionalWorkerNodePools property only if additional parameters are included.
+func validateAdditionalWorkerNodePools(planID string, includeAdditionalParams bool, pools []internal.AdditionalWorkerNodePool) error {
+	if len(pools) == 0 {
+		return nil
+	}
+	if !includeAdditionalParams {
+		return fmt.Errorf("additional worker node pools are not enabled")
+	}
+	if IsTrialPlan(planID) || IsFreemiumPlan(planID) || IsOwnClusterPlan(planID) {
+		return fmt.Errorf("additional worker node pools are not supported for the %s plan", PlanNamesMapping[planID])
+	}
+	return internal.ValidateAdditionalWorkerNodePools(pools)
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -189,6 +192,10 @@ type ProvisioningParametersDTO struct {
 
 	OIDC       *OIDCConfigDTO `json:"oidc,omitempty"`
 	Networking *NetworkingDTO `json:"networking,omitempty""`
+
+	AdditionalWorkerNodePools []AdditionalWorkerNodePool `json:"additionalWorkerNodePools,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 type UpdatingParametersDTO struct {
@@ -197,11 +204,30 @@ type UpdatingParametersDTO struct {
 	OIDC                  *OIDCConfigDTO `json:"oidc,omitempty"`
 	RuntimeAdministrators []string       `json:"administrators,omitempty"`
 	MachineType           *string        `json:"machineType,omitempty"`
+	// AdditionalWorkerNodePools - nil means no change, an empty list removes all additional worker node pools
+	AdditionalWorkerNodePools *[]AdditionalWorkerNodePool `json:"additionalWorkerNodePools,omitempty"`
+	// Labels - nil means no change, otherwise the labels of the instance are replaced
+	Labels map[string]string `json:"labels,omitempty"`
+	// OptionalComponentsToInstall - nil means no change, otherwise the optional components of the instance are replaced
+	OptionalComponentsToInstall *[]string `json:"components,omitempty"`
 
 	// Expired - means that the trial SKR is marked as expired
 	Expired bool `json:"expired"`
 }
 
+// ApplyPlanDefaults sets machine type and autoscaler limits of a new plan if they are not given explicitly
+func (u *UpdatingParametersDTO) ApplyPlanDefaults(machineType string, autoScalerMin, autoScalerMax int) {
+	if (u.MachineType == nil || *u.MachineType == "") && machineType != "" {
+		u.MachineType = &machineType
+	}
+	if u.AutoScalerMin == nil {
+		u.AutoScalerMin = &autoScalerMin
+	}
+	if u.AutoScalerMax == nil {
+		u.AutoScalerMax = &autoScalerMax
+	}
+}
+
 func (u UpdatingParametersDTO) UpdateAutoScaler(p *ProvisioningParametersDTO) bool {
 	updated := false
 	if u.AutoScalerMin != nil {
@@ -223,6 +249,77 @@ func (u UpdatingParametersDTO) UpdateAutoScaler(p *ProvisioningParametersDTO) bo
 	return updated
 }
 
+func (u UpdatingParametersDTO) UpdateAdditionalWorkerNodePools(p *ProvisioningParametersDTO) bool {
+	if u.AdditionalWorkerNodePools == nil {
+		return false
+	}
+	p.AdditionalWorkerNodePools = *u.AdditionalWorkerNodePools
+	return true
+}
+
+func (u UpdatingParametersDTO) UpdateOptionalComponents(p *ProvisioningParametersDTO) bool {
+	if u.OptionalComponentsToInstall == nil {
+		return false
+	}
+	p.OptionalComponentsToInstall = *u.OptionalComponentsToInstall
+	return true
+}
+
+// DefaultWorkerNodePoolName is the name of the worker group created by the provisioner from the machine type and autoscaler parameters
+const DefaultWorkerNodePoolName = "cpu-worker-0"
+
+var workerNodePoolNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+type AdditionalWorkerNodePool struct {
+	Name          string `json:"name"`
+	MachineType   string `json:"machineType"`
+	AutoScalerMin int    `json:"autoScalerMin"`
+	AutoScalerMax int    `json:"autoScalerMax"`
+}
+
+func (p AdditionalWorkerNodePool) Validate() error {
+	if len(p.Name) == 0 || len(p.Name) > 15 || !workerNodePoolNameRegexp.MatchString(p.Name) {
+		return fmt.Errorf("worker node pool name %q must consist of at most 15 lower case alphanumeric characters or '-'", p.Name)
+	}
+	if p.Name == DefaultWorkerNodePoolName {
+		return fmt.Errorf("worker node pool name %s is reserved", DefaultWorkerNodePoolName)
+	}
+	if p.MachineType == "" {
+		return fmt.Errorf("machine type of the worker node pool %s must not be empty", p.Name)
+	}
+	if p.AutoScalerMin < 0 || p.AutoScalerMax < 1 || p.AutoScalerMin > p.AutoScalerMax {
+		return fmt.Errorf("AutoScalerMax %v of the worker node pool %s should be positive and not smaller than AutoScalerMin %v", p.AutoScalerMax, p.Name, p.AutoScalerMin)
+	}
+	return nil
+}
+
+func ValidateAdditionalWorkerNodePools(pools []AdditionalWorkerNodePool) error {
+	names := map[string]struct{}{}
+	for _, pool := range pools {
+		if err := pool.Validate(); err != nil {
+			return err
+		}
+		if _, exists := names[pool.Name]; exists {
+			return fmt.Errorf("worker node pool name %s is not unique", pool.Name)
+		}
+		names[pool.Name] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateLabels checks if instance labels follow the Kubernetes label syntax
+func ValidateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value of the label %s: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
 type ERSContext struct {
 	TenantID              string                             `json:"tenant_id,omitempty"`
 	SubAccountID          string                             `json:"subaccount_id"`
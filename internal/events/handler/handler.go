@@ -2,14 +2,20 @@ package events
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/events"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 )
 
+const maxLimit = 1000
+
 type Handler struct {
 	e storage.Events
 	i storage.Instances
@@ -26,33 +32,135 @@ func split(s string) []string {
 	return strings.Split(s, ",")
 }
 
+// ServeHTTP returns a page of events if the limit or the cursor is set. Otherwise, it returns the array of all matching events,
+// as the API did before the pagination was introduced.
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	instanceId := r.URL.Query().Get("instance_ids")
-	instanceIds := split(instanceId)
-	runtimeId := r.URL.Query().Get("runtime_ids")
-	operationId := r.URL.Query().Get("operation_ids")
-	operationIds := split(operationId)
-	if runtimeId != "" {
-		instances, _, _, err := h.i.List(dbmodel.InstanceFilter{RuntimeIDs: split(runtimeId)})
+	query := r.URL.Query()
+	paginated := query.Has(events.LimitParam) || query.Has(events.CursorParam)
+	filter, err := parseFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if runtimeIDs := split(query.Get(events.RuntimeIDsParam)); len(runtimeIDs) != 0 {
+		instances, _, _, err := h.i.List(dbmodel.InstanceFilter{RuntimeIDs: runtimeIDs})
 		if err != nil {
 			http.Error(w, err.Error(), 503)
 			return
 		}
+		if len(instances) == 0 && len(filter.InstanceIDs) == 0 {
+			// none of the runtimes exists, the empty filter would match events of all instances
+			if paginated {
+				h.write(w, events.EventPage{Data: []events.EventDTO{}})
+			} else {
+				h.write(w, []events.EventDTO{})
+			}
+			return
+		}
 		for _, i := range instances {
-			instanceIds = append(instanceIds, i.InstanceID)
+			filter.InstanceIDs = append(filter.InstanceIDs, i.InstanceID)
 		}
 	}
-	events, err := h.e.ListEvents(events.EventFilter{InstanceIDs: instanceIds, OperationIDs: operationIds})
+
+	if !paginated {
+		filter.Limit = 0
+		list, err := h.e.ListEvents(filter)
+		if err != nil {
+			http.Error(w, err.Error(), 503)
+			return
+		}
+		if list == nil {
+			list = []events.EventDTO{}
+		}
+		h.write(w, list)
+		return
+	}
+
+	// one more event is read to know if there is the next page
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	list, err := h.e.ListEvents(filter)
 	if err != nil {
 		http.Error(w, err.Error(), 503)
 		return
 	}
-	bytes, err := json.Marshal(events)
+	page := events.EventPage{Data: list}
+	if len(list) > limit {
+		page.Data = list[:limit]
+		page.NextCursor = events.CursorOf(page.Data[limit-1])
+	}
+	if page.Data == nil {
+		page.Data = []events.EventDTO{}
+	}
+	page.Count = len(page.Data)
+	h.write(w, page)
+}
+
+func (h Handler) write(w http.ResponseWriter, response interface{}) {
+	bytes, err := json.Marshal(response)
 	if err != nil {
 		http.Error(w, err.Error(), 503)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
 	if _, err = w.Write(bytes); err != nil {
 		http.Error(w, err.Error(), 503)
 	}
 }
+
+func parseFilter(query url.Values) (events.EventFilter, error) {
+	filter := events.EventFilter{
+		InstanceIDs:     split(query.Get(events.InstanceIDsParam)),
+		OperationIDs:    split(query.Get(events.OperationIDsParam)),
+		MessageContains: query.Get(events.MessageParam),
+		Limit:           events.DefaultLimit,
+	}
+	for _, level := range split(query.Get(events.LevelsParam)) {
+		switch l := events.EventLevel(level); l {
+		case events.InfoEventLevel, events.WarningEventLevel, events.ErrorEventLevel:
+			filter.Levels = append(filter.Levels, l)
+		default:
+			return filter, fmt.Errorf("unknown event level %q", level)
+		}
+	}
+
+	var err error
+	if filter.From, err = parseTime(query, events.FromParam); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTime(query, events.ToParam); err != nil {
+		return filter, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("%s must be before %s", events.FromParam, events.ToParam)
+	}
+
+	if value := query.Get(events.LimitParam); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("%s has to be an integer", events.LimitParam)
+		}
+		if filter.Limit < 1 || filter.Limit > maxLimit {
+			return filter, fmt.Errorf("%s must be between 1 and %d", events.LimitParam, maxLimit)
+		}
+	}
+	if value := query.Get(events.CursorParam); value != "" {
+		filter.After, err = events.ParseCursor(value)
+		if err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+func parseTime(query url.Values, param string) (time.Time, error) {
+	value := query.Get(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a time in the RFC 3339 format: %w", param, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,166 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	for _, id := range []string{"inst-1", "inst-2"} {
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance(id)))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-"+id, id)))
+	}
+	ev := memory.NewEvents()
+	ev.InsertEvent(events.InfoEventLevel, "Provisioning started", "inst-1", "op-1", nil)
+	ev.InsertEvent(events.WarningEventLevel, "step Create_Runtime failed retries", "inst-1", "op-1", events.Fields{events.StepField: "Create_Runtime"})
+	ev.InsertEvent(events.ErrorEventLevel, "Provisioning failed", "inst-1", "op-1", nil)
+	ev.InsertEvent(events.InfoEventLevel, "Deprovisioning started", "inst-2", "op-2", nil)
+	ev.InsertEvent(events.InfoEventLevel, "Provisioning started", "inst-3", "op-3", nil)
+
+	handler := NewHandler(ev, db.Instances())
+
+	t.Run("should filter events", func(t *testing.T) {
+		// when
+		page := getPage(t, handler, url.Values{
+			events.InstanceIDsParam: {"inst-1,inst-2"},
+			events.LevelsParam:      {"info,warning"},
+			events.MessageParam:     {"started"},
+			events.LimitParam:       {"10"},
+		})
+
+		// then
+		assert.Equal(t, []string{"Provisioning started", "Deprovisioning started"}, messages(page.Data))
+		assert.Equal(t, 2, page.Count)
+		assert.Nil(t, page.NextCursor)
+	})
+
+	t.Run("should return the array of all events if neither limit nor cursor is set", func(t *testing.T) {
+		// when
+		list := getList(t, handler, url.Values{events.InstanceIDsParam: {"inst-1"}})
+
+		// then
+		assert.Equal(t, []string{"Provisioning started", "step Create_Runtime failed retries", "Provisioning failed"}, messages(list))
+	})
+
+	t.Run("should return fields of events", func(t *testing.T) {
+		// when
+		list := getList(t, handler, url.Values{events.LevelsParam: {"warning"}})
+
+		// then
+		require.Len(t, list, 1)
+		assert.Equal(t, events.Fields{events.StepField: "Create_Runtime"}, list[0].Fields)
+	})
+
+	t.Run("should find events of runtimes", func(t *testing.T) {
+		// when
+		list := getList(t, handler, url.Values{events.RuntimeIDsParam: {"runtime-inst-2"}})
+
+		// then
+		assert.Equal(t, []string{"Deprovisioning started"}, messages(list))
+	})
+
+	t.Run("should return no events of unknown runtimes", func(t *testing.T) {
+		// when
+		list := getList(t, handler, url.Values{events.RuntimeIDsParam: {"runtime-unknown"}})
+
+		// then
+		assert.Empty(t, list)
+
+		// when
+		page := getPage(t, handler, url.Values{events.RuntimeIDsParam: {"runtime-unknown"}, events.LimitParam: {"10"}})
+
+		// then
+		assert.Empty(t, page.Data)
+		assert.Equal(t, 0, page.Count)
+	})
+
+	t.Run("should return events page by page", func(t *testing.T) {
+		// given
+		query := url.Values{events.LimitParam: {"2"}}
+		var listed []string
+
+		// when
+		for pages := 0; pages < 5; pages++ {
+			page := getPage(t, handler, query)
+			listed = append(listed, messages(page.Data)...)
+			if page.NextCursor == nil {
+				break
+			}
+			assert.Equal(t, 2, page.Count)
+			query.Set(events.CursorParam, page.NextCursor.String())
+		}
+
+		// then
+		assert.Equal(t, []string{
+			"Provisioning started",
+			"step Create_Runtime failed retries",
+			"Provisioning failed",
+			"Deprovisioning started",
+			"Provisioning started",
+		}, listed)
+	})
+
+	t.Run("should reject invalid query", func(t *testing.T) {
+		for name, query := range map[string]url.Values{
+			"unknown level":  {events.LevelsParam: {"info,debug"}},
+			"invalid time":   {events.FromParam: {"yesterday"}},
+			"empty range":    {events.FromParam: {"2023-01-02T00:00:00Z"}, events.ToParam: {"2023-01-01T00:00:00Z"}},
+			"invalid limit":  {events.LimitParam: {"ten"}},
+			"too big limit":  {events.LimitParam: {"1001"}},
+			"zero limit":     {events.LimitParam: {"0"}},
+			"invalid cursor": {events.CursorParam: {"not a cursor"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// when
+				rr := get(t, handler, query)
+
+				// then
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+		}
+	})
+}
+
+func get(t *testing.T, handler Handler, query url.Values) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodGet, "/events?"+query.Encode(), nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func getPage(t *testing.T, handler Handler, query url.Values) events.EventPage {
+	rr := get(t, handler, query)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var page events.EventPage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	return page
+}
+
+func getList(t *testing.T, handler Handler, query url.Values) []events.EventDTO {
+	rr := get(t, handler, query)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var list []events.EventDTO
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	return list
+}
+
+func messages(list []events.EventDTO) []string {
+	messages := make([]string, 0, len(list))
+	for _, ev := range list {
+		messages = append(messages, ev.Message)
+	}
+	return messages
+}
@@ -21,7 +21,7 @@ var (
 
 type Interface interface {
 	ListEvents(filter events.EventFilter) ([]events.EventDTO, error)
-	InsertEvent(eventLevel events.EventLevel, message, instanceID, operationID string)
+	InsertEvent(eventLevel events.EventLevel, message, instanceID, operationID string, fields events.Fields)
 	RunGarbageCollection(pollingPeriod, retention time.Duration)
 }
 
@@ -39,15 +39,40 @@ func New(cfg Config, events Interface) Interface {
 }
 
 func Infof(instanceID, operationID, format string, args ...any) {
-	insertEvent(events.InfoEventLevel, fmt.Sprintf(format, args...), instanceID, operationID)
+	Entry{}.Infof(instanceID, operationID, format, args...)
+}
+
+func Warnf(instanceID, operationID, format string, args ...any) {
+	Entry{}.Warnf(instanceID, operationID, format, args...)
 }
 
 func Errorf(instanceID, operationID string, err error, format string, args ...any) {
-	insertEvent(events.ErrorEventLevel, fmt.Sprintf("%v: %v", fmt.Sprintf(format, args...), err), instanceID, operationID)
+	Entry{}.Errorf(instanceID, operationID, err, format, args...)
+}
+
+// Entry inserts events with structured fields
+type Entry struct {
+	fields events.Fields
+}
+
+func WithFields(fields events.Fields) Entry {
+	return Entry{fields: fields}
+}
+
+func (e Entry) Infof(instanceID, operationID, format string, args ...any) {
+	insertEvent(events.InfoEventLevel, fmt.Sprintf(format, args...), instanceID, operationID, e.fields)
+}
+
+func (e Entry) Warnf(instanceID, operationID, format string, args ...any) {
+	insertEvent(events.WarningEventLevel, fmt.Sprintf(format, args...), instanceID, operationID, e.fields)
+}
+
+func (e Entry) Errorf(instanceID, operationID string, err error, format string, args ...any) {
+	insertEvent(events.ErrorEventLevel, fmt.Sprintf("%v: %v", fmt.Sprintf(format, args...), err), instanceID, operationID, e.fields)
 }
 
-func insertEvent(eventLevel events.EventLevel, msg, instanceID, operationID string) {
+func insertEvent(eventLevel events.EventLevel, msg, instanceID, operationID string, fields events.Fields) {
 	if ev != nil {
-		ev.InsertEvent(eventLevel, msg, instanceID, operationID)
+		ev.InsertEvent(eventLevel, msg, instanceID, operationID, fields)
 	}
 }
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/binding"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BindingsCleanupCollector provides the following metrics:
+// - compass_keb_bindings_expired - number of expired bindings found by the last cleanup run
+// - compass_keb_bindings_cleanup_removed_total - number of expired bindings removed from the SKR and the storage
+// - compass_keb_bindings_cleanup_failures_total - number of expired bindings which could not be removed
+type BindingsCleanupCollector struct {
+	expiredGauge    prometheus.Gauge
+	removedCounter  prometheus.Counter
+	failuresCounter prometheus.Counter
+}
+
+func NewBindingsCleanupCollector() *BindingsCleanupCollector {
+	return &BindingsCleanupCollector{
+		expiredGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "bindings_expired",
+			Help:      "Number of expired bindings found by the last cleanup run",
+		}),
+		removedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "bindings_cleanup_removed_total",
+			Help:      "Number of removed expired bindings",
+		}),
+		failuresCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "bindings_cleanup_failures_total",
+			Help:      "Number of expired bindings which could not be removed",
+		}),
+	}
+}
+
+func (c *BindingsCleanupCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.expiredGauge.Describe(ch)
+	c.removedCounter.Describe(ch)
+	c.failuresCounter.Describe(ch)
+}
+
+func (c *BindingsCleanupCollector) Collect(ch chan<- prometheus.Metric) {
+	c.expiredGauge.Collect(ch)
+	c.removedCounter.Collect(ch)
+	c.failuresCounter.Collect(ch)
+}
+
+func (c *BindingsCleanupCollector) OnExpiredBindingsCleaned(ctx context.Context, ev interface{}) error {
+	cleaned, ok := ev.(binding.ExpiredBindingsCleaned)
+	if !ok {
+		return fmt.Errorf("expected ExpiredBindingsCleaned but got %+v", ev)
+	}
+
+	c.expiredGauge.Set(float64(cleaned.Expired))
+	c.removedCounter.Add(float64(cleaned.Removed))
+	c.failuresCounter.Add(float64(cleaned.Failed))
+	return nil
+}
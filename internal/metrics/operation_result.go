@@ -145,8 +145,19 @@ func (c *OperationResultCollector) OnOperationStepProcessed(ctx context.Context,
 			StepProcessed: e.StepProcessed,
 			Operation:     internal.DeprovisioningOperation{Operation: e.Operation},
 		})
+	case internal.OperationTypeUpgradeKyma:
+		return c.OnUpgradeKymaStepProcessed(ctx, process.UpgradeKymaStepProcessed{
+			StepProcessed: e.StepProcessed,
+			Operation:     internal.UpgradeKymaOperation{Operation: e.Operation},
+		})
+	case internal.OperationTypeUpgradeCluster:
+		return c.OnUpgradeClusterStepProcessed(ctx, process.UpgradeClusterStepProcessed{
+			StepProcessed: e.StepProcessed,
+			Operation:     internal.UpgradeClusterOperation{Operation: e.Operation},
+		})
 	default:
-		return fmt.Errorf("expected OperationStep of types [%s, %s] but got %+v", internal.OperationTypeProvision, internal.OperationTypeDeprovision, e.Operation.Type)
+		return fmt.Errorf("expected OperationStep of types [%s, %s, %s, %s] but got %+v", internal.OperationTypeProvision, internal.OperationTypeDeprovision,
+			internal.OperationTypeUpgradeKyma, internal.OperationTypeUpgradeCluster, e.Operation.Type)
 	}
 }
 
@@ -235,7 +246,8 @@ func (c *OperationResultCollector) OnOperationSucceeded(ctx context.Context, ev
 		return fmt.Errorf("expected OperationSucceeded but got %+v", ev)
 	}
 
-	if operationSucceeded.Operation.Type == internal.OperationTypeProvision {
+	switch operationSucceeded.Operation.Type {
+	case internal.OperationTypeProvision:
 		provisioningOperation := process.ProvisioningSucceeded{
 			Operation: internal.ProvisioningOperation{Operation: operationSucceeded.Operation},
 		}
@@ -243,7 +255,16 @@ func (c *OperationResultCollector) OnOperationSucceeded(ctx context.Context, ev
 		if err != nil {
 			return err
 		}
-	} else {
+	// upgrade operations have no separate succeeded events, the gauge is set from the state of the operation
+	case internal.OperationTypeUpgradeKyma:
+		return c.OnUpgradeKymaStepProcessed(ctx, process.UpgradeKymaStepProcessed{
+			Operation: internal.UpgradeKymaOperation{Operation: operationSucceeded.Operation},
+		})
+	case internal.OperationTypeUpgradeCluster:
+		return c.OnUpgradeClusterStepProcessed(ctx, process.UpgradeClusterStepProcessed{
+			Operation: internal.UpgradeClusterOperation{Operation: operationSucceeded.Operation},
+		})
+	default:
 		return fmt.Errorf("expected OperationStep of type %s but got %+v", internal.OperationTypeProvision, operationSucceeded.Operation.Type)
 	}
 
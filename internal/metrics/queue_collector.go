@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueueCollector provides the following metrics for every queue and priority:
+// - compass_keb_queue_depth{"queue", "priority"} - the number of operations ready to be processed
+// - compass_keb_queue_oldest_wait_seconds{"queue", "priority"} - the time the first operation ready to be processed waits
+// - compass_keb_queue_wait_seconds{"queue", "priority"} - the summary of the time operations waited before they were processed
+type QueueCollector struct {
+	queues map[string]QueueStatsGetter
+
+	depth      *prometheus.Desc
+	oldestWait *prometheus.Desc
+	wait       *prometheus.Desc
+}
+
+type QueueStatsGetter interface {
+	Stats() []process.QueueStats
+}
+
+func NewQueueCollector(queues map[string]QueueStatsGetter) *QueueCollector {
+	return &QueueCollector{
+		queues: queues,
+		depth: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "queue_depth"),
+			"The number of operations ready to be processed",
+			[]string{"queue", "priority"},
+			nil),
+		oldestWait: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "queue_oldest_wait_seconds"),
+			"The time the first operation ready to be processed waits",
+			[]string{"queue", "priority"},
+			nil),
+		wait: prometheus.NewDesc(
+			prometheus.BuildFQName(prometheusNamespace, prometheusSubsystem, "queue_wait_seconds"),
+			"The time operations waited in the queue before they were processed",
+			[]string{"queue", "priority"},
+			nil),
+	}
+}
+
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+	ch <- c.oldestWait
+	ch <- c.wait
+}
+
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, queue := range c.queues {
+		for _, stats := range queue.Stats() {
+			priority := stats.Priority.String()
+			ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(stats.Depth), name, priority)
+			ch <- prometheus.MustNewConstMetric(c.oldestWait, prometheus.GaugeValue, stats.OldestWait.Seconds(), name, priority)
+			ch <- prometheus.MustNewConstSummary(c.wait, stats.Dequeued, stats.WaitTotal.Seconds(), nil, name, priority)
+		}
+	}
+}
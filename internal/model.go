@@ -14,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	reconcilerApi "github.com/kyma-incubator/reconciler/pkg/keb"
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	eventsapi "github.com/kyma-project/kyma-environment-broker/common/events"
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
@@ -135,6 +136,8 @@ type Instance struct {
 	DashboardURL   string
 	Parameters     ProvisioningParameters
 	ProviderRegion string
+	// Labels are user defined key/value pairs used to group runtimes
+	Labels map[string]string
 
 	InstanceDetails InstanceDetails
 
@@ -142,6 +145,9 @@ type Instance struct {
 	UpdatedAt time.Time
 	DeletedAt time.Time
 	ExpiredAt *time.Time
+	// CompensationFailedAt is set when undoing side effects of the failed provisioning failed,
+	// such instances are deprovisioned by the deprovisioning retrigger job
+	CompensationFailedAt *time.Time
 
 	Version      int
 	Provider     CloudProvider
@@ -209,6 +215,9 @@ type Operation struct {
 	FinishedStages  []string           `json:"-"`
 	LastError       kebError.LastError `json:"-"`
 
+	// RequestIdentity is the X-Broker-API-Request-Identity header of the OSB request which created the operation
+	RequestIdentity string `json:"-"`
+
 	// PROVISIONING
 	RuntimeVersion RuntimeVersionData `json:"runtime_version"`
 	DashboardURL   string             `json:"dashboardURL"`
@@ -222,13 +231,34 @@ type Operation struct {
 	ExcutedButNotCompleted      []string  `json:"excutedButNotCompleted"`
 	UserAgent                   string    `json:"userAgent,omitempty"`
 
+	// RETRY
+	// RetryCount is the number of retries of the failed operation triggered by an operator, RetriedAt is the time of the last retry
+	RetryCount int        `json:"retryCount,omitempty"`
+	RetriedAt  *time.Time `json:"retriedAt,omitempty"`
+	// SkippedSteps are not executed by the staged manager
+	SkippedSteps []string `json:"skippedSteps,omitempty"`
+
+	// FinishedParallelSteps are steps of parallel groups which are not run again when the group is retried
+	FinishedParallelSteps []string `json:"finishedParallelSteps,omitempty"`
+
+	// COMPENSATION
+	// Compensations are names of steps which side effects are undone when the operation fails,
+	// Compensated is set when the compensation was run
+	Compensations []string `json:"compensations,omitempty"`
+	Compensated   bool     `json:"compensated,omitempty"`
+
 	// UPDATING
 	UpdatingParameters    UpdatingParametersDTO `json:"updating_parameters"`
 	CheckReconcilerStatus bool                  `json:"check_reconciler_status"`
 	K8sClient             client.Client         `json:"-"`
+	// PreviousPlanID is the plan of the instance before the plan change requested with the update
+	PreviousPlanID string `json:"previousPlanID,omitempty"`
 
 	// following fields are not stored in the storage
 
+	// ResumedAt is the time of the last resume of the operation, the staged manager sets it from the operation pause
+	ResumedAt time.Time `json:"-"`
+
 	// Last runtime state payload
 	LastRuntimeState RuntimeState `json:"-"`
 
@@ -242,6 +272,21 @@ type Operation struct {
 
 	// KymaTemplate is read from the configuration then used in the apply_kyma step
 	KymaTemplate string `json:"KymaTemplate"`
+
+	// StepPolicy is the effective retry and timeout policy of the step being processed, set by the staged manager
+	StepPolicy StepPolicy `json:"-"`
+	// StepStartedAt is the time of the first attempt of the step being processed since the processing of the operation started,
+	// set by the staged manager if the step policy sets the timeout
+	StepStartedAt time.Time `json:"-"`
+}
+
+// StepTimeLimit returns the time limit of the step being processed and the time elapsed towards it. The timeout of the step policy
+// is measured since the first attempt of the step, otherwise the given time limit is measured since the last update of the operation.
+func (o *Operation) StepTimeLimit(timeLimit time.Duration) (time.Duration, time.Duration) {
+	if o.StepPolicy.Timeout != 0 && !o.StepStartedAt.IsZero() {
+		return o.StepPolicy.Timeout, time.Since(o.StepStartedAt)
+	}
+	return timeLimit, time.Since(o.UpdatedAt)
 }
 
 func (o *Operation) IsFinished() bool {
@@ -252,10 +297,38 @@ func (o *Operation) EventInfof(fmt string, args ...any) {
 	events.Infof(o.InstanceID, o.ID, fmt, args...)
 }
 
+func (o *Operation) EventWarnf(fmt string, args ...any) {
+	events.Warnf(o.InstanceID, o.ID, fmt, args...)
+}
+
 func (o *Operation) EventErrorf(err error, fmt string, args ...any) {
 	events.Errorf(o.InstanceID, o.ID, err, fmt, args...)
 }
 
+// EventWithFields returns events of the operation with the structured fields
+func (o *Operation) EventWithFields(fields eventsapi.Fields) OperationEvents {
+	return OperationEvents{instanceID: o.InstanceID, operationID: o.ID, entry: events.WithFields(fields)}
+}
+
+// OperationEvents inserts events of the operation with structured fields
+type OperationEvents struct {
+	instanceID  string
+	operationID string
+	entry       events.Entry
+}
+
+func (e OperationEvents) Infof(fmt string, args ...any) {
+	e.entry.Infof(e.instanceID, e.operationID, fmt, args...)
+}
+
+func (e OperationEvents) Warnf(fmt string, args ...any) {
+	e.entry.Warnf(e.instanceID, e.operationID, fmt, args...)
+}
+
+func (e OperationEvents) Errorf(err error, fmt string, args ...any) {
+	e.entry.Errorf(e.instanceID, e.operationID, err, fmt, args...)
+}
+
 // Orchestration holds all information about an orchestration.
 // Orchestration performs operations of a specific type (UpgradeKymaOperation, UpgradeClusterOperation)
 // on specific targets of SKRs.
@@ -467,6 +540,120 @@ func (r *RuntimeState) buildKymaConfigFromClusterSetup() gqlschema.KymaConfigInp
 	return kymaConfig
 }
 
+// Binding holds information about an OSB service binding. Every binding is backed by a dedicated service account
+// created on the SKR, the kubeconfig contains a token of that service account which expires at ExpiresAt.
+type Binding struct {
+	ID         string
+	InstanceID string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ExpiresAt time.Time
+
+	Kubeconfig        string
+	ExpirationSeconds int64
+	ClusterRole       string
+}
+
+func (b *Binding) IsExpired() bool {
+	return !b.ExpiresAt.IsZero() && b.ExpiresAt.Before(time.Now())
+}
+
+// StepAttempt is a single run of an operation step. Backoff is set when the step asked for a retry,
+// error fields are set when the step returned an error.
+type StepAttempt struct {
+	OperationID string
+	Stage       string
+	StepName    string
+
+	StartedAt time.Time
+	Duration  time.Duration
+	Backoff   time.Duration
+
+	ErrorReason    string
+	ErrorComponent string
+	Error          string
+}
+
+// ProcessingPause stops the processing of all operations of the given type until the pause is removed
+type ProcessingPause struct {
+	OperationType OperationType
+	Reason        string
+	PausedAt      time.Time
+}
+
+// OperationPause stops the processing of the operation until it is resumed. The pause is stored apart from the operation,
+// so pausing does not change the version of the operation saved by the running step. ResumedAt is the time of the last resume
+// of the operation or of the processing of its type, the resumed operation gets the whole operation timeout again.
+type OperationPause struct {
+	OperationID string
+	Paused      bool
+	PausedAt    time.Time
+	ResumedAt   time.Time
+}
+
+// Lease allows only one replica of the broker to process the operation or orchestration with the given ID until LeaseUntil
+type Lease struct {
+	ID         string
+	Kind       string
+	Owner      string
+	LeaseUntil time.Time
+}
+
+type BackoffStrategy string
+
+const (
+	FixedBackoff       BackoffStrategy = "fixed"
+	ExponentialBackoff BackoffStrategy = "exponential"
+)
+
+// StepPolicy defines how a step is retried and how long it may take. Zero values are not set,
+// in such case the step decides on its own.
+type StepPolicy struct {
+	// RetryInterval is the time between attempts, the first interval in case of the exponential backoff
+	RetryInterval time.Duration   `yaml:"retryInterval"`
+	Backoff       BackoffStrategy `yaml:"backoff"`
+	// MaxRetryInterval limits the interval growing with the exponential backoff
+	MaxRetryInterval time.Duration `yaml:"maxRetryInterval"`
+	// Jitter is the fraction of the interval which is randomly added to or subtracted from it
+	Jitter float64 `yaml:"jitter"`
+	// MaxAttempts is the number of attempts after which the operation fails
+	MaxAttempts int `yaml:"maxAttempts"`
+	// Timeout is the time since the first attempt of the step after which the operation fails
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Merge returns the policy with values overridden by the values set in the given policy
+func (p StepPolicy) Merge(override StepPolicy) StepPolicy {
+	if override.RetryInterval != 0 {
+		p.RetryInterval = override.RetryInterval
+	}
+	if override.Backoff != "" {
+		p.Backoff = override.Backoff
+	}
+	if override.MaxRetryInterval != 0 {
+		p.MaxRetryInterval = override.MaxRetryInterval
+	}
+	if override.Jitter != 0 {
+		p.Jitter = override.Jitter
+	}
+	if override.MaxAttempts != 0 {
+		p.MaxAttempts = override.MaxAttempts
+	}
+	if override.Timeout != 0 {
+		p.Timeout = override.Timeout
+	}
+	return p
+}
+
+// RetryIntervalOr returns the retry interval of the policy or the given one if the policy does not set it
+func (p StepPolicy) RetryIntervalOr(retryInterval time.Duration) time.Duration {
+	if p.RetryInterval != 0 {
+		return p.RetryInterval
+	}
+	return retryInterval
+}
+
 // OperationStats provide number of operations per type and state
 type OperationStats struct {
 	Provisioning   map[domain.LastOperationState]int
@@ -570,6 +757,11 @@ func NewUpdateOperation(operationID string, instance *Instance, updatingParams U
 	}
 
 	updatingParams.UpdateAutoScaler(&op.ProvisioningParameters.Parameters)
+	updatingParams.UpdateAdditionalWorkerNodePools(&op.ProvisioningParameters.Parameters)
+	updatingParams.UpdateOptionalComponents(&op.ProvisioningParameters.Parameters)
+	if updatingParams.Labels != nil {
+		op.ProvisioningParameters.Parameters.Labels = updatingParams.Labels
+	}
 	if updatingParams.MachineType != nil && *updatingParams.MachineType != "" {
 		op.ProvisioningParameters.Parameters.MachineType = updatingParams.MachineType
 	}
@@ -620,6 +812,36 @@ func (o *Operation) IsStageFinished(stage string) bool {
 	return false
 }
 
+func (o *Operation) IsStepSkipped(step string) bool {
+	for _, value := range o.SkippedSteps {
+		if value == step {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Operation) IsParallelStepFinished(step string) bool {
+	for _, value := range o.FinishedParallelSteps {
+		if value == step {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessingStartedAt returns the time from which the operation timeout is measured, a retried or resumed operation gets the whole timeout again
+func (o *Operation) ProcessingStartedAt() time.Time {
+	startedAt := o.CreatedAt
+	if o.RetriedAt != nil && o.RetriedAt.After(startedAt) {
+		startedAt = *o.RetriedAt
+	}
+	if o.ResumedAt.After(startedAt) {
+		startedAt = o.ResumedAt
+	}
+	return startedAt
+}
+
 type ComponentConfigurationInputList []*gqlschema.ComponentConfigurationInput
 
 func (l ComponentConfigurationInputList) DeepCopy() []*gqlschema.ComponentConfigurationInput {
@@ -1,8 +1,11 @@
 package internal
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,6 +48,26 @@ func TestFinishStage(t *testing.T) {
 	})
 }
 
+func TestProcessingStartedAt(t *testing.T) {
+	createdAt := time.Date(2022, 10, 18, 13, 0, 0, 0, time.UTC)
+
+	t.Run("should return creation time of not retried operation", func(t *testing.T) {
+		operation := Operation{CreatedAt: createdAt}
+		assert.Equal(t, createdAt, operation.ProcessingStartedAt())
+	})
+
+	t.Run("should return time of the last retry", func(t *testing.T) {
+		operation := Operation{CreatedAt: createdAt, RetriedAt: ptr.Time(createdAt.Add(time.Hour))}
+		assert.Equal(t, createdAt.Add(time.Hour), operation.ProcessingStartedAt())
+	})
+
+	t.Run("should not serialize retry time of not retried operation", func(t *testing.T) {
+		data, err := json.Marshal(Operation{CreatedAt: createdAt})
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "retriedAt")
+	})
+}
+
 func countStageOccurrences(operation ProvisioningOperation, stage string) int {
 	foundStages := 0
 	for _, v := range operation.FinishedStages {
@@ -0,0 +1,522 @@
+package operations
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// StepAttempt is a single run of the step, the backoff is set if the step asked for a retry
+type StepAttempt struct {
+	Stage          string    `json:"stage,omitempty"`
+	Step           string    `json:"step"`
+	StartedAt      time.Time `json:"startedAt"`
+	DurationMs     int64     `json:"durationMs"`
+	BackoffMs      int64     `json:"backoffMs,omitempty"`
+	ErrorReason    string    `json:"errorReason,omitempty"`
+	ErrorComponent string    `json:"errorComponent,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// StepSummary aggregates all attempts of the step
+type StepSummary struct {
+	Stage           string `json:"stage,omitempty"`
+	Step            string `json:"step"`
+	Attempts        int    `json:"attempts"`
+	Retries         int    `json:"retries"`
+	Errors          int    `json:"errors"`
+	TotalDurationMs int64  `json:"totalDurationMs"`
+}
+
+type StepsResponse struct {
+	OperationID string        `json:"operationID"`
+	Attempts    int           `json:"attempts"`
+	Retries     int           `json:"retries"`
+	Errors      int           `json:"errors"`
+	Steps       []StepSummary `json:"steps"`
+	History     []StepAttempt `json:"history"`
+}
+
+// RetryResponse describes the operation queued again by the retry
+type RetryResponse struct {
+	OperationID  string   `json:"operationID"`
+	State        string   `json:"state"`
+	RetryCount   int      `json:"retryCount"`
+	SkippedSteps []string `json:"skippedSteps,omitempty"`
+}
+
+// PauseResponse describes the operation after it was paused or resumed
+type PauseResponse struct {
+	OperationID string `json:"operationID"`
+	State       string `json:"state"`
+	Paused      bool   `json:"paused"`
+}
+
+type ProcessingPause struct {
+	OperationType string    `json:"operationType"`
+	Reason        string    `json:"reason,omitempty"`
+	PausedAt      time.Time `json:"pausedAt"`
+}
+
+// StepPolicy is the retry and timeout policy of the step, durations are formatted like 1m30s
+type StepPolicy struct {
+	RetryInterval    string  `json:"retryInterval,omitempty"`
+	Backoff          string  `json:"backoff,omitempty"`
+	MaxRetryInterval string  `json:"maxRetryInterval,omitempty"`
+	Jitter           float64 `json:"jitter,omitempty"`
+	MaxAttempts      int     `json:"maxAttempts,omitempty"`
+	Timeout          string  `json:"timeout,omitempty"`
+}
+
+type StepPolicyWithPlans struct {
+	StepPolicy
+	Plans map[string]StepPolicy `json:"plans,omitempty"`
+}
+
+// StepPoliciesResponse shows policies merged with the default policy, the policies of plans are merged with the policy of the step
+type StepPoliciesResponse struct {
+	Default StepPolicy                     `json:"default"`
+	Steps   map[string]StepPolicyWithPlans `json:"steps"`
+}
+
+// pausableOperationTypes are processed by the staged manager, which does not run steps of paused operations
+var pausableOperationTypes = []internal.OperationType{
+	internal.OperationTypeProvision,
+	internal.OperationTypeDeprovision,
+	internal.OperationTypeUpdate,
+}
+
+type Queue interface {
+	Add(operationID string)
+}
+
+// StepChecker checks if the step is processed by the manager, it is implemented by the process.StagedManager
+type StepChecker interface {
+	HasStep(name string) bool
+}
+
+// RetryTarget defines the queue processing the retried operation and the manager which knows steps which can be skipped
+type RetryTarget struct {
+	Queue   Queue
+	Manager StepChecker
+}
+
+type Handler struct {
+	operations   storage.Operations
+	retryTargets map[internal.OperationType]RetryTarget
+	stepPolicies *process.StepPolicies
+	log          logrus.FieldLogger
+}
+
+// NewHandler creates the operations handler, only failed operations of types defined in retryTargets can be retried
+func NewHandler(operations storage.Operations, retryTargets map[internal.OperationType]RetryTarget, stepPolicies *process.StepPolicies, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		operations:   operations,
+		retryTargets: retryTargets,
+		stepPolicies: stepPolicies,
+		log:          log.WithField("service", "OperationsHandler"),
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/operations/{operation_id}/steps", h.getSteps).Methods(http.MethodGet)
+	router.HandleFunc("/operations/{operation_id}/retry", h.retry).Methods(http.MethodPost)
+	router.HandleFunc("/operations/{operation_id}/pause", h.pauseOperation).Methods(http.MethodPut)
+	router.HandleFunc("/operations/{operation_id}/resume", h.resumeOperation).Methods(http.MethodPut)
+	router.HandleFunc("/admin/processing", h.listProcessingPauses).Methods(http.MethodGet)
+	router.HandleFunc("/admin/processing/{type}/pause", h.pauseProcessing).Methods(http.MethodPut)
+	router.HandleFunc("/admin/processing/{type}/resume", h.resumeProcessing).Methods(http.MethodPut)
+	router.HandleFunc("/admin/step-policies", h.getStepPolicies).Methods(http.MethodGet)
+}
+
+func (h *Handler) getSteps(w http.ResponseWriter, r *http.Request) {
+	operationID := mux.Vars(r)["operation_id"]
+
+	_, err := h.operations.GetOperationByID(operationID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, fmt.Errorf("operation %s not found", operationID))
+		return
+	case err != nil:
+		h.log.Errorf("while getting operation %s: %v", operationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting operation: %w", err))
+		return
+	}
+
+	attempts, err := h.operations.ListStepAttemptsByOperationID(operationID)
+	if err != nil {
+		h.log.Errorf("while getting step attempts of operation %s: %v", operationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting step attempts: %w", err))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, newStepsResponse(operationID, attempts))
+}
+
+// retry queues the failed operation again. Finished stages are kept, so the processing continues from the failed stage.
+// Steps given in the skip query parameter are not executed anymore.
+func (h *Handler) retry(w http.ResponseWriter, r *http.Request) {
+	operationID := mux.Vars(r)["operation_id"]
+	skip := r.URL.Query()["skip"]
+
+	operation, err := h.operations.GetOperationByID(operationID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, fmt.Errorf("operation %s not found", operationID))
+		return
+	case err != nil:
+		h.log.Errorf("while getting operation %s: %v", operationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting operation: %w", err))
+		return
+	}
+
+	target, found := h.retryTargets[operation.Type]
+	if !found {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("retry of %s operations is not supported", operation.Type))
+		return
+	}
+	if operation.State != domain.Failed {
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("operation is %s, only failed operations can be retried", operation.State))
+		return
+	}
+	if operation.Compensated {
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("side effects of operation %s were compensated, the operation cannot be retried", operation.ID))
+		return
+	}
+	lastOperation, err := h.operations.GetLastOperation(operation.InstanceID)
+	if err != nil {
+		h.log.Errorf("while getting last operation of instance %s: %v", operation.InstanceID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting last operation: %w", err))
+		return
+	}
+	if lastOperation.ID != operation.ID {
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("operation %s is not the last operation of the instance %s", operation.ID, operation.InstanceID))
+		return
+	}
+	for _, step := range skip {
+		if !target.Manager.HasStep(step) {
+			httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("step %s is not defined for %s operations", step, operation.Type))
+			return
+		}
+	}
+
+	operation.State = domain.InProgress
+	operation.Description = "Operation retried"
+	operation.LastError = kebError.LastError{}
+	operation.RetryCount++
+	operation.RetriedAt = ptr.Time(time.Now())
+	for _, step := range skip {
+		if !operation.IsStepSkipped(step) {
+			operation.SkippedSteps = append(operation.SkippedSteps, step)
+		}
+	}
+	updated, err := h.operations.UpdateOperation(*operation)
+	switch {
+	case dberr.IsConflict(err):
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("operation %s was modified, try again", operationID))
+		return
+	case err != nil:
+		h.log.Errorf("while updating operation %s: %v", operationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while updating operation: %w", err))
+		return
+	}
+
+	h.log.Infof("Retrying operation %s (retry %d), skipped steps: %v", operationID, updated.RetryCount, updated.SkippedSteps)
+	updated.EventWithFields(events.Fields{events.RetryCountField: updated.RetryCount}).Infof("operation retried by an operator (retry %d)", updated.RetryCount)
+	for _, step := range skip {
+		updated.EventWithFields(events.Fields{events.StepField: step}).Infof("step %s will be skipped", step)
+	}
+	target.Queue.Add(operationID)
+
+	httputil.WriteResponse(w, http.StatusAccepted, RetryResponse{
+		OperationID:  updated.ID,
+		State:        string(updated.State),
+		RetryCount:   updated.RetryCount,
+		SkippedSteps: updated.SkippedSteps,
+	})
+}
+
+func (h *Handler) pauseOperation(w http.ResponseWriter, r *http.Request) {
+	h.setOperationPaused(w, r, true)
+}
+
+func (h *Handler) resumeOperation(w http.ResponseWriter, r *http.Request) {
+	h.setOperationPaused(w, r, false)
+}
+
+// setOperationPaused pauses or resumes the operation in progress, the step which is already running is not interrupted.
+// The resumed operation gets the whole operation timeout again.
+func (h *Handler) setOperationPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	operationID := mux.Vars(r)["operation_id"]
+
+	operation, err := h.operations.GetOperationByID(operationID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, fmt.Errorf("operation %s not found", operationID))
+		return
+	case err != nil:
+		h.log.Errorf("while getting operation %s: %v", operationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting operation: %w", err))
+		return
+	}
+
+	if !isPausable(operation.Type) {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("pausing of %s operations is not supported", operation.Type))
+		return
+	}
+	if operation.State != domain.InProgress {
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("operation is %s, only operations in progress can be paused or resumed", operation.State))
+		return
+	}
+
+	pause, err := h.operations.GetOperationPause(operationID)
+	switch {
+	case dberr.IsNotFound(err):
+		pause = &internal.OperationPause{OperationID: operationID}
+	case err != nil:
+		h.log.Errorf("while getting pause of operation %s: %v", operationID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting operation pause: %w", err))
+		return
+	}
+
+	if pause.Paused != paused {
+		// the pause is stored apart from the operation, so the step which is running can save the operation
+		pause.Paused = paused
+		if paused {
+			pause.PausedAt = time.Now()
+		} else {
+			pause.ResumedAt = time.Now()
+		}
+		if err := h.operations.UpsertOperationPause(*pause); err != nil {
+			h.log.Errorf("while saving pause of operation %s: %v", operationID, err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while saving operation pause: %w", err))
+			return
+		}
+
+		if paused {
+			h.log.Infof("Operation %s paused", operationID)
+			operation.EventInfof("operation paused by an operator")
+		} else {
+			h.log.Infof("Operation %s resumed", operationID)
+			operation.EventInfof("operation resumed by an operator")
+		}
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, PauseResponse{
+		OperationID: operation.ID,
+		State:       string(operation.State),
+		Paused:      pause.Paused,
+	})
+}
+
+func (h *Handler) listProcessingPauses(w http.ResponseWriter, _ *http.Request) {
+	pauses, err := h.operations.ListProcessingPauses()
+	if err != nil {
+		h.log.Errorf("while listing processing pauses: %v", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while listing processing pauses: %w", err))
+		return
+	}
+
+	response := make([]ProcessingPause, 0, len(pauses))
+	for _, pause := range pauses {
+		response = append(response, toProcessingPause(pause))
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+// pauseProcessing stops the processing of all operations of the given type, the pause is kept until it is resumed
+func (h *Handler) pauseProcessing(w http.ResponseWriter, r *http.Request) {
+	operationType := internal.OperationType(mux.Vars(r)["type"])
+	if !isPausable(operationType) {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("pausing of %s operations is not supported", operationType))
+		return
+	}
+
+	err := h.operations.InsertProcessingPause(internal.ProcessingPause{
+		OperationType: operationType,
+		Reason:        r.URL.Query().Get("reason"),
+		PausedAt:      time.Now(),
+	})
+	if err != nil && !dberr.IsAlreadyExists(err) {
+		h.log.Errorf("while pausing processing of %s operations: %v", operationType, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while pausing processing: %w", err))
+		return
+	}
+
+	pause, err := h.operations.GetProcessingPause(operationType)
+	if err != nil {
+		h.log.Errorf("while getting processing pause of %s operations: %v", operationType, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting processing pause: %w", err))
+		return
+	}
+	h.log.Infof("Processing of %s operations paused since %s, reason: %q", operationType, pause.PausedAt, pause.Reason)
+
+	httputil.WriteResponse(w, http.StatusOK, toProcessingPause(*pause))
+}
+
+// resumeProcessing removes the pause, operations of the given type in progress get the whole operation timeout again
+func (h *Handler) resumeProcessing(w http.ResponseWriter, r *http.Request) {
+	operationType := internal.OperationType(mux.Vars(r)["type"])
+	if !isPausable(operationType) {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("pausing of %s operations is not supported", operationType))
+		return
+	}
+
+	_, err := h.operations.GetProcessingPause(operationType)
+	switch {
+	case dberr.IsNotFound(err):
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case err != nil:
+		h.log.Errorf("while getting processing pause of %s operations: %v", operationType, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting processing pause: %w", err))
+		return
+	}
+
+	operations, err := h.operations.GetNotFinishedOperationsByType(operationType)
+	if err != nil {
+		h.log.Errorf("while getting %s operations in progress: %v", operationType, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting operations in progress: %w", err))
+		return
+	}
+	if err := h.operations.DeleteProcessingPause(operationType); err != nil {
+		h.log.Errorf("while resuming processing of %s operations: %v", operationType, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while resuming processing: %w", err))
+		return
+	}
+
+	resumedAt := time.Now()
+	for _, operation := range operations {
+		if err := h.setResumedAt(operation.ID, resumedAt); err != nil {
+			h.log.Errorf("while setting the resume time of the operation %s: %v", operation.ID, err)
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while setting the resume time of the operation %s: %w", operation.ID, err))
+			return
+		}
+	}
+	h.log.Infof("Processing of %s operations resumed", operationType)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setResumedAt sets the resume time in the operation pause, the operation paused on its own stays paused
+func (h *Handler) setResumedAt(operationID string, resumedAt time.Time) error {
+	pause, err := h.operations.GetOperationPause(operationID)
+	switch {
+	case dberr.IsNotFound(err):
+		pause = &internal.OperationPause{OperationID: operationID}
+	case err != nil:
+		return err
+	}
+	pause.ResumedAt = resumedAt
+	return h.operations.UpsertOperationPause(*pause)
+}
+
+func (h *Handler) getStepPolicies(w http.ResponseWriter, _ *http.Request) {
+	merged := h.stepPolicies.Merged()
+	response := StepPoliciesResponse{
+		Default: toStepPolicy(merged.Default),
+		Steps:   map[string]StepPolicyWithPlans{},
+	}
+	for name, step := range merged.Steps {
+		policy := StepPolicyWithPlans{StepPolicy: toStepPolicy(step.StepPolicy)}
+		for plan, planPolicy := range step.Plans {
+			if policy.Plans == nil {
+				policy.Plans = map[string]StepPolicy{}
+			}
+			policy.Plans[plan] = toStepPolicy(planPolicy)
+		}
+		response.Steps[name] = policy
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+func isPausable(operationType internal.OperationType) bool {
+	for _, t := range pausableOperationTypes {
+		if t == operationType {
+			return true
+		}
+	}
+	return false
+}
+
+func toProcessingPause(pause internal.ProcessingPause) ProcessingPause {
+	return ProcessingPause{
+		OperationType: string(pause.OperationType),
+		Reason:        pause.Reason,
+		PausedAt:      pause.PausedAt,
+	}
+}
+
+func toStepPolicy(policy internal.StepPolicy) StepPolicy {
+	return StepPolicy{
+		RetryInterval:    formatDuration(policy.RetryInterval),
+		Backoff:          string(policy.Backoff),
+		MaxRetryInterval: formatDuration(policy.MaxRetryInterval),
+		Jitter:           policy.Jitter,
+		MaxAttempts:      policy.MaxAttempts,
+		Timeout:          formatDuration(policy.Timeout),
+	}
+}
+
+// formatDuration returns an empty string for not set durations
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// newStepsResponse builds the step history, the steps summary keeps the order in which steps were executed for the first time
+func newStepsResponse(operationID string, attempts []internal.StepAttempt) StepsResponse {
+	response := StepsResponse{
+		OperationID: operationID,
+		Steps:       make([]StepSummary, 0),
+		History:     make([]StepAttempt, 0, len(attempts)),
+	}
+	summaryIndex := map[string]int{}
+	for _, attempt := range attempts {
+		response.History = append(response.History, StepAttempt{
+			Stage:          attempt.Stage,
+			Step:           attempt.StepName,
+			StartedAt:      attempt.StartedAt,
+			DurationMs:     attempt.Duration.Milliseconds(),
+			BackoffMs:      attempt.Backoff.Milliseconds(),
+			ErrorReason:    attempt.ErrorReason,
+			ErrorComponent: attempt.ErrorComponent,
+			Error:          attempt.Error,
+		})
+
+		key := attempt.Stage + "/" + attempt.StepName
+		idx, found := summaryIndex[key]
+		if !found {
+			idx = len(response.Steps)
+			summaryIndex[key] = idx
+			response.Steps = append(response.Steps, StepSummary{Stage: attempt.Stage, Step: attempt.StepName})
+		}
+		summary := &response.Steps[idx]
+		summary.Attempts++
+		summary.TotalDurationMs += attempt.Duration.Milliseconds()
+		response.Attempts++
+		if attempt.Backoff > 0 {
+			summary.Retries++
+			response.Retries++
+		}
+		if attempt.Error != "" {
+			summary.Errors++
+			response.Errors++
+		}
+	}
+	return response
+}
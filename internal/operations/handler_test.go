@@ -0,0 +1,381 @@
+package operations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetSteps(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-id", "instance-id")))
+	start := time.Date(2022, 10, 18, 13, 0, 0, 0, time.UTC)
+	for _, attempt := range []internal.StepAttempt{
+		{Stage: "start", StepName: "Starting", Duration: 10 * time.Millisecond},
+		{Stage: "create_runtime", StepName: "Create_Runtime", Duration: 20 * time.Millisecond, Backoff: time.Minute},
+		{Stage: "create_runtime", StepName: "Create_Runtime", Duration: 30 * time.Millisecond,
+			ErrorReason: string(kebError.ErrKEBInternal), ErrorComponent: string(kebError.ErrKEB), Error: "timeout"},
+	} {
+		attempt.OperationID = "op-id"
+		attempt.StartedAt = start
+		require.NoError(t, db.Operations().InsertStepAttempt(attempt))
+	}
+
+	handler := NewHandler(db.Operations(), nil, nil, logrus.New())
+	router := mux.NewRouter()
+	handler.AttachRoutes(router)
+
+	t.Run("should return step history", func(t *testing.T) {
+		// when
+		req, err := http.NewRequest(http.MethodGet, "/operations/op-id/steps", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response StepsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "op-id", response.OperationID)
+		assert.Equal(t, 3, response.Attempts)
+		assert.Equal(t, 1, response.Retries)
+		assert.Equal(t, 1, response.Errors)
+		assert.Equal(t, []StepSummary{
+			{Stage: "start", Step: "Starting", Attempts: 1, TotalDurationMs: 10},
+			{Stage: "create_runtime", Step: "Create_Runtime", Attempts: 2, Retries: 1, Errors: 1, TotalDurationMs: 50},
+		}, response.Steps)
+		require.Len(t, response.History, 3)
+		assert.Equal(t, StepAttempt{
+			Stage:          "create_runtime",
+			Step:           "Create_Runtime",
+			StartedAt:      start,
+			DurationMs:     30,
+			ErrorReason:    "err_keb_internal",
+			ErrorComponent: "keb",
+			Error:          "timeout",
+		}, response.History[2])
+	})
+
+	t.Run("should return not found for unknown operation", func(t *testing.T) {
+		// when
+		req, err := http.NewRequest(http.MethodGet, "/operations/unknown/steps", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestNewStepsResponse_Empty(t *testing.T) {
+	response := newStepsResponse("op-id", nil)
+
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"operationID":"op-id","attempts":0,"retries":0,"errors":0,"steps":[],"history":[]}`, string(data))
+}
+
+func TestHandler_Retry(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		operationType internal.OperationType
+		state         domain.LastOperationState
+		compensated   bool
+		query         string
+		expectedCode  int
+	}{
+		"failed provisioning": {
+			operationType: internal.OperationTypeProvision,
+			state:         domain.Failed,
+			expectedCode:  http.StatusAccepted,
+		},
+		"failed provisioning with skipped step": {
+			operationType: internal.OperationTypeProvision,
+			state:         domain.Failed,
+			query:         "?skip=Create_Runtime",
+			expectedCode:  http.StatusAccepted,
+		},
+		"unknown skipped step": {
+			operationType: internal.OperationTypeProvision,
+			state:         domain.Failed,
+			query:         "?skip=Unknown_Step",
+			expectedCode:  http.StatusBadRequest,
+		},
+		"operation in progress": {
+			operationType: internal.OperationTypeProvision,
+			state:         domain.InProgress,
+			expectedCode:  http.StatusConflict,
+		},
+		"compensated provisioning": {
+			operationType: internal.OperationTypeProvision,
+			state:         domain.Failed,
+			compensated:   true,
+			expectedCode:  http.StatusConflict,
+		},
+		"not supported operation type": {
+			operationType: internal.OperationTypeDeprovision,
+			state:         domain.Failed,
+			expectedCode:  http.StatusBadRequest,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			db := storage.NewMemoryStorage()
+			operation := fixture.FixProvisioningOperation("op-id", "instance-id")
+			operation.Type = tc.operationType
+			operation.State = tc.state
+			operation.Compensated = tc.compensated
+			operation.FinishedStages = []string{"start"}
+			operation.LastError = kebError.LastError{}.SetReason(kebError.ErrKEBInternal)
+			require.NoError(t, db.Operations().InsertOperation(operation))
+
+			queue := &fakeQueue{}
+			router := mux.NewRouter()
+			NewHandler(db.Operations(), map[internal.OperationType]RetryTarget{
+				internal.OperationTypeProvision: {Queue: queue, Manager: fakeStepChecker{"Create_Runtime"}},
+			}, nil, logrus.New()).AttachRoutes(router)
+
+			// when
+			req, err := http.NewRequest(http.MethodPost, "/operations/op-id/retry"+tc.query, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			// then
+			require.Equal(t, tc.expectedCode, rr.Code)
+			stored, err := db.Operations().GetOperationByID("op-id")
+			require.NoError(t, err)
+			if tc.expectedCode != http.StatusAccepted {
+				assert.Empty(t, queue.ids)
+				assert.Equal(t, tc.state, stored.State)
+				assert.Zero(t, stored.RetryCount)
+				return
+			}
+
+			assert.Equal(t, []string{"op-id"}, queue.ids)
+			assert.Equal(t, domain.InProgress, stored.State)
+			assert.Equal(t, 1, stored.RetryCount)
+			require.NotNil(t, stored.RetriedAt)
+			assert.False(t, stored.RetriedAt.IsZero())
+			assert.Equal(t, []string{"start"}, stored.FinishedStages)
+			assert.Empty(t, stored.LastError.Reason())
+			var response RetryResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, 1, response.RetryCount)
+			assert.Equal(t, stored.SkippedSteps, response.SkippedSteps)
+		})
+	}
+
+	t.Run("should not retry operation which is not the last one", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		failed := fixture.FixProvisioningOperation("op-id", "instance-id")
+		failed.State = domain.Failed
+		require.NoError(t, db.Operations().InsertOperation(failed))
+		deprovisioning := fixture.FixDeprovisioningOperationAsOperation("deprovisioning-id", "instance-id")
+		deprovisioning.CreatedAt = failed.CreatedAt.Add(time.Minute)
+		require.NoError(t, db.Operations().InsertOperation(deprovisioning))
+
+		queue := &fakeQueue{}
+		router := mux.NewRouter()
+		NewHandler(db.Operations(), map[internal.OperationType]RetryTarget{
+			internal.OperationTypeProvision: {Queue: queue, Manager: fakeStepChecker{}},
+		}, nil, logrus.New()).AttachRoutes(router)
+
+		// when
+		req, err := http.NewRequest(http.MethodPost, "/operations/op-id/retry", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Empty(t, queue.ids)
+	})
+}
+
+type fakeQueue struct {
+	ids []string
+}
+
+func (q *fakeQueue) Add(operationID string) {
+	q.ids = append(q.ids, operationID)
+}
+
+type fakeStepChecker []string
+
+func (c fakeStepChecker) HasStep(name string) bool {
+	for _, step := range c {
+		if step == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHandler_PauseOperation(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	operation := fixture.FixProvisioningOperation("op-id", "instance-id")
+	operation.State = domain.InProgress
+	require.NoError(t, db.Operations().InsertOperation(operation))
+	finished := fixture.FixProvisioningOperation("finished-op-id", "other-instance-id")
+	finished.State = domain.Succeeded
+	require.NoError(t, db.Operations().InsertOperation(finished))
+
+	router := mux.NewRouter()
+	NewHandler(db.Operations(), nil, nil, logrus.New()).AttachRoutes(router)
+
+	t.Run("should pause operation", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/operations/op-id/pause")
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response PauseResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.True(t, response.Paused)
+		pause, err := db.Operations().GetOperationPause("op-id")
+		require.NoError(t, err)
+		assert.True(t, pause.Paused)
+		stored, err := db.Operations().GetOperationByID("op-id")
+		require.NoError(t, err)
+		assert.Equal(t, operation.Version, stored.Version)
+	})
+
+	t.Run("should resume operation", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/operations/op-id/resume")
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		pause, err := db.Operations().GetOperationPause("op-id")
+		require.NoError(t, err)
+		assert.False(t, pause.Paused)
+		assert.False(t, pause.ResumedAt.IsZero())
+		stored, err := db.Operations().GetOperationByID("op-id")
+		require.NoError(t, err)
+		assert.Equal(t, operation.Version, stored.Version)
+	})
+
+	t.Run("should not pause finished operation", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/operations/finished-op-id/pause")
+
+		// then
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("should return not found for unknown operation", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/operations/unknown/pause")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestHandler_PauseProcessing(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	operation := fixture.FixProvisioningOperation("op-id", "instance-id")
+	operation.State = domain.InProgress
+	require.NoError(t, db.Operations().InsertOperation(operation))
+
+	router := mux.NewRouter()
+	NewHandler(db.Operations(), nil, nil, logrus.New()).AttachRoutes(router)
+
+	t.Run("should pause processing of provisioning operations", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/admin/processing/provision/pause?reason=incident")
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = serve(t, router, http.MethodPut, "/admin/processing/provision/pause")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		// then
+		rr = serve(t, router, http.MethodGet, "/admin/processing")
+		require.Equal(t, http.StatusOK, rr.Code)
+		var pauses []ProcessingPause
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &pauses))
+		require.Len(t, pauses, 1)
+		assert.Equal(t, "provision", pauses[0].OperationType)
+		assert.Equal(t, "incident", pauses[0].Reason)
+	})
+
+	t.Run("should resume processing of provisioning operations", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/admin/processing/provision/resume")
+
+		// then
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		pauses, err := db.Operations().ListProcessingPauses()
+		require.NoError(t, err)
+		assert.Empty(t, pauses)
+		pause, err := db.Operations().GetOperationPause("op-id")
+		require.NoError(t, err)
+		assert.False(t, pause.Paused)
+		assert.False(t, pause.ResumedAt.IsZero())
+	})
+
+	t.Run("should not pause processing of not supported operation type", func(t *testing.T) {
+		// when
+		rr := serve(t, router, http.MethodPut, "/admin/processing/upgradeKyma/pause")
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestHandler_GetStepPolicies(t *testing.T) {
+	// given
+	policies := &process.StepPolicies{
+		Default: internal.StepPolicy{RetryInterval: 10 * time.Second, Timeout: time.Hour},
+		Steps: map[string]process.StepPolicyConfig{
+			"Check_Runtime": {
+				StepPolicy: internal.StepPolicy{Backoff: internal.ExponentialBackoff, MaxRetryInterval: 5 * time.Minute},
+				Plans: map[string]internal.StepPolicy{
+					"trial": {Timeout: 20 * time.Minute},
+				},
+			},
+		},
+	}
+	router := mux.NewRouter()
+	NewHandler(storage.NewMemoryStorage().Operations(), nil, policies, logrus.New()).AttachRoutes(router)
+
+	// when
+	rr := serve(t, router, http.MethodGet, "/admin/step-policies")
+
+	// then
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response StepPoliciesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, StepPolicy{RetryInterval: "10s", Timeout: "1h0m0s"}, response.Default)
+	assert.Equal(t, StepPolicyWithPlans{
+		StepPolicy: StepPolicy{RetryInterval: "10s", Backoff: "exponential", MaxRetryInterval: "5m0s", Timeout: "1h0m0s"},
+		Plans: map[string]StepPolicy{
+			"trial": {RetryInterval: "10s", Backoff: "exponential", MaxRetryInterval: "5m0s", Timeout: "20m0s"},
+		},
+	}, response.Steps["Check_Runtime"])
+}
+
+func serve(t *testing.T, router *mux.Router, method, url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
@@ -0,0 +1,50 @@
+// Package plans holds identifiers of the service plans, it does not depend on other packages,
+// so the plans can be used by packages which cannot import the broker package.
+package plans
+
+const (
+	GCPPlanID          = "ca6e5357-707f-4565-bbbd-b3ab732597c6"
+	GCPPlanName        = "gcp"
+	AWSPlanID          = "361c511f-f939-4621-b228-d0fb79a1fe15"
+	AWSPlanName        = "aws"
+	AzurePlanID        = "4deee563-e5ec-4731-b9b1-53b42d855f0c"
+	AzurePlanName      = "azure"
+	AzureLitePlanID    = "8cb22518-aa26-44c5-91a0-e669ec9bf443"
+	AzureLitePlanName  = "azure_lite"
+	TrialPlanID        = "7d55d31d-35ae-4438-bf13-6ffdfa107d9f"
+	TrialPlanName      = "trial"
+	OpenStackPlanID    = "03b812ac-c991-4528-b5bd-08b303523a63"
+	OpenStackPlanName  = "openstack"
+	FreemiumPlanID     = "b1a5764e-2ea1-4f95-94c0-2b4538b37b55"
+	FreemiumPlanName   = "free"
+	OwnClusterPlanID   = "03e3cb66-a4c6-4c6a-b4b0-5d42224debea"
+	OwnClusterPlanName = "own_cluster"
+	PreviewPlanID      = "5cb3d976-b85c-42ea-a636-79cadda109a9"
+	PreviewPlanName    = "preview"
+)
+
+// NamesMapping maps plan IDs to plan names
+var NamesMapping = map[string]string{
+	GCPPlanID:        GCPPlanName,
+	AWSPlanID:        AWSPlanName,
+	AzurePlanID:      AzurePlanName,
+	AzureLitePlanID:  AzureLitePlanName,
+	TrialPlanID:      TrialPlanName,
+	OpenStackPlanID:  OpenStackPlanName,
+	FreemiumPlanID:   FreemiumPlanName,
+	OwnClusterPlanID: OwnClusterPlanName,
+	PreviewPlanID:    PreviewPlanName,
+}
+
+// IDsMapping maps plan names to plan IDs
+var IDsMapping = map[string]string{
+	AzurePlanName:      AzurePlanID,
+	AWSPlanName:        AWSPlanID,
+	AzureLitePlanName:  AzureLitePlanID,
+	GCPPlanName:        GCPPlanID,
+	TrialPlanName:      TrialPlanID,
+	OpenStackPlanName:  OpenStackPlanID,
+	FreemiumPlanName:   FreemiumPlanID,
+	OwnClusterPlanName: OwnClusterPlanID,
+	PreviewPlanName:    PreviewPlanID,
+}
@@ -0,0 +1,136 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+type AccountLimitsConfig struct {
+	// MaxConcurrentOperations is the number of operations of a global account processed at the same time, 0 means no limit
+	MaxConcurrentOperations int `envconfig:"default=5"`
+	// OverridesFilePath is the path of the YAML file with limits of specific global accounts
+	OverridesFilePath string `envconfig:"optional"`
+	// DeferInterval is the time after which a deferred operation is processed again
+	DeferInterval time.Duration `envconfig:"default=10s"`
+}
+
+// AccountLimitOverrides maps global account IDs to limits of concurrently processed operations
+type AccountLimitOverrides struct {
+	GlobalAccounts map[string]int `yaml:"globalAccounts"`
+}
+
+// AccountLimiter limits the number of operations of a global account processed at the same time by the replica.
+// The limiter is shared by managers of all operation types.
+type AccountLimiter struct {
+	mu        sync.Mutex
+	cfg       AccountLimitsConfig
+	overrides map[string]int
+	running   map[string]int
+}
+
+func NewAccountLimiter(cfg AccountLimitsConfig) (*AccountLimiter, error) {
+	limiter := &AccountLimiter{
+		cfg:       cfg,
+		overrides: map[string]int{},
+		running:   map[string]int{},
+	}
+	if cfg.OverridesFilePath == "" {
+		return limiter, nil
+	}
+	overrides, err := ReadAccountLimitOverridesFromFile(cfg.OverridesFilePath)
+	if err != nil {
+		return nil, err
+	}
+	for globalAccountID, limit := range overrides.GlobalAccounts {
+		if limit < 0 {
+			return nil, fmt.Errorf("invalid limit of global account %s: negative values are not allowed", globalAccountID)
+		}
+		limiter.overrides[globalAccountID] = limit
+	}
+	return limiter, nil
+}
+
+func ReadAccountLimitOverridesFromFile(filename string) (AccountLimitOverrides, error) {
+	overrides := AccountLimitOverrides{}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return overrides, fmt.Errorf("while reading %s file with account limits config: %w", filename, err)
+	}
+	err = yaml.Unmarshal(data, &overrides)
+	if err != nil {
+		return overrides, fmt.Errorf("while unmarshalling a file with account limits config: %w", err)
+	}
+	return overrides, nil
+}
+
+// Limit returns the number of operations of the global account which can be processed at the same time, 0 means no limit
+func (l *AccountLimiter) Limit(globalAccountID string) int {
+	if limit, found := l.overrides[globalAccountID]; found {
+		return limit
+	}
+	return l.cfg.MaxConcurrentOperations
+}
+
+// acquire returns false if the global account reached the limit, otherwise the operation must be released when processed
+func (l *AccountLimiter) acquire(globalAccountID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit := l.Limit(globalAccountID)
+	if limit > 0 && l.running[globalAccountID] >= limit {
+		return false
+	}
+	l.running[globalAccountID]++
+	return true
+}
+
+func (l *AccountLimiter) release(globalAccountID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running[globalAccountID]--
+	if l.running[globalAccountID] <= 0 {
+		delete(l.running, globalAccountID)
+	}
+}
+
+const deferredOperationDescription = "Operation deferred, the global account reached the limit of operations processed at the same time"
+
+// UseAccountLimiter makes the manager defer operations of global accounts which reached the limit of operations processed at the same time
+func (m *StagedManager) UseAccountLimiter(limiter *AccountLimiter) {
+	m.accountLimiter = limiter
+}
+
+// acquireAccountSlot returns false if the operation is deferred, otherwise the returned function must be called when the operation is processed
+func (m *StagedManager) acquireAccountSlot(operation *internal.Operation, log logrus.FieldLogger) (func(), bool) {
+	if m.accountLimiter == nil {
+		return func() {}, true
+	}
+	globalAccountID := operation.ProvisioningParameters.ErsContext.GlobalAccountID
+	if !m.accountLimiter.acquire(globalAccountID) {
+		log.Infof("Global account reached the limit of %d operations processed at the same time, deferring for %s", m.accountLimiter.Limit(globalAccountID), m.accountLimiter.cfg.DeferInterval)
+		m.setDescription(operation, deferredOperationDescription, log)
+		return nil, false
+	}
+	if operation.Description == deferredOperationDescription {
+		m.setDescription(operation, "Operation in progress", log)
+	}
+	return func() { m.accountLimiter.release(globalAccountID) }, true
+}
+
+func (m *StagedManager) setDescription(operation *internal.Operation, description string, log logrus.FieldLogger) {
+	if operation.Description == description {
+		return
+	}
+	operation.Description = description
+	updated, err := m.operationStorage.UpdateOperation(*operation)
+	if err != nil {
+		log.Warnf("unable to save description of the operation: %s", err)
+		return
+	}
+	*operation = *updated
+}
@@ -0,0 +1,64 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountLimiter(t *testing.T) {
+	t.Run("should read limits of global accounts from the file", func(t *testing.T) {
+		// when
+		limiter, err := NewAccountLimiter(AccountLimitsConfig{MaxConcurrentOperations: 5, OverridesFilePath: "testdata/account_limits.yaml"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 50, limiter.Limit("large-customer"))
+		assert.Equal(t, 0, limiter.Limit("unlimited"))
+		assert.Equal(t, 5, limiter.Limit("other"))
+	})
+
+	t.Run("should return error when the file does not exist", func(t *testing.T) {
+		// when
+		_, err := NewAccountLimiter(AccountLimitsConfig{OverridesFilePath: "testdata/not-existing.yaml"})
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should limit operations of the global account processed at the same time", func(t *testing.T) {
+		// given
+		limiter, err := NewAccountLimiter(AccountLimitsConfig{MaxConcurrentOperations: 2})
+		require.NoError(t, err)
+
+		// when
+		first := limiter.acquire("ga")
+		second := limiter.acquire("ga")
+		third := limiter.acquire("ga")
+		other := limiter.acquire("other-ga")
+
+		// then
+		assert.True(t, first)
+		assert.True(t, second)
+		assert.False(t, third)
+		assert.True(t, other)
+
+		// when
+		limiter.release("ga")
+
+		// then
+		assert.True(t, limiter.acquire("ga"))
+	})
+
+	t.Run("should not limit global accounts without limit", func(t *testing.T) {
+		// given
+		limiter, err := NewAccountLimiter(AccountLimitsConfig{})
+		require.NoError(t, err)
+
+		// then
+		for i := 0; i < 100; i++ {
+			assert.True(t, limiter.acquire("ga"))
+		}
+	})
+}
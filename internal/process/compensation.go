@@ -0,0 +1,140 @@
+package process
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/plans"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Compensable is implemented by steps which leave side effects, like resources in external systems, which must be undone
+// when the operation fails. The compensation must succeed if there is nothing to undo.
+type Compensable interface {
+	Compensate(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, error)
+}
+
+func (m *StagedManager) isCompensated(operation internal.Operation) bool {
+	if m.instanceStorage == nil {
+		return false
+	}
+	planName := plans.NamesMapping[operation.ProvisioningParameters.PlanID]
+	for _, plan := range m.cfg.CompensatedPlans {
+		if plan == planName {
+			return true
+		}
+	}
+	return false
+}
+
+// registerCompensation stores the name of the compensable step in the operation before the step is run,
+// so the compensation is known even if the processing is stopped in the middle of the step
+func (m *StagedManager) registerCompensation(step Step, operation internal.Operation, log logrus.FieldLogger) internal.Operation {
+	if withCondition, ok := step.(StepWithCondition); ok {
+		step = withCondition.Step
+	}
+	if _, compensable := step.(Compensable); !compensable || !m.isCompensated(operation) {
+		return operation
+	}
+	for _, name := range operation.Compensations {
+		if name == step.Name() {
+			return operation
+		}
+	}
+
+	operation.Compensations = append(operation.Compensations, step.Name())
+	saved, err := m.operationStorage.UpdateOperation(operation)
+	if err != nil {
+		// the compensation is saved with the next update of the operation
+		log.Warnf("unable to save compensation of the step: %s", err)
+		return operation
+	}
+	return *saved
+}
+
+// compensate runs compensations of steps of the failed operation in the reverse order. Failed compensations are added
+// to steps executed but not completed and the instance is marked for the deprovisioning retrigger job.
+func (m *StagedManager) compensate(operationID string, log logrus.FieldLogger) {
+	operation, err := m.operationStorage.GetOperationByID(operationID)
+	if err != nil {
+		log.Errorf("unable to get operation for the compensation: %s", err)
+		return
+	}
+	if operation.State != domain.Failed || operation.Compensated || len(operation.Compensations) == 0 || !m.isCompensated(*operation) {
+		return
+	}
+
+	processedOperation := *operation
+	var failed []string
+	for i := len(operation.Compensations) - 1; i >= 0; i-- {
+		name := operation.Compensations[i]
+		logStep := log.WithField("compensation", name)
+		step, found := m.compensableStep(name)
+		if !found {
+			logStep.Errorf("compensable step not found")
+			failed = append(failed, name)
+			continue
+		}
+		logStep.Infof("Start compensation")
+		op, err := step.Compensate(processedOperation, logStep)
+		if err != nil {
+			logStep.Errorf("compensation failed: %s", err)
+			processedOperation.EventWithFields(events.Fields{events.StepField: name}).Errorf(err, "compensation of step %s failed", name)
+			failed = append(failed, name)
+			continue
+		}
+		processedOperation = op
+		processedOperation.EventWithFields(events.Fields{events.StepField: name}).Infof("step %s compensated", name)
+	}
+
+	om := NewOperationManager(m.operationStorage)
+	_, _, err = om.UpdateOperation(processedOperation, func(op *internal.Operation) {
+		op.Compensated = true
+		op.ExcutedButNotCompleted = append(op.ExcutedButNotCompleted, failed...)
+	}, log)
+	if err != nil {
+		log.Errorf("unable to save compensated operation: %s", err)
+	}
+	if len(failed) > 0 {
+		m.markInstanceForDeprovisioning(operation.InstanceID, log)
+	}
+}
+
+// compensableStep returns the compensable step with the given name defined in any stage or parallel group
+func (m *StagedManager) compensableStep(name string) (Compensable, bool) {
+	for _, s := range m.stages {
+		for _, step := range s.steps {
+			steps := []Step{step.Step}
+			if group, isGroup := step.Step.(*ParallelGroup); isGroup {
+				steps = group.Steps()
+			}
+			for _, st := range steps {
+				if compensable, ok := st.(Compensable); ok && st.Name() == name {
+					return compensable, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// markInstanceForDeprovisioning sets the time of the failed compensation in the instance, such instances are deprovisioned
+// by the deprovisioning retrigger job. The deletion time is not set, so the instance is not treated as deleted.
+func (m *StagedManager) markInstanceForDeprovisioning(instanceID string, log logrus.FieldLogger) {
+	instance, err := m.instanceStorage.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return
+	case err != nil:
+		log.Errorf("unable to get instance %s: %s", instanceID, err)
+		return
+	}
+	instance.CompensationFailedAt = ptr.Time(time.Now())
+	if _, err := m.instanceStorage.Update(*instance); err != nil {
+		log.Errorf("unable to mark instance %s for the deprovisioning: %s", instanceID, err)
+	}
+}
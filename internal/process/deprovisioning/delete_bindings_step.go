@@ -0,0 +1,75 @@
+package deprovisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/sirupsen/logrus"
+)
+
+// DeleteBindingsStep revokes credentials of all service bindings of the instance and removes the bindings from the storage
+type DeleteBindingsStep struct {
+	operationManager *process.OperationManager
+	instances        storage.Instances
+	bindings         storage.Bindings
+	bindingsManager  broker.BindingsManager
+}
+
+var _ process.Step = &DeleteBindingsStep{}
+
+func NewDeleteBindingsStep(os storage.Operations, instances storage.Instances, bindings storage.Bindings, bindingsManager broker.BindingsManager) *DeleteBindingsStep {
+	return &DeleteBindingsStep{
+		operationManager: process.NewOperationManager(os),
+		instances:        instances,
+		bindings:         bindings,
+		bindingsManager:  bindingsManager,
+	}
+}
+
+func (s *DeleteBindingsStep) Name() string {
+	return "Delete_Bindings"
+}
+
+func (s *DeleteBindingsStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	bindings, err := s.bindings.ListByInstanceID(operation.InstanceID)
+	if err != nil {
+		return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), "unable to list bindings", 10*time.Second, time.Minute, log)
+	}
+	if len(bindings) == 0 {
+		return operation, 0, nil
+	}
+
+	instance, err := s.instances.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		log.Info("instance does not exist, only bindings are removed from the storage")
+	case err != nil:
+		return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), "unable to get instance", 10*time.Second, time.Minute, log)
+	}
+
+	for _, binding := range bindings {
+		if err := s.deleteBinding(instance, binding, operation.RuntimeID); err != nil {
+			log.Errorf("unable to delete binding %s: %s", binding.ID, err)
+			return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), fmt.Sprintf("unable to delete binding %s", binding.ID), 10*time.Second, 5*time.Minute, log)
+		}
+		log.Infof("binding %s deleted", binding.ID)
+	}
+
+	return operation, 0, nil
+}
+
+func (s *DeleteBindingsStep) deleteBinding(instance *internal.Instance, binding internal.Binding, runtimeID string) error {
+	// credentials are revoked only if the runtime still exists
+	if instance != nil && runtimeID != "" {
+		if err := s.bindingsManager.Delete(context.Background(), instance, binding.ID); err != nil {
+			return fmt.Errorf("while revoking credentials: %w", err)
+		}
+	}
+	return s.bindings.Delete(binding.InstanceID, binding.ID)
+}
@@ -0,0 +1,88 @@
+package deprovisioning
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteBindingsStep(t *testing.T) {
+	t.Run("should revoke credentials and delete all bindings of the instance", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorageWithBindings(t)
+		manager := automock.NewBindingsManager(t)
+		manager.On("Delete", mock.Anything, mock.AnythingOfType("*internal.Instance"), "binding-1").Return(nil).Once()
+		manager.On("Delete", mock.Anything, mock.AnythingOfType("*internal.Instance"), "binding-2").Return(nil).Once()
+		step := NewDeleteBindingsStep(memoryStorage.Operations(), memoryStorage.Instances(), memoryStorage.Bindings(), manager)
+
+		// when
+		_, backoff, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, backoff)
+		bindings, err := memoryStorage.Bindings().ListByInstanceID(instanceID)
+		require.NoError(t, err)
+		assert.Empty(t, bindings)
+	})
+
+	t.Run("should retry when credentials cannot be revoked", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorageWithBindings(t)
+		manager := automock.NewBindingsManager(t)
+		manager.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("runtime not reachable")).Once()
+		step := NewDeleteBindingsStep(memoryStorage.Operations(), memoryStorage.Instances(), memoryStorage.Bindings(), manager)
+
+		// when
+		_, backoff, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.NotZero(t, backoff)
+		bindings, err := memoryStorage.Bindings().ListByInstanceID(instanceID)
+		require.NoError(t, err)
+		assert.Len(t, bindings, 2)
+	})
+
+	t.Run("should only delete bindings from the storage when the runtime does not exist", func(t *testing.T) {
+		// given
+		memoryStorage, operation := fixStorageWithBindings(t)
+		operation.RuntimeID = ""
+		step := NewDeleteBindingsStep(memoryStorage.Operations(), memoryStorage.Instances(), memoryStorage.Bindings(), automock.NewBindingsManager(t))
+
+		// when
+		_, backoff, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, backoff)
+		bindings, err := memoryStorage.Bindings().ListByInstanceID(instanceID)
+		require.NoError(t, err)
+		assert.Empty(t, bindings)
+	})
+}
+
+func fixStorageWithBindings(t *testing.T) (storage.BrokerStorage, internal.Operation) {
+	memoryStorage := storage.NewMemoryStorage()
+	operation := fixture.FixDeprovisioningOperationAsOperation(operationID, instanceID)
+	require.NoError(t, memoryStorage.Operations().InsertOperation(operation))
+	require.NoError(t, memoryStorage.Instances().Insert(fixture.FixInstance(instanceID)))
+	for _, id := range []string{"binding-1", "binding-2"} {
+		require.NoError(t, memoryStorage.Bindings().Insert(internal.Binding{
+			ID:         id,
+			InstanceID: instanceID,
+			CreatedAt:  time.Now(),
+			ExpiresAt:  time.Now().Add(time.Hour),
+		}))
+	}
+	return memoryStorage, operation
+}
@@ -386,11 +386,13 @@ func (f *InputBuilderFactory) CreateUpgradeShootInput(provisioningParameters int
 
 	input := f.initUpgradeShootInput(provider)
 	return &RuntimeInput{
-		upgradeShootInput:        input,
-		config:                   cfg,
-		hyperscalerInputProvider: provider,
-		trialNodesNumber:         f.config.TrialNodesNumber,
-		oidcDefaultValues:        f.oidcDefaultValues,
+		upgradeShootInput:         input,
+		config:                    cfg,
+		hyperscalerInputProvider:  provider,
+		optionalComponentsService: f.optComponentsSvc,
+		enabledOptionalComponents: map[string]struct{}{},
+		trialNodesNumber:          f.config.TrialNodesNumber,
+		oidcDefaultValues:         f.oidcDefaultValues,
 	}, nil
 }
 
@@ -0,0 +1,157 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type LeaseConfig struct {
+	Enabled bool `envconfig:"default=true"`
+	// Duration is the time after which the lease of a stopped replica expires and another replica takes over the processing
+	Duration time.Duration `envconfig:"default=1m"`
+	// OrphansCheckInterval defines how often the replica looks for expired leases
+	OrphansCheckInterval time.Duration `envconfig:"default=1m"`
+}
+
+// leases makes sure an operation or an orchestration is processed by only one replica of the broker.
+// The lease is acquired before the processing and renewed while the executor runs. If the executor asks for a retry,
+// the lease is kept until the next processing, otherwise it is released. The lease of a stopped replica expires
+// and the operation is taken over by a replica which finds the expired lease.
+type leases struct {
+	storage storage.Leases
+	kind    string
+	owner   string
+	cfg     LeaseConfig
+	log     logrus.FieldLogger
+}
+
+func newLeases(storage storage.Leases, kind string, cfg LeaseConfig, log logrus.FieldLogger) *leases {
+	return &leases{
+		storage: storage,
+		kind:    kind,
+		owner:   newLeaseOwner(),
+		cfg:     cfg,
+		log:     log.WithFields(logrus.Fields{"leaseKind": kind}),
+	}
+}
+
+// newLeaseOwner returns the identity of the replica, the host name is the pod name
+func newLeaseOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "keb"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+}
+
+func (l *leases) acquire(id string, duration time.Duration) (bool, error) {
+	return l.storage.AcquireLease(internal.Lease{
+		ID:         id,
+		Kind:       l.kind,
+		Owner:      l.owner,
+		LeaseUntil: time.Now().Add(duration),
+	})
+}
+
+// execute runs the executor only if the lease is acquired
+func (l *leases) execute(executor Executor) func(id string) (time.Duration, error) {
+	return func(id string) (time.Duration, error) {
+		log := l.log.WithField("leaseID", id)
+		acquired, err := l.acquire(id, l.cfg.Duration)
+		if err != nil {
+			log.Errorf("while acquiring lease: %v", err)
+			return 3 * time.Second, nil
+		}
+		if !acquired {
+			log.Infof("Lease is held by another replica, skipping")
+			return 0, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stop := l.keepAlive(id, cancel, log)
+		when, err := executeWithContext(ctx, executor, id)
+		stop()
+
+		if ctx.Err() != nil {
+			// the lease was taken over, the operation is processed by another replica
+			return 0, nil
+		}
+		if err == nil && when > 0 {
+			// keep the lease until the next processing
+			if _, lErr := l.acquire(id, when+l.cfg.Duration); lErr != nil {
+				log.Warnf("unable to extend lease: %v", lErr)
+			}
+			return when, nil
+		}
+		if rErr := l.storage.ReleaseLease(id, l.owner); rErr != nil {
+			log.Warnf("unable to release lease: %v", rErr)
+		}
+		return when, err
+	}
+}
+
+func executeWithContext(ctx context.Context, executor Executor, id string) (time.Duration, error) {
+	if contextExecutor, ok := executor.(ContextExecutor); ok {
+		return contextExecutor.ExecuteWithContext(ctx, id)
+	}
+	return executor.Execute(id)
+}
+
+// keepAlive renews the lease until the returned function is called.
+// If the lease is taken over by another replica, the renewal stops and cancel is called to stop the executor.
+func (l *leases) keepAlive(id string, cancel context.CancelFunc, log logrus.FieldLogger) func() {
+	stopCh := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(l.cfg.Duration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+			acquired, err := l.acquire(id, l.cfg.Duration)
+			switch {
+			case err != nil:
+				log.Warnf("unable to renew lease: %v", err)
+			case !acquired:
+				log.Errorf("lease was taken over by another replica, stopping the processing")
+				cancel()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stopCh)
+		wg.Wait()
+	}
+}
+
+// adoptOrphans adds to the queue operations or orchestrations which leases expired
+func (l *leases) adoptOrphans(q *Queue, stop <-chan struct{}) {
+	wait.Until(func() {
+		expired, err := l.storage.ListExpiredLeases(l.kind, time.Now())
+		if err != nil {
+			l.log.Errorf("while listing expired leases: %v", err)
+			return
+		}
+		for _, lease := range expired {
+			l.log.Infof("Taking over %s with expired lease of %s", lease.ID, lease.Owner)
+			q.Add(lease.ID)
+		}
+	}, l.cfg.OrphansCheckInterval, stop)
+}
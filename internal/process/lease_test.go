@@ -0,0 +1,149 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestLeases_Execute(t *testing.T) {
+	cfg := LeaseConfig{Duration: time.Minute, OrphansCheckInterval: time.Minute}
+
+	t.Run("should process operation only by the replica holding the lease", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage().Operations()
+		first := newLeases(db, "provision", cfg, logrus.New())
+		second := newLeases(db, "provision", cfg, logrus.New())
+		executor := &countingExecutor{when: time.Second}
+
+		// when
+		when, err := first.execute(executor)("op-id")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, when)
+
+		// when
+		when, err = second.execute(executor)("op-id")
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.Equal(t, 1, executor.count("op-id"))
+
+		// when
+		executor.when = 0
+		_, err = first.execute(executor)("op-id")
+		require.NoError(t, err)
+		_, err = second.execute(executor)("op-id")
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, 3, executor.count("op-id"))
+	})
+
+	t.Run("should stop the executor when the lease is taken over", func(t *testing.T) {
+		// given
+		db := &takenOverLeases{Leases: storage.NewMemoryStorage().Operations()}
+		leases := newLeases(db, "provision", LeaseConfig{Duration: 30 * time.Millisecond}, logrus.New())
+		executor := &blockingExecutor{}
+
+		// when
+		when, err := leases.execute(executor)("op-id")
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.True(t, executor.canceled)
+	})
+
+	t.Run("should take over expired lease", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage().Operations()
+		_, err := db.AcquireLease(internal.Lease{ID: "op-id", Kind: "provision", Owner: "stopped-replica", LeaseUntil: time.Now().Add(-time.Second)})
+		require.NoError(t, err)
+		_, err = db.AcquireLease(internal.Lease{ID: "other-op-id", Kind: "provision", Owner: "running-replica", LeaseUntil: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+
+		executor := &countingExecutor{}
+		queue := NewQueue(executor, logrus.New())
+		queue.UseLeases(db, "provision", LeaseConfig{Duration: time.Minute, OrphansCheckInterval: 10 * time.Millisecond})
+		stop := make(chan struct{})
+		defer close(stop)
+
+		// when
+		queue.Run(stop, 1)
+
+		// then
+		err = wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+			return executor.count("op-id") == 1, nil
+		})
+		require.NoError(t, err)
+		assert.Zero(t, executor.count("other-op-id"))
+		expired, err := db.ListExpiredLeases("provision", time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+	})
+}
+
+type countingExecutor struct {
+	mu     sync.Mutex
+	when   time.Duration
+	counts map[string]int
+}
+
+func (e *countingExecutor) Execute(id string) (time.Duration, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.counts == nil {
+		e.counts = map[string]int{}
+	}
+	e.counts[id]++
+	return e.when, nil
+}
+
+func (e *countingExecutor) count(id string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.counts[id]
+}
+
+// takenOverLeases grants only the first lease, every renewal fails as if another replica took the lease over
+type takenOverLeases struct {
+	storage.Leases
+	acquired bool
+}
+
+func (l *takenOverLeases) AcquireLease(lease internal.Lease) (bool, error) {
+	if l.acquired {
+		return false, nil
+	}
+	l.acquired = true
+	return l.Leases.AcquireLease(lease)
+}
+
+type blockingExecutor struct {
+	canceled bool
+}
+
+func (e *blockingExecutor) Execute(string) (time.Duration, error) {
+	return 0, fmt.Errorf("the executor must be called with the context")
+}
+
+func (e *blockingExecutor) ExecuteWithContext(ctx context.Context, _ string) (time.Duration, error) {
+	select {
+	case <-ctx.Done():
+		e.canceled = true
+	case <-time.After(5 * time.Second):
+	}
+	return time.Second, nil
+}
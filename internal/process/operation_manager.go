@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/common/events"
 	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
@@ -50,11 +51,17 @@ func (om *OperationManager) OperationCanceled(operation internal.Operation, desc
 	return om.update(operation, orchestration.Canceled, description, log)
 }
 
-// RetryOperation checks if operation should be retried or if it's the status should be marked as failed
+// RetryOperation checks if operation should be retried or if it's the status should be marked as failed,
+// the retry interval and the time limit of the step policy of the operation replace the given ones
 func (om *OperationManager) RetryOperation(operation internal.Operation, errorMessage string, err error, retryInterval time.Duration, maxTime time.Duration, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	maxTime, elapsed := operation.StepTimeLimit(maxTime)
+	return om.retryOperation(operation, errorMessage, err, operation.StepPolicy.RetryIntervalOr(retryInterval), maxTime, elapsed, log)
+}
+
+func (om *OperationManager) retryOperation(operation internal.Operation, errorMessage string, err error, retryInterval, maxTime, elapsed time.Duration, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	log.Infof("Retry Operation was triggered with message: %s", errorMessage)
 	log.Infof("Retrying for %s in %s steps", maxTime.String(), retryInterval.String())
-	if time.Since(operation.UpdatedAt) < maxTime {
+	if elapsed < maxTime {
 		return operation, retryInterval, nil
 	}
 	log.Errorf("Aborting after %s of failing retries", maxTime.String())
@@ -67,11 +74,14 @@ func (om *OperationManager) RetryOperation(operation internal.Operation, errorMe
 	return op, retry, err
 }
 
-// RetryOperationWithoutFail checks if operation should be retried or updates the status to InProgress, but omits setting the operation to failed if maxTime is reached
+// RetryOperationWithoutFail checks if operation should be retried or updates the status to InProgress, but omits setting the operation to failed if maxTime is reached,
+// the retry interval and the time limit of the step policy of the operation replace the given ones
 func (om *OperationManager) RetryOperationWithoutFail(operation internal.Operation, stepName string, description string, retryInterval, maxTime time.Duration, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	retryInterval = operation.StepPolicy.RetryIntervalOr(retryInterval)
+	maxTime, elapsed := operation.StepTimeLimit(maxTime)
 	log.Infof("Retry Operation was triggered with message: %s", description)
 	log.Infof("Retrying for %s in %s steps", maxTime.String(), retryInterval.String())
-	if time.Since(operation.UpdatedAt) < maxTime {
+	if elapsed < maxTime {
 		return operation, retryInterval, nil
 	}
 	// update description to track failed steps
@@ -84,14 +94,14 @@ func (om *OperationManager) RetryOperationWithoutFail(operation internal.Operati
 		return op, repeat, err
 	}
 
-	op.EventErrorf(fmt.Errorf(description), "step %s failed retries: operation continues", stepName)
+	op.EventWithFields(events.Fields{events.StepField: stepName}).Warnf("step %s failed retries: operation continues: %s", stepName, description)
 	log.Errorf("Omitting after %s of failing retries", maxTime.String())
 	return op, 0, nil
 }
 
-// RetryOperationOnce retries the operation once and fails the operation when call second time
+// RetryOperationOnce retries the operation once and fails the operation when call second time, regardless of the step policy
 func (om *OperationManager) RetryOperationOnce(operation internal.Operation, errorMessage string, err error, wait time.Duration, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
-	return om.RetryOperation(operation, errorMessage, err, wait, wait+1, log)
+	return om.retryOperation(operation, errorMessage, err, wait, wait+1, time.Since(operation.UpdatedAt), log)
 }
 
 // UpdateOperation updates a given operation and handles conflict situation
@@ -129,7 +139,7 @@ func (om *OperationManager) MarkStepAsExcutedButNotCompleted(operation internal.
 		return op, repeat, err
 	}
 
-	op.EventErrorf(fmt.Errorf(msg), "step %s failed: operation continues", stepName)
+	op.EventWithFields(events.Fields{events.StepField: stepName}).Warnf("step %s failed: operation continues: %s", stepName, msg)
 	log.Errorf(msg)
 	return op, 0, nil
 }
@@ -77,3 +77,48 @@ func Test_OperationManager_RetryOperation(t *testing.T) {
 	assert.True(t, when > 0)
 	assert.Nil(t, err)
 }
+
+func Test_OperationManager_RetryOperationWithStepPolicy(t *testing.T) {
+	// given
+	memory := storage.NewMemoryStorage()
+	operations := memory.Operations()
+	opManager := NewOperationManager(operations)
+	op := internal.Operation{}
+	op.UpdatedAt = time.Now().Add(-2 * time.Hour)
+	op.StepPolicy = internal.StepPolicy{RetryInterval: time.Minute, Timeout: 3 * time.Hour}
+	op.StepStartedAt = time.Now().Add(-2 * time.Hour)
+	err := operations.InsertOperation(op)
+	require.NoError(t, err)
+
+	t.Run("should retry until the timeout of the policy", func(t *testing.T) {
+		// when
+		_, when, err := opManager.RetryOperation(op, "ups ... ", fmt.Errorf("error occurred"), time.Second, time.Hour, fixLogger())
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, when)
+	})
+
+	t.Run("should retry without fail until the timeout of the policy", func(t *testing.T) {
+		// when
+		op, when, err := opManager.RetryOperationWithoutFail(op, "step", "ups ... ", time.Second, time.Hour, fixLogger())
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, when)
+		assert.Empty(t, op.ExcutedButNotCompleted)
+	})
+
+	t.Run("should fail after the timeout of the policy", func(t *testing.T) {
+		// given
+		timedOut := op
+		timedOut.StepStartedAt = time.Now().Add(-4 * time.Hour)
+
+		// when
+		_, when, err := opManager.RetryOperation(timedOut, "ups ... ", fmt.Errorf("error occurred"), time.Second, 5*time.Hour, fixLogger())
+
+		// then
+		assert.Error(t, err)
+		assert.Zero(t, when)
+	})
+}
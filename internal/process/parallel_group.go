@@ -0,0 +1,188 @@
+package process
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// ParallelGroup is a step which runs independent steps concurrently. The group is finished when all its steps are finished.
+// A step of the group which is finished is not run again when the group is retried. Changes of the operation made by
+// steps are compared field by field with the operation before the group, so fields reset to zero values are merged too.
+// Fields which are not stored are merged only if they are set, because a step can return the operation read again
+// from the storage. Steps of the group must not change the same fields.
+type ParallelGroup struct {
+	name  string
+	steps []Step
+}
+
+type stepRunner func(step Step, operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error)
+
+type stepResult struct {
+	operation internal.Operation
+	when      time.Duration
+	err       error
+}
+
+// ensure the interface is implemented
+var _ Step = (*ParallelGroup)(nil)
+
+func NewParallelGroup(name string, steps ...Step) *ParallelGroup {
+	return &ParallelGroup{
+		name:  name,
+		steps: steps,
+	}
+}
+
+func (g *ParallelGroup) Name() string {
+	return g.name
+}
+
+func (g *ParallelGroup) Steps() []Step {
+	return g.steps
+}
+
+// Run runs steps of the group concurrently, the staged manager runs them with retries of every step
+func (g *ParallelGroup) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	return g.run(operation, logger, func(step Step, operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+		return step.Run(operation, logger)
+	})
+}
+
+// run waits for all steps and returns the merged operation. If any step needs a retry, the longest backoff is returned.
+// Errors are returned in the order of steps in the group.
+func (g *ParallelGroup) run(operation internal.Operation, logger logrus.FieldLogger, runStep stepRunner) (internal.Operation, time.Duration, error) {
+	results := make([]*stepResult, len(g.steps))
+	wg := sync.WaitGroup{}
+	for i, step := range g.steps {
+		logStep := logger.WithField("parallelStep", step.Name())
+		if operation.IsParallelStepFinished(step.Name()) {
+			logStep.Debugf("Skipping, the step is already finished")
+			continue
+		}
+		if operation.IsStepSkipped(step.Name()) {
+			logStep.Infof("Skipping, the step is marked as skipped")
+			operation.EventWithFields(events.Fields{events.StepField: step.Name()}).Infof("step %v skipped", step.Name())
+			continue
+		}
+		operation.EventWithFields(events.Fields{events.StepField: step.Name()}).Infof("processing step: %v", step.Name())
+
+		wg.Add(1)
+		go func(i int, step Step) {
+			defer wg.Done()
+			op, when, err := runStep(step, operation, logStep)
+			results[i] = &stepResult{operation: op, when: when, err: err}
+		}(i, step)
+	}
+	wg.Wait()
+
+	merged := operation
+	var when time.Duration
+	var err error
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		stepName := g.steps[i].Name()
+		for _, field := range mergeOperation(&merged, operation, result.operation) {
+			logger.Warnf("field %s was changed by more than one step, the change of step %s is kept", field, stepName)
+		}
+		if result.operation.Version > merged.Version {
+			merged.Version = result.operation.Version
+		}
+		switch {
+		case result.err != nil:
+			if err == nil {
+				err = result.err
+			}
+		case result.when > 0:
+			if result.when > when {
+				when = result.when
+			}
+		default:
+			merged.FinishedParallelSteps = append(merged.FinishedParallelSteps, stepName)
+		}
+	}
+	if err != nil || isFinalState(merged.State) {
+		return merged, 0, err
+	}
+	return merged, when, nil
+}
+
+// notStoredFields are fields of the operation which are lost when a step reads the operation from the storage
+var notStoredFields = map[string]bool{
+	"InputCreator":             true,
+	"K8sClient":                true,
+	"ResumedAt":                true,
+	"LastRuntimeState":         true,
+	"RequiresReconcilerUpdate": true,
+}
+
+// mergeOperation copies to the target fields of the result which differ from the base. Structs are merged field by field.
+// It returns names of fields which were already changed in the target to a different value.
+func mergeOperation(target *internal.Operation, base, result internal.Operation) []string {
+	var conflicts []string
+	mergeFields(reflect.ValueOf(target).Elem(), reflect.ValueOf(base), reflect.ValueOf(result), "", &conflicts)
+	return conflicts
+}
+
+func mergeFields(target, base, result reflect.Value, prefix string, conflicts *[]string) {
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		// the version is set from results of steps, the step policy and its start time are set for every step by the staged manager
+		if !field.IsExported() || (prefix == "" && (field.Name == "Version" || field.Name == "StepPolicy" || field.Name == "StepStartedAt")) {
+			continue
+		}
+		if isMergeableStruct(field.Type) {
+			mergeFields(target.Field(i), base.Field(i), result.Field(i), prefix+field.Name+".", conflicts)
+			continue
+		}
+		value := result.Field(i)
+		if reflect.DeepEqual(base.Field(i).Interface(), value.Interface()) {
+			continue
+		}
+		if prefix == "" && notStoredFields[field.Name] && value.IsZero() {
+			continue
+		}
+		if isAppended(target.Field(i), value) {
+			target.Field(i).Set(value)
+			continue
+		}
+		if isAppended(base.Field(i), value) && isAppended(base.Field(i), target.Field(i)) {
+			// elements appended by both steps are kept
+			appended := reflect.AppendSlice(reflect.MakeSlice(field.Type, 0, target.Field(i).Len()), target.Field(i))
+			target.Field(i).Set(reflect.AppendSlice(appended, value.Slice(base.Field(i).Len(), value.Len())))
+			continue
+		}
+		current := target.Field(i).Interface()
+		if !reflect.DeepEqual(base.Field(i).Interface(), current) && !reflect.DeepEqual(current, value.Interface()) {
+			*conflicts = append(*conflicts, prefix+field.Name)
+		}
+		target.Field(i).Set(value)
+	}
+}
+
+// isAppended returns true if the value is a slice which starts with elements of the base
+func isAppended(base, value reflect.Value) bool {
+	if value.Kind() != reflect.Slice || value.Len() < base.Len() {
+		return false
+	}
+	return reflect.DeepEqual(base.Interface(), value.Slice(0, base.Len()).Interface()) || base.Len() == 0
+}
+
+// isMergeableStruct returns true for structs which all fields are exported, other structs (like time.Time) are merged as a whole
+func isMergeableStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
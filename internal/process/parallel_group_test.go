@@ -0,0 +1,105 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeOperation(t *testing.T) {
+	t.Run("should merge changed fields of nested structs", func(t *testing.T) {
+		// given
+		base := internal.Operation{Version: 1, Description: "base"}
+		first := base
+		first.Avs.AvsEvaluationInternalId = 1
+		second := base
+		second.Avs.AVSEvaluationExternalId = 2
+		second.Version = 3
+		merged := base
+
+		// when
+		conflicts := append(mergeOperation(&merged, base, first), mergeOperation(&merged, base, second)...)
+
+		// then
+		assert.Empty(t, conflicts)
+		assert.Equal(t, int64(1), merged.Avs.AvsEvaluationInternalId)
+		assert.Equal(t, int64(2), merged.Avs.AVSEvaluationExternalId)
+		assert.Equal(t, "base", merged.Description)
+		assert.Equal(t, 1, merged.Version)
+	})
+
+	t.Run("should merge fields reset to zero values", func(t *testing.T) {
+		// given
+		base := internal.Operation{Description: "base"}
+		base.RuntimeVersion = internal.RuntimeVersionData{Version: "2.0"}
+		base.Avs.AvsEvaluationInternalId = 1
+		result := base
+		result.Avs.AvsEvaluationInternalId = 0
+		merged := base
+
+		// when
+		conflicts := mergeOperation(&merged, base, result)
+
+		// then
+		assert.Empty(t, conflicts)
+		assert.Equal(t, "base", merged.Description)
+		assert.Equal(t, "2.0", merged.RuntimeVersion.Version)
+		assert.Zero(t, merged.Avs.AvsEvaluationInternalId)
+	})
+
+	t.Run("should not merge not stored fields reset to zero values", func(t *testing.T) {
+		// given
+		base := internal.Operation{RequiresReconcilerUpdate: true, StepPolicy: internal.StepPolicy{MaxAttempts: 1}}
+		// the step returned the operation read from the storage
+		result := internal.Operation{StepPolicy: internal.StepPolicy{MaxAttempts: 2}}
+		merged := base
+
+		// when
+		conflicts := mergeOperation(&merged, base, result)
+
+		// then
+		assert.Empty(t, conflicts)
+		assert.True(t, merged.RequiresReconcilerUpdate)
+		assert.Equal(t, 1, merged.StepPolicy.MaxAttempts)
+	})
+
+	t.Run("should keep elements appended by steps", func(t *testing.T) {
+		// given
+		base := internal.Operation{ExcutedButNotCompleted: []string{"a"}}
+		first := base
+		first.ExcutedButNotCompleted = []string{"a", "b"}
+		second := base
+		second.ExcutedButNotCompleted = []string{"a", "c"}
+		// the step got the operation saved by the first step from the storage
+		third := base
+		third.ExcutedButNotCompleted = []string{"a", "b", "c", "d"}
+		merged := base
+
+		// when
+		conflicts := append(mergeOperation(&merged, base, first), mergeOperation(&merged, base, second)...)
+		conflicts = append(conflicts, mergeOperation(&merged, base, third)...)
+
+		// then
+		assert.Empty(t, conflicts)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, merged.ExcutedButNotCompleted)
+	})
+
+	t.Run("should report fields changed by more than one step", func(t *testing.T) {
+		// given
+		base := internal.Operation{Description: "base"}
+		first := base
+		first.Description = "first"
+		second := base
+		second.Description = "second"
+		merged := base
+
+		// when
+		mergeOperation(&merged, base, first)
+		conflicts := mergeOperation(&merged, base, second)
+
+		// then
+		assert.Equal(t, []string{"Description"}, conflicts)
+		assert.Equal(t, "second", merged.Description)
+	})
+}
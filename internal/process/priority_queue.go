@@ -0,0 +1,343 @@
+package process
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/plans"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+// Priority defines the order in which the queue processes operations, higher priorities are taken first
+type Priority int
+
+const (
+	HighPriority Priority = iota
+	NormalPriority
+	LowPriority
+)
+
+var priorities = []Priority{HighPriority, NormalPriority, LowPriority}
+
+func (p Priority) String() string {
+	switch p {
+	case HighPriority:
+		return "high"
+	case LowPriority:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// ClassifyFunc returns the priority and the global account of the operation or orchestration with the given ID
+type ClassifyFunc func(id string) (Priority, string)
+
+type PriorityConfig struct {
+	Enabled bool `envconfig:"default=true"`
+	// LowPriorityPlans are names of plans which operations are processed after operations of other plans
+	LowPriorityPlans []string `envconfig:"default=trial,free"`
+	// StarvationLimit is the number of times a waiting operation of a lower priority can be passed over by operations of higher priorities
+	StarvationLimit int `envconfig:"default=10"`
+}
+
+// OperationClassifier returns priorities and global accounts of operations. Provisioning and update operations are processed
+// first, unless their plan has the low priority. Suspensions and operations of plans with the low priority are processed last.
+// All operations have the normal priority if priorities are disabled.
+func OperationClassifier(operations storage.Operations, cfg PriorityConfig) ClassifyFunc {
+	lowPriorityPlans := map[string]struct{}{}
+	for _, plan := range cfg.LowPriorityPlans {
+		lowPriorityPlans[plan] = struct{}{}
+	}
+	return func(id string) (Priority, string) {
+		operation, err := operations.GetOperationByID(id)
+		if err != nil {
+			return NormalPriority, ""
+		}
+		globalAccountID := operation.ProvisioningParameters.ErsContext.GlobalAccountID
+		if !cfg.Enabled {
+			return NormalPriority, globalAccountID
+		}
+		if _, found := lowPriorityPlans[plans.NamesMapping[operation.ProvisioningParameters.PlanID]]; found || operation.Temporary {
+			return LowPriority, globalAccountID
+		}
+		switch operation.Type {
+		case internal.OperationTypeProvision, internal.OperationTypeUpdate:
+			return HighPriority, globalAccountID
+		default:
+			return NormalPriority, globalAccountID
+		}
+	}
+}
+
+// QueueStats describes items of the given priority waiting in the queue
+type QueueStats struct {
+	Priority Priority
+	// Depth is the number of items ready to be processed
+	Depth int
+	// OldestWait is the time the first item ready to be processed waits
+	OldestWait time.Duration
+	// Dequeued is the number of items taken from the queue, WaitTotal is the total time they waited
+	Dequeued  uint64
+	WaitTotal time.Duration
+}
+
+type queuedItem struct {
+	id       string
+	priority Priority
+	account  string
+	readyAt  time.Time
+}
+
+type delayedItem struct {
+	readyAt time.Time
+	timer   *time.Timer
+}
+
+// lane holds a FIFO of items for every global account, the accounts are served in the round-robin order
+type lane struct {
+	accounts  []string
+	items     map[string][]queuedItem
+	size      int
+	skipped   int
+	dequeued  uint64
+	waitTotal time.Duration
+}
+
+func (ln *lane) push(item queuedItem) {
+	if len(ln.items[item.account]) == 0 {
+		ln.accounts = append(ln.accounts, item.account)
+	}
+	ln.items[item.account] = append(ln.items[item.account], item)
+	ln.size++
+}
+
+// pop takes the first item of the next account, the account is moved to the end of the round if it has more items
+func (ln *lane) pop() queuedItem {
+	account := ln.accounts[0]
+	ln.accounts = ln.accounts[1:]
+	item := ln.items[account][0]
+	if len(ln.items[account]) > 1 {
+		ln.items[account] = ln.items[account][1:]
+		ln.accounts = append(ln.accounts, account)
+	} else {
+		delete(ln.items, account)
+	}
+	ln.size--
+	return item
+}
+
+func (ln *lane) oldest() time.Time {
+	var oldest time.Time
+	for _, items := range ln.items {
+		if oldest.IsZero() || items[0].readyAt.Before(oldest) {
+			oldest = items[0].readyAt
+		}
+	}
+	return oldest
+}
+
+// priorityLanes is a work queue with a lane for every priority. Like the work queue of client-go, it makes sure
+// an item is processed by one worker at a time and an item added while it is processed is processed again.
+// Workers take items from the highest priority, but a lane with waiting items is served after it was passed over
+// the number of times defined by the starvation limit. Within a lane, global accounts are served in turns,
+// so a global account with many operations does not delay operations of other global accounts.
+type priorityLanes struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	lanes           map[Priority]*lane
+	classify        ClassifyFunc
+	starvationLimit int
+
+	// dirty items wait to be processed, items added while processed are kept in dirty until they are done
+	dirty        map[string]queuedItem
+	processing   map[string]struct{}
+	delayed      map[string]*delayedItem
+	shuttingDown bool
+	// classified keeps the priority and the account of items, so retried items are not classified again
+	classified map[string]queuedItem
+}
+
+func newPriorityLanes() *priorityLanes {
+	l := &priorityLanes{
+		lanes:      map[Priority]*lane{},
+		dirty:      map[string]queuedItem{},
+		processing: map[string]struct{}{},
+		delayed:    map[string]*delayedItem{},
+		classified: map[string]queuedItem{},
+		classify:   func(string) (Priority, string) { return NormalPriority, "" },
+	}
+	for _, p := range priorities {
+		l.lanes[p] = &lane{items: map[string][]queuedItem{}}
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+func (l *priorityLanes) add(id string) {
+	l.mu.Lock()
+	item, classified := l.classified[id]
+	l.mu.Unlock()
+	if !classified {
+		// the item is classified without locking, because it may read the storage
+		priority, account := l.classify(id)
+		item = queuedItem{id: id, priority: priority, account: account}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.shuttingDown {
+		return
+	}
+	l.classified[id] = item
+	if _, found := l.dirty[id]; found {
+		return
+	}
+	l.dirty[id] = item
+	if _, found := l.processing[id]; found {
+		return
+	}
+	l.push(item)
+}
+
+// addAfter adds the item after the given time, if the item is already waiting, the earlier time is kept
+func (l *priorityLanes) addAfter(id string, duration time.Duration) {
+	if duration <= 0 {
+		l.add(id)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.shuttingDown {
+		return
+	}
+	readyAt := time.Now().Add(duration)
+	if waiting, found := l.delayed[id]; found {
+		if !readyAt.Before(waiting.readyAt) {
+			return
+		}
+		waiting.timer.Stop()
+	}
+	item := &delayedItem{readyAt: readyAt}
+	item.timer = time.AfterFunc(duration, func() {
+		l.mu.Lock()
+		if l.delayed[id] == item {
+			delete(l.delayed, id)
+		}
+		l.mu.Unlock()
+		l.add(id)
+	})
+	l.delayed[id] = item
+}
+
+func (l *priorityLanes) push(item queuedItem) {
+	item.readyAt = time.Now()
+	l.lanes[item.priority].push(item)
+	l.cond.Signal()
+}
+
+// get blocks until an item is ready to be processed, it returns true if the queue is shut down and no items are left
+func (l *priorityLanes) get() (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for !l.shuttingDown && l.depth() == 0 {
+		l.cond.Wait()
+	}
+	if l.depth() == 0 {
+		return "", true
+	}
+
+	next := l.next()
+	for _, p := range priorities {
+		if p != next && l.lanes[p].size > 0 {
+			l.lanes[p].skipped++
+		}
+	}
+	selected := l.lanes[next]
+	item := selected.pop()
+	selected.skipped = 0
+	selected.dequeued++
+	selected.waitTotal += time.Since(item.readyAt)
+
+	delete(l.dirty, item.id)
+	l.processing[item.id] = struct{}{}
+	return item.id, false
+}
+
+// next returns the priority of the lane to serve, the starved lane of the highest priority is served first
+func (l *priorityLanes) next() Priority {
+	if l.starvationLimit > 0 {
+		for _, p := range priorities {
+			if l.lanes[p].size > 0 && l.lanes[p].skipped >= l.starvationLimit {
+				return p
+			}
+		}
+	}
+	for _, p := range priorities {
+		if l.lanes[p].size > 0 {
+			return p
+		}
+	}
+	return NormalPriority
+}
+
+func (l *priorityLanes) done(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.processing, id)
+	if item, found := l.dirty[id]; found {
+		l.push(item)
+	}
+}
+
+// forget removes the classification of the item which is not processed anymore, unless the item was added again
+func (l *priorityLanes) forget(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, dirty := l.dirty[id]
+	_, delayed := l.delayed[id]
+	if !dirty && !delayed {
+		delete(l.classified, id)
+	}
+}
+
+func (l *priorityLanes) depth() int {
+	depth := 0
+	for _, ln := range l.lanes {
+		depth += ln.size
+	}
+	return depth
+}
+
+func (l *priorityLanes) shutDown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.shuttingDown = true
+	for _, item := range l.delayed {
+		item.timer.Stop()
+	}
+	l.cond.Broadcast()
+}
+
+func (l *priorityLanes) stats() []QueueStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := make([]QueueStats, 0, len(priorities))
+	for _, p := range priorities {
+		ln := l.lanes[p]
+		s := QueueStats{
+			Priority:  p,
+			Depth:     ln.size,
+			Dequeued:  ln.dequeued,
+			WaitTotal: ln.waitTotal,
+		}
+		if ln.size > 0 {
+			s.OldestWait = time.Since(ln.oldest())
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
@@ -0,0 +1,225 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/plans"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityLanes(t *testing.T) {
+	classify := func(id string) (Priority, string) {
+		switch id[0] {
+		case 'h':
+			return HighPriority, ""
+		case 'l':
+			return LowPriority, ""
+		default:
+			return NormalPriority, ""
+		}
+	}
+
+	t.Run("should take items of higher priorities first", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+		lanes.classify = classify
+		for _, id := range []string{"low-1", "normal-1", "high-1", "low-2", "high-2"} {
+			lanes.add(id)
+		}
+
+		// when
+		var taken []string
+		for i := 0; i < 5; i++ {
+			id, shutdown := lanes.get()
+			require.False(t, shutdown)
+			taken = append(taken, id)
+		}
+
+		// then
+		assert.Equal(t, []string{"high-1", "high-2", "normal-1", "low-1", "low-2"}, taken)
+	})
+
+	t.Run("should take a waiting item of a lower priority when the starvation limit is reached", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+		lanes.classify = classify
+		lanes.starvationLimit = 2
+		for _, id := range []string{"low-1", "high-1", "high-2", "high-3"} {
+			lanes.add(id)
+		}
+
+		// when
+		var taken []string
+		for i := 0; i < 4; i++ {
+			id, _ := lanes.get()
+			taken = append(taken, id)
+		}
+
+		// then
+		assert.Equal(t, []string{"high-1", "high-2", "low-1", "high-3"}, taken)
+	})
+
+	t.Run("should serve global accounts in turns", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+		lanes.classify = func(id string) (Priority, string) {
+			return NormalPriority, id[:2]
+		}
+		for _, id := range []string{"a-1", "a-2", "a-3", "b-1", "c-1", "b-2"} {
+			lanes.add(id)
+		}
+
+		// when
+		var taken []string
+		for i := 0; i < 6; i++ {
+			id, _ := lanes.get()
+			taken = append(taken, id)
+		}
+
+		// then
+		assert.Equal(t, []string{"a-1", "b-1", "c-1", "a-2", "b-2", "a-3"}, taken)
+	})
+
+	t.Run("should process an item added while it is processed again when it is done", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+		lanes.add("op-1")
+		id, _ := lanes.get()
+
+		// when
+		lanes.add("op-1")
+		lanes.add("op-1")
+
+		// then
+		assert.Zero(t, lanes.stats()[NormalPriority].Depth)
+
+		// when
+		lanes.done(id)
+
+		// then
+		assert.Equal(t, 1, lanes.stats()[NormalPriority].Depth)
+	})
+
+	t.Run("should classify an item once until it is forgotten", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+		calls := 0
+		lanes.classify = func(id string) (Priority, string) {
+			calls++
+			return classify(id)
+		}
+
+		// when
+		for i := 0; i < 3; i++ {
+			lanes.add("high-1")
+			id, _ := lanes.get()
+			lanes.done(id)
+		}
+
+		// then
+		assert.Equal(t, 1, calls)
+
+		// when
+		lanes.forget("high-1")
+		lanes.add("high-1")
+
+		// then
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should add item after the given time", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+
+		// when
+		lanes.addAfter("op-1", 10*time.Millisecond)
+		lanes.addAfter("op-1", time.Hour)
+
+		// then
+		assert.Zero(t, lanes.stats()[NormalPriority].Depth)
+		id, _ := lanes.get()
+		assert.Equal(t, "op-1", id)
+	})
+
+	t.Run("should return stats of every priority", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+		lanes.classify = classify
+		lanes.add("high-1")
+		lanes.add("low-1")
+		lanes.add("low-2")
+		_, _ = lanes.get()
+
+		// when
+		stats := lanes.stats()
+
+		// then
+		require.Len(t, stats, 3)
+		assert.Equal(t, HighPriority, stats[0].Priority)
+		assert.Zero(t, stats[0].Depth)
+		assert.Equal(t, uint64(1), stats[0].Dequeued)
+		assert.Equal(t, LowPriority, stats[2].Priority)
+		assert.Equal(t, 2, stats[2].Depth)
+		assert.Greater(t, stats[2].OldestWait, time.Duration(0))
+	})
+
+	t.Run("should return shutdown when no items are left", func(t *testing.T) {
+		// given
+		lanes := newPriorityLanes()
+		lanes.add("op-1")
+
+		// when
+		lanes.shutDown()
+
+		// then
+		id, shutdown := lanes.get()
+		assert.False(t, shutdown)
+		assert.Equal(t, "op-1", id)
+		_, shutdown = lanes.get()
+		assert.True(t, shutdown)
+	})
+}
+
+func TestOperationClassifier(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		operationType internal.OperationType
+		planID        string
+		temporary     bool
+		disabled      bool
+		expected      Priority
+	}{
+		"provisioning":           {operationType: internal.OperationTypeProvision, planID: plans.AzurePlanID, expected: HighPriority},
+		"update":                 {operationType: internal.OperationTypeUpdate, planID: plans.AWSPlanID, expected: HighPriority},
+		"deprovisioning":         {operationType: internal.OperationTypeDeprovision, planID: plans.AzurePlanID, expected: NormalPriority},
+		"suspension":             {operationType: internal.OperationTypeDeprovision, planID: plans.AzurePlanID, temporary: true, expected: LowPriority},
+		"trial provisioning":     {operationType: internal.OperationTypeProvision, planID: plans.TrialPlanID, expected: LowPriority},
+		"priorities disabled":    {operationType: internal.OperationTypeProvision, planID: plans.AzurePlanID, disabled: true, expected: NormalPriority},
+		"not existing operation": {expected: NormalPriority},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			db := storage.NewMemoryStorage().Operations()
+			if tc.operationType != "" {
+				operation := fixture.FixOperation("op-id", "instance-id", tc.operationType)
+				operation.ProvisioningParameters.PlanID = tc.planID
+				operation.Temporary = tc.temporary
+				require.NoError(t, db.InsertOperation(operation))
+			}
+			classify := OperationClassifier(db, PriorityConfig{Enabled: !tc.disabled, LowPriorityPlans: []string{plans.TrialPlanName}})
+
+			// when
+			priority, globalAccountID := classify("op-id")
+
+			// then
+			assert.Equal(t, tc.expected, priority)
+			if tc.operationType != "" {
+				assert.Equal(t, fixture.GlobalAccountId, globalAccountID)
+			}
+		})
+	}
+}
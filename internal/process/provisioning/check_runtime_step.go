@@ -45,9 +45,10 @@ func (s *CheckRuntimeStep) Run(operation internal.Operation, log logrus.FieldLog
 }
 
 func (s *CheckRuntimeStep) checkRuntimeStatus(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
-	if time.Since(operation.UpdatedAt) > s.provisioningTimeout {
+	timeout, elapsed := operation.StepTimeLimit(s.provisioningTimeout)
+	if elapsed > timeout {
 		log.Infof("operation has reached the time limit: updated operation time: %s", operation.UpdatedAt)
-		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", s.provisioningTimeout), nil, log)
+		return s.operationManager.OperationFailed(operation, fmt.Sprintf("operation has reached the time limit: %s", timeout), nil, log)
 	}
 
 	if operation.ProvisionerOperationID == "" {
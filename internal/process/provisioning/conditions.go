@@ -16,3 +16,7 @@ func SkipForOwnClusterPlan(operation internal.Operation) bool {
 func DoForOwnClusterPlanOnly(operation internal.Operation) bool {
 	return !SkipForOwnClusterPlan(operation)
 }
+
+func WhenAdditionalWorkerNodePoolsProvided(operation internal.Operation) bool {
+	return len(operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools) > 0 && SkipForOwnClusterPlan(operation)
+}
@@ -22,6 +22,9 @@ const (
 	globalKeyPrefix = "global_"
 )
 
+// ensure the interface is implemented
+var _ process.Compensable = (*CreateRuntimeWithoutKymaStep)(nil)
+
 type CreateRuntimeWithoutKymaStep struct {
 	operationManager    *process.OperationManager
 	instanceStorage     storage.Instances
@@ -156,3 +159,30 @@ func (s *CreateRuntimeWithoutKymaStep) createProvisionInput(operation internal.O
 
 	return request, nil
 }
+
+// Compensate deprovisions the runtime created for the failed operation. The runtime ID is removed from the instance,
+// so the deprovisioning of the instance does not deprovision the runtime again.
+func (s *CreateRuntimeWithoutKymaStep) Compensate(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, error) {
+	if operation.RuntimeID == "" {
+		return operation, nil
+	}
+	log.Infof("call DeprovisionRuntime: RuntimeID=%s", operation.RuntimeID)
+	provisionerOperationID, err := s.provisionerClient.DeprovisionRuntime(operation.ProvisioningParameters.ErsContext.GlobalAccountID, operation.RuntimeID)
+	if err != nil {
+		return operation, fmt.Errorf("while deprovisioning runtime %s: %w", operation.RuntimeID, err)
+	}
+	log.Infof("Deprovisioning runtime in the Provisioner started, provisioner operation=%s", provisionerOperationID)
+
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return operation, nil
+	case err != nil:
+		return operation, fmt.Errorf("while getting instance: %w", err)
+	}
+	instance.RuntimeID = ""
+	if _, err := s.instanceStorage.Update(*instance); err != nil {
+		return operation, fmt.Errorf("while updating instance: %w", err)
+	}
+	return operation, nil
+}
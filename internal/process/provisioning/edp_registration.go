@@ -25,6 +25,9 @@ type EDPClient interface {
 	DeleteMetadataTenant(name, env, key string) error
 }
 
+// ensure the interface is implemented
+var _ process.Compensable = (*EDPRegistrationStep)(nil)
+
 type EDPRegistrationStep struct {
 	operationManager *process.OperationManager
 	client           EDPClient
@@ -170,3 +173,29 @@ func (s *EDPRegistrationStep) handleConflict(operation internal.Operation, log l
 	log.Infof("Retrying...")
 	return operation, time.Second, nil
 }
+
+// Compensate deletes the data tenant and its metadata registered for the failed operation
+func (s *EDPRegistrationStep) Compensate(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, error) {
+	if !operation.EDPCreated {
+		return operation, nil
+	}
+	subAccountID := strings.ToLower(operation.ProvisioningParameters.ErsContext.SubAccountID)
+	for _, key := range []string{
+		edp.MaasConsumerEnvironmentKey,
+		edp.MaasConsumerRegionKey,
+		edp.MaasConsumerSubAccountKey,
+		edp.MaasConsumerServicePlan,
+	} {
+		log.Infof("Deleting DataTenant metadata %s (%s): %s", subAccountID, s.config.Environment, key)
+		if err := s.client.DeleteMetadataTenant(subAccountID, s.config.Environment, key); err != nil {
+			return operation, fmt.Errorf("cannot remove DataTenant metadata with key %s: %w", key, err)
+		}
+	}
+	log.Infof("Deleting DataTenant %s (%s)", subAccountID, s.config.Environment)
+	if err := s.client.DeleteDataTenant(subAccountID, s.config.Environment); err != nil {
+		return operation, fmt.Errorf("cannot remove DataTenant: %w", err)
+	}
+
+	operation.EDPCreated = false
+	return operation, nil
+}
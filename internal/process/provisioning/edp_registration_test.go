@@ -74,6 +74,40 @@ func TestEDPRegistration_Run(t *testing.T) {
 
 }
 
+func TestEDPRegistration_Compensate(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	client := edp.NewFakeClient()
+
+	step := NewEDPRegistrationStep(memoryStorage.Operations(), client, edp.Config{
+		Environment: edpEnvironment,
+		Required:    true,
+	})
+	operation := internal.Operation{
+		ProvisioningParameters: internal.ProvisioningParameters{
+			PlanID:         broker.AzurePlanID,
+			PlatformRegion: edpRegion,
+			ErsContext: internal.ERSContext{
+				SubAccountID: edpName,
+			},
+		},
+	}
+	memoryStorage.Operations().InsertOperation(operation)
+	operation, _, err := step.Run(operation, logger.NewLogDummy())
+	assert.NoError(t, err)
+
+	// when
+	operation, err = step.Compensate(operation, logger.NewLogDummy())
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, operation.EDPCreated)
+	_, dataTenantExists := client.GetDataTenantItem(edpName, edpEnvironment)
+	assert.False(t, dataTenantExists)
+	_, metadataTenantExists := client.GetMetadataItem(edpName, edpEnvironment, edp.MaasConsumerRegionKey)
+	assert.False(t, metadataTenantExists)
+}
+
 func TestEDPRegistrationStep_selectEnvironmentKey(t *testing.T) {
 	for name, tc := range map[string]struct {
 		region   string
@@ -30,3 +30,10 @@ func (eec *ExternalEvalCreator) createEval(operation internal.Operation, url str
 		return eec.delegator.CreateEvaluation(logger, operation, eec.assistant, url)
 	}
 }
+
+func (eec *ExternalEvalCreator) deleteEval(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, error) {
+	if eec.disabled {
+		return operation, nil
+	}
+	return eec.delegator.DeleteAvsEvaluation(operation, logger, eec.assistant)
+}
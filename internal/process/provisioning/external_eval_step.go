@@ -18,6 +18,7 @@ type ExternalEvalStep struct {
 
 // ensure the interface is implemented
 var _ process.Step = (*ExternalEvalStep)(nil)
+var _ process.Compensable = (*ExternalEvalStep)(nil)
 
 func NewExternalEvalStep(externalEvalCreator *ExternalEvalCreator) *ExternalEvalStep {
 	return &ExternalEvalStep{
@@ -42,3 +43,8 @@ func (s *ExternalEvalStep) Run(operation internal.Operation, log logrus.FieldLog
 	}
 	return op, 0, nil
 }
+
+// Compensate deletes the external evaluation created for the failed operation
+func (s *ExternalEvalStep) Compensate(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, error) {
+	return s.externalEvalCreator.deleteEval(operation, log)
+}
@@ -4,11 +4,15 @@ import (
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/avs"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/sirupsen/logrus"
 )
 
+// ensure the interface is implemented
+var _ process.Compensable = (*InternalEvaluationStep)(nil)
+
 type InternalEvaluationStep struct {
 	delegator *avs.Delegator
 	iec       *avs.InternalEvalAssistant
@@ -28,3 +32,8 @@ func (ies *InternalEvaluationStep) Name() string {
 func (ies *InternalEvaluationStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	return ies.delegator.CreateEvaluation(logger, operation, ies.iec, "")
 }
+
+// Compensate deletes the internal evaluation created for the failed operation
+func (ies *InternalEvaluationStep) Compensate(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, error) {
+	return ies.delegator.DeleteAvsEvaluation(operation, logger, ies.iec)
+}
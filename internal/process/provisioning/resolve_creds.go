@@ -9,6 +9,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,13 +17,18 @@ type ResolveCredentialsStep struct {
 	operationManager *process.OperationManager
 	accountProvider  hyperscaler.AccountProvider
 	opStorage        storage.Operations
+	instanceStorage  storage.Instances
 	tenant           string
 }
 
-func NewResolveCredentialsStep(os storage.Operations, accountProvider hyperscaler.AccountProvider) *ResolveCredentialsStep {
+// ensure the interface is implemented
+var _ process.Compensable = (*ResolveCredentialsStep)(nil)
+
+func NewResolveCredentialsStep(os storage.Operations, is storage.Instances, accountProvider hyperscaler.AccountProvider) *ResolveCredentialsStep {
 	return &ResolveCredentialsStep{
 		operationManager: process.NewOperationManager(os),
 		opStorage:        os,
+		instanceStorage:  is,
 		accountProvider:  accountProvider,
 	}
 }
@@ -80,3 +86,33 @@ func (s *ResolveCredentialsStep) Run(operation internal.Operation, log logrus.Fi
 
 	return *updatedOperation, 0, nil
 }
+
+// Compensate releases the secret binding of the global account used by the failed operation. The secret binding is marked
+// as dirty only if no other cluster uses it. Shared secret bindings of the trial plan are not released.
+func (s *ResolveCredentialsStep) Compensate(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, error) {
+	if broker.IsTrialPlan(operation.ProvisioningParameters.PlanID) || operation.ProvisioningParameters.Parameters.TargetSecret == nil {
+		return operation, nil
+	}
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		return operation, nil
+	case err != nil:
+		return operation, fmt.Errorf("while getting instance: %w", err)
+	}
+	if instance.Provider == "" {
+		log.Info("Instance does not contain cloud provider info, skipping")
+		return operation, nil
+	}
+	hypType, err := hyperscaler.FromCloudProvider(instance.Provider)
+	if err != nil {
+		return operation, fmt.Errorf("while determining the type of hyperscaler: %w", err)
+	}
+
+	euAccess := internal.IsEuAccess(operation.ProvisioningParameters.PlatformRegion)
+	if err := s.accountProvider.MarkUnusedGardenerSecretBindingAsDirty(hypType, instance.GetSubscriptionGlobalAccoundID(), euAccess); err != nil {
+		return operation, fmt.Errorf("while releasing the secret binding: %w", err)
+	}
+	log.Infof("Released the secret binding of the global account %s", instance.GetSubscriptionGlobalAccoundID())
+	return operation, nil
+}
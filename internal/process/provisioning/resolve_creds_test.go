@@ -7,6 +7,7 @@ import (
 
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -42,7 +43,7 @@ func TestResolveCredentialsStepHappyPath_Run(t *testing.T) {
 	accountProviderMock := &hyperscalerMocks.AccountProvider{}
 	accountProviderMock.On("GardenerSecretName", hyperscaler.GCP, statusGlobalAccountID, false).Return("gardener-secret-gcp", nil)
 
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProviderMock)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProviderMock)
 
 	// when
 	operation, repeat, err := step.Run(operation, log)
@@ -70,7 +71,7 @@ func TestResolveCredentialsEUStepHappyPath_Run(t *testing.T) {
 	accountProviderMock := &hyperscalerMocks.AccountProvider{}
 	accountProviderMock.On("GardenerSecretName", hyperscaler.AWS, statusGlobalAccountID, true).Return("gardener-secret-aws", nil)
 
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProviderMock)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProviderMock)
 
 	// when
 	operation, repeat, err := step.Run(operation, log)
@@ -98,7 +99,7 @@ func TestResolveCredentialsCHStepHappyPath_Run(t *testing.T) {
 	accountProviderMock := &hyperscalerMocks.AccountProvider{}
 	accountProviderMock.On("GardenerSecretName", hyperscaler.Azure, statusGlobalAccountID, true).Return("gardener-secret-az", nil)
 
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProviderMock)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProviderMock)
 
 	// when
 	operation, repeat, err := step.Run(operation, log)
@@ -125,7 +126,7 @@ func TestResolveCredentialsStepHappyPathTrialDefaultProvider_Run(t *testing.T) {
 	accountProviderMock := &hyperscalerMocks.AccountProvider{}
 	accountProviderMock.On("GardenerSharedSecretName", hyperscaler.Azure, false).Return("gardener-secret-azure", nil)
 
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProviderMock)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProviderMock)
 
 	// when
 	operation, repeat, err := step.Run(operation, log)
@@ -153,7 +154,7 @@ func TestResolveCredentialsStepHappyPathTrialGivenProvider_Run(t *testing.T) {
 	accountProviderMock := &hyperscalerMocks.AccountProvider{}
 	accountProviderMock.On("GardenerSharedSecretName", hyperscaler.GCP, false).Return("gardener-secret-gcp", nil)
 
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProviderMock)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProviderMock)
 
 	// when
 	operation, repeat, err := step.Run(operation, log)
@@ -180,7 +181,7 @@ func TestResolveCredentialsStepRetry_Run(t *testing.T) {
 	accountProviderMock := &hyperscalerMocks.AccountProvider{}
 	accountProviderMock.On("GardenerSecretName", hyperscaler.GCP, statusGlobalAccountID, false).Return("", fmt.Errorf("Failed!"))
 
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProviderMock)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProviderMock)
 
 	operation.UpdatedAt = time.Now()
 
@@ -214,7 +215,7 @@ func TestResolveCredentials_IntegrationAWS(t *testing.T) {
 
 	op := fixOperationWithPlatformRegion("cf-us10", internal.AWS)
 	memoryStorage.Operations().InsertOperation(op)
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProvider)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProvider)
 
 	// when
 	operation, backoff, err := step.Run(op, log)
@@ -238,7 +239,7 @@ func TestResolveCredentials_IntegrationAWSEuAccess(t *testing.T) {
 
 	op := fixOperationWithPlatformRegion("cf-eu11", internal.AWS)
 	memoryStorage.Operations().InsertOperation(op)
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProvider)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProvider)
 
 	// when
 	operation, backoff, err := step.Run(op, log)
@@ -260,7 +261,7 @@ func TestResolveCredentials_IntegrationAzure(t *testing.T) {
 
 	op := fixOperationWithPlatformRegion("cf-eu21", internal.Azure)
 	memoryStorage.Operations().InsertOperation(op)
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProvider)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProvider)
 
 	// when
 	operation, backoff, err := step.Run(op, log)
@@ -284,7 +285,7 @@ func TestResolveCredentials_IntegrationAzureEuAccess(t *testing.T) {
 
 	op := fixOperationWithPlatformRegion("cf-ch20", internal.Azure)
 	memoryStorage.Operations().InsertOperation(op)
-	step := NewResolveCredentialsStep(memoryStorage.Operations(), accountProvider)
+	step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProvider)
 
 	// when
 	operation, backoff, err := step.Run(op, log)
@@ -331,3 +332,39 @@ func fixEuAccessSecretBinding(name, hyperscalerType string) *unstructured.Unstru
 	o.SetLabels(labels)
 	return o
 }
+
+func TestResolveCredentialsStep_Compensate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		planID   string
+		released bool
+	}{
+		"should release the secret binding of the global account": {planID: broker.GCPPlanID, released: true},
+		"should not release the shared secret binding":            {planID: broker.TrialPlanID, released: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			operation := fixOperationRuntimeStatus(tc.planID, internal.GCP)
+			operation.ProvisioningParameters.Parameters.TargetSecret = ptr.String("gardener-secret-gcp")
+			instance := fixture.FixInstance(operation.InstanceID)
+			instance.Provider = internal.GCP
+			require.NoError(t, memoryStorage.Instances().Insert(instance))
+
+			accountProviderMock := &hyperscalerMocks.AccountProvider{}
+			accountProviderMock.On("MarkUnusedGardenerSecretBindingAsDirty", hyperscaler.GCP, instance.GetSubscriptionGlobalAccoundID(), false).Return(nil)
+
+			step := NewResolveCredentialsStep(memoryStorage.Operations(), memoryStorage.Instances(), accountProviderMock)
+
+			// when
+			_, err := step.Compensate(operation, logrus.New())
+
+			// then
+			require.NoError(t, err)
+			if tc.released {
+				accountProviderMock.AssertNumberOfCalls(t, "MarkUnusedGardenerSecretBindingAsDirty", 1)
+			} else {
+				accountProviderMock.AssertNotCalled(t, "MarkUnusedGardenerSecretBindingAsDirty")
+			}
+		})
+	}
+}
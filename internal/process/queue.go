@@ -1,31 +1,39 @@
 package process
 
 import (
+	"context"
 	"runtime/debug"
 	"sync"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/util/workqueue"
 )
 
 type Executor interface {
 	Execute(operationID string) (time.Duration, error)
 }
 
+// ContextExecutor is an executor which stops the processing when the context is canceled.
+// The queue which uses leases cancels the context when the lease is taken over by another replica.
+type ContextExecutor interface {
+	ExecuteWithContext(ctx context.Context, operationID string) (time.Duration, error)
+}
+
 type Queue struct {
-	queue     workqueue.RateLimitingInterface
+	queue     *priorityLanes
 	executor  Executor
 	waitGroup sync.WaitGroup
 	log       logrus.FieldLogger
+	leases    *leases
 
 	speedFactor int64
 }
 
 func NewQueue(executor Executor, log logrus.FieldLogger) *Queue {
 	return &Queue{
-		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "operations"),
+		queue:     newPriorityLanes(),
 		executor:  executor,
 		waitGroup: sync.WaitGroup{},
 		log:       log,
@@ -34,22 +42,45 @@ func NewQueue(executor Executor, log logrus.FieldLogger) *Queue {
 	}
 }
 
+// UseLeases makes the queue process only operations or orchestrations leased by this replica
+// and take over the ones with expired leases. It must be called before Run.
+func (q *Queue) UseLeases(storage storage.Leases, kind string, cfg LeaseConfig) {
+	q.leases = newLeases(storage, kind, cfg, q.log)
+}
+
+// UseClassifier makes workers take operations of higher priorities first and serve global accounts in turns.
+// It must be called before Run.
+func (q *Queue) UseClassifier(classify ClassifyFunc, cfg PriorityConfig) {
+	q.queue.classify = classify
+	q.queue.starvationLimit = cfg.StarvationLimit
+}
+
+// Stats returns the number of waiting items and the time they wait for every priority
+func (q *Queue) Stats() []QueueStats {
+	return q.queue.stats()
+}
+
 func (q *Queue) Add(processId string) {
-	q.queue.Add(processId)
+	q.queue.add(processId)
 }
 
 func (q *Queue) AddAfter(processId string, duration time.Duration) {
-	q.queue.AddAfter(processId, duration)
+	q.queue.addAfter(processId, duration)
 }
 
 func (q *Queue) ShutDown() {
-	q.queue.ShutDown()
+	q.queue.shutDown()
 }
 
 func (q *Queue) Run(stop <-chan struct{}, workersAmount int) {
+	execute := q.executor.Execute
+	if q.leases != nil {
+		execute = q.leases.execute(q.executor)
+		go q.leases.adoptOrphans(q, stop)
+	}
 	for i := 0; i < workersAmount; i++ {
 		q.waitGroup.Add(1)
-		q.createWorker(q.queue, q.executor.Execute, stop, &q.waitGroup, q.log)
+		q.createWorker(q.queue, execute, stop, &q.waitGroup, q.log)
 	}
 }
 
@@ -59,43 +90,42 @@ func (q *Queue) SpeedUp(speedFactor int64) {
 	q.speedFactor = speedFactor
 }
 
-func (q *Queue) createWorker(queue workqueue.RateLimitingInterface, process func(id string) (time.Duration, error), stopCh <-chan struct{}, waitGroup *sync.WaitGroup, log logrus.FieldLogger) {
+func (q *Queue) createWorker(queue *priorityLanes, process func(id string) (time.Duration, error), stopCh <-chan struct{}, waitGroup *sync.WaitGroup, log logrus.FieldLogger) {
 	go func() {
 		wait.Until(q.worker(queue, process, log), time.Second, stopCh)
 		waitGroup.Done()
 	}()
 }
 
-func (q *Queue) worker(queue workqueue.RateLimitingInterface, process func(key string) (time.Duration, error), log logrus.FieldLogger) func() {
+func (q *Queue) worker(queue *priorityLanes, process func(key string) (time.Duration, error), log logrus.FieldLogger) func() {
 	return func() {
 		exit := false
 		for !exit {
 			exit = func() bool {
-				key, shutdown := queue.Get()
+				id, shutdown := queue.get()
 				if shutdown {
 					return true
 				}
-				id := key.(string)
 				log = log.WithField("operationID", id)
 				defer func() {
 					if err := recover(); err != nil {
 						log.Errorf("panic error from process: %v. Stacktrace: %s", err, debug.Stack())
 					}
-					queue.Done(key)
+					queue.done(id)
 				}()
 
 				when, err := process(id)
 				if err == nil && when != 0 {
 					log.Infof("Adding %q item after %s", id, when)
 					afterDuration := time.Duration(int64(when) / q.speedFactor)
-					queue.AddAfter(key, afterDuration)
+					queue.addAfter(id, afterDuration)
 					return false
 				}
 				if err != nil {
 					log.Errorf("Error from process: %v", err)
 				}
+				queue.forget(id)
 
-				queue.Forget(key)
 				return false
 			}()
 		}
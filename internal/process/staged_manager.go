@@ -9,15 +9,21 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/sirupsen/logrus"
 )
 
+// pausedOperationRecheckInterval is the time after which the paused operation is checked again
+const pausedOperationRecheckInterval = time.Minute
+
 type StagedManager struct {
 	log              logrus.FieldLogger
 	operationStorage storage.Operations
@@ -28,14 +34,19 @@ type StagedManager struct {
 
 	mu sync.RWMutex
 
-	speedFactor int64
-	cfg         StagedManagerConfiguration
+	speedFactor     int64
+	cfg             StagedManagerConfiguration
+	stepPolicies    *StepPolicies
+	instanceStorage storage.Instances
+	accountLimiter  *AccountLimiter
 }
 
 type StagedManagerConfiguration struct {
 	// Max time of processing step by a worker without returning to the queue
 	MaxStepProcessingTime time.Duration `envconfig:"default=2m"`
 	WorkersAmount         int           `envconfig:"default=20"`
+	// CompensatedPlans are names of plans which failed operations are compensated
+	CompensatedPlans []string `envconfig:"optional"`
 }
 
 type Step interface {
@@ -79,6 +90,17 @@ func (m *StagedManager) SpeedUp(speedFactor int64) {
 	m.speedFactor = speedFactor
 }
 
+// UseStepPolicies makes the manager apply retry and timeout policies to the steps
+func (m *StagedManager) UseStepPolicies(policies *StepPolicies) {
+	m.stepPolicies = policies
+}
+
+// UseCompensation makes the manager undo side effects of compensable steps of failed operations of plans defined in the configuration.
+// Instances with failed compensations are marked for the deprovisioning retrigger job.
+func (m *StagedManager) UseCompensation(instances storage.Instances) {
+	m.instanceStorage = instances
+}
+
 func (m *StagedManager) DefineStages(names []string) {
 	m.stages = make([]*stage, len(names))
 	for i, n := range names {
@@ -96,6 +118,51 @@ func (m *StagedManager) AddStep(stageName string, step Step, cnd StepCondition)
 	return fmt.Errorf("stage %s not defined", stageName)
 }
 
+// HasStep returns true if the step with the given name is defined in any stage or parallel group
+func (m *StagedManager) HasStep(name string) bool {
+	for _, s := range m.stages {
+		for _, step := range s.steps {
+			if step.Name() == name {
+				return true
+			}
+			if group, isGroup := step.Step.(*ParallelGroup); isGroup {
+				for _, groupStep := range group.Steps() {
+					if groupStep.Name() == name {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isPaused checks if the operation or the processing of all operations of its type is paused.
+// Paused operations are not timed out. The resume time of the operation is set from its pause.
+func (m *StagedManager) isPaused(operation *internal.Operation, log logrus.FieldLogger) (bool, error) {
+	operationPause, err := m.operationStorage.GetOperationPause(operation.ID)
+	switch {
+	case dberr.IsNotFound(err):
+		// the operation was never paused
+	case err != nil:
+		return false, err
+	case operationPause.Paused:
+		log.Infof("Operation is paused, checking again in %s", pausedOperationRecheckInterval)
+		return true, nil
+	default:
+		operation.ResumedAt = operationPause.ResumedAt
+	}
+	pause, err := m.operationStorage.GetProcessingPause(operation.Type)
+	switch {
+	case dberr.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	log.Infof("Processing of %s operations is paused since %s (reason: %q), checking again in %s", pause.OperationType, pause.PausedAt, pause.Reason, pausedOperationRecheckInterval)
+	return true, nil
+}
+
 func (m *StagedManager) GetAllStages() []string {
 	var all []string
 	for _, s := range m.stages {
@@ -105,6 +172,12 @@ func (m *StagedManager) GetAllStages() []string {
 }
 
 func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
+	return m.ExecuteWithContext(context.Background(), operationID)
+}
+
+// ExecuteWithContext processes the operation until the context is canceled, the step which is already running is finished.
+// The context is canceled when another replica takes over the lease of the operation.
+func (m *StagedManager) ExecuteWithContext(ctx context.Context, operationID string) (time.Duration, error) {
 
 	operation, err := m.operationStorage.GetOperationByID(operationID)
 	if err != nil {
@@ -114,12 +187,30 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 
 	logOperation := m.log.WithFields(logrus.Fields{"operation": operationID, "instanceID": operation.InstanceID, "planID": operation.ProvisioningParameters.PlanID})
 	logOperation.Infof("Start process operation steps for GlobalAccount=%s, ", operation.ProvisioningParameters.ErsContext.GlobalAccountID)
-	if time.Since(operation.CreatedAt) > m.operationTimeout {
+	// the operation could be finished by another replica
+	if isFinalState(operation.State) {
+		logOperation.Infof("Operation was already finished, state: %s", operation.State)
+		return 0, nil
+	}
+	paused, err := m.isPaused(operation, logOperation)
+	if err != nil {
+		logOperation.Errorf("Cannot check if the operation processing is paused: %s", err)
+		return 3 * time.Second, nil
+	}
+	if paused {
+		return pausedOperationRecheckInterval, nil
+	}
+	release, acquired := m.acquireAccountSlot(operation, logOperation)
+	if !acquired {
+		return m.accountLimiter.cfg.DeferInterval, nil
+	}
+	defer release()
+	if m.operationTimeout > 0 && time.Since(operation.ProcessingStartedAt()) > m.operationTimeout {
 		timeoutErr := kebError.TimeoutError("operation has reached the time limit")
 		operation.LastError = timeoutErr
 		defer m.callPubSubOutsideSteps(operation, timeoutErr)
 
-		logOperation.Infof("operation has reached the time limit: operation was created at: %s", operation.CreatedAt)
+		logOperation.Infof("operation has reached the time limit: operation processing started at: %s", operation.ProcessingStartedAt())
 		operation.State = domain.Failed
 		_, err = m.operationStorage.UpdateOperation(*operation)
 		if err != nil {
@@ -128,6 +219,7 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 			operation.LastError = timeoutErr
 			return time.Second, timeoutErr
 		}
+		m.compensate(operationID, logOperation)
 
 		return 0, timeoutErr
 	}
@@ -143,21 +235,36 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 		for _, step := range stage.steps {
 			logStep := logOperation.WithField("step", step.Name()).
 				WithField("stage", stage.name)
+			if ctx.Err() != nil {
+				logStep.Warnf("Processing stopped, the operation is processed by another replica")
+				return 0, nil
+			}
 			if step.condition != nil && !step.condition(processedOperation) {
 				logStep.Debugf("Skipping")
 				continue
 			}
-			operation.EventInfof("processing step: %v", step.Name())
+			if processedOperation.IsStepSkipped(step.Name()) {
+				logStep.Infof("Skipping, the step is marked as skipped")
+				operation.EventWithFields(events.Fields{events.StepField: step.Name()}).Infof("step %v skipped", step.Name())
+				continue
+			}
+			operation.EventWithFields(events.Fields{events.StepField: step.Name()}).Infof("processing step: %v", step.Name())
 
-			processedOperation, when, err = m.runStep(step, processedOperation, logStep)
+			if group, isGroup := step.Step.(*ParallelGroup); isGroup {
+				processedOperation, when, err = m.runParallelGroup(ctx, group, stage.name, processedOperation, logStep)
+			} else {
+				processedOperation, when, err = m.runStep(ctx, step, stage.name, processedOperation, logStep)
+			}
 			if err != nil {
 				logStep.Errorf("Process operation failed: %s", err)
-				operation.EventErrorf(err, "step %v processing returned error", step.Name())
+				operation.EventWithFields(events.Fields{events.StepField: step.Name()}).Errorf(err, "step %v processing returned error", step.Name())
+				m.compensate(operationID, logOperation)
 				return 0, err
 			}
-			if processedOperation.State == domain.Failed || processedOperation.State == domain.Succeeded {
+			if isFinalState(processedOperation.State) {
 				logStep.Infof("Operation %q got status %s. Process finished.", operation.ID, processedOperation.State)
 				operation.EventInfof("operation processing %v", processedOperation.State)
+				m.compensate(operationID, logOperation)
 				return 0, nil
 			}
 
@@ -191,6 +298,27 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 	return 0, nil
 }
 
+// Reschedule changes the maintenance window of the operation, it is used by orchestrations to postpone pending upgrades
+func (m *StagedManager) Reschedule(operationID string, maintenanceWindowBegin, maintenanceWindowEnd time.Time) error {
+	operation, err := m.operationStorage.GetOperationByID(operationID)
+	if err != nil {
+		m.log.Errorf("Cannot fetch operation %s from storage: %s", operationID, err)
+		return err
+	}
+	operation.MaintenanceWindowBegin = maintenanceWindowBegin
+	operation.MaintenanceWindowEnd = maintenanceWindowEnd
+	_, err = m.operationStorage.UpdateOperation(*operation)
+	if err != nil {
+		m.log.Errorf("Cannot update (reschedule) operation %s in storage: %s", operationID, err)
+	}
+	return err
+}
+
+// isFinalState returns true if the operation is not processed anymore, operations run by orchestrations can be canceled
+func isFinalState(state domain.LastOperationState) bool {
+	return state == domain.Succeeded || state == domain.Failed || state == orchestration.Canceled
+}
+
 func (m *StagedManager) saveFinishedStage(operation internal.Operation, s *stage, log logrus.FieldLogger) (internal.Operation, error) {
 	operation.FinishStage(s.name)
 	op, err := m.operationStorage.UpdateOperation(operation)
@@ -202,23 +330,86 @@ func (m *StagedManager) saveFinishedStage(operation internal.Operation, s *stage
 	return *op, nil
 }
 
-func (m *StagedManager) runStep(step Step, operation internal.Operation, logger logrus.FieldLogger) (processedOperation internal.Operation, backoff time.Duration, err error) {
+// runParallelGroup runs steps of the group concurrently, every step with its own retries, and saves the merged operation
+func (m *StagedManager) runParallelGroup(ctx context.Context, group *ParallelGroup, stageName string, operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	processedOperation, when, err := group.run(operation, logger, func(step Step, operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+		return m.runStep(ctx, step, stageName, operation, logger)
+	})
+	if err != nil || isFinalState(processedOperation.State) {
+		return processedOperation, when, err
+	}
+
+	// steps could save the operation, changes of steps are applied again to the operation read after a conflict,
+	// so changes saved concurrently are not overwritten
+	saved, repeat, err := NewOperationManager(m.operationStorage).UpdateOperation(processedOperation, func(op *internal.Operation) {
+		mergeOperation(op, operation, processedOperation)
+	}, logger)
+	if err != nil {
+		logger.Errorf("unable to save operation after processing the parallel group: %s", err)
+		return processedOperation, repeat, nil
+	}
+	return saved, when, nil
+}
+
+func (m *StagedManager) runStep(ctx context.Context, step Step, stageName string, operation internal.Operation, logger logrus.FieldLogger) (processedOperation internal.Operation, backoff time.Duration, err error) {
 	var start time.Time
+	attemptSaved := false
+	// previous attempts are read once, attempts made by the loop are counted in memory
+	var attempts *stepAttempts
 	defer func() {
 		if pErr := recover(); pErr != nil {
 			log.Println("panic in RunStep in staged manager: ", pErr)
 			err = errors.New(fmt.Sprintf("%v", pErr))
+			if !start.IsZero() && !attemptSaved {
+				// the attempt which panicked is not saved by the loop
+				SaveStepAttempt(m.operationStorage, internal.StepAttempt{
+					OperationID: operation.ID,
+					Stage:       stageName,
+					StepName:    step.Name(),
+					StartedAt:   start,
+					Duration:    time.Since(start),
+				}, err, logger)
+			}
 			om := NewOperationManager(m.operationStorage)
 			processedOperation, _, _ = om.OperationFailed(operation, "recovered from panic", err, m.log)
 		}
 	}()
 
-	processedOperation = operation
+	processedOperation = m.registerCompensation(step, operation, logger)
+	policy := m.stepPolicies.For(step.Name(), operation.ProvisioningParameters.PlanID)
+	if policy.Timeout != 0 {
+		// steps measure the timeout of the policy since the first attempt
+		attempts = m.previousStepAttempts(step.Name(), stageName, operation, logger)
+	}
 	begin := time.Now()
 	for {
 		start = time.Now()
+		attemptSaved = false
+		// the operation returned by the previous attempt could be read from the storage without the step policy
+		processedOperation.StepPolicy = policy
+		if attempts != nil {
+			processedOperation.StepStartedAt = attempts.firstAttemptSince(start)
+		}
 		logger.Infof("Start step")
 		processedOperation, backoff, err = step.Run(processedOperation, logger)
+		if backoff > 0 && err == nil && !isFinalState(processedOperation.State) {
+			if attempts == nil {
+				attempts = m.previousStepAttempts(step.Name(), stageName, operation, logger)
+			}
+			processedOperation, backoff, err = m.applyStepPolicy(step.Name(), policy, processedOperation, attempts, start, backoff, logger)
+		}
+		SaveStepAttempt(m.operationStorage, internal.StepAttempt{
+			OperationID: operation.ID,
+			Stage:       stageName,
+			StepName:    step.Name(),
+			StartedAt:   start,
+			Duration:    time.Since(start),
+			Backoff:     backoff,
+		}, err, logger)
+		attemptSaved = true
+		if attempts != nil {
+			attempts.add(start)
+		}
 		if err != nil {
 			processedOperation.LastError = kebError.ReasonForError(err)
 			logOperation := m.log.WithFields(logrus.Fields{"operation": processedOperation.ID, "error_component": processedOperation.LastError.Component(), "error_reason": processedOperation.LastError.Reason()})
@@ -245,12 +436,85 @@ func (m *StagedManager) runStep(step Step, operation internal.Operation, logger
 		// - the step does not need a retry
 		// - step returns an error
 		// - the loop takes too much time (to not block the worker too long)
-		if backoff == 0 || err != nil || time.Since(begin) > m.cfg.MaxStepProcessingTime {
+		// - the operation is taken over by another replica
+		if backoff == 0 || err != nil || time.Since(begin) > m.cfg.MaxStepProcessingTime || ctx.Err() != nil {
 			return processedOperation, backoff, err
 		}
-		operation.EventInfof("step %v sleeping for %v", step.Name(), backoff)
-		time.Sleep(backoff / time.Duration(m.speedFactor))
+		operation.EventWithFields(events.Fields{events.StepField: step.Name(), events.BackoffField: backoff.String()}).
+			Infof("step %v sleeping for %v", step.Name(), backoff)
+		select {
+		case <-ctx.Done():
+			return processedOperation, backoff, err
+		case <-time.After(backoff / time.Duration(m.speedFactor)):
+		}
+	}
+}
+
+// stepAttempts are attempts of the step made since the processing of the operation started
+type stepAttempts struct {
+	count          int
+	firstAttemptAt time.Time
+}
+
+func (a *stepAttempts) add(startedAt time.Time) {
+	a.count++
+	if a.firstAttemptAt.IsZero() || startedAt.Before(a.firstAttemptAt) {
+		a.firstAttemptAt = startedAt
+	}
+}
+
+// firstAttemptSince returns the time of the first attempt including the current one started at the given time
+func (a *stepAttempts) firstAttemptSince(startedAt time.Time) time.Time {
+	if a.firstAttemptAt.IsZero() || startedAt.Before(a.firstAttemptAt) {
+		return startedAt
+	}
+	return a.firstAttemptAt
+}
+
+// applyStepPolicy fails the operation if the step which needs a retry reached the number of attempts or the time limit
+// measured since the first attempt of the step, otherwise it returns the retry interval of the policy
+func (m *StagedManager) applyStepPolicy(stepName string, policy internal.StepPolicy, operation internal.Operation, attempts *stepAttempts, start time.Time, backoff time.Duration, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	if policy.MaxAttempts == 0 && policy.Timeout == 0 && policy.RetryInterval == 0 {
+		return operation, backoff, nil
+	}
+	if attempts == nil {
+		log.Warnf("previous attempts of the step are unknown, the step policy is not applied")
+		return operation, backoff, nil
+	}
+	// the current attempt is not saved yet
+	current := *attempts
+	current.add(start)
+
+	om := NewOperationManager(m.operationStorage)
+	switch {
+	case policy.MaxAttempts > 0 && current.count >= policy.MaxAttempts:
+		log.Errorf("step reached the maximum number of attempts: %d", policy.MaxAttempts)
+		return om.OperationFailed(operation, fmt.Sprintf("step %s reached the maximum number of attempts: %d", stepName, policy.MaxAttempts), nil, log)
+	case policy.Timeout > 0 && time.Since(current.firstAttemptAt) > policy.Timeout:
+		log.Errorf("step reached the time limit: %s", policy.Timeout)
+		return om.OperationFailed(operation, fmt.Sprintf("step %s reached the time limit: %s", stepName, policy.Timeout), nil, log)
+	case policy.RetryInterval > 0:
+		return operation, retryInterval(policy, current.count), nil
+	}
+	return operation, backoff, nil
+}
+
+// previousStepAttempts reads saved attempts of the step since the processing of the operation started,
+// it returns nil if the attempts cannot be read
+func (m *StagedManager) previousStepAttempts(stepName, stageName string, operation internal.Operation, log logrus.FieldLogger) *stepAttempts {
+	saved, err := m.operationStorage.ListStepAttemptsByOperationID(operation.ID)
+	if err != nil {
+		log.Warnf("unable to get previous attempts of the step: %s", err)
+		return nil
+	}
+	attempts := &stepAttempts{}
+	for _, attempt := range saved {
+		if attempt.StepName != stepName || attempt.Stage != stageName || attempt.StartedAt.Before(operation.ProcessingStartedAt()) {
+			continue
+		}
+		attempts.add(attempt.StartedAt)
 	}
+	return attempts
 }
 
 func (m *StagedManager) callPubSubOutsideSteps(operation *internal.Operation, err error) {
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
@@ -19,8 +20,10 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -92,6 +95,15 @@ func TestWithRetry(t *testing.T) {
 	op, _ := operationStorage.GetOperationByID(operation.ID)
 	assert.True(t, op.IsStageFinished("stage-1"))
 	assert.True(t, op.IsStageFinished("stage-2"))
+
+	attempts, err := operationStorage.ListStepAttemptsByOperationID(operation.ID)
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 6)
+	assert.Equal(t, "stage-2", attempts[3].Stage)
+	assert.Equal(t, "first-2", attempts[3].StepName)
+	assert.Equal(t, time.Millisecond, attempts[3].Backoff)
+	assert.Equal(t, "first-2", attempts[4].StepName)
+	assert.Zero(t, attempts[4].Backoff)
 }
 
 func TestWithPanic(t *testing.T) {
@@ -114,6 +126,12 @@ func TestWithPanic(t *testing.T) {
 	assert.Equal(t, op.State, domain.Failed)
 	assert.True(t, op.IsStageFinished("stage-1"))
 	assert.False(t, op.IsStageFinished("stage-2"))
+
+	attempts, err := operationStorage.ListStepAttemptsByOperationID(operation.ID)
+	assert.NoError(t, err)
+	require.Len(t, attempts, 4)
+	assert.Equal(t, "first-2-panic", attempts[3].StepName)
+	assert.Equal(t, "Panicking just for test", attempts[3].Error)
 }
 
 func TestSkipFinishedStage(t *testing.T) {
@@ -138,6 +156,581 @@ func TestSkipFinishedStage(t *testing.T) {
 	assert.True(t, op.IsStageFinished("stage-2"))
 }
 
+func TestSkipStep(t *testing.T) {
+	// given
+	operation := FixOperation("op-0001234")
+	operation.SkippedSteps = []string{"second"}
+
+	mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+	mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+	mgr.AddStep("stage-1", &testingStep{name: "second", eventPublisher: eventCollector}, nil)
+	mgr.AddStep("stage-1", &testingStep{name: "third", eventPublisher: eventCollector}, nil)
+
+	// when
+	retry, _ := mgr.Execute(operation.ID)
+
+	// then
+	assert.Zero(t, retry)
+	eventCollector.WaitForEvents(t, 2)
+	eventCollector.AssertProcessedSteps(t, []string{"first", "third"})
+	op, _ := operationStorage.GetOperationByID(operation.ID)
+	assert.True(t, op.IsStageFinished("stage-1"))
+	assert.True(t, mgr.HasStep("second"))
+	assert.False(t, mgr.HasStep("fourth"))
+}
+
+func TestPausedOperation(t *testing.T) {
+	t.Run("should not process paused operation", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		operation.CreatedAt = time.Now().Add(-time.Hour)
+
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+		require.NoError(t, operationStorage.UpsertOperationPause(internal.OperationPause{OperationID: operation.ID, Paused: true}))
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, retry)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.Equal(t, domain.InProgress, op.State)
+		assert.False(t, op.IsStageFinished("stage-1"))
+	})
+
+	t.Run("should measure the timeout of resumed operation from the resume", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		operation.CreatedAt = time.Now().Add(-time.Hour)
+
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+		require.NoError(t, operationStorage.UpsertOperationPause(internal.OperationPause{OperationID: operation.ID, ResumedAt: time.Now()}))
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.True(t, op.IsStageFinished("stage-1"))
+	})
+
+	t.Run("should not process operation when processing of its type is paused", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+		require.NoError(t, operationStorage.InsertProcessingPause(internal.ProcessingPause{
+			OperationType: internal.OperationTypeProvision,
+			PausedAt:      time.Now(),
+		}))
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, retry)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.False(t, op.IsStageFinished("stage-1"))
+
+		// when
+		require.NoError(t, operationStorage.DeleteProcessingPause(internal.OperationTypeProvision))
+		retry, err = mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		op, _ = operationStorage.GetOperationByID(operation.ID)
+		assert.True(t, op.IsStageFinished("stage-1"))
+	})
+}
+
+func TestExecuteWithCanceledContext(t *testing.T) {
+	// given
+	operation := FixOperation("op-0001234")
+	mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+	mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	retry, err := mgr.ExecuteWithContext(ctx, operation.ID)
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, retry)
+	eventCollector.AssertProcessedSteps(t, []string{})
+	op, _ := operationStorage.GetOperationByID(operation.ID)
+	assert.Equal(t, domain.InProgress, op.State)
+	assert.False(t, op.IsStageFinished("stage-1"))
+}
+
+func TestCanceledOperation(t *testing.T) {
+	t.Run("should not process canceled operation", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		operation.State = orchestration.Canceled
+
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.Equal(t, domain.LastOperationState(orchestration.Canceled), op.State)
+		assert.False(t, op.IsStageFinished("stage-1"))
+	})
+
+	t.Run("should stop processing when the step cancels the operation", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		mgr.AddStep("stage-1", &updatingStep{name: "cancel", storage: operationStorage, update: func(op *internal.Operation) {
+			op.State = orchestration.Canceled
+		}}, nil)
+		mgr.AddStep("stage-1", &testingStep{name: "second", eventPublisher: eventCollector}, nil)
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		assert.Equal(t, []string{"cancel"}, eventCollector.StepsProcessed)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.Equal(t, domain.LastOperationState(orchestration.Canceled), op.State)
+	})
+}
+
+func TestOperationWithoutTimeout(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	operation := FixOperation("op-0001234")
+	operation.CreatedAt = time.Now().Add(-24 * time.Hour)
+	require.NoError(t, memoryStorage.Operations().InsertOperation(operation))
+
+	eventCollector := &CollectingEventHandler{}
+	mgr := process.NewStagedManager(memoryStorage.Operations(), eventCollector, 0, process.StagedManagerConfiguration{}, logrus.New())
+	mgr.DefineStages([]string{"stage-1"})
+	mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+
+	// when
+	_, err := mgr.Execute(operation.ID)
+
+	// then
+	require.NoError(t, err)
+	op, _ := memoryStorage.Operations().GetOperationByID(operation.ID)
+	assert.Equal(t, domain.Succeeded, op.State)
+}
+
+func TestReschedule(t *testing.T) {
+	// given
+	operation := FixOperation("op-0001234")
+	mgr, operationStorage, _ := SetupStagedManager(operation)
+	begin := time.Now().Add(time.Hour).Truncate(time.Second)
+	end := begin.Add(4 * time.Hour)
+
+	// when
+	err := mgr.Reschedule(operation.ID, begin, end)
+
+	// then
+	require.NoError(t, err)
+	op, _ := operationStorage.GetOperationByID(operation.ID)
+	assert.Equal(t, begin, op.MaintenanceWindowBegin)
+	assert.Equal(t, end, op.MaintenanceWindowEnd)
+	assert.Error(t, mgr.Reschedule("not-existing", begin, end))
+}
+
+func TestStepPolicy(t *testing.T) {
+	t.Run("should fail the operation when the step reached the maximum number of attempts", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, operationStorage, _ := SetupStagedManager(operation)
+		step := &retryingStep{name: "first"}
+		mgr.AddStep("stage-1", step, nil)
+		mgr.UseStepPolicies(&process.StepPolicies{
+			Steps: map[string]process.StepPolicyConfig{
+				"first": {StepPolicy: internal.StepPolicy{MaxAttempts: 3, RetryInterval: time.Millisecond}},
+			},
+		})
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		assert.Equal(t, 3, step.attempts)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.Equal(t, domain.Failed, op.State)
+		assert.Equal(t, "step first reached the maximum number of attempts: 3", op.Description)
+	})
+
+	t.Run("should fail the operation when the step reached the time limit", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		operation.CreatedAt = time.Now().Add(-2 * time.Second)
+		mgr, operationStorage, _ := SetupStagedManager(operation)
+		step := &retryingStep{name: "first"}
+		mgr.AddStep("stage-1", step, nil)
+		mgr.UseStepPolicies(&process.StepPolicies{
+			Default: internal.StepPolicy{Timeout: time.Second},
+		})
+		require.NoError(t, operationStorage.InsertStepAttempt(internal.StepAttempt{
+			OperationID: operation.ID,
+			Stage:       "stage-1",
+			StepName:    "first",
+			StartedAt:   time.Now().Add(-1500 * time.Millisecond),
+		}))
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		assert.Equal(t, 1, step.attempts)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.Equal(t, domain.Failed, op.State)
+		assert.Equal(t, "step first reached the time limit: 1s", op.Description)
+	})
+
+	t.Run("should measure the time limit since the first attempt of the step", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		operation.UpdatedAt = time.Now().Add(-2 * time.Second)
+		mgr, operationStorage, _ := SetupStagedManager(operation)
+		step := &retryOperationStep{name: "second", maxTime: time.Hour, operationManager: process.NewOperationManager(operationStorage)}
+		mgr.AddStep("stage-1", step, nil)
+		mgr.UseStepPolicies(&process.StepPolicies{
+			Default: internal.StepPolicy{Timeout: time.Second, RetryInterval: time.Millisecond},
+		})
+		require.NoError(t, operationStorage.InsertStepAttempt(internal.StepAttempt{
+			OperationID: operation.ID,
+			Stage:       "stage-1",
+			StepName:    "first",
+			StartedAt:   time.Now().Add(-1500 * time.Millisecond),
+		}))
+
+		// when
+		_, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, step.processed)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.NotEqual(t, domain.Failed, op.State)
+	})
+
+	t.Run("should keep retrying the step when the time limit of the policy is longer than the time limit of the step", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		operation.UpdatedAt = time.Now().Add(-2 * time.Second)
+		mgr, operationStorage, _ := SetupStagedManager(operation)
+		step := &retryOperationStep{name: "first", maxTime: time.Second, operationManager: process.NewOperationManager(operationStorage)}
+		mgr.AddStep("stage-1", step, nil)
+		mgr.UseStepPolicies(&process.StepPolicies{
+			Default: internal.StepPolicy{Timeout: time.Hour, RetryInterval: time.Millisecond},
+		})
+
+		// when
+		_, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, step.processed)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.NotEqual(t, domain.Failed, op.State)
+		attempts, err := operationStorage.ListStepAttemptsByOperationID(operation.ID)
+		require.NoError(t, err)
+		assert.Len(t, attempts, 2)
+	})
+
+	t.Run("should use the retry interval of the policy for the plan", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		step := &onceRetryingStep{name: "first", eventPublisher: eventCollector}
+		mgr.AddStep("stage-1", step, nil)
+		mgr.UseStepPolicies(&process.StepPolicies{
+			Default: internal.StepPolicy{RetryInterval: time.Second},
+			Steps: map[string]process.StepPolicyConfig{
+				"first": {
+					StepPolicy: internal.StepPolicy{RetryInterval: 2 * time.Second, Timeout: time.Hour},
+					Plans: map[string]internal.StepPolicy{
+						broker.AzurePlanName: {RetryInterval: 3 * time.Second},
+					},
+				},
+			},
+		})
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		assert.Equal(t, internal.StepPolicy{RetryInterval: 3 * time.Second, Timeout: time.Hour}, step.policy)
+		attempts, err := operationStorage.ListStepAttemptsByOperationID(operation.ID)
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+		assert.Equal(t, 3*time.Second, attempts[0].Backoff)
+	})
+}
+
+func TestParallelGroup(t *testing.T) {
+	t.Run("should run steps concurrently and merge changes of the operation", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		started := &sync.WaitGroup{}
+		started.Add(2)
+		mgr.AddStep("stage-1", process.NewParallelGroup("group",
+			&updatingStep{name: "internal", started: started, storage: operationStorage, update: func(op *internal.Operation) {
+				op.Avs.AvsEvaluationInternalId = 1
+			}},
+			&updatingStep{name: "external", started: started, storage: operationStorage, update: func(op *internal.Operation) {
+				op.Avs.AVSEvaluationExternalId = 2
+			}},
+		), nil)
+		mgr.AddStep("stage-2", &testingStep{name: "second", eventPublisher: eventCollector}, nil)
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.Equal(t, domain.Succeeded, op.State)
+		assert.Equal(t, int64(1), op.Avs.AvsEvaluationInternalId)
+		assert.Equal(t, int64(2), op.Avs.AVSEvaluationExternalId)
+		assert.ElementsMatch(t, []string{"internal", "external"}, op.FinishedParallelSteps)
+		assert.True(t, mgr.HasStep("internal"))
+	})
+
+	t.Run("should not overwrite changes of the operation saved during the group", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, operationStorage, _ := SetupStagedManager(operation)
+		started := &sync.WaitGroup{}
+		started.Add(2)
+		mgr.AddStep("stage-1", process.NewParallelGroup("group",
+			&updatingStep{name: "updating", started: started, storage: operationStorage, update: func(op *internal.Operation) {
+				op.EDPCreated = true
+			}},
+			&concurrentWriteStep{name: "writing", started: started, storage: operationStorage, update: func(op *internal.Operation) {
+				op.DashboardURL = "https://dashboard.example.com"
+			}},
+		), nil)
+
+		// when
+		_, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.True(t, op.EDPCreated)
+		assert.Equal(t, "https://dashboard.example.com", op.DashboardURL)
+		assert.ElementsMatch(t, []string{"updating", "writing"}, op.FinishedParallelSteps)
+	})
+
+	t.Run("should not run finished steps when the group is retried", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		finishing := &updatingStep{name: "finishing", storage: operationStorage, update: func(op *internal.Operation) {
+			op.EDPCreated = true
+		}}
+		retrying := &retryingStep{name: "retrying"}
+		mgr.AddStep("stage-1", process.NewParallelGroup("group", finishing, retrying), nil)
+		mgr.AddStep("stage-2", &testingStep{name: "second", eventPublisher: eventCollector}, nil)
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, retry)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.False(t, op.IsStageFinished("stage-1"))
+		assert.True(t, op.EDPCreated)
+		assert.Equal(t, []string{"finishing"}, op.FinishedParallelSteps)
+
+		// when
+		_, err = mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 1, finishing.attempts)
+		assert.Greater(t, retrying.attempts, 1)
+	})
+
+	t.Run("should fail the operation when any step fails", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, operationStorage, eventCollector := SetupStagedManager(operation)
+		mgr.AddStep("stage-1", process.NewParallelGroup("group",
+			&testingStep{name: "first", eventPublisher: eventCollector},
+			&failingStep{name: "failing", storage: operationStorage},
+		), nil)
+		mgr.AddStep("stage-2", &testingStep{name: "second", eventPublisher: eventCollector}, nil)
+
+		// when
+		retry, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, retry)
+		op, _ := operationStorage.GetOperationByID(operation.ID)
+		assert.Equal(t, domain.Failed, op.State)
+		assert.False(t, op.IsStageFinished("stage-1"))
+	})
+}
+
+func TestCompensation(t *testing.T) {
+	setup := func(op internal.Operation, plans ...string) (*process.StagedManager, storage.BrokerStorage) {
+		memoryStorage := storage.NewMemoryStorage()
+		require.NoError(t, memoryStorage.Operations().InsertOperation(op))
+		require.NoError(t, memoryStorage.Instances().Insert(fixture.FixInstance(op.InstanceID)))
+
+		mgr := process.NewStagedManager(memoryStorage.Operations(), &CollectingEventHandler{}, 3*time.Second,
+			process.StagedManagerConfiguration{MaxStepProcessingTime: time.Second, CompensatedPlans: plans}, logrus.New())
+		mgr.SpeedUp(100000)
+		mgr.DefineStages([]string{"stage-1", "stage-2"})
+		mgr.UseCompensation(memoryStorage.Instances())
+		return mgr, memoryStorage
+	}
+
+	t.Run("should compensate steps in the reverse order when the operation fails", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, db := setup(operation, broker.AzurePlanName)
+		var compensated []string
+		mgr.AddStep("stage-1", &compensableStep{name: "first", compensated: &compensated}, nil)
+		mgr.AddStep("stage-1", &compensableStep{name: "second", compensated: &compensated}, nil)
+		mgr.AddStep("stage-2", &failingStep{name: "failing", storage: db.Operations()}, nil)
+
+		// when
+		_, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"second", "first"}, compensated)
+		op, _ := db.Operations().GetOperationByID(operation.ID)
+		assert.Equal(t, domain.Failed, op.State)
+		assert.True(t, op.Compensated)
+		assert.Equal(t, []string{"first", "second"}, op.Compensations)
+		assert.Empty(t, op.ExcutedButNotCompleted)
+		instance, _ := db.Instances().GetByID(operation.InstanceID)
+		assert.Nil(t, instance.CompensationFailedAt)
+
+		// when
+		_, err = mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, compensated, 2)
+	})
+
+	t.Run("should mark the instance for deprovisioning when the compensation fails", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, db := setup(operation, broker.AzurePlanName)
+		var compensated []string
+		mgr.AddStep("stage-1", &compensableStep{name: "first", compensated: &compensated}, nil)
+		mgr.AddStep("stage-1", &compensableStep{name: "second", compensated: &compensated, err: fmt.Errorf("some error")}, nil)
+		mgr.AddStep("stage-2", &failingStep{name: "failing", storage: db.Operations()}, nil)
+
+		// when
+		_, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first"}, compensated)
+		op, _ := db.Operations().GetOperationByID(operation.ID)
+		assert.True(t, op.Compensated)
+		assert.Equal(t, []string{"second"}, op.ExcutedButNotCompleted)
+		instance, _ := db.Instances().GetByID(operation.InstanceID)
+		assert.NotNil(t, instance.CompensationFailedAt)
+		assert.True(t, instance.DeletedAt.IsZero())
+	})
+
+	t.Run("should not compensate operations of plans without compensation", func(t *testing.T) {
+		// given
+		operation := FixOperation("op-0001234")
+		mgr, db := setup(operation, broker.TrialPlanName)
+		var compensated []string
+		mgr.AddStep("stage-1", &compensableStep{name: "first", compensated: &compensated}, nil)
+		mgr.AddStep("stage-2", &failingStep{name: "failing", storage: db.Operations()}, nil)
+
+		// when
+		_, err := mgr.Execute(operation.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, compensated)
+		op, _ := db.Operations().GetOperationByID(operation.ID)
+		assert.Equal(t, domain.Failed, op.State)
+		assert.False(t, op.Compensated)
+		assert.Empty(t, op.Compensations)
+	})
+}
+
+func TestAccountLimit(t *testing.T) {
+	// given
+	first := FixOperation("op-0001")
+	second := FixOperation("op-0002")
+	mgr, operationStorage, _ := SetupStagedManager(first)
+	require.NoError(t, operationStorage.InsertOperation(second))
+	limiter, err := process.NewAccountLimiter(process.AccountLimitsConfig{MaxConcurrentOperations: 1, DeferInterval: time.Minute})
+	require.NoError(t, err)
+	mgr.UseAccountLimiter(limiter)
+	step := &blockingStep{name: "blocking", started: make(chan struct{}), release: make(chan struct{})}
+	mgr.AddStep("stage-1", step, nil)
+
+	done := make(chan struct{})
+	go func() {
+		_, err := mgr.Execute(first.ID)
+		assert.NoError(t, err)
+		close(done)
+	}()
+	<-step.started
+
+	// when
+	retry, err := mgr.Execute(second.ID)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, retry)
+	op, _ := operationStorage.GetOperationByID(second.ID)
+	assert.Equal(t, domain.InProgress, op.State)
+	assert.Contains(t, op.Description, "deferred")
+
+	// when
+	close(step.release)
+	<-done
+	retry, err = mgr.Execute(second.ID)
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, retry)
+	op, _ = operationStorage.GetOperationByID(second.ID)
+	assert.Equal(t, domain.Succeeded, op.State)
+	assert.NotContains(t, op.Description, "deferred")
+}
+
 func SetupStagedManager(op internal.Operation) (*process.StagedManager, storage.Operations, *CollectingEventHandler) {
 	memoryStorage := storage.NewMemoryStorage()
 	memoryStorage.Operations().InsertOperation(op)
@@ -169,6 +762,7 @@ func (s *testingStep) Run(operation internal.Operation, logger logrus.FieldLogge
 type onceRetryingStep struct {
 	name           string
 	processed      bool
+	policy         internal.StepPolicy
 	eventPublisher event.Publisher
 }
 
@@ -177,6 +771,7 @@ func (s *onceRetryingStep) Name() string {
 }
 func (s *onceRetryingStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	s.eventPublisher.Publish(context.Background(), s.name)
+	s.policy = operation.StepPolicy
 	if !s.processed {
 		s.processed = true
 		return operation, time.Millisecond, nil
@@ -185,6 +780,144 @@ func (s *onceRetryingStep) Run(operation internal.Operation, logger logrus.Field
 	return operation, 0, nil
 }
 
+// retryOperationStep retries the operation once for the given time since the last update of the operation
+type retryOperationStep struct {
+	name             string
+	processed        bool
+	maxTime          time.Duration
+	operationManager *process.OperationManager
+}
+
+func (s *retryOperationStep) Name() string {
+	return s.name
+}
+
+func (s *retryOperationStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	if !s.processed {
+		s.processed = true
+		return s.operationManager.RetryOperation(operation, "not ready", nil, time.Millisecond, s.maxTime, logger)
+	}
+	return operation, 0, nil
+}
+
+type retryingStep struct {
+	name     string
+	attempts int
+}
+
+func (s *retryingStep) Name() string {
+	return s.name
+}
+
+func (s *retryingStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	s.attempts++
+	return operation, time.Minute, nil
+}
+
+// updatingStep waits until all steps of the group are started and saves the changed operation
+type updatingStep struct {
+	name     string
+	started  *sync.WaitGroup
+	storage  storage.Operations
+	update   func(op *internal.Operation)
+	attempts int
+}
+
+func (s *updatingStep) Name() string {
+	return s.name
+}
+
+func (s *updatingStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	s.attempts++
+	if s.started != nil {
+		s.started.Done()
+		s.started.Wait()
+	}
+	return process.NewOperationManager(s.storage).UpdateOperation(operation, s.update, logger)
+}
+
+// concurrentWriteStep saves the change of the operation read from the storage, like another process, and returns the given operation
+type concurrentWriteStep struct {
+	name    string
+	started *sync.WaitGroup
+	storage storage.Operations
+	update  func(op *internal.Operation)
+}
+
+func (s *concurrentWriteStep) Name() string {
+	return s.name
+}
+
+func (s *concurrentWriteStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	s.started.Done()
+	s.started.Wait()
+	for {
+		op, err := s.storage.GetOperationByID(operation.ID)
+		if err != nil {
+			return operation, 0, err
+		}
+		s.update(op)
+		if _, err = s.storage.UpdateOperation(*op); !dberr.IsConflict(err) {
+			return operation, 0, err
+		}
+	}
+}
+
+type failingStep struct {
+	name    string
+	storage storage.Operations
+}
+
+func (s *failingStep) Name() string {
+	return s.name
+}
+
+func (s *failingStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	return process.NewOperationManager(s.storage).OperationFailed(operation, "failed", nil, logger)
+}
+
+type compensableStep struct {
+	name        string
+	compensated *[]string
+	err         error
+}
+
+func (s *compensableStep) Name() string {
+	return s.name
+}
+
+func (s *compensableStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	return operation, 0, nil
+}
+
+func (s *compensableStep) Compensate(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, error) {
+	if s.err != nil {
+		return operation, s.err
+	}
+	*s.compensated = append(*s.compensated, s.name)
+	return operation, nil
+}
+
+// blockingStep blocks the first run until it is released
+type blockingStep struct {
+	name    string
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStep) Name() string {
+	return s.name
+}
+
+func (s *blockingStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	s.once.Do(func() {
+		close(s.started)
+		<-s.release
+	})
+	return operation, 0, nil
+}
+
 type panicStep struct {
 	name           string
 	processed      bool
@@ -0,0 +1,22 @@
+package process
+
+import (
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// SaveStepAttempt stores the attempt in the step history of the operation, error fields are set from the error returned by the step.
+// The history is informational, that's why a storage error is only logged and does not affect the processing.
+func SaveStepAttempt(storage storage.StepAttempts, attempt internal.StepAttempt, err error, log logrus.FieldLogger) {
+	if err != nil {
+		lastErr := kebError.ReasonForError(err)
+		attempt.ErrorReason = string(lastErr.Reason())
+		attempt.ErrorComponent = string(lastErr.Component())
+		attempt.Error = lastErr.Error()
+	}
+	if err := storage.InsertStepAttempt(attempt); err != nil {
+		log.Warnf("unable to save attempt of step %s: %s", attempt.StepName, err)
+	}
+}
@@ -0,0 +1,133 @@
+package process
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/plans"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultMaxRetryInterval limits the exponential backoff if the policy does not set the max retry interval
+const defaultMaxRetryInterval = time.Hour
+
+// StepPolicies configure retries and timeouts of steps processed by the staged manager.
+// The default policy is overridden by the policy of the step, which can be overridden for a plan.
+type StepPolicies struct {
+	Default internal.StepPolicy         `yaml:"default"`
+	Steps   map[string]StepPolicyConfig `yaml:"steps"`
+}
+
+type StepPolicyConfig struct {
+	internal.StepPolicy `yaml:",inline"`
+	// Plans maps plan names to policies overriding the policy of the step
+	Plans map[string]internal.StepPolicy `yaml:"plans,omitempty"`
+}
+
+// ReadStepPoliciesFromFile reads step policies from the YAML file, no policies are applied if the file name is empty
+func ReadStepPoliciesFromFile(filename string) (*StepPolicies, error) {
+	policies := &StepPolicies{}
+	if filename == "" {
+		return policies, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return policies, fmt.Errorf("while reading %s file with step policies config: %w", filename, err)
+	}
+	err = yaml.Unmarshal(data, policies)
+	if err != nil {
+		return policies, fmt.Errorf("while unmarshalling a file with step policies config: %w", err)
+	}
+	return policies, policies.validate()
+}
+
+func (p *StepPolicies) validate() error {
+	if err := validateStepPolicy(p.Default); err != nil {
+		return fmt.Errorf("invalid default policy: %w", err)
+	}
+	for name, step := range p.Steps {
+		if err := validateStepPolicy(step.StepPolicy); err != nil {
+			return fmt.Errorf("invalid policy of step %s: %w", name, err)
+		}
+		for plan, policy := range step.Plans {
+			if _, found := plans.IDsMapping[plan]; !found {
+				return fmt.Errorf("invalid policy of step %s: unknown plan %s", name, plan)
+			}
+			if err := validateStepPolicy(policy); err != nil {
+				return fmt.Errorf("invalid policy of step %s for plan %s: %w", name, plan, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateStepPolicy(policy internal.StepPolicy) error {
+	switch policy.Backoff {
+	case "", internal.FixedBackoff, internal.ExponentialBackoff:
+	default:
+		return fmt.Errorf("unknown backoff strategy %q", policy.Backoff)
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return fmt.Errorf("jitter must be between 0 and 1")
+	}
+	if policy.RetryInterval < 0 || policy.MaxRetryInterval < 0 || policy.Timeout < 0 || policy.MaxAttempts < 0 {
+		return fmt.Errorf("negative values are not allowed")
+	}
+	return nil
+}
+
+// For returns the effective policy of the step for the plan
+func (p *StepPolicies) For(stepName, planID string) internal.StepPolicy {
+	if p == nil {
+		return internal.StepPolicy{}
+	}
+	policy := p.Default
+	step, found := p.Steps[stepName]
+	if !found {
+		return policy
+	}
+	return policy.Merge(step.StepPolicy).Merge(step.Plans[plans.NamesMapping[planID]])
+}
+
+// Merged returns policies of steps merged with the default policy and policies of plans merged with the policy of the step
+func (p *StepPolicies) Merged() StepPolicies {
+	merged := StepPolicies{Steps: map[string]StepPolicyConfig{}}
+	if p == nil {
+		return merged
+	}
+	merged.Default = p.Default
+	for name, step := range p.Steps {
+		stepPolicy := p.Default.Merge(step.StepPolicy)
+		planPolicies := map[string]internal.StepPolicy{}
+		for plan, policy := range step.Plans {
+			planPolicies[plan] = stepPolicy.Merge(policy)
+		}
+		merged.Steps[name] = StepPolicyConfig{StepPolicy: stepPolicy, Plans: planPolicies}
+	}
+	return merged
+}
+
+// retryInterval returns the time to wait after the given attempt of the step, attempts are counted from 1
+func retryInterval(policy internal.StepPolicy, attempt int) time.Duration {
+	interval := policy.RetryInterval
+	if policy.Backoff == internal.ExponentialBackoff {
+		maxInterval := policy.MaxRetryInterval
+		if maxInterval == 0 {
+			maxInterval = defaultMaxRetryInterval
+		}
+		for i := 1; i < attempt && interval < maxInterval; i++ {
+			interval *= 2
+		}
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+	if policy.Jitter > 0 {
+		delta := float64(interval) * policy.Jitter
+		interval = time.Duration(float64(interval) - delta + rand.Float64()*2*delta)
+	}
+	return interval
+}
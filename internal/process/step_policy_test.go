@@ -0,0 +1,130 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/plans"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStepPoliciesFromFile(t *testing.T) {
+	// given/when
+	policies, err := ReadStepPoliciesFromFile("testdata/step_policies.yaml")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, internal.StepPolicy{RetryInterval: 10 * time.Second}, policies.Default)
+	assert.Equal(t, internal.StepPolicy{
+		RetryInterval:    30 * time.Second,
+		Backoff:          internal.ExponentialBackoff,
+		MaxRetryInterval: 5 * time.Minute,
+		Jitter:           0.1,
+		Timeout:          time.Hour,
+	}, policies.Steps["Check_Runtime"].StepPolicy)
+	assert.Equal(t, internal.StepPolicy{Timeout: 20 * time.Minute}, policies.Steps["Check_Runtime"].Plans["trial"])
+
+	t.Run("should return no policies when the file is not set", func(t *testing.T) {
+		// when
+		policies, err := ReadStepPoliciesFromFile("")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, internal.StepPolicy{}, policies.For("Check_Runtime", plans.TrialPlanID))
+	})
+}
+
+func TestStepPolicies_For(t *testing.T) {
+	policies, err := ReadStepPoliciesFromFile("testdata/step_policies.yaml")
+	require.NoError(t, err)
+
+	for tn, tc := range map[string]struct {
+		stepName string
+		planID   string
+		expected internal.StepPolicy
+	}{
+		"default policy": {
+			stepName: "Create_Runtime",
+			planID:   plans.AzurePlanID,
+			expected: internal.StepPolicy{RetryInterval: 10 * time.Second},
+		},
+		"step policy merged with the default policy": {
+			stepName: "Initialisation",
+			planID:   plans.AzurePlanID,
+			expected: internal.StepPolicy{RetryInterval: 10 * time.Second, MaxAttempts: 5},
+		},
+		"step policy": {
+			stepName: "Check_Runtime",
+			planID:   plans.AzurePlanID,
+			expected: internal.StepPolicy{RetryInterval: 30 * time.Second, Backoff: internal.ExponentialBackoff, MaxRetryInterval: 5 * time.Minute, Jitter: 0.1, Timeout: time.Hour},
+		},
+		"plan policy merged with the step policy": {
+			stepName: "Check_Runtime",
+			planID:   plans.TrialPlanID,
+			expected: internal.StepPolicy{RetryInterval: 30 * time.Second, Backoff: internal.ExponentialBackoff, MaxRetryInterval: 5 * time.Minute, Jitter: 0.1, Timeout: 20 * time.Minute},
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, policies.For(tc.stepName, tc.planID))
+		})
+	}
+}
+
+func TestStepPolicies_Validate(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		policies StepPolicies
+		expected string
+	}{
+		"unknown backoff": {
+			policies: StepPolicies{Default: internal.StepPolicy{Backoff: "linear"}},
+			expected: `invalid default policy: unknown backoff strategy "linear"`,
+		},
+		"jitter out of range": {
+			policies: StepPolicies{Steps: map[string]StepPolicyConfig{"step": {StepPolicy: internal.StepPolicy{Jitter: 1.5}}}},
+			expected: "invalid policy of step step: jitter must be between 0 and 1",
+		},
+		"unknown plan": {
+			policies: StepPolicies{Steps: map[string]StepPolicyConfig{"step": {Plans: map[string]internal.StepPolicy{"unknown": {}}}}},
+			expected: "invalid policy of step step: unknown plan unknown",
+		},
+		"negative timeout": {
+			policies: StepPolicies{Steps: map[string]StepPolicyConfig{"step": {Plans: map[string]internal.StepPolicy{"trial": {Timeout: -time.Minute}}}}},
+			expected: "invalid policy of step step for plan trial: negative values are not allowed",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			assert.EqualError(t, tc.policies.validate(), tc.expected)
+		})
+	}
+}
+
+func TestRetryInterval(t *testing.T) {
+	t.Run("fixed backoff", func(t *testing.T) {
+		policy := internal.StepPolicy{RetryInterval: 10 * time.Second, Backoff: internal.FixedBackoff}
+
+		assert.Equal(t, 10*time.Second, retryInterval(policy, 1))
+		assert.Equal(t, 10*time.Second, retryInterval(policy, 5))
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		policy := internal.StepPolicy{RetryInterval: 10 * time.Second, Backoff: internal.ExponentialBackoff, MaxRetryInterval: time.Minute}
+
+		assert.Equal(t, 10*time.Second, retryInterval(policy, 1))
+		assert.Equal(t, 20*time.Second, retryInterval(policy, 2))
+		assert.Equal(t, 40*time.Second, retryInterval(policy, 3))
+		assert.Equal(t, time.Minute, retryInterval(policy, 4))
+		assert.Equal(t, time.Minute, retryInterval(policy, 1000))
+	})
+
+	t.Run("exponential backoff with jitter", func(t *testing.T) {
+		policy := internal.StepPolicy{RetryInterval: 10 * time.Second, Backoff: internal.ExponentialBackoff, Jitter: 0.5}
+
+		for i := 0; i < 100; i++ {
+			interval := retryInterval(policy, 2)
+			assert.GreaterOrEqual(t, interval, 10*time.Second)
+			assert.LessOrEqual(t, interval, 30*time.Second)
+		}
+	})
+}
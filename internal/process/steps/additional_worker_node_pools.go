@@ -0,0 +1,75 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/gardener"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// ApplyAdditionalWorkerNodePoolsStep sets workers of the additional worker node pools in the shoot.
+// The Provisioner API supports only one worker group, that's why the workers are set directly in the shoot
+// once the Provisioner created or upgraded it.
+type ApplyAdditionalWorkerNodePoolsStep struct {
+	operationManager *process.OperationManager
+	gardenerClient   dynamic.Interface
+	namespace        string
+}
+
+var _ process.Step = &ApplyAdditionalWorkerNodePoolsStep{}
+
+func NewApplyAdditionalWorkerNodePoolsStep(os storage.Operations, gardenerClient dynamic.Interface, namespace string) *ApplyAdditionalWorkerNodePoolsStep {
+	return &ApplyAdditionalWorkerNodePoolsStep{
+		operationManager: process.NewOperationManager(os),
+		gardenerClient:   gardenerClient,
+		namespace:        namespace,
+	}
+}
+
+func (s *ApplyAdditionalWorkerNodePoolsStep) Name() string {
+	return "Apply_Additional_Worker_Node_Pools"
+}
+
+func (s *ApplyAdditionalWorkerNodePoolsStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	if operation.ShootName == "" {
+		log.Infof("Shoot does not exist, skipping")
+		return operation, 0, nil
+	}
+
+	shoot, err := s.gardenerClient.Resource(gardener.ShootResource).Namespace(s.namespace).Get(context.Background(), operation.ShootName, metav1.GetOptions{})
+	if err != nil {
+		return s.operationManager.RetryOperation(operation, fmt.Sprintf("unable to get shoot %s", operation.ShootName), err, 10*time.Second, time.Minute, log)
+	}
+	workers, _, err := unstructured.NestedSlice(shoot.Object, "spec", "provider", "workers")
+	if err != nil {
+		return s.operationManager.OperationFailed(operation, "unable to read workers of the shoot", err, log)
+	}
+	updated, err := provider.WorkersWithAdditionalPools(workers, operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools)
+	if err != nil {
+		return s.operationManager.OperationFailed(operation, "unable to create workers of additional worker node pools", err, log)
+	}
+	if reflect.DeepEqual(workers, updated) {
+		log.Infof("Workers of shoot %s are up to date", operation.ShootName)
+		return operation, 0, nil
+	}
+
+	if err := unstructured.SetNestedSlice(shoot.Object, updated, "spec", "provider", "workers"); err != nil {
+		return s.operationManager.OperationFailed(operation, "unable to set workers of the shoot", err, log)
+	}
+	_, err = s.gardenerClient.Resource(gardener.ShootResource).Namespace(s.namespace).Update(context.Background(), shoot, metav1.UpdateOptions{})
+	if err != nil {
+		return s.operationManager.RetryOperation(operation, fmt.Sprintf("unable to update shoot %s", operation.ShootName), err, 10*time.Second, time.Minute, log)
+	}
+	log.Infof("Shoot %s updated with %d additional worker node pools", operation.ShootName, len(updated)-1)
+	return operation, 0, nil
+}
@@ -0,0 +1,112 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/common/gardener"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const gardenerNamespace = "garden-kyma"
+
+func TestApplyAdditionalWorkerNodePoolsStep_Run(t *testing.T) {
+	t.Run("should set workers of additional worker node pools", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixture.FixProvisioningOperation("op-id", "inst-id")
+		operation.ShootName = "c-1234"
+		operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools = []internal.AdditionalWorkerNodePool{
+			{Name: "memory", MachineType: "Standard_D8_v3", AutoScalerMin: 1, AutoScalerMax: 5},
+		}
+		require.NoError(t, memoryStorage.Operations().InsertOperation(operation))
+		gardenerClient := gardener.NewDynamicFakeClient(fixShootWithWorkers("c-1234", fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3")))
+		step := NewApplyAdditionalWorkerNodePoolsStep(memoryStorage.Operations(), gardenerClient, gardenerNamespace)
+
+		// when
+		_, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		workers := shootWorkers(t, gardenerClient, "c-1234")
+		require.Len(t, workers, 2)
+		assert.Equal(t, internal.DefaultWorkerNodePoolName, workers[0]["name"])
+		assert.Equal(t, "memory", workers[1]["name"])
+		assert.Equal(t, "Standard_D8_v3", workers[1]["machine"].(map[string]interface{})["type"])
+		assert.EqualValues(t, 1, workers[1]["minimum"])
+		assert.EqualValues(t, 5, workers[1]["maximum"])
+		assert.Equal(t, []interface{}{"1", "2", "3"}, workers[1]["zones"])
+	})
+
+	t.Run("should remove workers of removed pools", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+		operation.ShootName = "c-1234"
+		operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools = []internal.AdditionalWorkerNodePool{}
+		require.NoError(t, memoryStorage.Operations().InsertOperation(operation))
+		gardenerClient := gardener.NewDynamicFakeClient(fixShootWithWorkers("c-1234",
+			fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3"), fixWorker("memory", "Standard_D8_v3")))
+		step := NewApplyAdditionalWorkerNodePoolsStep(memoryStorage.Operations(), gardenerClient, gardenerNamespace)
+
+		// when
+		_, repeat, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, repeat)
+		workers := shootWorkers(t, gardenerClient, "c-1234")
+		require.Len(t, workers, 1)
+		assert.Equal(t, internal.DefaultWorkerNodePoolName, workers[0]["name"])
+	})
+}
+
+func fixShootWithWorkers(name string, workers ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "core.gardener.cloud/v1beta1",
+		"kind":       "Shoot",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": gardenerNamespace,
+		},
+		"spec": map[string]interface{}{
+			"provider": map[string]interface{}{
+				"type":    "azure",
+				"workers": workers,
+			},
+		},
+	}}
+}
+
+func fixWorker(name, machineType string) interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"machine": map[string]interface{}{
+			"type": machineType,
+		},
+		"minimum": int64(3),
+		"maximum": int64(20),
+		"zones":   []interface{}{"1", "2", "3"},
+	}
+}
+
+func shootWorkers(t *testing.T, gardenerClient dynamic.Interface, name string) []map[string]interface{} {
+	shoot, err := gardenerClient.Resource(gardener.ShootResource).Namespace(gardenerNamespace).Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	workers, _, err := unstructured.NestedSlice(shoot.Object, "spec", "provider", "workers")
+	require.NoError(t, err)
+	result := make([]map[string]interface{}, 0, len(workers))
+	for _, w := range workers {
+		result = append(result, w.(map[string]interface{}))
+	}
+	return result
+}
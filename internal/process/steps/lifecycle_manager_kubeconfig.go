@@ -86,17 +86,3 @@ func initSecret(o internal.Operation) *corev1.Secret {
 	ApplyLabelsAndAnnotationsForLM(secret, o)
 	return secret
 }
-
-// NOTE: adapter for upgrade_kyma which is currently not using shared staged_manager
-type syncKubeconfigUpgradeKyma struct {
-	syncKubeconfig
-}
-
-func SyncKubeconfigUpgradeKyma(os storage.Operations, k8sClient client.Client) syncKubeconfigUpgradeKyma {
-	return syncKubeconfigUpgradeKyma{SyncKubeconfig(os, k8sClient)}
-}
-
-func (s syncKubeconfigUpgradeKyma) Run(o internal.UpgradeKymaOperation, logger logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
-	o2, w, err := s.syncKubeconfig.Run(o.Operation, logger)
-	return internal.UpgradeKymaOperation{o2}, w, err
-}
@@ -66,6 +66,34 @@ func TestCheckRuntimeStep_RunProvisioningSucceeded(t *testing.T) {
 	}
 }
 
+func TestCheckRuntimeStep_RunWithStepPolicyTimeout(t *testing.T) {
+	// given
+	provisionerClient := provisioner.NewFakeClient()
+	provisionerClient.SetOperation(statusProvisionerOperationID, gqlschema.OperationStatus{
+		ID:        ptr.String(statusProvisionerOperationID),
+		Operation: gqlschema.OperationTypeProvision,
+		State:     gqlschema.OperationStateInProgress,
+		RuntimeID: ptr.String(statusRuntimeID),
+	})
+	st := storage.NewMemoryStorage()
+	operation := fixOperationRuntimeStatus(broker.GCPPlanID)
+	operation.RuntimeID = statusRuntimeID
+	operation.UpdatedAt = time.Now().Add(-time.Minute)
+	operation.StepPolicy = internal.StepPolicy{Timeout: time.Hour}
+	operation.StepStartedAt = time.Now().Add(-time.Minute)
+	assert.NoError(t, st.Operations().InsertOperation(operation))
+
+	step := NewCheckStep(st.Operations(), provisionerClient, time.Second)
+
+	// when
+	operation, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, repeat > 0)
+	assert.Equal(t, domain.InProgress, operation.State)
+}
+
 func fixOperationRuntimeStatus(id string) internal.Operation {
 	return internal.Operation{
 		ID:                     id,
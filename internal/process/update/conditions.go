@@ -25,3 +25,15 @@ func SkipForOwnClusterPlan(op internal.Operation) bool {
 func RequiresBTPOperatorCredentials(op internal.Operation) bool {
 	return ForBTPOperatorCredentialsProvided(op) && !broker.IsPreviewPlan(op.ProvisioningParameters.PlanID)
 }
+
+func ForOptionalComponentsChange(op internal.Operation) bool {
+	return op.UpdatingParameters.OptionalComponentsToInstall != nil && !broker.IsPreviewPlan(op.ProvisioningParameters.PlanID)
+}
+
+func ForPlanChange(op internal.Operation) bool {
+	return op.PreviousPlanID != ""
+}
+
+func ForAdditionalWorkerNodePoolsChange(op internal.Operation) bool {
+	return op.UpdatingParameters.AdditionalWorkerNodePools != nil && SkipForOwnClusterPlan(op)
+}
@@ -0,0 +1,90 @@
+package update
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	reconcilerApi "github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/input"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// OptionalComponentsStep adds optional components requested in the update and removes the ones which are no longer requested
+// from the last cluster configuration. The new configuration is applied by the ApplyReconcilerConfigurationStep.
+type OptionalComponentsStep struct {
+	operationManager   *process.OperationManager
+	components         input.ComponentListProvider
+	optionalComponents []string
+}
+
+func NewOptionalComponentsStep(os storage.Operations, components input.ComponentListProvider, optionalComponents []string) *OptionalComponentsStep {
+	names := append([]string{}, optionalComponents...)
+	sort.Strings(names)
+	return &OptionalComponentsStep{
+		operationManager:   process.NewOperationManager(os),
+		components:         components,
+		optionalComponents: names,
+	}
+}
+
+func (s *OptionalComponentsStep) Name() string {
+	return "Update_Optional_Components"
+}
+
+func (s *OptionalComponentsStep) Run(operation internal.Operation, logger logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	if operation.UpdatingParameters.OptionalComponentsToInstall == nil {
+		return operation, 0, nil
+	}
+	if operation.LastRuntimeState.ClusterSetup == nil {
+		logger.Infof("no last runtime state found, skipping")
+		return operation, 0, nil
+	}
+
+	requested := map[string]struct{}{}
+	for _, name := range *operation.UpdatingParameters.OptionalComponentsToInstall {
+		requested[strings.ToLower(name)] = struct{}{}
+	}
+
+	kymaConfig := &operation.LastRuntimeState.ClusterSetup.KymaConfig
+	var enabled, disabled []string
+	for _, name := range s.optionalComponents {
+		_, isRequested := requested[strings.ToLower(name)]
+		idx := componentIndex(kymaConfig.Components, name)
+		switch {
+		case isRequested && idx == -1:
+			ci, err := getComponentInput(s.components, name, operation.RuntimeVersion, operation.InputCreator.Configuration())
+			if err != nil {
+				return s.operationManager.RetryOperation(operation, "failed to get components", err, 5*time.Second, 30*time.Second, logger)
+			}
+			kymaConfig.Components = append(kymaConfig.Components, ci)
+			operation.InputCreator.EnableOptionalComponent(name)
+			enabled = append(enabled, name)
+		case !isRequested && idx != -1:
+			kymaConfig.Components = append(kymaConfig.Components[:idx], kymaConfig.Components[idx+1:]...)
+			operation.InputCreator.DisableOptionalComponent(name)
+			disabled = append(disabled, name)
+		}
+	}
+
+	if len(enabled) == 0 && len(disabled) == 0 {
+		logger.Infof("optional components are up to date")
+		return operation, 0, nil
+	}
+	logger.Infof("enabling optional components: %v, disabling optional components: %v", enabled, disabled)
+	operation.EventInfof("optional components changed, enabled: [%s], disabled: [%s]", strings.Join(enabled, ", "), strings.Join(disabled, ", "))
+	operation.RequiresReconcilerUpdate = true
+	return operation, 0, nil
+}
+
+func componentIndex(components []reconcilerApi.Component, name string) int {
+	for i, c := range components {
+		if c.Component == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,119 @@
+package update
+
+import (
+	"testing"
+
+	reconcilerApi "github.com/kyma-incubator/reconciler/pkg/keb"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/input"
+	inputAutomock "github.com/kyma-project/kyma-environment-broker/internal/process/input/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionalComponentsStep_Run(t *testing.T) {
+	optionalComponents := []string{"kiali", "tracing"}
+
+	componentsProvider := &inputAutomock.ComponentListProvider{}
+	componentsProvider.On("AllComponents", mock.Anything, mock.Anything).Return([]internal.KymaComponent{
+		{Name: "keb", Namespace: "kyma-system"},
+		{Name: "kiali", Namespace: "kyma-system", Source: &internal.ComponentSource{URL: "https://kiali.local"}},
+		{Name: "tracing", Namespace: "kyma-system"},
+	}, nil)
+
+	t.Run("should enable and disable optional components", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		step := NewOptionalComponentsStep(memoryStorage.Operations(), componentsProvider, optionalComponents)
+		operation := fixOptionalComponentsOperation(t, []string{"Kiali"}, "keb", "tracing")
+
+		// when
+		op, d, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, d)
+		assert.True(t, op.RequiresReconcilerUpdate)
+		assert.Equal(t, []reconcilerApi.Component{
+			{Component: "keb", Namespace: "kyma-system"},
+			{Component: "kiali", Namespace: "kyma-system", URL: "https://kiali.local"},
+		}, op.LastRuntimeState.ClusterSetup.KymaConfig.Components)
+	})
+
+	t.Run("should remove all optional components", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		step := NewOptionalComponentsStep(memoryStorage.Operations(), componentsProvider, optionalComponents)
+		operation := fixOptionalComponentsOperation(t, []string{}, "keb", "kiali", "tracing")
+
+		// when
+		op, d, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, d)
+		assert.True(t, op.RequiresReconcilerUpdate)
+		assert.Equal(t, []reconcilerApi.Component{
+			{Component: "keb", Namespace: "kyma-system"},
+		}, op.LastRuntimeState.ClusterSetup.KymaConfig.Components)
+	})
+
+	t.Run("should not require reconciler update when components are not changed", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		step := NewOptionalComponentsStep(memoryStorage.Operations(), componentsProvider, optionalComponents)
+		operation := fixOptionalComponentsOperation(t, []string{"tracing"}, "keb", "tracing")
+
+		// when
+		op, d, err := step.Run(operation, logrus.New())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, d)
+		assert.False(t, op.RequiresReconcilerUpdate)
+		assert.Len(t, op.LastRuntimeState.ClusterSetup.KymaConfig.Components, 2)
+	})
+}
+
+func fixOptionalComponentsOperation(t *testing.T, requested []string, installed ...string) internal.Operation {
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.UpdatingParameters.OptionalComponentsToInstall = &requested
+	operation.InputCreator = fixUpgradeShootInputCreator(t)
+
+	var components []reconcilerApi.Component
+	for _, name := range installed {
+		components = append(components, reconcilerApi.Component{Component: name, Namespace: "kyma-system"})
+	}
+	operation.LastRuntimeState = internal.RuntimeState{
+		ClusterSetup: &reconcilerApi.Cluster{
+			KymaConfig: reconcilerApi.KymaConfig{Components: components},
+		},
+	}
+	return operation
+}
+
+func fixUpgradeShootInputCreator(t *testing.T) internal.ProvisionerInputCreator {
+	optComponentsSvc := runtime.NewOptionalComponentsService(runtime.ComponentsDisablers{
+		"kiali":   runtime.NewGenericComponentDisabler("kiali"),
+		"tracing": runtime.NewGenericComponentDisabler("tracing"),
+	})
+	configProvider := &inputAutomock.ConfigurationProvider{}
+	configProvider.On("ProvideForGivenVersionAndPlan", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return(&internal.ConfigForPlan{}, nil)
+
+	ibf, err := input.NewInputBuilderFactory(optComponentsSvc, runtime.NewDisabledComponentsProvider(),
+		&inputAutomock.ComponentListProvider{}, configProvider, input.Config{KubernetesVersion: "1.18"}, "1.20",
+		fixTrialRegionMapping(), fixFreemiumProviders(), fixture.FixOIDCConfigDTO())
+	require.NoError(t, err)
+
+	creator, err := ibf.CreateUpgradeShootInput(internal.ProvisioningParameters{PlanID: broker.GCPPlanID},
+		internal.RuntimeVersionData{Version: "1.20", Origin: internal.Defaults})
+	require.NoError(t, err)
+	return creator
+}
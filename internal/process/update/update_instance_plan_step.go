@@ -0,0 +1,51 @@
+package update
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// UpdateInstancePlanStep saves the new plan in the instance after the cluster was upgraded to it,
+// so the instance keeps its previous plan if the plan change fails.
+type UpdateInstancePlanStep struct {
+	operationManager *process.OperationManager
+	instanceStorage  storage.Instances
+}
+
+func NewUpdateInstancePlanStep(is storage.Instances, os storage.Operations) *UpdateInstancePlanStep {
+	return &UpdateInstancePlanStep{
+		operationManager: process.NewOperationManager(os),
+		instanceStorage:  is,
+	}
+}
+
+func (s *UpdateInstancePlanStep) Name() string {
+	return "Update_Instance_Plan"
+}
+
+func (s *UpdateInstancePlanStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
+	instance, err := s.instanceStorage.GetByID(operation.InstanceID)
+	if err != nil {
+		return s.operationManager.RetryOperation(operation, "unable to get the instance", err, 5*time.Second, time.Minute, log)
+	}
+
+	planID := operation.ProvisioningParameters.PlanID
+	if instance.ServicePlanID == planID {
+		return operation, 0, nil
+	}
+	instance.ServicePlanID = planID
+	instance.ServicePlanName = broker.PlanNamesMapping[planID]
+	instance.Parameters.PlanID = planID
+	if _, err := s.instanceStorage.Update(*instance); err != nil {
+		return s.operationManager.RetryOperation(operation, fmt.Sprintf("unable to save the plan %s in the instance", instance.ServicePlanName), err, 5*time.Second, time.Minute, log)
+	}
+	log.Infof("Plan of the instance changed from %s to %s", broker.PlanNamesMapping[operation.PreviousPlanID], instance.ServicePlanName)
+
+	return operation, 0, nil
+}
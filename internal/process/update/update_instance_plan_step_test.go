@@ -0,0 +1,56 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateInstancePlanStep_Run(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	instance := fixture.FixInstance("inst-id")
+	instance.ServicePlanID = broker.FreemiumPlanID
+	instance.ServicePlanName = broker.FreemiumPlanName
+	instance.Parameters.PlanID = broker.FreemiumPlanID
+	require.NoError(t, memoryStorage.Instances().Insert(instance))
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.PreviousPlanID = broker.FreemiumPlanID
+	operation.ProvisioningParameters.PlanID = broker.AWSPlanID
+	require.NoError(t, memoryStorage.Operations().InsertOperation(operation))
+	step := NewUpdateInstancePlanStep(memoryStorage.Instances(), memoryStorage.Operations())
+
+	// when
+	_, repeat, err := step.Run(operation, logrus.New())
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, repeat)
+	updated, err := memoryStorage.Instances().GetByID("inst-id")
+	require.NoError(t, err)
+	assert.Equal(t, broker.AWSPlanID, updated.ServicePlanID)
+	assert.Equal(t, broker.AWSPlanName, updated.ServicePlanName)
+	assert.Equal(t, broker.AWSPlanID, updated.Parameters.PlanID)
+}
+
+func TestForPlanChange(t *testing.T) {
+	for name, tc := range map[string]struct {
+		previousPlanID string
+		expected       bool
+	}{
+		"plan change":           {previousPlanID: broker.FreemiumPlanID, expected: true},
+		"update without change": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			op := internal.Operation{PreviousPlanID: tc.previousPlanID}
+			op.ProvisioningParameters.PlanID = broker.AWSPlanID
+			assert.Equal(t, tc.expected, ForPlanChange(op))
+		})
+	}
+}
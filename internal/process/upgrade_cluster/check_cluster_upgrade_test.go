@@ -0,0 +1,878 @@
+package upgrade_cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/avs"
+	"github.com/kyma-project/kyma-environment-broker/internal/notification"
+	notificationAutomock "github.com/kyma-project/kyma-environment-broker/internal/notification/mocks"
+	provisionerAutomock "github.com/kyma-project/kyma-environment-broker/internal/provisioner/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckClusterUpgradeStep_Run(t *testing.T) {
+	t.Run("should mark operation as Succeeded when upgrade was successful", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, _ := createEvalManager(t, memoryStorage, log)
+
+		orch := internal.Orchestration{
+			OrchestrationID: fixOrchestrationID,
+			State:           orchestration.InProgress,
+			Parameters: orchestration.Parameters{
+				Kyma: &orchestration.KymaParameters{
+					Version: fixKymaVersion,
+				},
+				Notification: true,
+			},
+		}
+		err := memoryStorage.Orchestrations().Insert(orch)
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		upgradeOperation := fixUpgradeClusterOperation()
+		upgradeOperation.State = domain.InProgress
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateSucceeded,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Succeeded, operation.State)
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+
+	})
+
+	t.Run("should refresh avs on success (both monitors, empty init)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManager(t, memoryStorage, log)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		avsData := createMonitors(t, client, "", "")
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateSucceeded,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should refresh avs on success (both monitors)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManager(t, memoryStorage, log)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		internalStatus, externalStatus := avs.StatusActive, avs.StatusInactive
+		avsData := createMonitors(t, client, internalStatus, externalStatus)
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateSucceeded,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should refresh avs on fail (both monitors)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManager(t, memoryStorage, log)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		internalStatus, externalStatus := avs.StatusActive, avs.StatusInactive
+		avsData := createMonitors(t, client, internalStatus, externalStatus)
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateFailed,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NotNil(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Failed, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should refresh avs on success (internal monitor)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManager(t, memoryStorage, log)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		internalStatus, externalStatus := avs.StatusActive, ""
+		avsData := createMonitors(t, client, internalStatus, externalStatus)
+		avsData.AVSEvaluationExternalId = 0
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateSucceeded,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should refresh avs on success (external monitor)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManager(t, memoryStorage, log)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		internalStatus, externalStatus := "", avs.StatusInactive
+		avsData := createMonitors(t, client, internalStatus, externalStatus)
+		avsData.AvsEvaluationInternalId = 0
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateSucceeded,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should refresh avs on success (no monitors)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManager(t, memoryStorage, log)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		internalStatus, externalStatus := "", ""
+		avsData := createMonitors(t, client, internalStatus, externalStatus)
+		avsData.AvsEvaluationInternalId = 0
+		avsData.AVSEvaluationExternalId = 0
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateSucceeded,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should retry on client error (both monitors)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		_, client := createEvalManager(t, memoryStorage, log)
+		evalManagerInvalid, _ := createEvalManagerWithValidity(t, memoryStorage, log, false)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		internalStatus, externalStatus := avs.StatusInactive, avs.StatusActive
+		avsData := createMonitors(t, client, internalStatus, externalStatus)
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(
+			gqlschema.OperationStatus{
+				ID:        ptr.String(fixProvisionerOperationID),
+				Operation: "",
+				State:     gqlschema.OperationStateSucceeded,
+				Message:   nil,
+				RuntimeID: StringPtr(fixRuntimeID),
+			}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManagerInvalid, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, 10*time.Second, repeat)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, operation.Avs.AvsInternalEvaluationStatus)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, operation.Avs.AvsExternalEvaluationStatus)
+	})
+
+	t.Run("should go through init and finish steps (both monitors)", func(t *testing.T) {
+		// given
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManager(t, memoryStorage, log)
+		evalManagerInvalid, _ := createEvalManagerWithValidity(t, memoryStorage, log, false)
+
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		provisioningOperation := fixProvisioningOperation()
+		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		internalStatus, externalStatus := avs.StatusInactive, avs.StatusActive
+		avsData := createMonitors(t, client, internalStatus, externalStatus)
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		callCounter := 0
+		provisionerClient := &provisionerAutomock.Client{}
+		// for the first 2 step.Run calls, RuntimeOperationStatus will return OperationStateInProgress
+		// otherwise, OperationStateSucceeded
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(
+			func(accountID string, operationID string) gqlschema.OperationStatus {
+				callCounter++
+				if callCounter <= 2 {
+					return gqlschema.OperationStatus{
+						ID:        ptr.String(fixProvisionerOperationID),
+						Operation: "",
+						State:     gqlschema.OperationStateInProgress,
+						Message:   nil,
+						RuntimeID: StringPtr(fixRuntimeID),
+					}
+				}
+
+				return gqlschema.OperationStatus{
+					ID:        ptr.String(fixProvisionerOperationID),
+					Operation: "",
+					State:     gqlschema.OperationStateSucceeded,
+					Message:   nil,
+					RuntimeID: StringPtr(fixRuntimeID),
+				}
+			}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParas := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManagerInvalid, nil, notificationBuilder)
+
+		// when invalid client request, this should be delayed
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, 10*time.Second, repeat)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, operation.Avs.AvsInternalEvaluationStatus)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, operation.Avs.AvsExternalEvaluationStatus)
+
+		// when valid client request and InProgress state from RuntimeOperationStatus, this should do init tasks
+		step.evaluationManager = evalManager
+		operation, repeat, err = step.Run(operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, 1*time.Minute, repeat)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: internalStatus})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: externalStatus})
+
+		// when valid client request and Succeeded state from RuntimeOperationStatus, this should do finish tasks
+		operation, repeat, err = step.Run(operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), repeat)
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should set AvS evaluations statuses to maintenance", func(t *testing.T) {
+		// given
+		maintenanceModeDisabled := false
+		maintenanceModeAlwaysDisabledGAIDs := []string{fixMaintenanceModeAlwaysDisabledGlobalAccountID}
+
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManagerWithMaintenanceModeConfig(t, memoryStorage, maintenanceModeDisabled, maintenanceModeAlwaysDisabledGAIDs)
+
+		provisioningOperation := fixProvisioningOperation()
+		err := memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		err = memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		avsData := createMonitors(t, client, avs.StatusActive, avs.StatusActive)
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateInProgress,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParams := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParams).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should keep active AvS evaluations statuses for given GlobalAccount ID", func(t *testing.T) {
+		// given
+		maintenanceModeDisabled := false
+		maintenanceModeAlwaysDisabledGAIDs := []string{fixGlobalAccountID}
+
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManagerWithMaintenanceModeConfig(t, memoryStorage, maintenanceModeDisabled, maintenanceModeAlwaysDisabledGAIDs)
+
+		provisioningOperation := fixProvisioningOperation()
+		err := memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		err = memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		avsData := createMonitors(t, client, avs.StatusActive, avs.StatusActive)
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateInProgress,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParams := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParams).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should keep active AvS evaluations statuses for all GA IDs", func(t *testing.T) {
+		// given
+		maintenanceModeDisabled := true
+		maintenanceModeAlwaysDisabledGAIDs := []string{fixMaintenanceModeAlwaysDisabledGlobalAccountID}
+
+		log := logrus.New()
+		memoryStorage := storage.NewMemoryStorage()
+		evalManager, client := createEvalManagerWithMaintenanceModeConfig(t, memoryStorage, maintenanceModeDisabled, maintenanceModeAlwaysDisabledGAIDs)
+
+		provisioningOperation := fixProvisioningOperation()
+		err := memoryStorage.Operations().InsertOperation(provisioningOperation)
+		require.NoError(t, err)
+
+		err = memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		require.NoError(t, err)
+
+		avsData := createMonitors(t, client, avs.StatusActive, avs.StatusActive)
+		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
+		upgradeOperation.State = domain.InProgress
+
+		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
+		require.NoError(t, err)
+
+		instance := fixInstanceRuntimeStatus()
+		err = memoryStorage.Instances().Insert(instance)
+		require.NoError(t, err)
+
+		provisionerClient := &provisionerAutomock.Client{}
+		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
+			ID:        ptr.String(fixProvisionerOperationID),
+			Operation: "",
+			State:     gqlschema.OperationStateInProgress,
+			Message:   nil,
+			RuntimeID: StringPtr(fixRuntimeID),
+		}, nil)
+
+		notificationTenants := []notification.NotificationTenant{
+			{
+				InstanceID: fixInstanceID,
+				State:      notification.FinishedMaintenanceState,
+				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
+			},
+		}
+		notificationParams := notification.NotificationParams{
+			OrchestrationID: fixOrchestrationID,
+			Tenants:         notificationTenants,
+		}
+		notificationBuilder := &notificationAutomock.BundleBuilder{}
+		bundle := &notificationAutomock.Bundle{}
+		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParams).Return(bundle, nil).Once()
+		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+
+		step := NewCheckClusterUpgradeStep(memoryStorage.Operations(), provisionerClient, evalManager, nil, notificationBuilder)
+
+		// when
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
+		assert.NoError(t, err)
+	})
+}
@@ -8,13 +8,9 @@ import (
 
 	"github.com/kyma-project/control-plane/components/provisioner/pkg/gqlschema"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
-	"github.com/kyma-project/kyma-environment-broker/internal/notification"
-	notificationAutomock "github.com/kyma-project/kyma-environment-broker/internal/notification/mocks"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/input"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/upgrade_kyma/automock"
 	cloudProvider "github.com/kyma-project/kyma-environment-broker/internal/provider"
-	provisionerAutomock "github.com/kyma-project/kyma-environment-broker/internal/provisioner/automock"
-	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -140,23 +136,12 @@ func createEvalManagerWithMaintenanceModeConfig(t *testing.T, storage storage.Br
 }
 
 func TestInitialisationStep_Run(t *testing.T) {
-	t.Run("should mark operation as Succeeded when upgrade was successful", func(t *testing.T) {
+	t.Run("should initialize UpgradeRuntimeInput request when run", func(t *testing.T) {
 		// given
 		log := logrus.New()
 		memoryStorage := storage.NewMemoryStorage()
-		evalManager, _ := createEvalManager(t, memoryStorage, log)
 
-		orch := internal.Orchestration{
-			OrchestrationID: fixOrchestrationID,
-			State:           orchestration.InProgress,
-			Parameters: orchestration.Parameters{
-				Kyma: &orchestration.KymaParameters{
-					Version: fixKymaVersion,
-				},
-				Notification: true,
-			},
-		}
-		err := memoryStorage.Orchestrations().Insert(orch)
+		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
 		require.NoError(t, err)
 
 		provisioningOperation := fixProvisioningOperation()
@@ -164,6 +149,7 @@ func TestInitialisationStep_Run(t *testing.T) {
 		require.NoError(t, err)
 
 		upgradeOperation := fixUpgradeClusterOperation()
+		upgradeOperation.ProvisionerOperationID = ""
 		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
 		require.NoError(t, err)
 
@@ -171,53 +157,34 @@ func TestInitialisationStep_Run(t *testing.T) {
 		err = memoryStorage.Instances().Insert(instance)
 		require.NoError(t, err)
 
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateSucceeded,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
+		inputBuilder := &automock.CreatorForPlan{}
+		inputBuilder.On("CreateUpgradeShootInput",
+			fixProvisioningParameters(), mock.AnythingOfType("internal.RuntimeVersionData")).
+			Return(&input.RuntimeInput{},
+				nil)
 
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient,
-			nil, evalManager, nil, notificationBuilder)
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), inputBuilder, nil)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
+		inputBuilder.AssertNumberOfCalls(t, "CreateUpgradeShootInput", 1)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
+		assert.NotNil(t, operation.InputCreator)
+		assert.Equal(t, domain.InProgress, operation.State)
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
+		operation.InputCreator = nil
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
-
 	})
 
-	t.Run("should initialize UpgradeRuntimeInput request when run", func(t *testing.T) {
+	t.Run("should create input creator again when the operation is in progress", func(t *testing.T) {
 		// given
 		log := logrus.New()
 		memoryStorage := storage.NewMemoryStorage()
-		evalManager, _ := createEvalManager(t, memoryStorage, log)
 
 		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
 		require.NoError(t, err)
@@ -227,63 +194,32 @@ func TestInitialisationStep_Run(t *testing.T) {
 		require.NoError(t, err)
 
 		upgradeOperation := fixUpgradeClusterOperation()
-		upgradeOperation.ProvisionerOperationID = ""
+		upgradeOperation.State = domain.InProgress
 		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
 		require.NoError(t, err)
 
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
 		inputBuilder := &automock.CreatorForPlan{}
 		inputBuilder.On("CreateUpgradeShootInput",
 			fixProvisioningParameters(), mock.AnythingOfType("internal.RuntimeVersionData")).
 			Return(&input.RuntimeInput{},
 				nil)
 
-		expectedOperation := upgradeOperation
-		expectedOperation.Version++
-		expectedOperation.State = orchestration.InProgress
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManager, nil, notificationBuilder)
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), inputBuilder, nil)
 
 		// when
-		op, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
-		assert.NoError(t, err)
-		inputBuilder.AssertNumberOfCalls(t, "CreateUpgradeShootInput", 1)
+		require.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.NotNil(t, op.InputCreator)
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(op.Operation.ID)
-		op.InputCreator = nil
-		assert.Equal(t, op, *storedOp)
-		assert.NoError(t, err)
+		assert.NotNil(t, operation.InputCreator)
+		assert.Equal(t, upgradeOperation.Version, operation.Version)
 	})
 
 	t.Run("should mark finish if orchestration was canceled", func(t *testing.T) {
 		// given
 		log := logrus.New()
 		memoryStorage := storage.NewMemoryStorage()
-		evalManager, _ := createEvalManager(t, memoryStorage, log)
 
 		err := memoryStorage.Orchestrations().Insert(internal.Orchestration{
 			OrchestrationID: fixOrchestrationID,
@@ -302,821 +238,19 @@ func TestInitialisationStep_Run(t *testing.T) {
 		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
 		require.NoError(t, err)
 
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), nil, nil, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		require.NoError(t, err)
-		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, orchestration.Canceled, string(upgradeOperation.State))
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		require.NoError(t, err)
-		assert.Equal(t, upgradeOperation, *storedOp)
-	})
-
-	t.Run("should refresh avs on success (both monitors, empty init)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManager(t, memoryStorage, log)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		avsData := createMonitors(t, client, "", "")
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateSucceeded,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should refresh avs on success (both monitors)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManager(t, memoryStorage, log)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		internalStatus, externalStatus := avs.StatusActive, avs.StatusInactive
-		avsData := createMonitors(t, client, internalStatus, externalStatus)
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateSucceeded,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should refresh avs on fail (both monitors)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManager(t, memoryStorage, log)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		internalStatus, externalStatus := avs.StatusActive, avs.StatusInactive
-		avsData := createMonitors(t, client, internalStatus, externalStatus)
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateFailed,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManager, nil, notificationBuilder)
+		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), nil, nil)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
-		assert.NotNil(t, err)
-		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Failed, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should refresh avs on success (internal monitor)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManager(t, memoryStorage, log)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		internalStatus, externalStatus := avs.StatusActive, ""
-		avsData := createMonitors(t, client, internalStatus, externalStatus)
-		avsData.AVSEvaluationExternalId = 0
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
 		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateSucceeded,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
+		assert.Equal(t, orchestration.Canceled, string(operation.State))
 
-	t.Run("should refresh avs on success (external monitor)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManager(t, memoryStorage, log)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
+		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(operation.ID)
 		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		internalStatus, externalStatus := "", avs.StatusInactive
-		avsData := createMonitors(t, client, internalStatus, externalStatus)
-		avsData.AvsEvaluationInternalId = 0
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateSucceeded,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should refresh avs on success (no monitors)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManager(t, memoryStorage, log)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		internalStatus, externalStatus := "", ""
-		avsData := createMonitors(t, client, internalStatus, externalStatus)
-		avsData.AvsEvaluationInternalId = 0
-		avsData.AVSEvaluationExternalId = 0
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateSucceeded,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should retry on client error (both monitors)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		_, client := createEvalManager(t, memoryStorage, log)
-		evalManagerInvalid, _ := createEvalManagerWithValidity(t, memoryStorage, log, false)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		internalStatus, externalStatus := avs.StatusInactive, avs.StatusActive
-		avsData := createMonitors(t, client, internalStatus, externalStatus)
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(
-			gqlschema.OperationStatus{
-				ID:        ptr.String(fixProvisionerOperationID),
-				Operation: "",
-				State:     gqlschema.OperationStateSucceeded,
-				Message:   nil,
-				RuntimeID: StringPtr(fixRuntimeID),
-			}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManagerInvalid, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, 10*time.Second, repeat)
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, upgradeOperation.Avs.AvsInternalEvaluationStatus)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, upgradeOperation.Avs.AvsExternalEvaluationStatus)
-	})
-
-	t.Run("should go through init and finish steps (both monitors)", func(t *testing.T) {
-		// given
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManager(t, memoryStorage, log)
-		evalManagerInvalid, _ := createEvalManagerWithValidity(t, memoryStorage, log, false)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		err := memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		provisioningOperation := fixProvisioningOperation()
-		err = memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		internalStatus, externalStatus := avs.StatusInactive, avs.StatusActive
-		avsData := createMonitors(t, client, internalStatus, externalStatus)
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		callCounter := 0
-		provisionerClient := &provisionerAutomock.Client{}
-		// for the first 2 step.Run calls, RuntimeOperationStatus will return OperationStateInProgress
-		// otherwise, OperationStateSucceeded
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(
-			func(accountID string, operationID string) gqlschema.OperationStatus {
-				callCounter++
-				if callCounter <= 2 {
-					return gqlschema.OperationStatus{
-						ID:        ptr.String(fixProvisionerOperationID),
-						Operation: "",
-						State:     gqlschema.OperationStateInProgress,
-						Message:   nil,
-						RuntimeID: StringPtr(fixRuntimeID),
-					}
-				}
-
-				return gqlschema.OperationStatus{
-					ID:        ptr.String(fixProvisionerOperationID),
-					Operation: "",
-					State:     gqlschema.OperationStateSucceeded,
-					Message:   nil,
-					RuntimeID: StringPtr(fixRuntimeID),
-				}
-			}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParas := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParas).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient, inputBuilder, evalManagerInvalid, nil, notificationBuilder)
-
-		// when invalid client request, this should be delayed
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, 10*time.Second, repeat)
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, upgradeOperation.Avs.AvsInternalEvaluationStatus)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, upgradeOperation.Avs.AvsExternalEvaluationStatus)
-
-		// when valid client request and InProgress state from RuntimeOperationStatus, this should do init tasks
-		step.evaluationManager = evalManager
-		upgradeOperation, repeat, err = step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, 1*time.Minute, repeat)
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: internalStatus})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: externalStatus})
-
-		// when valid client request and Succeeded state from RuntimeOperationStatus, this should do finish tasks
-		upgradeOperation, repeat, err = step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should set AvS evaluations statuses to maintenance", func(t *testing.T) {
-		// given
-		maintenanceModeDisabled := false
-		maintenanceModeAlwaysDisabledGAIDs := []string{fixMaintenanceModeAlwaysDisabledGlobalAccountID}
-
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManagerWithMaintenanceModeConfig(t, memoryStorage, maintenanceModeDisabled, maintenanceModeAlwaysDisabledGAIDs)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		provisioningOperation := fixProvisioningOperation()
-		err := memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		err = memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		avsData := createMonitors(t, client, avs.StatusActive, avs.StatusActive)
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateInProgress,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParams := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParams).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient,
-			inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should keep active AvS evaluations statuses for given GlobalAccount ID", func(t *testing.T) {
-		// given
-		maintenanceModeDisabled := false
-		maintenanceModeAlwaysDisabledGAIDs := []string{fixGlobalAccountID}
-
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManagerWithMaintenanceModeConfig(t, memoryStorage, maintenanceModeDisabled, maintenanceModeAlwaysDisabledGAIDs)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		provisioningOperation := fixProvisioningOperation()
-		err := memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		err = memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		avsData := createMonitors(t, client, avs.StatusActive, avs.StatusActive)
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateInProgress,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParams := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParams).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient,
-			inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
-	})
-
-	t.Run("should keep active AvS evaluations statuses for all GA IDs", func(t *testing.T) {
-		// given
-		maintenanceModeDisabled := true
-		maintenanceModeAlwaysDisabledGAIDs := []string{fixMaintenanceModeAlwaysDisabledGlobalAccountID}
-
-		log := logrus.New()
-		memoryStorage := storage.NewMemoryStorage()
-		evalManager, client := createEvalManagerWithMaintenanceModeConfig(t, memoryStorage, maintenanceModeDisabled, maintenanceModeAlwaysDisabledGAIDs)
-		inputBuilder := &automock.CreatorForPlan{}
-
-		provisioningOperation := fixProvisioningOperation()
-		err := memoryStorage.Operations().InsertOperation(provisioningOperation)
-		require.NoError(t, err)
-
-		err = memoryStorage.Orchestrations().Insert(fixOrchestrationWithKymaVer())
-		require.NoError(t, err)
-
-		avsData := createMonitors(t, client, avs.StatusActive, avs.StatusActive)
-		upgradeOperation := fixUpgradeClusterOperationWithAvs(avsData)
-
-		err = memoryStorage.Operations().InsertUpgradeClusterOperation(upgradeOperation)
-		require.NoError(t, err)
-
-		instance := fixInstanceRuntimeStatus()
-		err = memoryStorage.Instances().Insert(instance)
-		require.NoError(t, err)
-
-		provisionerClient := &provisionerAutomock.Client{}
-		provisionerClient.On("RuntimeOperationStatus", fixGlobalAccountID, fixProvisionerOperationID).Return(gqlschema.OperationStatus{
-			ID:        ptr.String(fixProvisionerOperationID),
-			Operation: "",
-			State:     gqlschema.OperationStateInProgress,
-			Message:   nil,
-			RuntimeID: StringPtr(fixRuntimeID),
-		}, nil)
-
-		notificationTenants := []notification.NotificationTenant{
-			{
-				InstanceID: fixInstanceID,
-				State:      notification.FinishedMaintenanceState,
-				EndDate:    time.Now().Format("2006-01-02 15:04:05"),
-			},
-		}
-		notificationParams := notification.NotificationParams{
-			OrchestrationID: fixOrchestrationID,
-			Tenants:         notificationTenants,
-		}
-		notificationBuilder := &notificationAutomock.BundleBuilder{}
-		bundle := &notificationAutomock.Bundle{}
-		notificationBuilder.On("NewBundle", fixOrchestrationID, notificationParams).Return(bundle, nil).Once()
-		bundle.On("UpdateNotificationEvent").Return(nil).Once()
-
-		step := NewInitialisationStep(memoryStorage.Operations(), memoryStorage.Orchestrations(), provisionerClient,
-			inputBuilder, evalManager, nil, notificationBuilder)
-
-		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
-
-		// then
-		assert.NoError(t, err)
-		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
-
-		storedOp, err := memoryStorage.Operations().GetUpgradeClusterOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
-		assert.NoError(t, err)
+		assert.Equal(t, operation, storedOp.Operation)
 	})
 }
 
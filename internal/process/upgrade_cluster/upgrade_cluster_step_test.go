@@ -98,12 +98,12 @@ func TestUpgradeClusterStep_Run(t *testing.T) {
 
 	// when
 
-	operation, repeat, err := step.Run(operation, log.WithFields(logrus.Fields{"step": "TEST"}))
+	upgradeOperation, repeat, err := step.Run(operation.Operation, log.WithFields(logrus.Fields{"step": "TEST"}))
 
 	// then
 	assert.NoError(t, err)
-	assert.Equal(t, 5*time.Second, repeat)
-	assert.Equal(t, fixProvisionerOperationID, operation.ProvisionerOperationID)
+	assert.Equal(t, time.Duration(0), repeat)
+	assert.Equal(t, fixProvisionerOperationID, upgradeOperation.ProvisionerOperationID)
 }
 
 func fixUpgradeClusterOperationWithInputCreator(t *testing.T) internal.UpgradeClusterOperation {
@@ -15,14 +15,14 @@ import (
 )
 
 type ApplyClusterConfigurationStep struct {
-	operationManager    *process.UpgradeKymaOperationManager
+	operationManager    *process.OperationManager
 	reconcilerClient    reconciler.Client
 	runtimeStateStorage storage.RuntimeStates
 }
 
 func NewApplyClusterConfigurationStep(os storage.Operations, rs storage.RuntimeStates, reconcilerClient reconciler.Client) *ApplyClusterConfigurationStep {
 	return &ApplyClusterConfigurationStep{
-		operationManager:    process.NewUpgradeKymaOperationManager(os),
+		operationManager:    process.NewOperationManager(os),
 		reconcilerClient:    reconcilerClient,
 		runtimeStateStorage: rs,
 	}
@@ -32,7 +32,7 @@ func (s *ApplyClusterConfigurationStep) Name() string {
 	return "Apply_Cluster_Configuration"
 }
 
-func (s *ApplyClusterConfigurationStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+func (s *ApplyClusterConfigurationStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	if operation.ClusterConfigurationApplied {
 		log.Infof("Cluster configuration already applied")
 		return operation, 0, nil
@@ -56,7 +56,7 @@ func (s *ApplyClusterConfigurationStep) Run(operation internal.UpgradeKymaOperat
 	}
 
 	err = s.runtimeStateStorage.Insert(
-		internal.NewRuntimeStateWithReconcilerInput(clusterConfiguration.RuntimeID, operation.Operation.ID, &clusterConfiguration))
+		internal.NewRuntimeStateWithReconcilerInput(clusterConfiguration.RuntimeID, operation.ID, &clusterConfiguration))
 	if err != nil {
 		log.Errorf("cannot insert runtimeState with reconciler payload: %s", err)
 		return operation, 10 * time.Second, nil
@@ -81,7 +81,7 @@ func (s *ApplyClusterConfigurationStep) Run(operation internal.UpgradeKymaOperat
 	}
 	log.Infof("Cluster configuration version %d", state.ConfigurationVersion)
 
-	updatedOperation, repeat, _ := s.operationManager.UpdateOperation(operation, func(operation *internal.UpgradeKymaOperation) {
+	updatedOperation, repeat, _ := s.operationManager.UpdateOperation(operation, func(operation *internal.Operation) {
 		operation.ClusterConfigurationVersion = state.ConfigurationVersion
 		operation.ClusterConfigurationApplied = true
 		operation.ClusterName = clusterConfiguration.RuntimeInput.Name
@@ -91,9 +91,7 @@ func (s *ApplyClusterConfigurationStep) Run(operation internal.UpgradeKymaOperat
 		return operation, 5 * time.Second, nil
 	}
 
-	// return some retry value to get back to initialisation step
-	return updatedOperation, 5 * time.Second, nil
-
+	return updatedOperation, 0, nil
 }
 
 func (s *ApplyClusterConfigurationStep) componentList(cluster reconcilerApi.Cluster) string {
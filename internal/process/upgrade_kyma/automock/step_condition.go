@@ -13,11 +13,11 @@ type StepCondition struct {
 }
 
 // Execute provides a mock function with given fields: operation
-func (_m *StepCondition) Execute(operation internal.UpgradeKymaOperation) bool {
+func (_m *StepCondition) Execute(operation internal.Operation) bool {
 	ret := _m.Called(operation)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(internal.UpgradeKymaOperation) bool); ok {
+	if rf, ok := ret.Get(0).(func(internal.Operation) bool); ok {
 		r0 = rf(operation)
 	} else {
 		r0 = ret.Get(0).(bool)
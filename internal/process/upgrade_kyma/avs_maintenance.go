@@ -10,7 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func SetAvsStatusMaintenance(evaluationManager *avs.EvaluationManager, operationManager *process.UpgradeKymaOperationManager, operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, error) {
+func SetAvsStatusMaintenance(evaluationManager *avs.EvaluationManager, operationManager *process.OperationManager, operation internal.Operation, log logrus.FieldLogger) (internal.Operation, error) {
 	hasMonitors := evaluationManager.HasMonitors(operation.Avs)
 	inMaintenance := evaluationManager.InMaintenance(operation.Avs)
 	var err error = nil
@@ -21,7 +21,7 @@ func SetAvsStatusMaintenance(evaluationManager *avs.EvaluationManager, operation
 		evaluationManager.IsMaintenanceModeApplicableForGAID(operation.ProvisioningParameters.ErsContext.GlobalAccountID) {
 		log.Infof("setting AVS evaluations statuses to maintenance")
 		err = evaluationManager.SetMaintenanceStatus(&operation.Avs, log)
-		operation, delay, _ = operationManager.UpdateOperation(operation, func(op *internal.UpgradeKymaOperation) {
+		operation, delay, _ = operationManager.UpdateOperation(operation, func(op *internal.Operation) {
 			op.Avs.AvsInternalEvaluationStatus = operation.Avs.AvsInternalEvaluationStatus
 			op.Avs.AvsExternalEvaluationStatus = operation.Avs.AvsExternalEvaluationStatus
 		}, log)
@@ -33,7 +33,7 @@ func SetAvsStatusMaintenance(evaluationManager *avs.EvaluationManager, operation
 	return operation, err
 }
 
-func RestoreAvsStatus(evaluationManager *avs.EvaluationManager, operationManager *process.UpgradeKymaOperationManager, operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, error) {
+func RestoreAvsStatus(evaluationManager *avs.EvaluationManager, operationManager *process.OperationManager, operation internal.Operation, log logrus.FieldLogger) (internal.Operation, error) {
 	hasMonitors := evaluationManager.HasMonitors(operation.Avs)
 	inMaintenance := evaluationManager.InMaintenance(operation.Avs)
 	var err error = nil
@@ -42,7 +42,7 @@ func RestoreAvsStatus(evaluationManager *avs.EvaluationManager, operationManager
 	if hasMonitors && inMaintenance {
 		log.Infof("clearing AVS maintenantce statuses and restoring original AVS evaluation statuses")
 		err = evaluationManager.RestoreStatus(&operation.Avs, log)
-		operation, delay, _ = operationManager.UpdateOperation(operation, func(op *internal.UpgradeKymaOperation) {
+		operation, delay, _ = operationManager.UpdateOperation(operation, func(op *internal.Operation) {
 			op.Avs.AvsInternalEvaluationStatus = operation.Avs.AvsInternalEvaluationStatus
 			op.Avs.AvsExternalEvaluationStatus = operation.Avs.AvsExternalEvaluationStatus
 		}, log)
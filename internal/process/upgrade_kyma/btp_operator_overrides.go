@@ -13,12 +13,12 @@ import (
 var ConfigMapGetter internal.ClusterIDGetter = internal.GetClusterIDWithKubeconfig
 
 type BTPOperatorOverridesStep struct {
-	operationManager *process.UpgradeKymaOperationManager
+	operationManager *process.OperationManager
 }
 
 func NewBTPOperatorOverridesStep(os storage.Operations) *BTPOperatorOverridesStep {
 	return &BTPOperatorOverridesStep{
-		operationManager: process.NewUpgradeKymaOperationManager(os),
+		operationManager: process.NewOperationManager(os),
 	}
 }
 
@@ -26,7 +26,7 @@ func (s *BTPOperatorOverridesStep) Name() string {
 	return "BTPOperatorOverrides"
 }
 
-func (s *BTPOperatorOverridesStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+func (s *BTPOperatorOverridesStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	if !operation.InputCreator.Configuration().ContainsAdditionalComponent(internal.BTPOperatorComponentName) {
 		log.Infof("BTP operator is not in the list of additional components, skipping")
 		return operation, 0, nil
@@ -45,7 +45,7 @@ func (s *BTPOperatorOverridesStep) Run(operation internal.UpgradeKymaOperation,
 	if clusterID == operation.InstanceDetails.ServiceManagerClusterID {
 		return operation, 0, nil
 	}
-	f := func(op *internal.UpgradeKymaOperation) {
+	f := func(op *internal.Operation) {
 		op.InstanceDetails.ServiceManagerClusterID = clusterID
 	}
 	return s.operationManager.UpdateOperation(operation, f, log)
@@ -5,18 +5,18 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 )
 
-func ForKyma2(op internal.UpgradeKymaOperation) bool {
+func ForKyma2(op internal.Operation) bool {
 	return op.RuntimeVersion.MajorVersion == 2
 }
 
-func ForKyma1(op internal.UpgradeKymaOperation) bool {
+func ForKyma1(op internal.Operation) bool {
 	return op.RuntimeVersion.MajorVersion == 1
 }
 
-func SkipForPreviewPlan(op internal.UpgradeKymaOperation) bool {
+func SkipForPreviewPlan(op internal.Operation) bool {
 	return !broker.IsPreviewPlan(op.ProvisioningParameters.PlanID)
 }
 
-func WhenBTPOperatorCredentialsProvided(op internal.UpgradeKymaOperation) bool {
+func WhenBTPOperatorCredentialsProvided(op internal.Operation) bool {
 	return op.ProvisioningParameters.ErsContext.SMOperatorCredentials != nil
 }
@@ -13,7 +13,7 @@ import (
 
 type GetKubeconfigStep struct {
 	provisionerClient   provisioner.Client
-	operationManager    *process.UpgradeKymaOperationManager
+	operationManager    *process.OperationManager
 	provisioningTimeout time.Duration
 }
 
@@ -21,17 +21,17 @@ func NewGetKubeconfigStep(os storage.Operations,
 	provisionerClient provisioner.Client) *GetKubeconfigStep {
 	return &GetKubeconfigStep{
 		provisionerClient: provisionerClient,
-		operationManager:  process.NewUpgradeKymaOperationManager(os),
+		operationManager:  process.NewOperationManager(os),
 	}
 }
 
-var _ Step = (*GetKubeconfigStep)(nil)
+var _ process.Step = (*GetKubeconfigStep)(nil)
 
 func (s *GetKubeconfigStep) Name() string {
 	return "Get_Kubeconfig"
 }
 
-func (s *GetKubeconfigStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+func (s *GetKubeconfigStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	if operation.Kubeconfig != "" {
 		operation.InputCreator.SetKubeconfig(operation.Kubeconfig)
 		return operation, 0, nil
@@ -59,7 +59,7 @@ func (s *GetKubeconfigStep) Run(operation internal.UpgradeKymaOperation, log log
 		return operation, 30 * time.Second, nil
 	}
 
-	newOperation, retry, _ := s.operationManager.UpdateOperation(operation, func(operation *internal.UpgradeKymaOperation) {
+	newOperation, retry, _ := s.operationManager.UpdateOperation(operation, func(operation *internal.Operation) {
 		operation.Kubeconfig = *status.RuntimeConfiguration.Kubeconfig
 	}, log)
 	if retry > 0 {
@@ -3,8 +3,11 @@ package upgrade_kyma
 import (
 	"testing"
 
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/provisioner"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,10 +28,18 @@ func TestNewGetKubeconfigStep(t *testing.T) {
 	st.Operations().InsertUpgradeKymaOperation(op)
 
 	// when
-	newOp, d, err := step.Run(op, logrus.New())
+	newOp, d, err := step.Run(op.Operation, logrus.New())
 
 	// then
 	require.NoError(t, err)
 	assert.Zero(t, d)
 	assert.NotEmpty(t, newOp.Kubeconfig)
 }
+
+func fixOperation(ID string) internal.UpgradeKymaOperation {
+	upgradeOperation := fixture.FixUpgradeKymaOperation(ID, "fea2c1a1-139d-43f6-910a-a618828a79d5")
+	upgradeOperation.State = domain.InProgress
+	upgradeOperation.Description = ""
+
+	return upgradeOperation
+}
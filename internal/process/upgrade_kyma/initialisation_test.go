@@ -196,15 +196,15 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
+		assert.Equal(t, domain.Succeeded, operation.State)
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 
 	})
@@ -271,7 +271,7 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		op, repeat, err := step.Run(upgradeOperation, log)
+		op, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
@@ -279,8 +279,8 @@ func TestInitialisationStep_Run(t *testing.T) {
 		assert.Equal(t, time.Duration(0), repeat)
 		assert.NotNil(t, op.InputCreator)
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(op.Operation.ID)
-		assert.Equal(t, op, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(op.ID)
+		assert.Equal(t, op, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -327,16 +327,16 @@ func TestInitialisationStep_Run(t *testing.T) {
 			nil, evalManager, nil, nil, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		require.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, orchestration.Canceled, string(upgradeOperation.State))
+		assert.Equal(t, orchestration.Canceled, string(operation.State))
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
 		require.NoError(t, err)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		assert.Equal(t, operation, storedOp.Operation)
 	})
 
 	t.Run("should refresh avs on success (both monitors, empty init)", func(t *testing.T) {
@@ -404,17 +404,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: avs.StatusMaintenance})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -485,17 +485,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -559,17 +559,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NotNil(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Failed, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, domain.Failed, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -640,17 +640,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -721,17 +721,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -803,17 +803,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: "", Original: ""})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -885,14 +885,14 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManagerInvalid, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, 10*time.Second, repeat)
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, upgradeOperation.Avs.AvsInternalEvaluationStatus)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, upgradeOperation.Avs.AvsExternalEvaluationStatus)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, operation.Avs.AvsInternalEvaluationStatus)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, operation.Avs.AvsExternalEvaluationStatus)
 	})
 
 	t.Run("should go through init and finish steps (both monitors)", func(t *testing.T) {
@@ -979,38 +979,38 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManagerInvalid, nil, rvc, notificationBuilder)
 
 		// when invalid client request, this should be delayed
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, 10*time.Second, repeat)
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, upgradeOperation.Avs.AvsInternalEvaluationStatus)
-		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, upgradeOperation.Avs.AvsExternalEvaluationStatus)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: internalStatus, Original: internalStatus}, operation.Avs.AvsInternalEvaluationStatus)
+		assert.Equal(t, internal.AvsEvaluationStatus{Current: externalStatus, Original: ""}, operation.Avs.AvsExternalEvaluationStatus)
 
 		// when valid client request and InProgress state from RuntimeOperationStatus, this should do init tasks
 		step.evaluationManager = evalManager
-		upgradeOperation, repeat, err = step.Run(upgradeOperation, log)
+		operation, repeat, err = step.Run(operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, 1*time.Minute, repeat)
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: internalStatus})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: externalStatus})
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: internalStatus})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: externalStatus})
 
 		// when valid client request and Succeeded state from RuntimeOperationStatus, this should do finish tasks
-		upgradeOperation, repeat, err = step.Run(upgradeOperation, log)
+		operation, repeat, err = step.Run(operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, time.Duration(0), repeat)
-		assert.Equal(t, domain.Succeeded, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: internalStatus, Original: avs.StatusMaintenance})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: externalStatus, Original: avs.StatusMaintenance})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -1076,17 +1076,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusMaintenance, Original: avs.StatusActive})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -1152,17 +1152,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 
@@ -1228,17 +1228,17 @@ func TestInitialisationStep_Run(t *testing.T) {
 			inputBuilder, evalManager, nil, rvc, notificationBuilder)
 
 		// when
-		upgradeOperation, repeat, err := step.Run(upgradeOperation, log)
+		operation, repeat, err := step.Run(upgradeOperation.Operation, log)
 
 		// then
 		assert.NoError(t, err)
 		assert.Equal(t, 1*time.Minute, repeat) // 1 min for StatusCheck
-		assert.Equal(t, domain.InProgress, upgradeOperation.State)
-		assert.Equal(t, upgradeOperation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
-		assert.Equal(t, upgradeOperation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, operation.Avs.AvsInternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
+		assert.Equal(t, operation.Avs.AvsExternalEvaluationStatus, internal.AvsEvaluationStatus{Current: avs.StatusActive, Original: ""})
 
-		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(upgradeOperation.Operation.ID)
-		assert.Equal(t, upgradeOperation, *storedOp)
+		storedOp, err := memoryStorage.Operations().GetUpgradeKymaOperationByID(operation.ID)
+		assert.Equal(t, operation, storedOp.Operation)
 		assert.NoError(t, err)
 	})
 }
@@ -23,7 +23,7 @@ type RuntimeVersionConfiguratorForUpgrade interface {
 }
 
 type OverridesFromSecretsAndConfigStep struct {
-	operationManager       *process.UpgradeKymaOperationManager
+	operationManager       *process.OperationManager
 	runtimeOverrides       RuntimeOverridesAppender
 	runtimeVerConfigurator RuntimeVersionConfiguratorForUpgrade
 }
@@ -31,7 +31,7 @@ type OverridesFromSecretsAndConfigStep struct {
 func NewOverridesFromSecretsAndConfigStep(os storage.Operations, runtimeOverrides RuntimeOverridesAppender,
 	rvc RuntimeVersionConfiguratorForUpgrade) *OverridesFromSecretsAndConfigStep {
 	return &OverridesFromSecretsAndConfigStep{
-		operationManager:       process.NewUpgradeKymaOperationManager(os),
+		operationManager:       process.NewOperationManager(os),
 		runtimeOverrides:       runtimeOverrides,
 		runtimeVerConfigurator: rvc,
 	}
@@ -41,7 +41,7 @@ func (s *OverridesFromSecretsAndConfigStep) Name() string {
 	return "Overrides_From_Secrets_And_Config_Step"
 }
 
-func (s *OverridesFromSecretsAndConfigStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+func (s *OverridesFromSecretsAndConfigStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	planName, exists := broker.PlanNamesMapping[operation.ProvisioningParameters.PlanID]
 	if !exists {
 		log.Errorf("cannot map planID '%s' to planName", operation.ProvisioningParameters.PlanID)
@@ -61,7 +61,7 @@ func (s *OverridesFromSecretsAndConfigStep) Run(operation internal.UpgradeKymaOp
 	return operation, 0, nil
 }
 
-func (s *OverridesFromSecretsAndConfigStep) getRuntimeVersion(operation internal.UpgradeKymaOperation) (*internal.RuntimeVersionData, error) {
+func (s *OverridesFromSecretsAndConfigStep) getRuntimeVersion(operation internal.Operation) (*internal.RuntimeVersionData, error) {
 	// for some previously stored operations the RuntimeVersion property may not be initialized
 	if operation.RuntimeVersion.Version != "" {
 		return &operation.RuntimeVersion, nil
@@ -70,5 +70,5 @@ func (s *OverridesFromSecretsAndConfigStep) getRuntimeVersion(operation internal
 	// if so, we manually compute the correct version using the same algorithm as when preparing
 	// the provisioning operation. The following code can be removed after all operations will use
 	// new approach for setting up runtime version in operation struct
-	return s.runtimeVerConfigurator.ForUpgrade(operation)
+	return s.runtimeVerConfigurator.ForUpgrade(internal.UpgradeKymaOperation{Operation: operation})
 }
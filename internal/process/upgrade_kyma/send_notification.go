@@ -14,7 +14,7 @@ import (
 )
 
 type SendNotificationStep struct {
-	operationManager *process.UpgradeKymaOperationManager
+	operationManager *process.OperationManager
 	bundleBuilder    notification.BundleBuilder
 }
 
@@ -24,12 +24,12 @@ func (s *SendNotificationStep) Name() string {
 
 func NewSendNotificationStep(os storage.Operations, bundleBuilder notification.BundleBuilder) *SendNotificationStep {
 	return &SendNotificationStep{
-		operationManager: process.NewUpgradeKymaOperationManager(os),
+		operationManager: process.NewOperationManager(os),
 		bundleBuilder:    bundleBuilder,
 	}
 }
 
-func (s *SendNotificationStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+func (s *SendNotificationStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	if operation.RuntimeOperation.Notification {
 		tenants := []notification.NotificationTenant{
 			{
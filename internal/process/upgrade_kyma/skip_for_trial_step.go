@@ -7,15 +7,16 @@ import (
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
 )
 
 type SkipForTrialPlanStep struct {
-	step Step
+	step process.Step
 }
 
-var _ Step = &SkipForTrialPlanStep{}
+var _ process.Step = &SkipForTrialPlanStep{}
 
-func NewSkipForTrialPlanStep(step Step) SkipForTrialPlanStep {
+func NewSkipForTrialPlanStep(step process.Step) SkipForTrialPlanStep {
 	return SkipForTrialPlanStep{
 		step: step,
 	}
@@ -25,7 +26,7 @@ func (s SkipForTrialPlanStep) Name() string {
 	return s.step.Name()
 }
 
-func (s SkipForTrialPlanStep) Run(operation internal.UpgradeKymaOperation, log logrus.FieldLogger) (internal.UpgradeKymaOperation, time.Duration, error) {
+func (s SkipForTrialPlanStep) Run(operation internal.Operation, log logrus.FieldLogger) (internal.Operation, time.Duration, error) {
 	if broker.IsTrialPlan(operation.ProvisioningParameters.PlanID) {
 		log.Infof("Skipping step %s", s.Name())
 		return operation, 0, nil
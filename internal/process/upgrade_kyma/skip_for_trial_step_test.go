@@ -58,10 +58,10 @@ func TestSkipForTrialPlanStepShouldNotSkip(t *testing.T) {
 	assert.Equal(t, wantOperation2, gotOperation)
 }
 
-func fixOperationWithPlanID(planID string) internal.UpgradeKymaOperation {
+func fixOperationWithPlanID(planID string) internal.Operation {
 	upgradeOperation := fixture.FixUpgradeKymaOperation(operationID, instanceID)
 	upgradeOperation.ProvisioningParameters = fixture.FixProvisioningParameters("dummy")
 	upgradeOperation.ProvisioningParameters.PlanID = planID
 
-	return upgradeOperation
+	return upgradeOperation.Operation
 }
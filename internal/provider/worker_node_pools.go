@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// WorkersWithAdditionalPools returns Gardener workers of the shoot consisting of the default worker and workers of the additional worker node pools.
+// The Provisioner API creates only the default worker, so additional workers copy its machine image, volume and zones
+// and override the name, machine type and autoscaler limits. Workers of pools not given anymore are removed.
+func WorkersWithAdditionalPools(workers []interface{}, pools []internal.AdditionalWorkerNodePool) ([]interface{}, error) {
+	defaultWorker, err := defaultWorker(workers)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{defaultWorker}
+	for _, pool := range pools {
+		worker := runtime.DeepCopyJSON(defaultWorker)
+		worker["name"] = pool.Name
+		worker["minimum"] = int64(pool.AutoScalerMin)
+		worker["maximum"] = int64(pool.AutoScalerMax)
+		machine, ok := worker["machine"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the default worker %s has no machine", internal.DefaultWorkerNodePoolName)
+		}
+		machine["type"] = pool.MachineType
+		result = append(result, worker)
+	}
+	return result, nil
+}
+
+func defaultWorker(workers []interface{}) (map[string]interface{}, error) {
+	for _, w := range workers {
+		worker, ok := w.(map[string]interface{})
+		if ok && worker["name"] == internal.DefaultWorkerNodePoolName {
+			return worker, nil
+		}
+	}
+	return nil, fmt.Errorf("the shoot has no default worker %s", internal.DefaultWorkerNodePoolName)
+}
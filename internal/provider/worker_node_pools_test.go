@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkersWithAdditionalPools(t *testing.T) {
+	t.Run("should add workers of additional pools based on the default worker", func(t *testing.T) {
+		// given
+		workers := []interface{}{fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3", 3, 20)}
+
+		// when
+		result, err := WorkersWithAdditionalPools(workers, []internal.AdditionalWorkerNodePool{
+			{Name: "memory", MachineType: "Standard_D8_v3", AutoScalerMin: 1, AutoScalerMax: 5},
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3", 3, 20),
+			fixWorker("memory", "Standard_D8_v3", 1, 5),
+		}, result)
+	})
+
+	t.Run("should replace workers of previous pools", func(t *testing.T) {
+		// given
+		workers := []interface{}{
+			fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3", 3, 20),
+			fixWorker("memory", "Standard_D8_v3", 1, 5),
+			fixWorker("compute", "Standard_F8s_v2", 1, 5),
+		}
+
+		// when
+		result, err := WorkersWithAdditionalPools(workers, []internal.AdditionalWorkerNodePool{
+			{Name: "memory", MachineType: "Standard_D16_v3", AutoScalerMin: 2, AutoScalerMax: 4},
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3", 3, 20),
+			fixWorker("memory", "Standard_D16_v3", 2, 4),
+		}, result)
+	})
+
+	t.Run("should remove all additional workers", func(t *testing.T) {
+		// given
+		workers := []interface{}{
+			fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3", 3, 20),
+			fixWorker("memory", "Standard_D8_v3", 1, 5),
+		}
+
+		// when
+		result, err := WorkersWithAdditionalPools(workers, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{fixWorker(internal.DefaultWorkerNodePoolName, "Standard_D4_v3", 3, 20)}, result)
+	})
+
+	t.Run("should fail without the default worker", func(t *testing.T) {
+		// when
+		_, err := WorkersWithAdditionalPools([]interface{}{fixWorker("other", "Standard_D4_v3", 3, 20)}, nil)
+
+		// then
+		assert.EqualError(t, err, "the shoot has no default worker cpu-worker-0")
+	})
+}
+
+func fixWorker(name, machineType string, minimum, maximum int64) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"machine": map[string]interface{}{
+			"type": machineType,
+			"image": map[string]interface{}{
+				"name":    "gardenlinux",
+				"version": "934.8.0",
+			},
+		},
+		"minimum":        minimum,
+		"maximum":        maximum,
+		"maxSurge":       int64(4),
+		"maxUnavailable": int64(0),
+		"volume": map[string]interface{}{
+			"type": "Standard_LRS",
+			"size": "50Gi",
+		},
+		"zones": []interface{}{"1", "2", "3"},
+	}
+}
@@ -0,0 +1,91 @@
+package quota
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// PlanQuota shows the number of instances of the plan and the limit, the limit is not set for plans which are not restricted
+type PlanQuota struct {
+	PlanName string `json:"planName"`
+	Used     int    `json:"used"`
+	Limit    *int   `json:"limit,omitempty"`
+}
+
+type GlobalAccountQuotas struct {
+	GlobalAccountID string      `json:"globalAccountID"`
+	Plans           []PlanQuota `json:"plans"`
+}
+
+type Handler struct {
+	instances storage.Instances
+	quotas    Quotas
+	planNames map[string]string
+	log       logrus.FieldLogger
+}
+
+// NewHandler creates the quotas handler, planNames maps plan IDs to plan names used in the quotas config
+func NewHandler(instances storage.Instances, quotas Quotas, planNames map[string]string, log logrus.FieldLogger) *Handler {
+	return &Handler{
+		instances: instances,
+		quotas:    quotas,
+		planNames: planNames,
+		log:       log.WithField("service", "QuotasHandler"),
+	}
+}
+
+func (h *Handler) AttachRoutes(router *mux.Router) {
+	router.HandleFunc("/quotas/{global_account_id}", h.getQuotas).Methods(http.MethodGet)
+}
+
+func (h *Handler) getQuotas(w http.ResponseWriter, r *http.Request) {
+	globalAccountID := mux.Vars(r)["global_account_id"]
+
+	instancesByPlan, err := h.instances.GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID)
+	if err != nil {
+		h.log.Errorf("while getting number of instances for global account %s: %v", globalAccountID, err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while getting number of instances: %w", err))
+		return
+	}
+
+	used := map[string]int{}
+	for planID, count := range instancesByPlan {
+		planName, found := h.planNames[planID]
+		if !found {
+			planName = planID
+		}
+		used[planName] += count
+	}
+
+	limits := h.quotas.LimitsForGlobalAccount(globalAccountID)
+	plans := map[string]struct{}{}
+	for plan := range used {
+		plans[plan] = struct{}{}
+	}
+	for plan := range limits {
+		plans[plan] = struct{}{}
+	}
+
+	response := GlobalAccountQuotas{
+		GlobalAccountID: globalAccountID,
+		Plans:           make([]PlanQuota, 0, len(plans)),
+	}
+	for plan := range plans {
+		planQuota := PlanQuota{PlanName: plan, Used: used[plan]}
+		if limit, found := limits[plan]; found {
+			planQuota.Limit = &limit
+		}
+		response.Plans = append(response.Plans, planQuota)
+	}
+	sort.Slice(response.Plans, func(i, j int) bool {
+		return response.Plans[i].PlanName < response.Plans[j].PlanName
+	})
+
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
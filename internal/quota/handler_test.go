@@ -0,0 +1,56 @@
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	const (
+		azurePlanID = "azure-plan-id"
+		gcpPlanID   = "gcp-plan-id"
+	)
+	db := storage.NewMemoryStorage()
+	for i, planID := range []string{azurePlanID, azurePlanID, gcpPlanID} {
+		instance := fixture.FixInstance(fmt.Sprintf("instance-%d", i))
+		instance.GlobalAccountID = "premium-global-account-id"
+		instance.ServicePlanID = planID
+		require.NoError(t, db.Instances().Insert(instance))
+	}
+	quotas, err := ReadQuotasFromFile("testdata/quotas.yaml")
+	require.NoError(t, err)
+
+	handler := NewHandler(db.Instances(), quotas, map[string]string{azurePlanID: "azure", gcpPlanID: "gcp"}, logrus.New())
+	router := mux.NewRouter()
+	handler.AttachRoutes(router)
+
+	// when
+	req, err := http.NewRequest(http.MethodGet, "/quotas/premium-global-account-id", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// then
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response GlobalAccountQuotas
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "premium-global-account-id", response.GlobalAccountID)
+	assert.Equal(t, []PlanQuota{
+		{PlanName: "aws", Used: 0, Limit: ptr.Integer(10)},
+		{PlanName: "azure", Used: 2, Limit: ptr.Integer(20)},
+		{PlanName: "gcp", Used: 1},
+		{PlanName: "trial", Used: 0, Limit: ptr.Integer(1)},
+	}, response.Plans)
+}
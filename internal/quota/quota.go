@@ -0,0 +1,52 @@
+package quota
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Limits maps plan names to the maximal number of instances
+type Limits map[string]int
+
+// Quotas holds limits applied to every global account and limits overridden for specific global accounts.
+// A plan without a limit is not restricted.
+type Quotas struct {
+	Default        Limits            `yaml:"default"`
+	GlobalAccounts map[string]Limits `yaml:"globalAccounts"`
+}
+
+func ReadQuotasFromFile(filename string) (Quotas, error) {
+	quotas := Quotas{}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return quotas, fmt.Errorf("while reading %s file with quotas config: %w", filename, err)
+	}
+	err = yaml.Unmarshal(data, &quotas)
+	if err != nil {
+		return quotas, fmt.Errorf("while unmarshalling a file with quotas config: %w", err)
+	}
+	return quotas, nil
+}
+
+// Limit returns the limit of instances of the plan in the global account, false means the plan is not limited
+func (q Quotas) Limit(globalAccountID, planName string) (int, bool) {
+	if limit, found := q.GlobalAccounts[globalAccountID][planName]; found {
+		return limit, true
+	}
+	limit, found := q.Default[planName]
+	return limit, found
+}
+
+// LimitsForGlobalAccount returns all limits applied to the global account
+func (q Quotas) LimitsForGlobalAccount(globalAccountID string) Limits {
+	limits := Limits{}
+	for plan, limit := range q.Default {
+		limits[plan] = limit
+	}
+	for plan, limit := range q.GlobalAccounts[globalAccountID] {
+		limits[plan] = limit
+	}
+	return limits
+}
@@ -0,0 +1,63 @@
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadQuotasFromFile(t *testing.T) {
+	// given/when
+	quotas, err := ReadQuotasFromFile("testdata/quotas.yaml")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, Limits{"azure": 5, "trial": 1}, quotas.Default)
+	assert.Equal(t, Limits{"azure": 20, "aws": 10}, quotas.GlobalAccounts["premium-global-account-id"])
+}
+
+func TestQuotas_Limit(t *testing.T) {
+	quotas, err := ReadQuotasFromFile("testdata/quotas.yaml")
+	require.NoError(t, err)
+
+	for tn, tc := range map[string]struct {
+		globalAccountID string
+		planName        string
+		expectedLimit   int
+		expectedLimited bool
+	}{
+		"default limit": {
+			globalAccountID: "global-account-id",
+			planName:        "azure",
+			expectedLimit:   5,
+			expectedLimited: true,
+		},
+		"global account limit": {
+			globalAccountID: "premium-global-account-id",
+			planName:        "azure",
+			expectedLimit:   20,
+			expectedLimited: true,
+		},
+		"default limit not overridden for the global account": {
+			globalAccountID: "premium-global-account-id",
+			planName:        "trial",
+			expectedLimit:   1,
+			expectedLimited: true,
+		},
+		"plan not limited": {
+			globalAccountID: "global-account-id",
+			planName:        "gcp",
+			expectedLimited: false,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			limit, limited := quotas.Limit(tc.globalAccountID, tc.planName)
+
+			// then
+			assert.Equal(t, tc.expectedLimited, limited)
+			assert.Equal(t, tc.expectedLimit, limit)
+		})
+	}
+}
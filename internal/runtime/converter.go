@@ -84,6 +84,7 @@ func (c *converter) NewDTO(instance internal.Instance) (pkg.RuntimeDTO, error) {
 		ProviderRegion:              instance.ProviderRegion,
 		UserID:                      instance.Parameters.ErsContext.UserID,
 		ShootName:                   instance.InstanceDetails.ShootName,
+		Labels:                      instance.Labels,
 		Status: pkg.RuntimeStatus{
 			CreatedAt:  instance.CreatedAt,
 			ModifiedAt: instance.UpdatedAt,
@@ -3,6 +3,7 @@ package runtime
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
@@ -78,6 +79,7 @@ func recreateInstances(operations []internal.Operation) []internal.Instance {
 			DeletedAt:       last.UpdatedAt,
 			InstanceDetails: last.InstanceDetails,
 			Parameters:      last.ProvisioningParameters,
+			Labels:          last.ProvisioningParameters.Parameters.Labels,
 		})
 	}
 	return instances
@@ -98,7 +100,7 @@ func unionInstances(sets ...[]internal.Instance) (union []internal.Instance) {
 	return
 }
 
-func (h *Handler) listInstances(filter dbmodel.InstanceFilter) ([]internal.Instance, int, int, error) {
+func (h *Handler) listInstances(filter dbmodel.InstanceFilter) ([]internal.Instance, int, int, map[string][]internal.Operation, error) {
 	if slices.Contains(filter.States, dbmodel.InstanceDeprovisioned) {
 		// try to list instances where deletion didn't finish successfully
 		// entry in the Instances table still exists but has deletion timestamp and contains list of incomplete steps
@@ -113,16 +115,107 @@ func (h *Handler) listInstances(filter dbmodel.InstanceFilter) ([]internal.Insta
 		opFilter.PageSize = filter.PageSize
 		operations, _, _, err := h.operationsDb.ListOperations(opFilter)
 		if err != nil {
-			return instances, instancesCount, instancesTotalCount, err
+			return instances, instancesCount, instancesTotalCount, nil, err
+		}
+		// operations of instances deprovisioned long ago are moved to the archive, only the summary operation is left
+		archived, err := h.archivedOperations(operations)
+		if err != nil {
+			return instances, instancesCount, instancesTotalCount, nil, err
+		}
+		for instanceID, archivedOperations := range archived {
+			operations = replaceOperations(operations, instanceID, archivedOperations)
 		}
 		instancesFromOperations := recreateInstances(operations)
 
 		// return union of both sets of instances
 		instancesUnion := unionInstances(instances, instancesFromOperations)
 		count := len(instancesFromOperations)
-		return instancesUnion, count + instancesCount, count + instancesTotalCount, nil
+		return instancesUnion, count + instancesCount, count + instancesTotalCount, archived, nil
+	}
+	instances, count, totalCount, err := h.instancesDb.List(filter)
+	return instances, count, totalCount, nil, err
+}
+
+func (h *Handler) archivedOperations(operations []internal.Operation) (map[string][]internal.Operation, error) {
+	var instanceIDs []string
+	for _, o := range operations {
+		if !slices.Contains(instanceIDs, o.InstanceID) {
+			instanceIDs = append(instanceIDs, o.InstanceID)
+		}
+	}
+	archived := make(map[string][]internal.Operation)
+	if len(instanceIDs) == 0 {
+		return archived, nil
+	}
+	archivedOperations, err := h.operationsDb.ListArchivedOperationsByInstanceIDs(instanceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching archived operations: %w", err)
+	}
+	for _, o := range archivedOperations {
+		archived[o.InstanceID] = append(archived[o.InstanceID], o)
+	}
+	return archived, nil
+}
+
+func replaceOperations(operations []internal.Operation, instanceID string, replacement []internal.Operation) []internal.Operation {
+	result := make([]internal.Operation, 0, len(operations)+len(replacement))
+	for _, o := range operations {
+		if o.InstanceID != instanceID {
+			result = append(result, o)
+		}
+	}
+	return append(result, replacement...)
+}
+
+// runtimeOperations holds operations of the runtime grouped by type, the latest operations first
+type runtimeOperations struct {
+	provisioning   []internal.ProvisioningOperation
+	deprovisioning []internal.DeprovisioningOperation
+	upgradeKyma    []internal.UpgradeKymaOperation
+	upgradeCluster []internal.UpgradeClusterOperation
+	updating       []internal.UpdatingOperation
+}
+
+// newArchivedRuntimeOperations groups archived operations of the runtime by type
+func newArchivedRuntimeOperations(operations []internal.Operation) runtimeOperations {
+	sorted := make([]internal.Operation, len(operations))
+	copy(sorted, operations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	result := runtimeOperations{}
+	for _, o := range sorted {
+		switch o.Type {
+		case internal.OperationTypeProvision:
+			result.provisioning = append(result.provisioning, internal.ProvisioningOperation{Operation: o})
+		case internal.OperationTypeDeprovision:
+			result.deprovisioning = append(result.deprovisioning, internal.DeprovisioningOperation{Operation: o})
+		case internal.OperationTypeUpgradeKyma:
+			result.upgradeKyma = append(result.upgradeKyma, internal.UpgradeKymaOperation{Operation: o})
+		case internal.OperationTypeUpgradeCluster:
+			result.upgradeCluster = append(result.upgradeCluster, internal.UpgradeClusterOperation{Operation: o})
+		case internal.OperationTypeUpdate:
+			result.updating = append(result.updating, internal.UpdatingOperation{Operation: o})
+		}
 	}
-	return h.instancesDb.List(filter)
+	return result
+}
+
+// lastArchivedOperation returns the archived operation which is the last operation of the runtime,
+// pending and canceled operations are skipped the same way as by the storage
+func lastArchivedOperation(operations []internal.Operation) *internal.Operation {
+	var last *internal.Operation
+	for i := range operations {
+		op := &operations[i]
+		if op.State == orchestration.Pending || op.State == orchestration.Canceled {
+			continue
+		}
+		if last == nil || op.CreatedAt.After(last.CreatedAt) {
+			last = op
+		}
+	}
+	return last
 }
 
 func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
@@ -134,17 +227,32 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	filter := h.getFilters(req)
+	filter.Labels, err = pkg.ParseLabelSelectors(req.URL.Query()[pkg.LabelParam])
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
 	filter.PageSize = pageSize
 	filter.Page = page
 	opDetail := getOpDetail(req)
 	kymaConfig := getBoolParam(pkg.KymaConfigParam, req)
 	clusterConfig := getBoolParam(pkg.ClusterConfigParam, req)
 
-	instances, count, totalCount, err := h.listInstances(filter)
+	instances, count, totalCount, archived, err := h.listInstances(filter)
 	if err != nil {
 		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while fetching instances: %w", err))
 		return
 	}
+	pauses, err := h.operationsDb.ListProcessingPauses()
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while fetching processing pauses: %w", err))
+		return
+	}
+	pausedOperations, err := h.operationsDb.ListPausedOperations()
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while fetching paused operations: %w", err))
+		return
+	}
 
 	for _, instance := range instances {
 		dto, err := h.converter.NewDTO(instance)
@@ -153,10 +261,15 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		switch opDetail {
-		case pkg.AllOperation:
+		archivedOperations, isArchived := archived[instance.InstanceID]
+		switch {
+		case isArchived && opDetail == pkg.AllOperation:
+			h.applyRuntimeAllOperations(newArchivedRuntimeOperations(archivedOperations), &dto)
+		case isArchived && opDetail == pkg.LastOperation:
+			err = h.applyRuntimeLastOperation(lastArchivedOperation(archivedOperations), newArchivedRuntimeOperations(archivedOperations), &dto)
+		case opDetail == pkg.AllOperation:
 			err = h.setRuntimeAllOperations(instance, &dto)
-		case pkg.LastOperation:
+		case opDetail == pkg.LastOperation:
 			err = h.setRuntimeLastOperation(instance, &dto)
 		}
 		if err != nil {
@@ -164,10 +277,19 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		err = h.determineStatusModifiedAt(&dto)
-		if err != nil {
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
-			return
+		applyOperationPauses(&dto, pausedOperations)
+		applyProcessingPauses(&dto, pauses)
+
+		if isArchived {
+			if last := lastArchivedOperation(archivedOperations); last != nil {
+				dto.Status.ModifiedAt = last.UpdatedAt
+			}
+		} else {
+			err = h.determineStatusModifiedAt(&dto)
+			if err != nil {
+				httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+				return
+			}
 		}
 		err = h.setRuntimeOptionalAttributes(instance, &dto, kymaConfig, clusterConfig)
 		if err != nil {
@@ -186,6 +308,58 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 	httputil.WriteResponse(w, http.StatusOK, runtimePage)
 }
 
+// applyOperationPauses marks paused operations and sets the paused state if the last operation in progress is paused
+func applyOperationPauses(dto *pkg.RuntimeDTO, pauses []internal.OperationPause) {
+	if len(pauses) == 0 {
+		return
+	}
+	paused := make(map[string]bool, len(pauses))
+	for _, pause := range pauses {
+		paused[pause.OperationID] = true
+	}
+
+	operations := []*pkg.Operation{dto.Status.Provisioning, dto.Status.Deprovisioning}
+	for _, data := range []*pkg.OperationsData{dto.Status.UpgradingKyma, dto.Status.UpgradingCluster, dto.Status.Suspension, dto.Status.Unsuspension, dto.Status.Update} {
+		if data == nil {
+			continue
+		}
+		for i := range data.Data {
+			operations = append(operations, &data.Data[i])
+		}
+	}
+	for _, op := range operations {
+		if op != nil && paused[op.OperationID] {
+			op.Paused = true
+		}
+	}
+
+	lastOp := dto.LastOperation()
+	if lastOp.Paused && lastOp.State == string(domain.InProgress) {
+		dto.Status.State = pkg.StatePaused
+	}
+}
+
+// applyProcessingPauses sets the paused state if the processing of all operations of the last operation type is paused
+func applyProcessingPauses(dto *pkg.RuntimeDTO, pauses []internal.ProcessingPause) {
+	var operationType internal.OperationType
+	switch dto.Status.State {
+	case pkg.StateProvisioning:
+		operationType = internal.OperationTypeProvision
+	case pkg.StateDeprovisioning:
+		operationType = internal.OperationTypeDeprovision
+	case pkg.StateUpdating:
+		operationType = internal.OperationTypeUpdate
+	default:
+		return
+	}
+	for _, pause := range pauses {
+		if pause.OperationType == operationType {
+			dto.Status.State = pkg.StatePaused
+			return
+		}
+	}
+}
+
 func (h *Handler) takeLastNonDryRunOperations(oprs []internal.UpgradeKymaOperation) ([]internal.UpgradeKymaOperation, int) {
 	toReturn := make([]internal.UpgradeKymaOperation, 0)
 	totalCount := 0
@@ -229,10 +403,36 @@ func (h *Handler) determineStatusModifiedAt(dto *pkg.RuntimeDTO) error {
 }
 
 func (h *Handler) setRuntimeAllOperations(instance internal.Instance, dto *pkg.RuntimeDTO) error {
-	provOprs, err := h.operationsDb.ListProvisioningOperationsByInstanceID(instance.InstanceID)
+	var ops runtimeOperations
+	var err error
+
+	ops.provisioning, err = h.operationsDb.ListProvisioningOperationsByInstanceID(instance.InstanceID)
 	if err != nil && !dberr.IsNotFound(err) {
 		return fmt.Errorf("while fetching provisioning operations list for instance %s: %w", instance.InstanceID, err)
 	}
+	ops.deprovisioning, err = h.operationsDb.ListDeprovisioningOperationsByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return fmt.Errorf("while fetching deprovisioning operations list for instance %s: %w", instance.InstanceID, err)
+	}
+	ops.upgradeKyma, err = h.operationsDb.ListUpgradeKymaOperationsByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return fmt.Errorf("while fetching upgrade kyma operation for instance %s: %w", instance.InstanceID, err)
+	}
+	ops.upgradeCluster, err = h.operationsDb.ListUpgradeClusterOperationsByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return fmt.Errorf("while fetching upgrade cluster operation for instance %s: %w", instance.InstanceID, err)
+	}
+	ops.updating, err = h.operationsDb.ListUpdatingOperationsByInstanceID(instance.InstanceID)
+	if err != nil && !dberr.IsNotFound(err) {
+		return fmt.Errorf("while fetching update operation for instance %s: %w", instance.InstanceID, err)
+	}
+
+	h.applyRuntimeAllOperations(ops, dto)
+	return nil
+}
+
+func (h *Handler) applyRuntimeAllOperations(ops runtimeOperations, dto *pkg.RuntimeDTO) {
+	provOprs := ops.provisioning
 	if len(provOprs) != 0 {
 		firstProvOp := &provOprs[len(provOprs)-1]
 		lastProvOp := provOprs[0]
@@ -244,48 +444,29 @@ func (h *Handler) setRuntimeAllOperations(instance internal.Instance, dto *pkg.R
 		}
 	}
 
-	deprovOprs, err := h.operationsDb.ListDeprovisioningOperationsByInstanceID(instance.InstanceID)
-	if err != nil && !dberr.IsNotFound(err) {
-		return fmt.Errorf("while fetching deprovisioning operations list for instance %s: %w", instance.InstanceID, err)
-	}
 	var deprovOp *internal.DeprovisioningOperation
-	if len(deprovOprs) != 0 {
-		for _, op := range deprovOprs {
-			if !op.Temporary {
-				deprovOp = &op
-				break
-			}
+	for _, op := range ops.deprovisioning {
+		if !op.Temporary {
+			deprovOp = &op
+			break
 		}
 	}
 	h.converter.ApplyDeprovisioningOperation(dto, deprovOp)
-	h.converter.ApplySuspensionOperations(dto, deprovOprs)
+	h.converter.ApplySuspensionOperations(dto, ops.deprovisioning)
 
-	ukOprs, err := h.operationsDb.ListUpgradeKymaOperationsByInstanceID(instance.InstanceID)
-	if err != nil && !dberr.IsNotFound(err) {
-		return fmt.Errorf("while fetching upgrade kyma operation for instance %s: %w", instance.InstanceID, err)
-	}
-	dto.KymaVersion = determineKymaVersion(provOprs, ukOprs)
-	ukOprs, totalCount := h.takeLastNonDryRunOperations(ukOprs)
+	dto.KymaVersion = determineKymaVersion(provOprs, ops.upgradeKyma)
+	ukOprs, totalCount := h.takeLastNonDryRunOperations(ops.upgradeKyma)
 	h.converter.ApplyUpgradingKymaOperations(dto, ukOprs, totalCount)
 
-	ucOprs, err := h.operationsDb.ListUpgradeClusterOperationsByInstanceID(instance.InstanceID)
-	if err != nil && !dberr.IsNotFound(err) {
-		return fmt.Errorf("while fetching upgrade cluster operation for instance %s: %w", instance.InstanceID, err)
-	}
-	ucOprs, totalCount = h.takeLastNonDryRunClusterOperations(ucOprs)
+	ucOprs, totalCount := h.takeLastNonDryRunClusterOperations(ops.upgradeCluster)
 	h.converter.ApplyUpgradingClusterOperations(dto, ucOprs, totalCount)
 
-	uOprs, err := h.operationsDb.ListUpdatingOperationsByInstanceID(instance.InstanceID)
-	if err != nil && !dberr.IsNotFound(err) {
-		return fmt.Errorf("while fetching update operation for instance %s: %w", instance.InstanceID, err)
-	}
+	uOprs := ops.updating
 	totalCount = len(uOprs)
 	if len(uOprs) > numberOfUpgradeOperationsToReturn {
 		uOprs = uOprs[0:numberOfUpgradeOperationsToReturn]
 	}
 	h.converter.ApplyUpdateOperations(dto, uOprs, totalCount)
-
-	return nil
 }
 
 func (h *Handler) setRuntimeLastOperation(instance internal.Instance, dto *pkg.RuntimeDTO) error {
@@ -294,27 +475,39 @@ func (h *Handler) setRuntimeLastOperation(instance internal.Instance, dto *pkg.R
 		return fmt.Errorf("while fetching last operation instance %s: %w", instance.InstanceID, err)
 	}
 
+	var ops runtimeOperations
+	if lastOp.Type == internal.OperationTypeProvision {
+		ops.provisioning, err = h.operationsDb.ListProvisioningOperationsByInstanceID(instance.InstanceID)
+		if err != nil {
+			return fmt.Errorf("while fetching provisioning operations for instance %s: %w", instance.InstanceID, err)
+		}
+	}
+	return h.applyRuntimeLastOperation(lastOp, ops, dto)
+}
+
+// applyRuntimeLastOperation sets the last operation of the runtime, provisioning operations must be given if the last operation is a provisioning
+func (h *Handler) applyRuntimeLastOperation(lastOp *internal.Operation, ops runtimeOperations, dto *pkg.RuntimeDTO) error {
+	if lastOp == nil {
+		return nil
+	}
+
 	// Set AVS evaluation ID based on the data in the last operation
 	dto.AVSInternalEvaluationID = lastOp.InstanceDetails.Avs.AvsEvaluationInternalId
 
 	switch lastOp.Type {
 	case internal.OperationTypeProvision:
-		provOps, err := h.operationsDb.ListProvisioningOperationsByInstanceID(instance.InstanceID)
-		if err != nil {
-			return fmt.Errorf("while fetching provisioning operations for instance %s: %w", instance.InstanceID, err)
+		if len(ops.provisioning) == 0 {
+			return fmt.Errorf("provisioning operations of instance %s not found", lastOp.InstanceID)
 		}
-		lastProvOp := &provOps[0]
-		if len(provOps) > 1 {
+		lastProvOp := &ops.provisioning[0]
+		if len(ops.provisioning) > 1 {
 			h.converter.ApplyUnsuspensionOperations(dto, []internal.ProvisioningOperation{*lastProvOp})
 		} else {
 			h.converter.ApplyProvisioningOperation(dto, lastProvOp)
 		}
 
 	case internal.OperationTypeDeprovision:
-		deprovOp, err := h.operationsDb.GetDeprovisioningOperationByID(lastOp.ID)
-		if err != nil {
-			return fmt.Errorf("while fetching deprovisioning operation for instance %s: %w", instance.InstanceID, err)
-		}
+		deprovOp := &internal.DeprovisioningOperation{Operation: *lastOp}
 		if deprovOp.Temporary {
 			h.converter.ApplySuspensionOperations(dto, []internal.DeprovisioningOperation{*deprovOp})
 		} else {
@@ -322,25 +515,13 @@ func (h *Handler) setRuntimeLastOperation(instance internal.Instance, dto *pkg.R
 		}
 
 	case internal.OperationTypeUpgradeKyma:
-		upgKymaOp, err := h.operationsDb.GetUpgradeKymaOperationByID(lastOp.ID)
-		if err != nil {
-			return fmt.Errorf("while fetching upgrade kyma operation for instance %s: %w", instance.InstanceID, err)
-		}
-		h.converter.ApplyUpgradingKymaOperations(dto, []internal.UpgradeKymaOperation{*upgKymaOp}, 1)
+		h.converter.ApplyUpgradingKymaOperations(dto, []internal.UpgradeKymaOperation{{Operation: *lastOp}}, 1)
 
 	case internal.OperationTypeUpgradeCluster:
-		upgClusterOp, err := h.operationsDb.GetUpgradeClusterOperationByID(lastOp.ID)
-		if err != nil {
-			return fmt.Errorf("while fetching upgrade cluster operation for instance %s: %w", instance.InstanceID, err)
-		}
-		h.converter.ApplyUpgradingClusterOperations(dto, []internal.UpgradeClusterOperation{*upgClusterOp}, 1)
+		h.converter.ApplyUpgradingClusterOperations(dto, []internal.UpgradeClusterOperation{{Operation: *lastOp}}, 1)
 
 	case internal.OperationTypeUpdate:
-		updOp, err := h.operationsDb.GetUpdatingOperationByID(lastOp.ID)
-		if err != nil {
-			return fmt.Errorf("while fetching update operation for instance %s: %w", instance.InstanceID, err)
-		}
-		h.converter.ApplyUpdateOperations(dto, []internal.UpdatingOperation{*updOp}, 1)
+		h.converter.ApplyUpdateOperations(dto, []internal.UpdatingOperation{{Operation: *lastOp}}, 1)
 
 	default:
 		return fmt.Errorf("unsupported operation type: %s", lastOp.Type)
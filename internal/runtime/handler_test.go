@@ -48,6 +48,10 @@ func TestRuntimeHandler(t *testing.T) {
 		require.NoError(t, err)
 		err = instances.Insert(testInstance2)
 		require.NoError(t, err)
+		err = operations.InsertOperation(fixture.FixProvisioningOperation("op1", testID1))
+		require.NoError(t, err)
+		err = operations.InsertOperation(fixture.FixProvisioningOperation("op2", testID2))
+		require.NoError(t, err)
 
 		runtimeHandler := runtime.NewHandler(instances, operations, states, 2, "")
 
@@ -180,6 +184,55 @@ func TestRuntimeHandler(t *testing.T) {
 		assert.Equal(t, testID1, out.Data[0].InstanceID)
 	})
 
+	t.Run("test label filtering should work", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		states := memory.NewRuntimeStates()
+		testID1 := "Test1"
+		testID2 := "Test2"
+		testInstance1 := fixInstance(testID1, time.Now())
+		testInstance2 := fixInstance(testID2, time.Now().Add(time.Minute))
+		testInstance1.Labels = map[string]string{"team": "payments", "env": "staging"}
+		testInstance2.Labels = map[string]string{"team": "payments", "env": "production"}
+
+		require.NoError(t, instances.Insert(testInstance1))
+		require.NoError(t, instances.Insert(testInstance2))
+		require.NoError(t, operations.InsertOperation(fixture.FixProvisioningOperation("op1", testID1)))
+		require.NoError(t, operations.InsertOperation(fixture.FixProvisioningOperation("op2", testID2)))
+
+		runtimeHandler := runtime.NewHandler(instances, operations, states, 2, "")
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		req, err := http.NewRequest("GET", "/runtimes?label=team=payments&label=env=staging", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimesPage
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, out.TotalCount)
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, testID1, out.Data[0].InstanceID)
+		assert.Equal(t, testInstance1.Labels, out.Data[0].Labels)
+
+		// when
+		req, err = http.NewRequest("GET", "/runtimes?label=team", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
 	t.Run("test state filtering should work", func(t *testing.T) {
 		// given
 		operations := memory.NewOperation()
@@ -575,6 +628,138 @@ func TestRuntimeHandler(t *testing.T) {
 		assert.Equal(t, pkg.StateError, out.Data[0].Status.State)
 	})
 
+	t.Run("should return operations of deprovisioned runtime from the archive", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		states := memory.NewRuntimeStates()
+		testID := "Test1"
+		provisioningTime := time.Now().Add(-time.Hour)
+
+		provOp := fixture.FixProvisioningOperation(fixRandomID(), testID)
+		provOp.CreatedAt = provisioningTime
+		err := operations.InsertOperation(provOp)
+		require.NoError(t, err)
+		upgOp := fixture.FixUpgradeKymaOperation(fixRandomID(), testID)
+		upgOp.CreatedAt = provisioningTime.Add(time.Minute)
+		err = operations.InsertUpgradeKymaOperation(upgOp)
+		require.NoError(t, err)
+		deprovOp := fixture.FixDeprovisioningOperation(fixRandomID(), testID)
+		deprovOp.State = domain.Succeeded
+		deprovOp.CreatedAt = provisioningTime.Add(2 * time.Minute)
+		err = operations.InsertDeprovisioningOperation(deprovOp)
+		require.NoError(t, err)
+
+		err = operations.ArchiveOperations(testID, deprovOp.ID)
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(instances, operations, states, 2, "")
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		req, err := http.NewRequest("GET", fmt.Sprintf("/runtimes?state=%s&op_detail=%s", pkg.StateDeprovisioned, pkg.AllOperation), nil)
+		require.NoError(t, err)
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimesPage
+
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, testID, out.Data[0].InstanceID)
+		assert.Equal(t, provisioningTime.Unix(), out.Data[0].Status.CreatedAt.Unix())
+		assert.NotNil(t, out.Data[0].Status.Provisioning)
+		assert.NotNil(t, out.Data[0].Status.UpgradingKyma)
+		assert.Equal(t, 1, out.Data[0].Status.UpgradingKyma.Count)
+		assert.NotNil(t, out.Data[0].Status.Deprovisioning)
+		assert.Equal(t, deprovOp.UpdatedAt.Unix(), out.Data[0].Status.ModifiedAt.Unix())
+
+		// when
+		rr = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", fmt.Sprintf("/runtimes?state=%s&op_detail=%s", pkg.StateDeprovisioned, pkg.LastOperation), nil)
+		require.NoError(t, err)
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		out = pkg.RuntimesPage{}
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+
+		require.Len(t, out.Data, 1)
+		assert.Nil(t, out.Data[0].Status.Provisioning)
+		assert.Nil(t, out.Data[0].Status.UpgradingKyma)
+		assert.NotNil(t, out.Data[0].Status.Deprovisioning)
+	})
+
+	t.Run("should return paused state", func(t *testing.T) {
+		// given
+		operations := memory.NewOperation()
+		instances := memory.NewInstance(operations)
+		states := memory.NewRuntimeStates()
+		testID := "Test1"
+		testInstance := fixInstance(testID, time.Now())
+
+		err := instances.Insert(testInstance)
+		require.NoError(t, err)
+
+		provOp := fixture.FixProvisioningOperation(fixRandomID(), testID)
+		provOp.State = domain.InProgress
+		err = operations.InsertOperation(provOp)
+		require.NoError(t, err)
+		err = operations.UpsertOperationPause(internal.OperationPause{OperationID: provOp.ID, Paused: true, PausedAt: time.Now()})
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(instances, operations, states, 2, "")
+		router := mux.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		getRuntime := func() pkg.RuntimeDTO {
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/runtimes", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var out pkg.RuntimesPage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+			require.Len(t, out.Data, 1)
+			return out.Data[0]
+		}
+
+		// when
+		dto := getRuntime()
+
+		// then
+		assert.Equal(t, pkg.StatePaused, dto.Status.State)
+		assert.True(t, dto.Status.Provisioning.Paused)
+
+		// when
+		err = operations.UpsertOperationPause(internal.OperationPause{OperationID: provOp.ID, ResumedAt: time.Now()})
+		require.NoError(t, err)
+		err = operations.InsertProcessingPause(internal.ProcessingPause{OperationType: internal.OperationTypeProvision, PausedAt: time.Now()})
+		require.NoError(t, err)
+		dto = getRuntime()
+
+		// then
+		assert.Equal(t, pkg.StatePaused, dto.Status.State)
+
+		// when
+		err = operations.DeleteProcessingPause(internal.OperationTypeProvision)
+		require.NoError(t, err)
+		dto = getRuntime()
+
+		// then
+		assert.Equal(t, pkg.StateProvisioning, dto.Status.State)
+	})
+
 	t.Run("test kyma_config and cluster_config optional attributes", func(t *testing.T) {
 		// given
 		operations := memory.NewOperation()
@@ -131,6 +131,341 @@ func (_m *Operations) GetOperationByID(operationID string) (*internal.Operation,
 	return r0, r1
 }
 
+// InsertStepAttempt provides a mock function with given fields: attempt
+func (_m *Operations) InsertStepAttempt(attempt internal.StepAttempt) error {
+	ret := _m.Called(attempt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(internal.StepAttempt) error); ok {
+		r0 = rf(attempt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListStepAttemptsByOperationID provides a mock function with given fields: operationID
+func (_m *Operations) ListStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error) {
+	ret := _m.Called(operationID)
+
+	var r0 []internal.StepAttempt
+	if rf, ok := ret.Get(0).(func(string) []internal.StepAttempt); ok {
+		r0 = rf(operationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]internal.StepAttempt)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(operationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertProcessingPause provides a mock function with given fields: pause
+func (_m *Operations) InsertProcessingPause(pause internal.ProcessingPause) error {
+	ret := _m.Called(pause)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(internal.ProcessingPause) error); ok {
+		r0 = rf(pause)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetProcessingPause provides a mock function with given fields: operationType
+func (_m *Operations) GetProcessingPause(operationType internal.OperationType) (*internal.ProcessingPause, error) {
+	ret := _m.Called(operationType)
+
+	var r0 *internal.ProcessingPause
+	if rf, ok := ret.Get(0).(func(internal.OperationType) *internal.ProcessingPause); ok {
+		r0 = rf(operationType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*internal.ProcessingPause)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(internal.OperationType) error); ok {
+		r1 = rf(operationType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListProcessingPauses provides a mock function with given fields:
+func (_m *Operations) ListProcessingPauses() ([]internal.ProcessingPause, error) {
+	ret := _m.Called()
+
+	var r0 []internal.ProcessingPause
+	if rf, ok := ret.Get(0).(func() []internal.ProcessingPause); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]internal.ProcessingPause)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteProcessingPause provides a mock function with given fields: operationType
+func (_m *Operations) DeleteProcessingPause(operationType internal.OperationType) error {
+	ret := _m.Called(operationType)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(internal.OperationType) error); ok {
+		r0 = rf(operationType)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertOperationPause provides a mock function with given fields: pause
+func (_m *Operations) UpsertOperationPause(pause internal.OperationPause) error {
+	ret := _m.Called(pause)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(internal.OperationPause) error); ok {
+		r0 = rf(pause)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetOperationPause provides a mock function with given fields: operationID
+func (_m *Operations) GetOperationPause(operationID string) (*internal.OperationPause, error) {
+	ret := _m.Called(operationID)
+
+	var r0 *internal.OperationPause
+	if rf, ok := ret.Get(0).(func(string) *internal.OperationPause); ok {
+		r0 = rf(operationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*internal.OperationPause)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(operationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPausedOperations provides a mock function with given fields:
+func (_m *Operations) ListPausedOperations() ([]internal.OperationPause, error) {
+	ret := _m.Called()
+
+	var r0 []internal.OperationPause
+	if rf, ok := ret.Get(0).(func() []internal.OperationPause); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]internal.OperationPause)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AcquireLease provides a mock function with given fields: lease
+func (_m *Operations) AcquireLease(lease internal.Lease) (bool, error) {
+	ret := _m.Called(lease)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(internal.Lease) bool); ok {
+		r0 = rf(lease)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(internal.Lease) error); ok {
+		r1 = rf(lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseLease provides a mock function with given fields: id, owner
+func (_m *Operations) ReleaseLease(id string, owner string) error {
+	ret := _m.Called(id, owner)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(id, owner)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListExpiredLeases provides a mock function with given fields: kind, now
+func (_m *Operations) ListExpiredLeases(kind string, now time.Time) ([]internal.Lease, error) {
+	ret := _m.Called(kind, now)
+
+	var r0 []internal.Lease
+	if rf, ok := ret.Get(0).(func(string, time.Time) []internal.Lease); ok {
+		r0 = rf(kind, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]internal.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(kind, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListInstancesToArchive provides a mock function with given fields: finishedBefore, limit
+func (_m *Operations) ListInstancesToArchive(finishedBefore time.Time, limit int) ([]string, error) {
+	ret := _m.Called(finishedBefore, limit)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(time.Time, int) []string); ok {
+		r0 = rf(finishedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(finishedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ArchiveOperations provides a mock function with given fields: instanceID, summaryOperationID
+func (_m *Operations) ArchiveOperations(instanceID string, summaryOperationID string) error {
+	ret := _m.Called(instanceID, summaryOperationID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(instanceID, summaryOperationID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListArchivedOperationsByInstanceIDs provides a mock function with given fields: instanceIDs
+func (_m *Operations) ListArchivedOperationsByInstanceIDs(instanceIDs []string) ([]internal.Operation, error) {
+	ret := _m.Called(instanceIDs)
+
+	var r0 []internal.Operation
+	if rf, ok := ret.Get(0).(func([]string) []internal.Operation); ok {
+		r0 = rf(instanceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]internal.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(instanceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListArchivedStepAttemptsByOperationID provides a mock function with given fields: operationID
+func (_m *Operations) ListArchivedStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error) {
+	ret := _m.Called(operationID)
+
+	var r0 []internal.StepAttempt
+	if rf, ok := ret.Get(0).(func(string) []internal.StepAttempt); ok {
+		r0 = rf(operationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]internal.StepAttempt)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(operationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOperationByRequestIdentity provides a mock function with given fields: requestIdentity
+func (_m *Operations) GetOperationByRequestIdentity(requestIdentity string) (*internal.Operation, error) {
+	ret := _m.Called(requestIdentity)
+
+	var r0 *internal.Operation
+	if rf, ok := ret.Get(0).(func(string) *internal.Operation); ok {
+		r0 = rf(requestIdentity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*internal.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(requestIdentity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOperationByInstanceID provides a mock function with given fields: instanceID
 func (_m *Operations) GetOperationByInstanceID(instanceID string) (*internal.Operation, error) {
 	ret := _m.Called(instanceID)
@@ -19,6 +19,10 @@ type Config struct {
 	SSLRootCert string `envconfig:"optional"`
 
 	SecretKey string `envconfig:"optional"`
+	// EncryptionKeysFilePath is the path of the YAML file with encryption keys, the SecretKey is used if it is not set
+	EncryptionKeysFilePath string `envconfig:"optional"`
+	// AllowLegacyCFB allows decrypting payloads encrypted with AES-CFB, disable it once all data is re-encrypted with AES-GCM
+	AllowLegacyCFB bool `envconfig:"default=true"`
 
 	MaxOpenConns    int           `envconfig:"default=8"`
 	MaxIdleConns    int           `envconfig:"default=2"`
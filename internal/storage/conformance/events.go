@@ -0,0 +1,166 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewEvents returns an empty events storage, it is called for every test.
+// Events are tested separately, because the broker storage keeps them in a global instance.
+type NewEvents func(t *testing.T) storage.Events
+
+// RunEvents runs the conformance tests of events against the storage returned by the given function
+func RunEvents(t *testing.T, newEvents NewEvents) {
+	t.Run("should keep fields of the event", func(t *testing.T) {
+		// given
+		db := newEvents(t)
+		db.InsertEvent(events.WarningEventLevel, "step failed", "inst-1", "op-1", events.Fields{events.StepField: "Provision", events.RetryCountField: 3})
+		db.InsertEvent(events.InfoEventLevel, "without fields", "inst-1", "op-1", nil)
+
+		// when
+		got, err := db.ListEvents(events.EventFilter{})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, events.WarningEventLevel, got[0].Level)
+		assert.Equal(t, "inst-1", *got[0].InstanceID)
+		assert.Equal(t, "op-1", *got[0].OperationID)
+		assert.Equal(t, "Provision", got[0].Fields[events.StepField])
+		// numbers are kept as JSON, so they are read as float64
+		assert.EqualValues(t, 3, got[0].Fields[events.RetryCountField])
+		assert.Empty(t, got[1].Fields)
+	})
+
+	t.Run("should filter events by instance, operation and level", func(t *testing.T) {
+		// given
+		db := newEvents(t)
+		db.InsertEvent(events.InfoEventLevel, "first", "inst-1", "op-1", nil)
+		db.InsertEvent(events.WarningEventLevel, "second", "inst-1", "op-2", nil)
+		db.InsertEvent(events.ErrorEventLevel, "third", "inst-2", "op-3", nil)
+		db.InsertEvent(events.InfoEventLevel, "fourth", "inst-3", "op-4", nil)
+
+		for name, tc := range map[string]struct {
+			filter   events.EventFilter
+			expected []string
+		}{
+			"instances": {
+				filter:   events.EventFilter{InstanceIDs: []string{"inst-1", "inst-2"}},
+				expected: []string{"first", "second", "third"},
+			},
+			"operations": {
+				filter:   events.EventFilter{OperationIDs: []string{"op-2", "op-4"}},
+				expected: []string{"second", "fourth"},
+			},
+			"levels": {
+				filter:   events.EventFilter{Levels: []events.EventLevel{events.WarningEventLevel, events.ErrorEventLevel}},
+				expected: []string{"second", "third"},
+			},
+			"all criteria": {
+				filter: events.EventFilter{
+					InstanceIDs: []string{"inst-1"},
+					Levels:      []events.EventLevel{events.InfoEventLevel},
+				},
+				expected: []string{"first"},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// when
+				got, err := db.ListEvents(tc.filter)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, messages(got))
+			})
+		}
+	})
+
+	t.Run("should filter events by message ignoring case", func(t *testing.T) {
+		// given
+		db := newEvents(t)
+		db.InsertEvent(events.InfoEventLevel, "Provisioning started", "inst-1", "op-1", nil)
+		db.InsertEvent(events.InfoEventLevel, "100% done", "inst-1", "op-1", nil)
+		db.InsertEvent(events.InfoEventLevel, "step_1 done", "inst-1", "op-1", nil)
+		db.InsertEvent(events.InfoEventLevel, "step 1 done", "inst-1", "op-1", nil)
+
+		for message, expected := range map[string][]string{
+			"provisioning": {"Provisioning started"},
+			"% done":       {"100% done"},
+			"step_":        {"step_1 done"},
+			"DONE":         {"100% done", "step_1 done", "step 1 done"},
+		} {
+			// when
+			got, err := db.ListEvents(events.EventFilter{MessageContains: message})
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, expected, messages(got), message)
+		}
+	})
+
+	t.Run("should filter events by time range", func(t *testing.T) {
+		// given
+		db := newEvents(t)
+		db.InsertEvent(events.InfoEventLevel, "before", "inst-1", "op-1", nil)
+		time.Sleep(10 * time.Millisecond)
+		from := time.Now()
+		db.InsertEvent(events.InfoEventLevel, "inside", "inst-1", "op-1", nil)
+		time.Sleep(10 * time.Millisecond)
+		to := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		db.InsertEvent(events.InfoEventLevel, "after", "inst-1", "op-1", nil)
+
+		// when
+		got, err := db.ListEvents(events.EventFilter{From: from, To: to})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"inside"}, messages(got))
+
+		// when
+		got, err = db.ListEvents(events.EventFilter{From: from})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"inside", "after"}, messages(got))
+	})
+
+	t.Run("should list all events page by page", func(t *testing.T) {
+		// given
+		db := newEvents(t)
+		expected := []string{"1", "2", "3", "4", "5"}
+		for _, message := range expected {
+			db.InsertEvent(events.InfoEventLevel, message, "inst-1", "op-1", nil)
+		}
+		db.InsertEvent(events.InfoEventLevel, "other", "inst-2", "op-2", nil)
+
+		// when
+		var listed []string
+		filter := events.EventFilter{InstanceIDs: []string{"inst-1"}, Limit: 2}
+		for {
+			page, err := db.ListEvents(filter)
+			require.NoError(t, err)
+			listed = append(listed, messages(page)...)
+			if len(page) < filter.Limit {
+				break
+			}
+			filter.After = events.CursorOf(page[len(page)-1])
+		}
+
+		// then
+		assert.Equal(t, expected, listed)
+	})
+}
+
+func messages(list []events.EventDTO) []string {
+	messages := make([]string, 0, len(list))
+	for _, ev := range list {
+		messages = append(messages, ev.Message)
+	}
+	return messages
+}
@@ -0,0 +1,387 @@
+package conformance
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInstances(t *testing.T, newStorage NewStorage) {
+	t.Run("should insert and get instance with decrypted credentials", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		instance := fixture.FixInstance("inst-1")
+		instance.Parameters.ErsContext.SMOperatorCredentials = fixSMCredentials()
+		instance.Parameters.Parameters.Kubeconfig = fixKubeconfig
+		require.NoError(t, db.Instances().Insert(instance))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-1", "inst-1")))
+
+		// when
+		got, err := db.Instances().GetByID("inst-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, fixSMCredentials(), got.Parameters.ErsContext.SMOperatorCredentials)
+		assert.Equal(t, fixKubeconfig, got.Parameters.Parameters.Kubeconfig)
+		assert.Equal(t, instance.GlobalAccountID, got.GlobalAccountID)
+		assert.Equal(t, instance.RuntimeID, got.RuntimeID)
+
+		// when
+		listed, _, _, err := db.Instances().List(dbmodel.InstanceFilter{})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, fixSMCredentials(), listed[0].Parameters.ErsContext.SMOperatorCredentials)
+		assert.Equal(t, fixKubeconfig, listed[0].Parameters.Parameters.Kubeconfig)
+	})
+
+	t.Run("should return not found for not existing instance", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+
+		// when
+		_, err := db.Instances().GetByID("not-existing")
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should not insert instance with existing ID", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+
+		// when
+		err := db.Instances().Insert(fixture.FixInstance("inst-1"))
+
+		// then
+		assert.True(t, dberr.IsAlreadyExists(err))
+	})
+
+	t.Run("should not update instance with stale version", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		instance := fixture.FixInstance("inst-1")
+		require.NoError(t, db.Instances().Insert(instance))
+		updated, err := db.Instances().Update(instance)
+		require.NoError(t, err)
+		assert.Equal(t, instance.Version+1, updated.Version)
+
+		// when
+		instance.DashboardURL = "https://stale.example.com"
+		_, err = db.Instances().Update(instance)
+
+		// then
+		assert.True(t, dberr.IsConflict(err))
+	})
+
+	t.Run("should not update not existing instance", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+
+		// when
+		_, err := db.Instances().Update(fixture.FixInstance("not-existing"))
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should insert instances within quota", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		deleted := fixture.FixInstance("inst-deleted")
+		deleted.DeletedAt = time.Now()
+		otherPlan := fixture.FixInstance("inst-other-plan")
+		otherPlan.ServicePlanID = "other-plan"
+		require.NoError(t, db.Instances().Insert(deleted))
+		require.NoError(t, db.Instances().Insert(otherPlan))
+
+		// when
+		inserted := make(chan string, 5)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				ok, err := db.Instances().InsertWithinQuota(fixture.FixInstance(id), 2)
+				assert.NoError(t, err)
+				if ok {
+					inserted <- id
+				}
+			}(fmt.Sprintf("inst-%d", i))
+		}
+		wg.Wait()
+		close(inserted)
+
+		// then
+		var ids []string
+		for id := range inserted {
+			ids = append(ids, id)
+		}
+		assert.Len(t, ids, 2)
+		instances, err := db.Instances().GetNumberOfInstancesByPlanForGlobalAccountID(deleted.GlobalAccountID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, instances[deleted.ServicePlanID])
+		assert.Equal(t, 1, instances["other-plan"])
+	})
+
+	t.Run("should insert, update and list instances without encryption", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		instance := fixture.FixInstance("inst-1")
+		instance.Parameters.ErsContext.SMOperatorCredentials = fixSMCredentials()
+		require.NoError(t, db.Instances().InsertWithoutEncryption(instance))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-1", "inst-1")))
+
+		// when
+		err := db.Instances().InsertWithoutEncryption(instance)
+
+		// then
+		assert.True(t, dberr.IsAlreadyExists(err))
+
+		// when
+		instance.DashboardURL = "https://updated.example.com"
+		updated, err := db.Instances().UpdateWithoutEncryption(instance)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, instance.Version+1, updated.Version)
+
+		// when
+		_, err = db.Instances().UpdateWithoutEncryption(instance)
+
+		// then
+		assert.True(t, dberr.IsConflict(err))
+
+		// when
+		listed, count, totalCount, err := db.Instances().ListWithoutDecryption(dbmodel.InstanceFilter{})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 1, totalCount)
+		assert.Equal(t, "https://updated.example.com", listed[0].DashboardURL)
+		assert.Equal(t, fixSMCredentials(), listed[0].Parameters.ErsContext.SMOperatorCredentials)
+	})
+
+	t.Run("should count not deleted instances per global account", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		for id, globalAccountID := range map[string]string{"inst-1": "ga-1", "inst-2": "ga-1", "inst-3": "ga-2"} {
+			instance := fixture.FixInstance(id)
+			instance.GlobalAccountID = globalAccountID
+			require.NoError(t, db.Instances().Insert(instance))
+		}
+		deleted := fixture.FixInstance("inst-deleted")
+		deleted.GlobalAccountID = "ga-2"
+		deleted.DeletedAt = time.Now()
+		require.NoError(t, db.Instances().Insert(deleted))
+
+		// when
+		stats, err := db.Instances().GetInstanceStats()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats.TotalNumberOfInstances)
+		assert.Equal(t, map[string]int{"ga-1": 2, "ga-2": 1}, stats.PerGlobalAccountID)
+	})
+
+	t.Run("should count instances per license type of the last operation", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		createdAt := baseTime()
+		insertInstance := func(id string, deleted bool, licenseTypes ...string) {
+			instance := fixture.FixInstance(id)
+			if deleted {
+				instance.DeletedAt = createdAt
+			}
+			require.NoError(t, db.Instances().Insert(instance))
+			for i, licenseType := range licenseTypes {
+				op := fixture.FixOperation(fmt.Sprintf("%s-op-%d", id, i), id, internal.OperationTypeProvision)
+				op.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+				op.ProvisioningParameters.ErsContext.LicenseType = ptr.String(licenseType)
+				require.NoError(t, db.Operations().InsertOperation(op))
+			}
+		}
+		insertInstance("inst-1", false, "CUSTOMER")
+		insertInstance("inst-2", false, "PARTNER", "CUSTOMER")
+		insertInstance("inst-3", false, "CUSTOMER", "PARTNER")
+		insertInstance("inst-4", false)
+		insertInstance("inst-deleted", true, "PARTNER")
+		canceled := fixture.FixUpdatingOperation("inst-1-canceled", "inst-1")
+		canceled.CreatedAt = createdAt.Add(time.Hour)
+		canceled.State = orchestration.Canceled
+		canceled.ProvisioningParameters.ErsContext.LicenseType = ptr.String("PARTNER")
+		require.NoError(t, db.Operations().InsertUpdatingOperation(canceled))
+
+		// when
+		stats, err := db.Instances().GetERSContextStats()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"CUSTOMER": 2, "PARTNER": 1}, stats.LicenseType)
+	})
+
+	t.Run("should filter instances", func(t *testing.T) {
+		db := newStorage(t)
+		insertFilteredInstances(t, db)
+
+		for tn, tc := range map[string]struct {
+			filter   dbmodel.InstanceFilter
+			expected []string
+		}{
+			"no filter":                       {filter: dbmodel.InstanceFilter{}, expected: []string{"inst-1", "inst-2", "inst-3", "inst-4", "inst-5"}},
+			"global accounts":                 {filter: dbmodel.InstanceFilter{GlobalAccountIDs: []string{"ga-1"}}, expected: []string{"inst-1", "inst-3"}},
+			"subscription global accounts":    {filter: dbmodel.InstanceFilter{SubscriptionGlobalAccountIDs: []string{"sga-2"}}, expected: []string{"inst-2"}},
+			"subaccounts":                     {filter: dbmodel.InstanceFilter{SubAccountIDs: []string{"sa-1", "sa-4"}}, expected: []string{"inst-1", "inst-4"}},
+			"instance IDs":                    {filter: dbmodel.InstanceFilter{InstanceIDs: []string{"inst-2", "inst-5", "inst-6"}}, expected: []string{"inst-2", "inst-5"}},
+			"runtime IDs":                     {filter: dbmodel.InstanceFilter{RuntimeIDs: []string{"runtime-inst-3"}}, expected: []string{"inst-3"}},
+			"regions":                         {filter: dbmodel.InstanceFilter{Regions: []string{"us-east"}}, expected: []string{"inst-2", "inst-4"}},
+			"plan names":                      {filter: dbmodel.InstanceFilter{Plans: []string{"trial"}}, expected: []string{"inst-3"}},
+			"plan IDs":                        {filter: dbmodel.InstanceFilter{PlanIDs: []string{"plan-aws"}}, expected: []string{"inst-2", "inst-5"}},
+			"labels":                          {filter: dbmodel.InstanceFilter{Labels: map[string]string{"env": "dev"}}, expected: []string{"inst-1", "inst-3"}},
+			"all labels":                      {filter: dbmodel.InstanceFilter{Labels: map[string]string{"env": "dev", "team": "a"}}, expected: []string{"inst-1"}},
+			"expired":                         {filter: dbmodel.InstanceFilter{Expired: ptr.Bool(true)}, expected: []string{"inst-2"}},
+			"not expired":                     {filter: dbmodel.InstanceFilter{Expired: ptr.Bool(false)}, expected: []string{"inst-1", "inst-3", "inst-4", "inst-5"}},
+			"deletion attempted":              {filter: dbmodel.InstanceFilter{DeletionAttempted: ptr.Bool(true)}, expected: []string{"inst-3", "inst-5"}},
+			"deletion not attempted":          {filter: dbmodel.InstanceFilter{DeletionAttempted: ptr.Bool(false)}, expected: []string{"inst-1", "inst-2", "inst-4"}},
+			"compensation failed":             {filter: dbmodel.InstanceFilter{CompensationFailed: ptr.Bool(true)}, expected: []string{"inst-4"}},
+			"compensation not failed":         {filter: dbmodel.InstanceFilter{CompensationFailed: ptr.Bool(false)}, expected: []string{"inst-1", "inst-2", "inst-3", "inst-5"}},
+			"shoots":                          {filter: dbmodel.InstanceFilter{Shoots: []string{"Shoot-inst-1", "Shoot-inst-4"}}, expected: []string{"inst-1", "inst-4"}},
+			"succeeded state":                 {filter: dbmodel.InstanceFilter{States: []dbmodel.InstanceState{dbmodel.InstanceSucceeded}}, expected: []string{"inst-1"}},
+			"updating state":                  {filter: dbmodel.InstanceFilter{States: []dbmodel.InstanceState{dbmodel.InstanceUpdating}}, expected: []string{"inst-2"}},
+			"deprovisioning state":            {filter: dbmodel.InstanceFilter{States: []dbmodel.InstanceState{dbmodel.InstanceDeprovisioning}}, expected: []string{"inst-3"}},
+			"failed state":                    {filter: dbmodel.InstanceFilter{States: []dbmodel.InstanceState{dbmodel.InstanceFailed}}, expected: []string{"inst-4"}},
+			"deprovisioned state":             {filter: dbmodel.InstanceFilter{States: []dbmodel.InstanceState{dbmodel.InstanceDeprovisioned}}, expected: []string{"inst-5"}},
+			"not deprovisioned state":         {filter: dbmodel.InstanceFilter{States: []dbmodel.InstanceState{dbmodel.InstanceNotDeprovisioned}}, expected: []string{"inst-1", "inst-2", "inst-3", "inst-4"}},
+			"multiple states":                 {filter: dbmodel.InstanceFilter{States: []dbmodel.InstanceState{dbmodel.InstanceSucceeded, dbmodel.InstanceFailed}}, expected: []string{"inst-1", "inst-4"}},
+			"multiple filters":                {filter: dbmodel.InstanceFilter{GlobalAccountIDs: []string{"ga-1", "ga-2"}, Regions: []string{"eu-west"}}, expected: []string{"inst-1", "inst-3"}},
+			"not matching filter":             {filter: dbmodel.InstanceFilter{GlobalAccountIDs: []string{"not-existing"}}, expected: []string{}},
+			"instance without last operation": {filter: dbmodel.InstanceFilter{InstanceIDs: []string{"inst-6"}}, expected: []string{}},
+		} {
+			t.Run(tn, func(t *testing.T) {
+				// when
+				instances, count, totalCount, err := db.Instances().List(tc.filter)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, instanceIDs(instances))
+				assert.Equal(t, len(tc.expected), count)
+				assert.Equal(t, len(tc.expected), totalCount)
+			})
+		}
+	})
+
+	t.Run("should paginate instances", func(t *testing.T) {
+		db := newStorage(t)
+		createdAt := baseTime()
+		for i := 1; i <= 5; i++ {
+			id := fmt.Sprintf("inst-%d", i)
+			instance := fixture.FixInstance(id)
+			instance.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, db.Instances().Insert(instance))
+			require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-"+id, id)))
+		}
+
+		for tn, tc := range map[string]struct {
+			page, pageSize int
+			expected       []string
+		}{
+			"first page":  {page: 1, pageSize: 2, expected: []string{"inst-1", "inst-2"}},
+			"second page": {page: 2, pageSize: 2, expected: []string{"inst-3", "inst-4"}},
+			"last page":   {page: 3, pageSize: 2, expected: []string{"inst-5"}},
+			"empty page":  {page: 4, pageSize: 2, expected: []string{}},
+		} {
+			t.Run(tn, func(t *testing.T) {
+				// when
+				instances, count, totalCount, err := db.Instances().List(dbmodel.InstanceFilter{Page: tc.page, PageSize: tc.pageSize})
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, instanceIDs(instances))
+				assert.Equal(t, len(tc.expected), count)
+				assert.Equal(t, 5, totalCount)
+			})
+		}
+	})
+}
+
+// insertFilteredInstances inserts instances which differ in every filtered attribute:
+//
+//	inst-1 - succeeded provisioning, labels env=dev and team=a
+//	inst-2 - update in progress followed by a canceled update, expired
+//	inst-3 - deprovisioning in progress, deletion attempted, label env=dev
+//	inst-4 - failed provisioning
+//	inst-5 - succeeded deprovisioning, deletion attempted
+//	inst-6 - no operations, it is never listed
+func insertFilteredInstances(t *testing.T, db storage.BrokerStorage) {
+	createdAt := baseTime()
+	fixInstance := func(idx int, globalAccountID, region, planID, planName string) internal.Instance {
+		instance := fixture.FixInstance(fmt.Sprintf("inst-%d", idx))
+		instance.GlobalAccountID = globalAccountID
+		instance.SubscriptionGlobalAccountID = fmt.Sprintf("sga-%d", idx)
+		instance.SubAccountID = fmt.Sprintf("sa-%d", idx)
+		instance.ProviderRegion = region
+		instance.ServicePlanID = planID
+		instance.ServicePlanName = planName
+		instance.CreatedAt = createdAt.Add(time.Duration(idx) * time.Minute)
+		return instance
+	}
+	fixOperation := func(instanceID, id string, opType internal.OperationType, state domain.LastOperationState, after time.Duration) internal.Operation {
+		op := fixture.FixOperation(id, instanceID, opType)
+		op.State = state
+		op.CreatedAt = createdAt.Add(after)
+		op.UpdatedAt = op.CreatedAt.Add(time.Minute)
+		return op
+	}
+
+	inst1 := fixInstance(1, "ga-1", "eu-west", "plan-azure", "azure")
+	inst1.Labels = map[string]string{"env": "dev", "team": "a"}
+	inst2 := fixInstance(2, "ga-2", "us-east", "plan-aws", "aws")
+	inst2.ExpiredAt = ptr.Time(createdAt)
+	inst3 := fixInstance(3, "ga-1", "eu-west", "plan-trial", "trial")
+	inst3.Labels = map[string]string{"env": "dev"}
+	inst3.DeletedAt = createdAt.Add(time.Hour)
+	inst4 := fixInstance(4, "ga-3", "us-east", "plan-azure", "azure")
+	inst4.CompensationFailedAt = ptr.Time(createdAt.Add(time.Hour))
+	inst5 := fixInstance(5, "ga-3", "ap-south", "plan-aws", "aws")
+	inst5.DeletedAt = createdAt.Add(time.Hour)
+	inst6 := fixInstance(6, "ga-1", "eu-west", "plan-azure", "azure")
+	for _, instance := range []internal.Instance{inst1, inst2, inst3, inst4, inst5, inst6} {
+		require.NoError(t, db.Instances().Insert(instance))
+	}
+
+	operations := []internal.Operation{
+		fixOperation("inst-1", "op-1-provision", internal.OperationTypeProvision, domain.Succeeded, time.Minute),
+		fixOperation("inst-2", "op-2-provision", internal.OperationTypeProvision, domain.Succeeded, time.Minute),
+		fixOperation("inst-3", "op-3-provision", internal.OperationTypeProvision, domain.Succeeded, time.Minute),
+		fixOperation("inst-3", "op-3-deprovision", internal.OperationTypeDeprovision, domain.InProgress, 2*time.Minute),
+		fixOperation("inst-4", "op-4-provision", internal.OperationTypeProvision, domain.Failed, time.Minute),
+		fixOperation("inst-5", "op-5-provision", internal.OperationTypeProvision, domain.Succeeded, time.Minute),
+		fixOperation("inst-5", "op-5-deprovision", internal.OperationTypeDeprovision, domain.Succeeded, 2*time.Minute),
+	}
+	for _, op := range operations {
+		require.NoError(t, db.Operations().InsertOperation(op))
+	}
+	updates := []internal.UpdatingOperation{
+		{Operation: fixOperation("inst-2", "op-2-update", internal.OperationTypeUpdate, domain.InProgress, 2*time.Minute)},
+		{Operation: fixOperation("inst-2", "op-2-canceled", internal.OperationTypeUpdate, orchestration.Canceled, 3*time.Minute)},
+	}
+	for _, op := range updates {
+		require.NoError(t, db.Operations().InsertUpdatingOperation(op))
+	}
+}
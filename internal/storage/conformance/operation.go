@@ -0,0 +1,384 @@
+package conformance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOperations(t *testing.T, newStorage NewStorage) {
+	t.Run("should insert and get operation with decrypted credentials", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+		operation.ProvisioningParameters.ErsContext.SMOperatorCredentials = fixSMCredentials()
+		operation.ProvisioningParameters.Parameters.Kubeconfig = fixKubeconfig
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		// when
+		got, err := db.Operations().GetOperationByID("op-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, fixSMCredentials(), got.ProvisioningParameters.ErsContext.SMOperatorCredentials)
+		assert.Equal(t, fixKubeconfig, got.ProvisioningParameters.Parameters.Kubeconfig)
+		assert.Equal(t, operation.InstanceID, got.InstanceID)
+		assert.Equal(t, operation.Type, got.Type)
+		assert.Equal(t, operation.State, got.State)
+
+		// when
+		listed, err := db.Operations().ListOperationsByInstanceID("inst-1")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, fixSMCredentials(), listed[0].ProvisioningParameters.ErsContext.SMOperatorCredentials)
+		assert.Equal(t, fixKubeconfig, listed[0].ProvisioningParameters.Parameters.Kubeconfig)
+	})
+
+	t.Run("should return not found for not existing operation", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+
+		// when
+		_, err := db.Operations().GetOperationByID("not-existing")
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should not insert operation with existing ID", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-1", "inst-1")))
+
+		// when
+		err := db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-1", "inst-2"))
+
+		// then
+		assert.True(t, dberr.IsAlreadyExists(err))
+	})
+
+	t.Run("should not insert operation with existing request identity", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		first := fixture.FixProvisioningOperation("op-1", "inst-1")
+		first.RequestIdentity = "request-1"
+		require.NoError(t, db.Operations().InsertOperation(first))
+		second := fixture.FixDeprovisioningOperation("op-2", "inst-1")
+		second.RequestIdentity = "request-1"
+
+		// when
+		err := db.Operations().InsertDeprovisioningOperation(second)
+
+		// then
+		assert.True(t, dberr.IsAlreadyExists(err))
+		got, err := db.Operations().GetOperationByRequestIdentity("request-1")
+		require.NoError(t, err)
+		assert.Equal(t, "op-1", got.ID)
+	})
+
+	t.Run("should not update operation with stale version", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+		operation.State = domain.InProgress
+		require.NoError(t, db.Operations().InsertOperation(operation))
+		updated, err := db.Operations().UpdateOperation(operation)
+		require.NoError(t, err)
+		assert.Equal(t, operation.Version+1, updated.Version)
+
+		// when
+		operation.Description = "stale"
+		_, err = db.Operations().UpdateOperation(operation)
+
+		// then
+		assert.True(t, dberr.IsConflict(err))
+		got, err := db.Operations().GetOperationByID("op-1")
+		require.NoError(t, err)
+		assert.Equal(t, updated.Description, got.Description)
+	})
+
+	t.Run("should not update updating operation with stale version", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		operation := fixture.FixUpdatingOperation("op-1", "inst-1")
+		operation.State = domain.InProgress
+		require.NoError(t, db.Operations().InsertUpdatingOperation(operation))
+		_, err := db.Operations().UpdateUpdatingOperation(operation)
+		require.NoError(t, err)
+
+		// when
+		_, err = db.Operations().UpdateOperation(operation.Operation)
+
+		// then
+		assert.True(t, dberr.IsConflict(err))
+	})
+
+	t.Run("should return the last operation which is not pending or canceled", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		createdAt := baseTime()
+		provisioning := fixture.FixProvisioningOperation("op-1", "inst-1")
+		provisioning.CreatedAt = createdAt
+		require.NoError(t, db.Operations().InsertOperation(provisioning))
+		update := fixture.FixUpdatingOperation("op-2", "inst-1")
+		update.CreatedAt = createdAt.Add(time.Minute)
+		update.State = orchestration.Canceled
+		require.NoError(t, db.Operations().InsertUpdatingOperation(update))
+		upgrade := fixture.FixUpgradeKymaOperation("op-3", "inst-1")
+		upgrade.CreatedAt = createdAt.Add(2 * time.Minute)
+		upgrade.State = orchestration.Pending
+		require.NoError(t, db.Operations().InsertUpgradeKymaOperation(upgrade))
+
+		// when
+		last, err := db.Operations().GetLastOperation("inst-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "op-1", last.ID)
+
+		// when
+		_, err = db.Operations().GetLastOperation("inst-2")
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should list operations of the instance starting with the latest", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		createdAt := baseTime()
+		provisioning := fixture.FixProvisioningOperation("op-1", "inst-1")
+		provisioning.CreatedAt = createdAt
+		require.NoError(t, db.Operations().InsertOperation(provisioning))
+		update := fixture.FixUpdatingOperation("op-2", "inst-1")
+		update.CreatedAt = createdAt.Add(time.Minute)
+		require.NoError(t, db.Operations().InsertUpdatingOperation(update))
+		deprovisioning := fixture.FixDeprovisioningOperation("op-3", "inst-1")
+		deprovisioning.CreatedAt = createdAt.Add(2 * time.Minute)
+		require.NoError(t, db.Operations().InsertDeprovisioningOperation(deprovisioning))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-4", "inst-2")))
+
+		// when
+		operations, err := db.Operations().ListOperationsByInstanceID("inst-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"op-3", "op-2", "op-1"}, operationIDs(operations))
+
+		// when
+		operations, err = db.Operations().ListOperationsByInstanceID("inst-3")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, operations)
+	})
+
+	t.Run("should list operations created or updated in the time range", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		from := baseTime()
+		to := from.Add(time.Hour)
+		insert := func(id string, createdAt, updatedAt time.Time) {
+			op := fixture.FixOperation(id, "inst-1", internal.OperationTypeProvision)
+			op.CreatedAt = createdAt
+			op.UpdatedAt = updatedAt
+			require.NoError(t, db.Operations().InsertOperation(op))
+		}
+		insert("op-created", from.Add(time.Minute), to.Add(time.Hour))
+		insert("op-updated", from.Add(-time.Hour), from.Add(time.Minute))
+		insert("op-within", from, to)
+		insert("op-before", from.Add(-2*time.Hour), from.Add(-time.Hour))
+		insert("op-after", to.Add(time.Minute), to.Add(time.Hour))
+		update := fixture.FixUpdatingOperation("op-update", "inst-1")
+		update.CreatedAt = from.Add(2 * time.Minute)
+		update.UpdatedAt = from.Add(3 * time.Minute)
+		require.NoError(t, db.Operations().InsertUpdatingOperation(update))
+
+		// when
+		operations, err := db.Operations().ListOperationsInTimeRange(from, to)
+
+		// then
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"op-created", "op-updated", "op-within", "op-update"}, operationIDs(operations))
+	})
+
+	t.Run("should filter and paginate operations", func(t *testing.T) {
+		db := newStorage(t)
+		createdAt := baseTime()
+		for i := 1; i <= 6; i++ {
+			op := fixture.FixProvisioningOperation(fmt.Sprintf("op-%d", i), fmt.Sprintf("inst-%d", (i+1)/2))
+			op.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+			if i%2 == 0 {
+				op.State = domain.Failed
+			}
+			require.NoError(t, db.Operations().InsertOperation(op))
+		}
+
+		for tn, tc := range map[string]struct {
+			filter     dbmodel.OperationFilter
+			expected   []string
+			totalCount int
+		}{
+			"no filter":    {filter: dbmodel.OperationFilter{}, expected: []string{"op-1", "op-2", "op-3", "op-4", "op-5", "op-6"}, totalCount: 6},
+			"states":       {filter: dbmodel.OperationFilter{States: []string{string(domain.Failed)}}, expected: []string{"op-2", "op-4", "op-6"}, totalCount: 3},
+			"instance IDs": {filter: dbmodel.OperationFilter{InstanceFilter: &dbmodel.InstanceFilter{InstanceIDs: []string{"inst-1", "inst-3"}}}, expected: []string{"op-1", "op-2", "op-5", "op-6"}, totalCount: 4},
+			"states and instance IDs": {
+				filter:     dbmodel.OperationFilter{States: []string{string(domain.Succeeded)}, InstanceFilter: &dbmodel.InstanceFilter{InstanceIDs: []string{"inst-2"}}},
+				expected:   []string{"op-3"},
+				totalCount: 1,
+			},
+			"first page":          {filter: dbmodel.OperationFilter{Page: 1, PageSize: 4}, expected: []string{"op-1", "op-2", "op-3", "op-4"}, totalCount: 6},
+			"second page":         {filter: dbmodel.OperationFilter{Page: 2, PageSize: 4}, expected: []string{"op-5", "op-6"}, totalCount: 6},
+			"page of states":      {filter: dbmodel.OperationFilter{Page: 2, PageSize: 2, States: []string{string(domain.Succeeded)}}, expected: []string{"op-5"}, totalCount: 3},
+			"not matching filter": {filter: dbmodel.OperationFilter{States: []string{string(domain.InProgress)}}, expected: []string{}, totalCount: 0},
+		} {
+			t.Run(tn, func(t *testing.T) {
+				// when
+				operations, count, totalCount, err := db.Operations().ListOperations(tc.filter)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, operationIDs(operations))
+				assert.Equal(t, len(tc.expected), count)
+				assert.Equal(t, tc.totalCount, totalCount)
+			})
+		}
+	})
+
+	t.Run("should list instances to archive", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		finishedBefore := baseTime().Add(10 * time.Hour)
+		insert := func(op internal.Operation, createdAt time.Duration) {
+			op.CreatedAt = baseTime().Add(createdAt)
+			op.UpdatedAt = op.CreatedAt.Add(time.Minute)
+			require.NoError(t, db.Operations().InsertOperation(op))
+		}
+		deprovisioning := func(id, instanceID string, state domain.LastOperationState) internal.Operation {
+			op := fixture.FixDeprovisioningOperationAsOperation(id, instanceID)
+			op.State = state
+			return op
+		}
+		for _, instanceID := range []string{"inst-deprovisioned", "inst-deprovisioned-later", "inst-suspended", "inst-deprovisioning-failed", "inst-provisioned-again", "inst-recent", "inst-in-progress", "inst-archived"} {
+			insert(fixture.FixProvisioningOperation("prov-"+instanceID, instanceID), 0)
+		}
+		insert(deprovisioning("deprov-inst-deprovisioned", "inst-deprovisioned", domain.Succeeded), 2*time.Hour)
+		insert(deprovisioning("deprov-inst-deprovisioned-later", "inst-deprovisioned-later", domain.Succeeded), 3*time.Hour)
+		suspension := fixture.FixSuspensionOperationAsOperation("deprov-inst-suspended", "inst-suspended")
+		suspension.State = domain.Succeeded
+		insert(suspension, time.Hour)
+		insert(deprovisioning("deprov-inst-deprovisioning-failed", "inst-deprovisioning-failed", domain.Failed), time.Hour)
+		insert(deprovisioning("deprov-inst-provisioned-again", "inst-provisioned-again", domain.Succeeded), time.Hour)
+		insert(fixture.FixProvisioningOperation("prov-again-inst-provisioned-again", "inst-provisioned-again"), 2*time.Hour)
+		insert(deprovisioning("deprov-inst-recent", "inst-recent", domain.Succeeded), 11*time.Hour)
+		update := fixture.FixOperation("update-inst-in-progress", "inst-in-progress", internal.OperationTypeUpdate)
+		update.State = domain.InProgress
+		insert(update, time.Hour)
+		insert(deprovisioning("deprov-inst-in-progress", "inst-in-progress", domain.Succeeded), 2*time.Hour)
+		insert(deprovisioning("deprov-inst-archived", "inst-archived", domain.Succeeded), time.Hour)
+		require.NoError(t, db.Operations().ArchiveOperations("inst-archived", "deprov-inst-archived"))
+
+		// when
+		instanceIDs, err := db.Operations().ListInstancesToArchive(finishedBefore, 10)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"inst-deprovisioned", "inst-deprovisioned-later"}, instanceIDs)
+
+		// when
+		instanceIDs, err = db.Operations().ListInstancesToArchive(finishedBefore, 1)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"inst-deprovisioned"}, instanceIDs)
+	})
+
+	t.Run("should move step attempts of archived operations to the archive", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		startedAt := baseTime()
+		provisioning := fixture.FixProvisioningOperation("op-prov", "inst-1")
+		provisioning.CreatedAt = baseTime()
+		require.NoError(t, db.Operations().InsertOperation(provisioning))
+		deprovisioning := fixture.FixDeprovisioningOperationAsOperation("op-deprov", "inst-1")
+		deprovisioning.State = domain.Succeeded
+		deprovisioning.CreatedAt = baseTime().Add(time.Hour)
+		require.NoError(t, db.Operations().InsertOperation(deprovisioning))
+		for _, operationID := range []string{"op-prov", "op-deprov"} {
+			require.NoError(t, db.Operations().InsertStepAttempt(internal.StepAttempt{OperationID: operationID, StepName: "Starting", StartedAt: startedAt}))
+		}
+
+		// when
+		err := db.Operations().ArchiveOperations("inst-1", "op-deprov")
+
+		// then
+		require.NoError(t, err)
+		attempts, err := db.Operations().ListStepAttemptsByOperationID("op-prov")
+		require.NoError(t, err)
+		assert.Empty(t, attempts)
+		attempts, err = db.Operations().ListArchivedStepAttemptsByOperationID("op-prov")
+		require.NoError(t, err)
+		require.Len(t, attempts, 1)
+		assert.Equal(t, "Starting", attempts[0].StepName)
+		assert.Equal(t, startedAt, attempts[0].StartedAt.UTC())
+		attempts, err = db.Operations().ListStepAttemptsByOperationID("op-deprov")
+		require.NoError(t, err)
+		assert.Len(t, attempts, 1)
+		attempts, err = db.Operations().ListArchivedStepAttemptsByOperationID("op-deprov")
+		require.NoError(t, err)
+		assert.Empty(t, attempts)
+	})
+
+	t.Run("should store operation pauses", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		pausedAt := baseTime()
+
+		// when
+		_, err := db.Operations().GetOperationPause("op-1")
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+
+		// when
+		require.NoError(t, db.Operations().UpsertOperationPause(internal.OperationPause{OperationID: "op-1", Paused: true, PausedAt: pausedAt}))
+		require.NoError(t, db.Operations().UpsertOperationPause(internal.OperationPause{OperationID: "op-2", Paused: true, PausedAt: pausedAt}))
+		require.NoError(t, db.Operations().UpsertOperationPause(internal.OperationPause{OperationID: "op-2", PausedAt: pausedAt, ResumedAt: pausedAt.Add(time.Hour)}))
+
+		// then
+		got, err := db.Operations().GetOperationPause("op-2")
+		require.NoError(t, err)
+		assert.False(t, got.Paused)
+		assert.True(t, pausedAt.Add(time.Hour).Equal(got.ResumedAt))
+		paused, err := db.Operations().ListPausedOperations()
+		require.NoError(t, err)
+		require.Len(t, paused, 1)
+		assert.Equal(t, "op-1", paused[0].OperationID)
+		assert.True(t, pausedAt.Equal(paused[0].PausedAt))
+	})
+
+	t.Run("should list operations of empty storage", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+
+		// when
+		operations, count, totalCount, err := db.Operations().ListOperations(dbmodel.OperationFilter{Page: 1, PageSize: 10})
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, operations)
+		assert.Zero(t, count)
+		assert.Zero(t, totalCount)
+	})
+}
@@ -0,0 +1,106 @@
+package conformance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/orchestration"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrchestrations(t *testing.T, newStorage NewStorage) {
+	t.Run("should insert, update and get orchestration", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		o := fixture.FixOrchestration("orch-1")
+		o.Type = orchestration.UpgradeKymaOrchestration
+		o.State = orchestration.InProgress
+		require.NoError(t, db.Orchestrations().Insert(o))
+
+		// when
+		o.State = orchestration.Succeeded
+		o.Description = "done"
+		err := db.Orchestrations().Update(o)
+
+		// then
+		require.NoError(t, err)
+		got, err := db.Orchestrations().GetByID("orch-1")
+		require.NoError(t, err)
+		assert.Equal(t, orchestration.Succeeded, got.State)
+		assert.Equal(t, "done", got.Description)
+		assert.Equal(t, orchestration.UpgradeKymaOrchestration, got.Type)
+	})
+
+	t.Run("should not insert orchestration with existing ID", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		require.NoError(t, db.Orchestrations().Insert(fixture.FixOrchestration("orch-1")))
+
+		// when
+		err := db.Orchestrations().Insert(fixture.FixOrchestration("orch-1"))
+
+		// then
+		assert.True(t, dberr.IsAlreadyExists(err))
+	})
+
+	t.Run("should return not found for not existing orchestration", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+
+		// when
+		_, err := db.Orchestrations().GetByID("not-existing")
+		updateErr := db.Orchestrations().Update(fixture.FixOrchestration("not-existing"))
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+		assert.True(t, dberr.IsNotFound(updateErr))
+	})
+
+	t.Run("should filter and paginate orchestrations", func(t *testing.T) {
+		db := newStorage(t)
+		createdAt := baseTime()
+		for i := 1; i <= 5; i++ {
+			o := fixture.FixOrchestration(fmt.Sprintf("orch-%d", i))
+			o.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+			o.Type = orchestration.UpgradeKymaOrchestration
+			if i > 3 {
+				o.Type = orchestration.UpgradeClusterOrchestration
+			}
+			o.State = orchestration.Succeeded
+			if i%2 == 0 {
+				o.State = orchestration.Failed
+			}
+			require.NoError(t, db.Orchestrations().Insert(o))
+		}
+
+		for tn, tc := range map[string]struct {
+			filter     dbmodel.OrchestrationFilter
+			expected   []string
+			totalCount int
+		}{
+			"no filter":           {filter: dbmodel.OrchestrationFilter{}, expected: []string{"orch-1", "orch-2", "orch-3", "orch-4", "orch-5"}, totalCount: 5},
+			"types":               {filter: dbmodel.OrchestrationFilter{Types: []string{string(orchestration.UpgradeClusterOrchestration)}}, expected: []string{"orch-4", "orch-5"}, totalCount: 2},
+			"states":              {filter: dbmodel.OrchestrationFilter{States: []string{orchestration.Failed}}, expected: []string{"orch-2", "orch-4"}, totalCount: 2},
+			"types and states":    {filter: dbmodel.OrchestrationFilter{Types: []string{string(orchestration.UpgradeKymaOrchestration)}, States: []string{orchestration.Succeeded}}, expected: []string{"orch-1", "orch-3"}, totalCount: 2},
+			"first page":          {filter: dbmodel.OrchestrationFilter{Page: 1, PageSize: 3}, expected: []string{"orch-1", "orch-2", "orch-3"}, totalCount: 5},
+			"second page":         {filter: dbmodel.OrchestrationFilter{Page: 2, PageSize: 3}, expected: []string{"orch-4", "orch-5"}, totalCount: 5},
+			"not matching filter": {filter: dbmodel.OrchestrationFilter{States: []string{orchestration.Canceled}}, expected: []string{}, totalCount: 0},
+		} {
+			t.Run(tn, func(t *testing.T) {
+				// when
+				orchestrations, count, totalCount, err := db.Orchestrations().List(tc.filter)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, orchestrationIDs(orchestrations))
+				assert.Equal(t, len(tc.expected), count)
+				assert.Equal(t, tc.totalCount, totalCount)
+			})
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRuntimeStates(t *testing.T, newStorage NewStorage) {
+	t.Run("should insert and get runtime state with decrypted configuration", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		state := fixture.FixRuntimeState("state-1", "runtime-1", "op-1")
+		state.KymaConfig.Version = "2.0.0"
+		state.ClusterConfig.KubernetesVersion = "1.25"
+		clusterSetup := fixture.FixClusterSetup("runtime-1")
+		state.ClusterSetup = &clusterSetup
+		require.NoError(t, db.RuntimeStates().Insert(state))
+
+		// when
+		got, err := db.RuntimeStates().GetByOperationID("op-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "state-1", got.ID)
+		assert.Equal(t, "runtime-1", got.RuntimeID)
+		assert.Equal(t, "2.0.0", got.KymaConfig.Version)
+		assert.Equal(t, "1.25", got.ClusterConfig.KubernetesVersion)
+		require.NotNil(t, got.ClusterSetup)
+		assert.Equal(t, clusterSetup.Kubeconfig, got.ClusterSetup.Kubeconfig)
+		assert.Equal(t, clusterSetup.KymaConfig.Version, got.ClusterSetup.KymaConfig.Version)
+	})
+
+	t.Run("should return not found for not existing runtime state", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+
+		// when
+		_, err := db.RuntimeStates().GetByOperationID("not-existing")
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should list runtime states starting with the latest", func(t *testing.T) {
+		// given
+		db := newStorage(t)
+		createdAt := baseTime()
+		for i, id := range []string{"state-1", "state-2", "state-3"} {
+			state := fixture.FixRuntimeState(id, "runtime-1", "op-"+id)
+			state.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, db.RuntimeStates().Insert(state))
+		}
+		require.NoError(t, db.RuntimeStates().Insert(fixture.FixRuntimeState("state-4", "runtime-2", "op-state-4")))
+
+		// when
+		states, err := db.RuntimeStates().ListByRuntimeID("runtime-1")
+
+		// then
+		require.NoError(t, err)
+		var ids []string
+		for _, state := range states {
+			ids = append(ids, state.ID)
+		}
+		assert.Equal(t, []string{"state-3", "state-2", "state-1"}, ids)
+
+		// when
+		latest, err := db.RuntimeStates().GetLatestByRuntimeID("runtime-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "state-3", latest.ID)
+	})
+}
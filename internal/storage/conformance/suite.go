@@ -0,0 +1,71 @@
+// Package conformance contains tests which every storage driver must pass.
+// Drivers run the suite from their own tests, so all of them behave the same way as the database used in production.
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+// NewStorage returns an empty storage, it is called for every test
+type NewStorage func(t *testing.T) storage.BrokerStorage
+
+// Run runs all conformance tests against the storage returned by the given function
+func Run(t *testing.T, newStorage NewStorage) {
+	t.Run("Instances", func(t *testing.T) {
+		testInstances(t, newStorage)
+	})
+	t.Run("Operations", func(t *testing.T) {
+		testOperations(t, newStorage)
+	})
+	t.Run("Orchestrations", func(t *testing.T) {
+		testOrchestrations(t, newStorage)
+	})
+	t.Run("RuntimeStates", func(t *testing.T) {
+		testRuntimeStates(t, newStorage)
+	})
+}
+
+// baseTime is truncated, the database does not keep nanoseconds
+func baseTime() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond).Add(-24 * time.Hour)
+}
+
+func fixSMCredentials() *internal.ServiceManagerOperatorCredentials {
+	return &internal.ServiceManagerOperatorCredentials{
+		ClientID:          "client-id",
+		ClientSecret:      "client-secret",
+		ServiceManagerURL: "https://service-manager.example.com",
+		URL:               "https://auth.example.com",
+		XSAppName:         "xsapp",
+	}
+}
+
+const fixKubeconfig = "apiVersion: v1\nkind: Config\n"
+
+func operationIDs(operations []internal.Operation) []string {
+	ids := make([]string, 0, len(operations))
+	for _, op := range operations {
+		ids = append(ids, op.ID)
+	}
+	return ids
+}
+
+func instanceIDs(instances []internal.Instance) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.InstanceID)
+	}
+	return ids
+}
+
+func orchestrationIDs(orchestrations []internal.Orchestration) []string {
+	ids := make([]string, 0, len(orchestrations))
+	for _, o := range orchestrations {
+		ids = append(ids, o.OrchestrationID)
+	}
+	return ids
+}
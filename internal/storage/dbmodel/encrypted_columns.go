@@ -0,0 +1,8 @@
+package dbmodel
+
+// EncryptedColumnsDTO holds values of columns with encrypted data of a table row, NULL values are empty.
+// The Key holds values of the primary key columns of the row.
+type EncryptedColumnsDTO struct {
+	Key     []string
+	Columns map[string]string
+}
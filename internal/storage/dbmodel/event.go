@@ -0,0 +1,17 @@
+package dbmodel
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EventDTO struct {
+	ID          string
+	Level       string
+	InstanceID  *string
+	OperationID *string
+	Message     string
+	// Fields are stored as a JSON object
+	Fields    sql.NullString
+	CreatedAt time.Time
+}
@@ -35,6 +35,9 @@ type InstanceFilter struct {
 	States                       []InstanceState
 	Expired                      *bool
 	DeletionAttempted            *bool
+	CompensationFailed           *bool
+	// Labels filters instances having all given labels
+	Labels map[string]string
 }
 
 type InstanceDTO struct {
@@ -52,12 +55,16 @@ type InstanceDTO struct {
 	ProvisioningParameters string
 	ProviderRegion         string
 	Provider               string
+	// Labels is a JSON object with instance labels
+	Labels string
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt time.Time
 	ExpiredAt *time.Time
 
+	CompensationFailedAt *time.Time
+
 	Version int
 }
 
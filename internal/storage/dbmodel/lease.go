@@ -0,0 +1,12 @@
+package dbmodel
+
+import (
+	"time"
+)
+
+type LeaseDTO struct {
+	ID         string
+	Kind       string
+	Owner      string
+	LeaseUntil time.Time
+}
@@ -30,6 +30,7 @@ type OperationDTO struct {
 	Description            string
 	FinishedStages         sql.NullString
 	ProvisioningParameters sql.NullString
+	RequestIdentity        string
 
 	Type internal.OperationType
 }
@@ -46,6 +47,11 @@ type InstanceByGlobalAccountIDStatEntry struct {
 	Total           int
 }
 
+type InstanceByPlanIDStatEntry struct {
+	ServicePlanID string
+	Total         int
+}
+
 type InstanceERSContextStatsEntry struct {
 	LicenseType sql.NullString
 	Total       int
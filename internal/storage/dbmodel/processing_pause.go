@@ -0,0 +1,18 @@
+package dbmodel
+
+import (
+	"time"
+)
+
+type ProcessingPauseDTO struct {
+	OperationType string
+	Reason        string
+	PausedAt      time.Time
+}
+
+type OperationPauseDTO struct {
+	OperationID string
+	Paused      bool
+	PausedAt    time.Time
+	ResumedAt   time.Time
+}
@@ -0,0 +1,20 @@
+package dbmodel
+
+import (
+	"time"
+)
+
+type StepAttemptDTO struct {
+	ID          int64
+	OperationID string
+	Stage       string
+	StepName    string
+
+	StartedAt  time.Time
+	DurationMs int64
+	BackoffMs  int64
+
+	ErrorReason    string
+	ErrorComponent string
+	Error          string
+}
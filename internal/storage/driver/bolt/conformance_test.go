@@ -0,0 +1,34 @@
+package bolt_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/conformance"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/bolt"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) storage.BrokerStorage {
+		brokerStorage, journal, err := storage.NewEmbeddedStorage(filepath.Join(t.TempDir(), "broker.db"), events.Config{}, storage.NewEncrypter(secretKey), logrus.StandardLogger())
+		require.NoError(t, err)
+		t.Cleanup(func() { journal.Close() })
+		return brokerStorage
+	})
+}
+
+func TestEventsConformance(t *testing.T) {
+	conformance.RunEvents(t, func(t *testing.T) storage.Events {
+		journal, err := bolt.Open(filepath.Join(t.TempDir(), "broker.db"), storage.NewEncrypter(secretKey))
+		require.NoError(t, err)
+		t.Cleanup(func() { journal.Close() })
+		ev, err := memory.NewEventsWithJournal(journal)
+		require.NoError(t, err)
+		return ev
+	})
+}
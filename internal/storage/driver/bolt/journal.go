@@ -0,0 +1,159 @@
+// Package bolt keeps the in-memory storage in a bbolt database file, so a single broker instance can be run locally
+// without the Postgres database and without losing data on restart. Records are serialized to JSON and encrypted with the cipher.
+package bolt
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
+
+	"go.etcd.io/bbolt"
+)
+
+const openTimeout = 5 * time.Second
+
+type Cipher interface {
+	Encrypt(text []byte) ([]byte, error)
+	Decrypt(text []byte) ([]byte, error)
+}
+
+// ReencryptionCipher is the cipher which can tell if data must be encrypted again with the active key
+type ReencryptionCipher interface {
+	Cipher
+	NeedsReencryption(obj []byte) bool
+}
+
+// Journal implements memory.Journal on top of the bbolt database
+type Journal struct {
+	db     *bbolt.DB
+	cipher Cipher
+}
+
+// Open opens the database file, it is created if it does not exist. The file is locked until the journal is closed,
+// so it cannot be used by more than one broker at the same time. If the cipher supports re-encryption, records
+// encrypted with other keys than the active one are encrypted again.
+func Open(path string, cipher Cipher) (*Journal, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("while opening database file %s: %w", path, err)
+	}
+	j := &Journal{db: db, cipher: cipher}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range memory.Buckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return fmt.Errorf("while creating bucket %s: %w", bucket, err)
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		if c, ok := cipher.(ReencryptionCipher); ok {
+			err = j.reencrypt(c)
+		}
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *Journal) Close() error {
+	return j.db.Close()
+}
+
+func (j *Journal) Apply(changes ...memory.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	values := make([][]byte, len(changes))
+	for i, change := range changes {
+		if change.Value == nil {
+			continue
+		}
+		data, err := json.Marshal(change.Value)
+		if err != nil {
+			return fmt.Errorf("while marshalling %s/%s: %w", change.Bucket, change.Key, err)
+		}
+		values[i], err = j.cipher.Encrypt(data)
+		if err != nil {
+			return fmt.Errorf("while encrypting %s/%s: %w", change.Bucket, change.Key, err)
+		}
+	}
+
+	return j.db.Update(func(tx *bbolt.Tx) error {
+		for i, change := range changes {
+			b := tx.Bucket([]byte(change.Bucket))
+			if b == nil {
+				return fmt.Errorf("bucket %s does not exist", change.Bucket)
+			}
+			var err error
+			if values[i] == nil {
+				err = b.Delete([]byte(change.Key))
+			} else {
+				err = b.Put([]byte(change.Key), values[i])
+			}
+			if err != nil {
+				return fmt.Errorf("while writing %s/%s: %w", change.Bucket, change.Key, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (j *Journal) ForEach(bucket string, fn func(decode func(value interface{}) error) error) error {
+	return j.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return fmt.Errorf("bucket %s does not exist", bucket)
+		}
+		return b.ForEach(func(k, v []byte) error {
+			return fn(func(value interface{}) error {
+				data, err := j.cipher.Decrypt(v)
+				if err != nil {
+					return fmt.Errorf("while decrypting %s/%s: %w", bucket, k, err)
+				}
+				if err := json.Unmarshal(data, value); err != nil {
+					return fmt.Errorf("while unmarshalling %s/%s: %w", bucket, k, err)
+				}
+				return nil
+			})
+		})
+	})
+}
+
+func (j *Journal) reencrypt(cipher ReencryptionCipher) error {
+	return j.db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range memory.Buckets {
+			b := tx.Bucket([]byte(bucket))
+			reencrypted := map[string][]byte{}
+			err := b.ForEach(func(k, v []byte) error {
+				if !cipher.NeedsReencryption(v) {
+					return nil
+				}
+				data, err := cipher.Decrypt(v)
+				if err != nil {
+					return fmt.Errorf("while decrypting %s/%s: %w", bucket, k, err)
+				}
+				reencrypted[string(k)], err = cipher.Encrypt(data)
+				if err != nil {
+					return fmt.Errorf("while encrypting %s/%s: %w", bucket, k, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			// the bucket must not be modified while iterating over it
+			for k, v := range reencrypted {
+				if err := b.Put([]byte(k), v); err != nil {
+					return fmt.Errorf("while writing %s/%s: %w", bucket, k, err)
+				}
+			}
+		}
+		return nil
+	})
+}
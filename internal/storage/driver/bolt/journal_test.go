@@ -0,0 +1,202 @@
+package bolt_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eventsapi "github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/bolt"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	secretKey      = "qbl92bqtl6zshtjb4bvbwwc2qk7vtw2d"
+	otherSecretKey = "1ee1fa2b6zshtjb4bvbwwc2qk7vtw2d3"
+)
+
+func TestEmbeddedStorage(t *testing.T) {
+	t.Run("should restore data after reopening the database file", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "broker.db")
+		db, journal := openStorage(t, path, storage.NewEncrypter(secretKey))
+
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-2")))
+		require.NoError(t, db.Instances().Delete("inst-2"))
+		operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+		require.NoError(t, db.Operations().InsertOperation(operation))
+		operation.Description = "updated"
+		_, err := db.Operations().UpdateOperation(operation)
+		require.NoError(t, err)
+		require.NoError(t, db.Operations().InsertUpdatingOperation(fixture.FixUpdatingOperation("op-2", "inst-1")))
+		require.NoError(t, db.Operations().InsertStepAttempt(internal.StepAttempt{OperationID: "op-1", StepName: "first"}))
+		require.NoError(t, db.Operations().InsertStepAttempt(internal.StepAttempt{OperationID: "op-1", StepName: "second"}))
+		require.NoError(t, db.Operations().InsertProcessingPause(internal.ProcessingPause{OperationType: internal.OperationTypeUpdate, Reason: "maintenance"}))
+		_, err = db.Operations().AcquireLease(internal.Lease{ID: "op-1", Kind: "operation", Owner: "keb-1", LeaseUntil: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.NoError(t, db.Orchestrations().Insert(fixture.FixOrchestration("orch-1")))
+		require.NoError(t, db.RuntimeStates().Insert(fixture.FixRuntimeState("state-1", "runtime-1", "op-1")))
+		require.NoError(t, db.Bindings().Insert(internal.Binding{ID: "binding-1", InstanceID: "inst-1", Kubeconfig: "kubeconfig"}))
+		require.NoError(t, journal.Close())
+
+		// when
+		db, _ = openStorage(t, path, storage.NewEncrypter(secretKey))
+
+		// then
+		_, err = db.Instances().GetByID("inst-1")
+		assert.NoError(t, err)
+		_, err = db.Instances().GetByID("inst-2")
+		assert.True(t, dberr.IsNotFound(err))
+		gotOperation, err := db.Operations().GetOperationByID("op-1")
+		require.NoError(t, err)
+		assert.Equal(t, "updated", gotOperation.Description)
+		_, err = db.Operations().GetUpdatingOperationByID("op-2")
+		assert.NoError(t, err)
+		attempts, err := db.Operations().ListStepAttemptsByOperationID("op-1")
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+		assert.Equal(t, "first", attempts[0].StepName)
+		assert.Equal(t, "second", attempts[1].StepName)
+		pause, err := db.Operations().GetProcessingPause(internal.OperationTypeUpdate)
+		require.NoError(t, err)
+		assert.Equal(t, "maintenance", pause.Reason)
+		acquired, err := db.Operations().AcquireLease(internal.Lease{ID: "op-1", Kind: "operation", Owner: "keb-2", LeaseUntil: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		assert.False(t, acquired)
+		_, err = db.Orchestrations().GetByID("orch-1")
+		assert.NoError(t, err)
+		_, err = db.RuntimeStates().GetByOperationID("op-1")
+		assert.NoError(t, err)
+		binding, err := db.Bindings().Get("inst-1", "binding-1")
+		require.NoError(t, err)
+		assert.Equal(t, "kubeconfig", binding.Kubeconfig)
+	})
+
+	t.Run("should restore archived operations", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "broker.db")
+		db, journal := openStorage(t, path, storage.NewEncrypter(secretKey))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("op-1", "inst-1")))
+		require.NoError(t, db.Operations().InsertDeprovisioningOperation(fixture.FixDeprovisioningOperation("op-2", "inst-1")))
+		require.NoError(t, db.Operations().ArchiveOperations("inst-1", "op-2"))
+		require.NoError(t, journal.Close())
+
+		// when
+		db, _ = openStorage(t, path, storage.NewEncrypter(secretKey))
+
+		// then
+		_, err := db.Operations().GetOperationByID("op-1")
+		assert.True(t, dberr.IsNotFound(err))
+		_, err = db.Operations().GetOperationByID("op-2")
+		assert.NoError(t, err)
+		archived, err := db.Operations().ListArchivedOperationsByInstanceIDs([]string{"inst-1"})
+		require.NoError(t, err)
+		assert.Len(t, archived, 2)
+	})
+
+	t.Run("should not keep secrets in plain text", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "broker.db")
+		db, journal := openStorage(t, path, storage.NewEncrypter(secretKey))
+		operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+		operation.ProvisioningParameters.ErsContext.SMOperatorCredentials = &internal.ServiceManagerOperatorCredentials{ClientSecret: "very-secret-client-secret"}
+		require.NoError(t, db.Operations().InsertOperation(operation))
+		require.NoError(t, journal.Close())
+
+		// when
+		data, err := os.ReadFile(path)
+
+		// then
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "very-secret-client-secret")
+		assert.NotContains(t, string(data), "inst-1")
+
+		// when
+		_, _, err = storage.NewEmbeddedStorage(path, events.Config{}, storage.NewEncrypter(otherSecretKey), logrus.StandardLogger())
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should re-encrypt data with the active key", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "broker.db")
+		db, journal := openStorage(t, path, storage.NewEncrypter(secretKey))
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+		require.NoError(t, journal.Close())
+
+		// when
+		_, journal = openStorage(t, path, newEncrypter(t, storage.EncryptionKeys{
+			ActiveKeyID: "key-2",
+			Keys:        map[string]string{storage.DefaultKeyID: secretKey, "key-2": otherSecretKey},
+		}))
+		require.NoError(t, journal.Close())
+
+		// then
+		db, _ = openStorage(t, path, newEncrypter(t, storage.EncryptionKeys{
+			ActiveKeyID: "key-2",
+			Keys:        map[string]string{"key-2": otherSecretKey},
+		}))
+		_, err := db.Instances().GetByID("inst-1")
+		assert.NoError(t, err)
+	})
+}
+
+func TestEvents(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "broker.db")
+	journal, err := bolt.Open(path, storage.NewEncrypter(secretKey))
+	require.NoError(t, err)
+	ev, err := memory.NewEventsWithJournal(journal)
+	require.NoError(t, err)
+	ev.InsertEvent(eventsapi.InfoEventLevel, "old", "inst-1", "op-1", nil)
+	require.NoError(t, ev.DeleteEvents(time.Now()))
+	ev.InsertEvent(eventsapi.InfoEventLevel, "first", "inst-1", "op-1", nil)
+	ev.InsertEvent(eventsapi.ErrorEventLevel, "second", "inst-1", "op-2", nil)
+	ev.InsertEvent(eventsapi.InfoEventLevel, "other", "inst-2", "op-3", nil)
+	require.NoError(t, journal.Close())
+
+	// when
+	journal, err = bolt.Open(path, storage.NewEncrypter(secretKey))
+	require.NoError(t, err)
+	defer journal.Close()
+	ev, err = memory.NewEventsWithJournal(journal)
+	require.NoError(t, err)
+	got, err := ev.ListEvents(eventsapi.EventFilter{InstanceIDs: []string{"inst-1"}})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "first", got[0].Message)
+	assert.Equal(t, "second", got[1].Message)
+	assert.Equal(t, eventsapi.ErrorEventLevel, got[1].Level)
+}
+
+func openStorage(t *testing.T, path string, cipher bolt.Cipher) (storage.BrokerStorage, *bolt.Journal) {
+	db, journal, err := storage.NewEmbeddedStorage(path, events.Config{}, cipher, logrus.StandardLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { journal.Close() })
+	return db, journal
+}
+
+func newEncrypter(t *testing.T, keys storage.EncryptionKeys) *storage.Encrypter {
+	data, err := yaml.Marshal(keys)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	encrypter, err := storage.NewEncrypterFromConfig(storage.Config{EncryptionKeysFilePath: path})
+	require.NoError(t, err)
+	return encrypter
+}
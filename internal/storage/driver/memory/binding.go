@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type bindings struct {
+	mu sync.Mutex
+
+	// bindings are stored per instance ID
+	bindings map[string]map[string]internal.Binding
+	journal  Journal
+}
+
+func NewBinding() *bindings {
+	return &bindings{
+		bindings: make(map[string]map[string]internal.Binding, 0),
+		journal:  noJournal{},
+	}
+}
+
+// NewBindingWithJournal creates in-memory storage for bindings which persists all changes in the journal.
+// The bindings stored in the journal are restored.
+func NewBindingWithJournal(journal Journal) (*bindings, error) {
+	s := NewBinding()
+	s.journal = journal
+
+	err := restore(journal, BindingsBucket, func(binding internal.Binding) {
+		if _, found := s.bindings[binding.InstanceID]; !found {
+			s.bindings[binding.InstanceID] = make(map[string]internal.Binding)
+		}
+		s.bindings[binding.InstanceID][binding.ID] = binding
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while restoring bindings: %w", err)
+	}
+	return s, nil
+}
+
+func bindingKey(instanceID, bindingID string) string {
+	return instanceID + "/" + bindingID
+}
+
+func (s *bindings) Insert(binding internal.Binding) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.bindings[binding.InstanceID][binding.ID]; found {
+		return dberr.AlreadyExists("binding with id %s already exist", binding.ID)
+	}
+	if err := s.journal.Apply(put(BindingsBucket, bindingKey(binding.InstanceID, binding.ID), binding)); err != nil {
+		return err
+	}
+	if _, found := s.bindings[binding.InstanceID]; !found {
+		s.bindings[binding.InstanceID] = make(map[string]internal.Binding)
+	}
+	s.bindings[binding.InstanceID][binding.ID] = binding
+
+	return nil
+}
+
+func (s *bindings) Get(instanceID string, bindingID string) (*internal.Binding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	binding, found := s.bindings[instanceID][bindingID]
+	if !found {
+		return nil, dberr.NotFound("binding with id %s for instance %s not exist", bindingID, instanceID)
+	}
+
+	return &binding, nil
+}
+
+func (s *bindings) ListByInstanceID(instanceID string) ([]internal.Binding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.Binding, 0)
+	for _, binding := range s.bindings[instanceID] {
+		result = append(result, binding)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *bindings) ListExpired(before time.Time) ([]internal.Binding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.Binding, 0)
+	for _, instanceBindings := range s.bindings {
+		for _, binding := range instanceBindings {
+			if !binding.ExpiresAt.IsZero() && binding.ExpiresAt.Before(before) {
+				result = append(result, binding)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ExpiresAt.Before(result[j].ExpiresAt)
+	})
+
+	return result, nil
+}
+
+func (s *bindings) Delete(instanceID, bindingID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.journal.Apply(remove(BindingsBucket, bindingKey(instanceID, bindingID))); err != nil {
+		return err
+	}
+	delete(s.bindings[instanceID], bindingID)
+	if len(s.bindings[instanceID]) == 0 {
+		delete(s.bindings, instanceID)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/conformance"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) storage.BrokerStorage {
+		return storage.NewMemoryStorage()
+	})
+}
+
+func TestEventsConformance(t *testing.T) {
+	conformance.RunEvents(t, func(t *testing.T) storage.Events {
+		return memory.NewEvents()
+	})
+}
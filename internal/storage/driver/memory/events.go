@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	eventsapi "github.com/kyma-project/kyma-environment-broker/common/events"
+
+	"github.com/google/uuid"
+)
+
+type events struct {
+	mu sync.Mutex
+
+	events  []eventsapi.EventDTO
+	journal Journal
+}
+
+func NewEvents() *events {
+	return &events{
+		events:  make([]eventsapi.EventDTO, 0),
+		journal: noJournal{},
+	}
+}
+
+// NewEventsWithJournal creates in-memory storage for events which persists all changes in the journal.
+// The events stored in the journal are restored.
+func NewEventsWithJournal(journal Journal) (*events, error) {
+	e := NewEvents()
+	e.journal = journal
+
+	err := restore(journal, EventsBucket, func(event eventsapi.EventDTO) { e.events = append(e.events, event) })
+	if err != nil {
+		return nil, fmt.Errorf("while restoring events: %w", err)
+	}
+	return e, nil
+}
+
+// eventKey keeps events in the journal in the order of creation
+func eventKey(event eventsapi.EventDTO) string {
+	return event.CreatedAt.UTC().Format("20060102150405.000000000") + "/" + event.ID
+}
+
+func (e *events) RunGarbageCollection(pollingPeriod, retention time.Duration) {
+	if retention == 0 {
+		return
+	}
+	ticker := time.NewTicker(pollingPeriod)
+	for range ticker.C {
+		if err := e.DeleteEvents(time.Now().Add(-retention)); err != nil {
+			log.Printf("failed to delete old events: %v", err)
+		}
+	}
+}
+
+func (e *events) InsertEvent(eventLevel eventsapi.EventLevel, message, instanceID, operationID string, fields eventsapi.Fields) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event := eventsapi.EventDTO{
+		ID:          uuid.NewString(),
+		Level:       eventLevel,
+		InstanceID:  &instanceID,
+		OperationID: &operationID,
+		Message:     message,
+		Fields:      fields,
+		CreatedAt:   time.Now(),
+	}
+	if err := e.journal.Apply(put(EventsBucket, eventKey(event), event)); err != nil {
+		log.Printf("failed to insert event [%v] %v/%v %q: %v", eventLevel, instanceID, operationID, message, err)
+		return
+	}
+	e.events = append(e.events, event)
+	log.Printf("EVENT [%v/%v] %v: %v\n", instanceID, operationID, eventLevel, message)
+}
+
+func (e *events) ListEvents(filter eventsapi.EventFilter) ([]eventsapi.EventDTO, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	events := make([]eventsapi.EventDTO, 0)
+	for _, ev := range e.events {
+		if matchEvent(ev, filter) {
+			events = append(events, ev)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].ID < events[j].ID
+		}
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+	return events, nil
+}
+
+func matchEvent(ev eventsapi.EventDTO, filter eventsapi.EventFilter) bool {
+	if !requiredContains(ev.InstanceID, filter.InstanceIDs) {
+		return false
+	}
+	if !requiredContains(ev.OperationID, filter.OperationIDs) {
+		return false
+	}
+	if !requiredContains(&ev.Level, filter.Levels) {
+		return false
+	}
+	if !filter.From.IsZero() && ev.CreatedAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !ev.CreatedAt.Before(filter.To) {
+		return false
+	}
+	if filter.MessageContains != "" && !strings.Contains(strings.ToLower(ev.Message), strings.ToLower(filter.MessageContains)) {
+		return false
+	}
+	if filter.After != nil && !filter.After.Precedes(ev) {
+		return false
+	}
+	return true
+}
+
+// DeleteEvents removes events created until the given time
+func (e *events) DeleteEvents(until time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	kept := make([]eventsapi.EventDTO, 0, len(e.events))
+	var changes []Change
+	for _, ev := range e.events {
+		if ev.CreatedAt.After(until) {
+			kept = append(kept, ev)
+			continue
+		}
+		changes = append(changes, remove(EventsBucket, eventKey(ev)))
+	}
+	if err := e.journal.Apply(changes...); err != nil {
+		return err
+	}
+	e.events = kept
+	return nil
+}
+
+func requiredContains[T comparable](el *T, sl []T) bool {
+	if len(sl) == 0 {
+		return true
+	}
+	if el == nil {
+		return false
+	}
+	for _, x := range sl {
+		if *el == x {
+			return true
+		}
+	}
+	return false
+}
@@ -3,12 +3,12 @@ package memory
 import (
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"sort"
 	"sync"
 
 	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
@@ -20,23 +20,43 @@ type instances struct {
 	mu                sync.Mutex
 	instances         map[string]internal.Instance
 	operationsStorage *operations
+	journal           Journal
 }
 
 func NewInstance(operations *operations) *instances {
 	return &instances{
 		instances:         make(map[string]internal.Instance, 0),
 		operationsStorage: operations,
+		journal:           noJournal{},
 	}
 }
 
+// NewInstanceWithJournal creates in-memory storage for instances which persists all changes in the journal.
+// The instances stored in the journal are restored.
+func NewInstanceWithJournal(operations *operations, journal Journal) (*instances, error) {
+	s := NewInstance(operations)
+	s.journal = journal
+
+	err := restore(journal, InstancesBucket, func(instance internal.Instance) { s.instances[instance.InstanceID] = instance })
+	if err != nil {
+		return nil, fmt.Errorf("while restoring instances: %w", err)
+	}
+	return s, nil
+}
+
+// InsertWithoutEncryption inserts the instance, the memory storage does not encrypt credentials
 func (s *instances) InsertWithoutEncryption(instance internal.Instance) error {
-	return errors.New("not implemented")
+	return s.Insert(instance)
 }
+
+// UpdateWithoutEncryption updates the instance, the memory storage does not encrypt credentials
 func (s *instances) UpdateWithoutEncryption(instance internal.Instance) (*internal.Instance, error) {
-	return nil, errors.New("not implemented")
+	return s.Update(instance)
 }
-func (s *instances) ListWithoutDecryption(dbmodel.InstanceFilter) ([]internal.Instance, int, int, error) {
-	return nil, 0, 0, errors.New("not implemented")
+
+// ListWithoutDecryption lists the instances, the memory storage does not encrypt credentials
+func (s *instances) ListWithoutDecryption(filter dbmodel.InstanceFilter) ([]internal.Instance, int, int, error) {
+	return s.List(filter)
 }
 
 func (s *instances) FindAllJoinedWithOperations(prct ...predicate.Predicate) ([]internal.InstanceWithOperation, error) {
@@ -148,8 +168,23 @@ func (s *instances) GetNumberOfInstancesForGlobalAccountID(globalAccountID strin
 	return numberOfInstances, nil
 }
 
+func (s *instances) GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID string) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]int)
+	for _, inst := range s.instances {
+		if inst.GlobalAccountID == globalAccountID && inst.DeletedAt.IsZero() {
+			result[inst.ServicePlanID]++
+		}
+	}
+	return result, nil
+}
+
 func (s *instances) GetByID(instanceID string) (*internal.Instance, error) {
+	s.mu.Lock()
 	inst, ok := s.instances[instanceID]
+	s.mu.Unlock()
 	if !ok {
 		return nil, dberr.NotFound("instance with id %s not exist", instanceID)
 	}
@@ -182,6 +217,9 @@ func (s *instances) Delete(instanceID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.journal.Apply(remove(InstancesBucket, instanceID)); err != nil {
+		return err
+	}
 	delete(s.instances, instanceID)
 	return nil
 }
@@ -189,11 +227,40 @@ func (s *instances) Delete(instanceID string) error {
 func (s *instances) Insert(instance internal.Instance) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if _, exists := s.instances[instance.InstanceID]; exists {
+		return dberr.AlreadyExists("instance with id %s already exist", instance.InstanceID)
+	}
+	if err := s.journal.Apply(put(InstancesBucket, instance.InstanceID, instance)); err != nil {
+		return err
+	}
 	s.instances[instance.InstanceID] = instance
 
 	return nil
 }
 
+func (s *instances) InsertWithinQuota(instance internal.Instance, limit int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.instances[instance.InstanceID]; exists {
+		return false, dberr.AlreadyExists("instance with id %s already exist", instance.InstanceID)
+	}
+	count := 0
+	for _, inst := range s.instances {
+		if inst.GlobalAccountID == instance.GlobalAccountID && inst.ServicePlanID == instance.ServicePlanID && inst.DeletedAt.IsZero() {
+			count++
+		}
+	}
+	if count >= limit {
+		return false, nil
+	}
+	if err := s.journal.Apply(put(InstancesBucket, instance.InstanceID, instance)); err != nil {
+		return false, err
+	}
+	s.instances[instance.InstanceID] = instance
+
+	return true, nil
+}
+
 func (s *instances) Update(instance internal.Instance) (*internal.Instance, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -205,17 +272,61 @@ func (s *instances) Update(instance internal.Instance) (*internal.Instance, erro
 		return nil, dberr.Conflict("unable to update instance %s - conflict", instance.InstanceID)
 	}
 	instance.Version = instance.Version + 1
+	if err := s.journal.Apply(put(InstancesBucket, instance.InstanceID, instance)); err != nil {
+		return nil, err
+	}
 	s.instances[instance.InstanceID] = instance
 
 	return &instance, nil
 }
 
 func (s *instances) GetInstanceStats() (internal.InstanceStats, error) {
-	return internal.InstanceStats{}, fmt.Errorf("not implemented")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := internal.InstanceStats{
+		PerGlobalAccountID: make(map[string]int),
+	}
+	for _, inst := range s.instances {
+		if !inst.DeletedAt.IsZero() {
+			continue
+		}
+		result.PerGlobalAccountID[inst.GlobalAccountID]++
+		result.TotalNumberOfInstances++
+	}
+	return result, nil
 }
 
+// GetERSContextStats groups not deleted instances by the license type of their last operation,
+// instances without operations are not counted
 func (s *instances) GetERSContextStats() (internal.ERSContextStats, error) {
-	return internal.ERSContextStats{}, fmt.Errorf("not implemented")
+	s.mu.Lock()
+	instanceIDs := make([]string, 0, len(s.instances))
+	for _, inst := range s.instances {
+		if inst.DeletedAt.IsZero() {
+			instanceIDs = append(instanceIDs, inst.InstanceID)
+		}
+	}
+	s.mu.Unlock()
+
+	result := internal.ERSContextStats{
+		LicenseType: make(map[string]int),
+	}
+	for _, instanceID := range instanceIDs {
+		op, err := s.operationsStorage.GetLastOperation(instanceID)
+		switch {
+		case dberr.IsNotFound(err):
+			continue
+		case err != nil:
+			return internal.ERSContextStats{}, err
+		}
+		licenseType := ""
+		if op.ProvisioningParameters.ErsContext.LicenseType != nil {
+			licenseType = *op.ProvisioningParameters.ErsContext.LicenseType
+		}
+		result.LicenseType[licenseType]++
+	}
+	return result, nil
 }
 
 func (s *instances) List(filter dbmodel.InstanceFilter) ([]internal.Instance, int, int, error) {
@@ -273,12 +384,30 @@ func (s *instances) filterInstances(filter dbmodel.InstanceFilter) []internal.In
 		if ok = matchFilter(v.ServicePlanName, filter.Plans, equal); !ok {
 			continue
 		}
+		if ok = matchFilter(v.ServicePlanID, filter.PlanIDs, equal); !ok {
+			continue
+		}
 		if ok = matchFilter(v.ProviderRegion, filter.Regions, equal); !ok {
 			continue
 		}
+		if ok = runtime.MatchLabels(v.Labels, filter.Labels); !ok {
+			continue
+		}
+		if filter.Expired != nil && *filter.Expired != v.IsExpired() {
+			continue
+		}
+		if filter.DeletionAttempted != nil && *filter.DeletionAttempted == v.DeletedAt.IsZero() {
+			continue
+		}
+		if filter.CompensationFailed != nil && *filter.CompensationFailed != (v.CompensationFailedAt != nil) {
+			continue
+		}
 		if len(filter.Shoots) > 0 {
 			// required for shootName
-			lastOp, _ := s.operationsStorage.GetLastOperation(v.InstanceID)
+			lastOp, err := s.operationsStorage.GetLastOperation(v.InstanceID)
+			if err != nil {
+				continue
+			}
 			if ok = matchFilter(lastOp.ShootName, filter.Shoots, shootMatch); !ok {
 				continue
 			}
@@ -306,12 +435,12 @@ func matchFilter(value string, filters []string, match func(string, string) bool
 }
 
 func (s *instances) matchInstanceState(instanceID string, states []dbmodel.InstanceState) bool {
-	if len(states) == 0 {
-		return true
-	}
+	// instances without operations are not listed, the same as in the database
 	op, err := s.operationsStorage.GetLastOperation(instanceID)
 	if err != nil {
-		// To support instance test cases without any operations
+		return false
+	}
+	if len(states) == 0 {
 		return true
 	}
 
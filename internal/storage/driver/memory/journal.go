@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+)
+
+// Buckets of the journal, every bucket keeps records of one type
+const (
+	InstancesBucket                = "instances"
+	OperationsBucket               = "operations"
+	UpdatingOperationsBucket       = "updating_operations"
+	UpgradeClusterOperationsBucket = "upgrade_cluster_operations"
+	ArchivedOperationsBucket       = "archived_operations"
+	StepAttemptsBucket             = "step_attempts"
+	ArchivedStepAttemptsBucket     = "archived_step_attempts"
+	ProcessingPausesBucket         = "processing_pauses"
+	OperationPausesBucket          = "operation_pauses"
+	LeasesBucket                   = "leases"
+	OrchestrationsBucket           = "orchestrations"
+	RuntimeStatesBucket            = "runtime_states"
+	BindingsBucket                 = "bindings"
+	EventsBucket                   = "events"
+)
+
+// Buckets lists all buckets used by the in-memory storage
+var Buckets = []string{
+	InstancesBucket,
+	OperationsBucket,
+	UpdatingOperationsBucket,
+	UpgradeClusterOperationsBucket,
+	ArchivedOperationsBucket,
+	StepAttemptsBucket,
+	ArchivedStepAttemptsBucket,
+	ProcessingPausesBucket,
+	OperationPausesBucket,
+	LeasesBucket,
+	OrchestrationsBucket,
+	RuntimeStatesBucket,
+	BindingsBucket,
+	EventsBucket,
+}
+
+// Change describes a single record of the journal, the record is removed if the value is nil
+type Change struct {
+	Bucket string
+	Key    string
+	Value  interface{}
+}
+
+// operationRecord is the operation kept in the journal. The operation is serialized to JSON without some fields,
+// so they are kept next to it, the same way as the Postgres driver keeps them in separate columns.
+type operationRecord struct {
+	Operation internal.Operation
+
+	ID                     string
+	Version                int
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	Type                   internal.OperationType
+	InstanceID             string
+	ProvisionerOperationID string
+	State                  domain.LastOperationState
+	Description            string
+	ProvisioningParameters internal.ProvisioningParameters
+	OrchestrationID        string
+	FinishedStages         []string
+	RequestIdentity        string
+}
+
+func newOperationRecord(op internal.Operation) operationRecord {
+	return operationRecord{
+		Operation:              op,
+		ID:                     op.ID,
+		Version:                op.Version,
+		CreatedAt:              op.CreatedAt,
+		UpdatedAt:              op.UpdatedAt,
+		Type:                   op.Type,
+		InstanceID:             op.InstanceID,
+		ProvisionerOperationID: op.ProvisionerOperationID,
+		State:                  op.State,
+		Description:            op.Description,
+		ProvisioningParameters: op.ProvisioningParameters,
+		OrchestrationID:        op.OrchestrationID,
+		FinishedStages:         op.FinishedStages,
+		RequestIdentity:        op.RequestIdentity,
+	}
+}
+
+func (r operationRecord) toOperation() internal.Operation {
+	op := r.Operation
+	op.ID = r.ID
+	op.RuntimeOperation.ID = r.ID
+	op.Version = r.Version
+	op.CreatedAt = r.CreatedAt
+	op.UpdatedAt = r.UpdatedAt
+	op.Type = r.Type
+	op.InstanceID = r.InstanceID
+	op.ProvisionerOperationID = r.ProvisionerOperationID
+	op.State = r.State
+	op.Description = r.Description
+	op.ProvisioningParameters = r.ProvisioningParameters
+	op.OrchestrationID = r.OrchestrationID
+	op.FinishedStages = r.FinishedStages
+	op.RequestIdentity = r.RequestIdentity
+	return op
+}
+
+// Journal persists changes of the in-memory storage, so the state can be restored after restart.
+// The in-memory storage applies a change only if the journal accepted it.
+type Journal interface {
+	// Apply persists all changes at once, none of them is persisted if an error is returned
+	Apply(changes ...Change) error
+	// ForEach calls fn for every record of the bucket in the order of keys, decode unmarshals the record
+	ForEach(bucket string, fn func(decode func(value interface{}) error) error) error
+}
+
+type noJournal struct{}
+
+func (noJournal) Apply(...Change) error {
+	return nil
+}
+
+func (noJournal) ForEach(string, func(func(interface{}) error) error) error {
+	return nil
+}
+
+func put(bucket, key string, value interface{}) Change {
+	return Change{Bucket: bucket, Key: key, Value: value}
+}
+
+func remove(bucket, key string) Change {
+	return Change{Bucket: bucket, Key: key}
+}
+
+// restore calls add for every record of the bucket
+func restore[T any](journal Journal, bucket string, add func(T)) error {
+	return journal.ForEach(bucket, func(decode func(value interface{}) error) error {
+		var value T
+		if err := decode(&value); err != nil {
+			return err
+		}
+		add(value)
+		return nil
+	})
+}
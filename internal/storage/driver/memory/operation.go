@@ -28,6 +28,14 @@ type operations struct {
 	operations               map[string]internal.Operation
 	upgradeClusterOperations map[string]internal.UpgradeClusterOperation
 	updateOperations         map[string]internal.UpdatingOperation
+	stepAttempts             map[string][]internal.StepAttempt
+	processingPauses         map[internal.OperationType]internal.ProcessingPause
+	operationPauses          map[string]internal.OperationPause
+	leases                   map[string]internal.Lease
+	archivedOperations       map[string]internal.Operation
+	archivedStepAttempts     map[string][]internal.StepAttempt
+
+	journal Journal
 }
 
 // NewOperation creates in-memory storage for OSB operations.
@@ -36,9 +44,66 @@ func NewOperation() *operations {
 		operations:               make(map[string]internal.Operation, 0),
 		upgradeClusterOperations: make(map[string]internal.UpgradeClusterOperation, 0),
 		updateOperations:         make(map[string]internal.UpdatingOperation, 0),
+		stepAttempts:             make(map[string][]internal.StepAttempt, 0),
+		processingPauses:         make(map[internal.OperationType]internal.ProcessingPause, 0),
+		operationPauses:          make(map[string]internal.OperationPause, 0),
+		leases:                   make(map[string]internal.Lease, 0),
+		archivedOperations:       make(map[string]internal.Operation, 0),
+		archivedStepAttempts:     make(map[string][]internal.StepAttempt, 0),
+		journal:                  noJournal{},
 	}
 }
 
+// NewOperationWithJournal creates in-memory storage for OSB operations which persists all changes in the journal.
+// The operations stored in the journal are restored.
+func NewOperationWithJournal(journal Journal) (*operations, error) {
+	s := NewOperation()
+	s.journal = journal
+
+	for _, r := range []func() error{
+		func() error {
+			return restore(journal, OperationsBucket, func(r operationRecord) { s.operations[r.ID] = r.toOperation() })
+		},
+		func() error {
+			return restore(journal, UpgradeClusterOperationsBucket, func(r operationRecord) {
+				s.upgradeClusterOperations[r.ID] = internal.UpgradeClusterOperation{Operation: r.toOperation()}
+			})
+		},
+		func() error {
+			return restore(journal, UpdatingOperationsBucket, func(r operationRecord) {
+				s.updateOperations[r.ID] = internal.UpdatingOperation{Operation: r.toOperation()}
+			})
+		},
+		func() error {
+			return restore(journal, ArchivedOperationsBucket, func(r operationRecord) { s.archivedOperations[r.ID] = r.toOperation() })
+		},
+		func() error {
+			return restore(journal, StepAttemptsBucket, func(attempt internal.StepAttempt) {
+				s.stepAttempts[attempt.OperationID] = append(s.stepAttempts[attempt.OperationID], attempt)
+			})
+		},
+		func() error {
+			return restore(journal, ArchivedStepAttemptsBucket, func(attempt internal.StepAttempt) {
+				s.archivedStepAttempts[attempt.OperationID] = append(s.archivedStepAttempts[attempt.OperationID], attempt)
+			})
+		},
+		func() error {
+			return restore(journal, ProcessingPausesBucket, func(pause internal.ProcessingPause) { s.processingPauses[pause.OperationType] = pause })
+		},
+		func() error {
+			return restore(journal, OperationPausesBucket, func(pause internal.OperationPause) { s.operationPauses[pause.OperationID] = pause })
+		},
+		func() error {
+			return restore(journal, LeasesBucket, func(lease internal.Lease) { s.leases[lease.ID] = lease })
+		},
+	} {
+		if err := r(); err != nil {
+			return nil, fmt.Errorf("while restoring operations: %w", err)
+		}
+	}
+	return s, nil
+}
+
 func (s *operations) InsertProvisioningOperation(operation internal.ProvisioningOperation) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -47,7 +112,13 @@ func (s *operations) InsertProvisioningOperation(operation internal.Provisioning
 	if _, exists := s.operations[id]; exists {
 		return dberr.AlreadyExists("instance operation with id %s already exist", id)
 	}
+	if _, exists := s.findByRequestIdentity(operation.RequestIdentity); exists {
+		return dberr.AlreadyExists("instance operation with request identity %s already exist", operation.RequestIdentity)
+	}
 
+	if err := s.journal.Apply(put(OperationsBucket, id, newOperationRecord(operation.Operation))); err != nil {
+		return err
+	}
 	s.operations[id] = operation.Operation
 	return nil
 }
@@ -60,7 +131,13 @@ func (s *operations) InsertOperation(operation internal.Operation) error {
 	if _, exists := s.operations[id]; exists {
 		return dberr.AlreadyExists("instance operation with id %s already exist", id)
 	}
+	if _, exists := s.findByRequestIdentity(operation.RequestIdentity); exists {
+		return dberr.AlreadyExists("instance operation with request identity %s already exist", operation.RequestIdentity)
+	}
 
+	if err := s.journal.Apply(put(OperationsBucket, id, newOperationRecord(operation))); err != nil {
+		return err
+	}
 	s.operations[id] = operation
 	return nil
 }
@@ -126,6 +203,9 @@ func (s *operations) UpdateProvisioningOperation(op internal.ProvisioningOperati
 		return nil, dberr.Conflict("unable to update provisioning operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
 	}
 	op.Version = op.Version + 1
+	if err := s.journal.Apply(put(OperationsBucket, op.ID, newOperationRecord(op.Operation))); err != nil {
+		return nil, err
+	}
 	s.operations[op.ID] = op.Operation
 
 	return &op, nil
@@ -135,6 +215,29 @@ func (s *operations) UpdateOperation(op internal.Operation) (*internal.Operation
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if upgradeClusterOp, exists := s.upgradeClusterOperations[op.ID]; exists {
+		if upgradeClusterOp.Version != op.Version {
+			return nil, dberr.Conflict("unable to update operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
+		}
+		op.Version = op.Version + 1
+		if err := s.journal.Apply(put(UpgradeClusterOperationsBucket, op.ID, newOperationRecord(op))); err != nil {
+			return nil, err
+		}
+		s.upgradeClusterOperations[op.ID] = internal.UpgradeClusterOperation{Operation: op}
+		return &op, nil
+	}
+	if updateOp, exists := s.updateOperations[op.ID]; exists {
+		if updateOp.Version != op.Version {
+			return nil, dberr.Conflict("unable to update operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
+		}
+		op.Version = op.Version + 1
+		if err := s.journal.Apply(put(UpdatingOperationsBucket, op.ID, newOperationRecord(op))); err != nil {
+			return nil, err
+		}
+		s.updateOperations[op.ID] = internal.UpdatingOperation{Operation: op}
+		return &op, nil
+	}
+
 	oldOp, exists := s.operations[op.ID]
 	if !exists {
 		return nil, dberr.NotFound("instance operation with id %s not found", op.ID)
@@ -143,6 +246,9 @@ func (s *operations) UpdateOperation(op internal.Operation) (*internal.Operation
 		return nil, dberr.Conflict("unable to update operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
 	}
 	op.Version = op.Version + 1
+	if err := s.journal.Apply(put(OperationsBucket, op.ID, newOperationRecord(op))); err != nil {
+		return nil, err
+	}
 	s.operations[op.ID] = op
 
 	return &op, nil
@@ -172,23 +278,318 @@ func (s *operations) ListOperationsByInstanceID(instanceID string) ([]internal.O
 	defer s.mu.Unlock()
 
 	operations := make([]internal.Operation, 0)
-	for _, op := range s.operations {
+	for _, op := range s.operationsOfAllTypes() {
 		if op.InstanceID == instanceID {
 			operations = append(operations, op)
 		}
 	}
+	s.sortOperationsByCreatedAtDesc(operations)
 
-	if len(operations) != 0 {
-		s.sortOperationsByCreatedAtDesc(operations)
-		return operations, nil
+	return operations, nil
+}
+
+// ListOperationsInTimeRange lists operations created or updated within the given time range
+func (s *operations) ListOperationsInTimeRange(from, to time.Time) ([]internal.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && !t.After(to)
 	}
+	operations := make([]internal.Operation, 0)
+	for _, op := range s.operationsOfAllTypes() {
+		if inRange(op.CreatedAt) || inRange(op.UpdatedAt) {
+			operations = append(operations, op)
+		}
+	}
+	return operations, nil
+}
 
-	return nil, dberr.NotFound("instance provisioning operations with instanceID %s not found", instanceID)
+func (s *operations) InsertStepAttempt(attempt internal.StepAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%06d", attempt.OperationID, len(s.stepAttempts[attempt.OperationID]))
+	if err := s.journal.Apply(put(StepAttemptsBucket, key, attempt)); err != nil {
+		return err
+	}
+	s.stepAttempts[attempt.OperationID] = append(s.stepAttempts[attempt.OperationID], attempt)
+	return nil
 }
 
-func (s *operations) ListOperationsInTimeRange(from, to time.Time) ([]internal.Operation, error) {
-	panic("not implemented") //also not used in any tests
-	return nil, nil
+func (s *operations) ListStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := make([]internal.StepAttempt, len(s.stepAttempts[operationID]))
+	copy(attempts, s.stepAttempts[operationID])
+	return attempts, nil
+}
+
+func (s *operations) InsertProcessingPause(pause internal.ProcessingPause) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.processingPauses[pause.OperationType]; found {
+		return dberr.AlreadyExists("processing of %s operations is already paused", pause.OperationType)
+	}
+	if err := s.journal.Apply(put(ProcessingPausesBucket, string(pause.OperationType), pause)); err != nil {
+		return err
+	}
+	s.processingPauses[pause.OperationType] = pause
+	return nil
+}
+
+func (s *operations) GetProcessingPause(operationType internal.OperationType) (*internal.ProcessingPause, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pause, found := s.processingPauses[operationType]
+	if !found {
+		return nil, dberr.NotFound("processing of %s operations is not paused", operationType)
+	}
+	return &pause, nil
+}
+
+func (s *operations) ListProcessingPauses() ([]internal.ProcessingPause, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pauses := make([]internal.ProcessingPause, 0, len(s.processingPauses))
+	for _, pause := range s.processingPauses {
+		pauses = append(pauses, pause)
+	}
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].OperationType < pauses[j].OperationType
+	})
+	return pauses, nil
+}
+
+func (s *operations) DeleteProcessingPause(operationType internal.OperationType) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.journal.Apply(remove(ProcessingPausesBucket, string(operationType))); err != nil {
+		return err
+	}
+	delete(s.processingPauses, operationType)
+	return nil
+}
+
+func (s *operations) UpsertOperationPause(pause internal.OperationPause) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.journal.Apply(put(OperationPausesBucket, pause.OperationID, pause)); err != nil {
+		return err
+	}
+	s.operationPauses[pause.OperationID] = pause
+	return nil
+}
+
+func (s *operations) GetOperationPause(operationID string) (*internal.OperationPause, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pause, found := s.operationPauses[operationID]
+	if !found {
+		return nil, dberr.NotFound("operation %s was never paused", operationID)
+	}
+	return &pause, nil
+}
+
+func (s *operations) ListPausedOperations() ([]internal.OperationPause, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pauses := make([]internal.OperationPause, 0)
+	for _, pause := range s.operationPauses {
+		if pause.Paused {
+			pauses = append(pauses, pause)
+		}
+	}
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].OperationID < pauses[j].OperationID
+	})
+	return pauses, nil
+}
+
+func (s *operations) AcquireLease(lease internal.Lease) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, found := s.leases[lease.ID]
+	if found && existing.Owner != lease.Owner && !existing.LeaseUntil.Before(time.Now()) {
+		return false, nil
+	}
+	if err := s.journal.Apply(put(LeasesBucket, lease.ID, lease)); err != nil {
+		return false, err
+	}
+	s.leases[lease.ID] = lease
+	return true, nil
+}
+
+func (s *operations) ReleaseLease(id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, found := s.leases[id]; found && lease.Owner == owner {
+		if err := s.journal.Apply(remove(LeasesBucket, id)); err != nil {
+			return err
+		}
+		delete(s.leases, id)
+	}
+	return nil
+}
+
+func (s *operations) ListExpiredLeases(kind string, now time.Time) ([]internal.Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	leases := make([]internal.Lease, 0)
+	for _, lease := range s.leases {
+		if lease.Kind == kind && lease.LeaseUntil.Before(now) {
+			leases = append(leases, lease)
+		}
+	}
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].LeaseUntil.Before(leases[j].LeaseUntil)
+	})
+	return leases, nil
+}
+
+// ListInstancesToArchive returns instances which last operation is a succeeded deprovisioning which is not temporary,
+// the operations of the instance must not be archived yet and all of them must be finished before the given time
+func (s *operations) ListInstancesToArchive(finishedBefore time.Time, limit int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archived := make(map[string]bool)
+	for _, op := range s.archivedOperations {
+		archived[op.InstanceID] = true
+	}
+	byInstance := make(map[string][]internal.Operation)
+	for _, op := range s.operationsOfAllTypes() {
+		if !archived[op.InstanceID] {
+			byInstance[op.InstanceID] = append(byInstance[op.InstanceID], op)
+		}
+	}
+
+	var last []internal.Operation
+	for _, ops := range byInstance {
+		s.sortByCreatedAt(ops)
+		finished := true
+		lastUpdate := time.Time{}
+		for _, op := range ops {
+			if op.State == domain.InProgress {
+				finished = false
+			}
+			if op.UpdatedAt.After(lastUpdate) {
+				lastUpdate = op.UpdatedAt
+			}
+		}
+		op := ops[len(ops)-1]
+		if !finished || !lastUpdate.Before(finishedBefore) || op.Type != internal.OperationTypeDeprovision || op.State != domain.Succeeded || op.Temporary {
+			continue
+		}
+		op.UpdatedAt = lastUpdate
+		last = append(last, op)
+	}
+	sort.Slice(last, func(i, j int) bool {
+		return last[i].UpdatedAt.Before(last[j].UpdatedAt)
+	})
+
+	instanceIDs := make([]string, 0)
+	for i := 0; i < len(last) && i < limit; i++ {
+		instanceIDs = append(instanceIDs, last[i].InstanceID)
+	}
+	return instanceIDs, nil
+}
+
+func (s *operations) ArchiveOperations(instanceID, summaryOperationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var archived []internal.Operation
+	var changes []Change
+	for _, op := range s.operationsOfAllTypes() {
+		if op.InstanceID != instanceID {
+			continue
+		}
+		archived = append(archived, op)
+		changes = append(changes, put(ArchivedOperationsBucket, op.ID, newOperationRecord(op)))
+		if op.ID != summaryOperationID {
+			changes = append(changes,
+				remove(OperationsBucket, op.ID),
+				remove(UpgradeClusterOperationsBucket, op.ID),
+				remove(UpdatingOperationsBucket, op.ID))
+			for i, attempt := range s.stepAttempts[op.ID] {
+				key := fmt.Sprintf("%s/%06d", op.ID, i)
+				changes = append(changes, put(ArchivedStepAttemptsBucket, key, attempt), remove(StepAttemptsBucket, key))
+			}
+			if _, found := s.operationPauses[op.ID]; found {
+				changes = append(changes, remove(OperationPausesBucket, op.ID))
+			}
+		}
+	}
+	if err := s.journal.Apply(changes...); err != nil {
+		return err
+	}
+
+	for _, op := range archived {
+		s.archivedOperations[op.ID] = op
+		if op.ID != summaryOperationID {
+			delete(s.operations, op.ID)
+			delete(s.upgradeClusterOperations, op.ID)
+			delete(s.updateOperations, op.ID)
+			if attempts, found := s.stepAttempts[op.ID]; found {
+				s.archivedStepAttempts[op.ID] = attempts
+			}
+			delete(s.stepAttempts, op.ID)
+			delete(s.operationPauses, op.ID)
+		}
+	}
+	return nil
+}
+
+func (s *operations) ListArchivedOperationsByInstanceIDs(instanceIDs []string) ([]internal.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations := make([]internal.Operation, 0)
+	for _, op := range s.archivedOperations {
+		for _, id := range instanceIDs {
+			if op.InstanceID == id {
+				operations = append(operations, op)
+				break
+			}
+		}
+	}
+	s.sortByCreatedAt(operations)
+	return operations, nil
+}
+
+func (s *operations) ListArchivedStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := make([]internal.StepAttempt, len(s.archivedStepAttempts[operationID]))
+	copy(attempts, s.archivedStepAttempts[operationID])
+	return attempts, nil
+}
+
+func (s *operations) operationsOfAllTypes() []internal.Operation {
+	ops := make([]internal.Operation, 0, len(s.operations)+len(s.upgradeClusterOperations)+len(s.updateOperations))
+	for _, op := range s.operations {
+		ops = append(ops, op)
+	}
+	for _, op := range s.upgradeClusterOperations {
+		ops = append(ops, op.Operation)
+	}
+	for _, op := range s.updateOperations {
+		ops = append(ops, op.Operation)
+	}
+	return ops
 }
 
 func (s *operations) InsertDeprovisioningOperation(operation internal.DeprovisioningOperation) error {
@@ -199,7 +600,13 @@ func (s *operations) InsertDeprovisioningOperation(operation internal.Deprovisio
 	if _, exists := s.operations[id]; exists {
 		return dberr.AlreadyExists("instance operation with id %s already exist", id)
 	}
+	if _, exists := s.findByRequestIdentity(operation.RequestIdentity); exists {
+		return dberr.AlreadyExists("instance operation with request identity %s already exist", operation.RequestIdentity)
+	}
 
+	if err := s.journal.Apply(put(OperationsBucket, id, newOperationRecord(operation.Operation))); err != nil {
+		return err
+	}
 	s.operations[id] = operation.Operation
 	return nil
 }
@@ -246,6 +653,9 @@ func (s *operations) UpdateDeprovisioningOperation(op internal.DeprovisioningOpe
 		return nil, dberr.Conflict("unable to update deprovisioning operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
 	}
 	op.Version = op.Version + 1
+	if err := s.journal.Apply(put(OperationsBucket, op.ID, newOperationRecord(op.Operation))); err != nil {
+		return nil, err
+	}
 	s.operations[op.ID] = op.Operation
 
 	return &op, nil
@@ -294,6 +704,9 @@ func (s *operations) InsertUpgradeKymaOperation(operation internal.UpgradeKymaOp
 		return dberr.AlreadyExists("instance operation with id %s already exist", id)
 	}
 
+	if err := s.journal.Apply(put(OperationsBucket, id, newOperationRecord(operation.Operation))); err != nil {
+		return err
+	}
 	s.operations[id] = operation.Operation
 	return nil
 }
@@ -334,6 +747,9 @@ func (s *operations) UpdateUpgradeKymaOperation(op internal.UpgradeKymaOperation
 		return nil, dberr.Conflict("unable to update upgradeKyma operation with id %s (for instance id %s) - conflict", op.Operation.ID, op.InstanceID)
 	}
 	op.Version = op.Version + 1
+	if err := s.journal.Apply(put(OperationsBucket, op.Operation.ID, newOperationRecord(op.Operation))); err != nil {
+		return nil, err
+	}
 	s.operations[op.Operation.ID] = op.Operation
 
 	return &op, nil
@@ -348,6 +764,9 @@ func (s *operations) InsertUpgradeClusterOperation(operation internal.UpgradeClu
 		return dberr.AlreadyExists("instance operation with id %s already exist", id)
 	}
 
+	if err := s.journal.Apply(put(UpgradeClusterOperationsBucket, id, newOperationRecord(operation.Operation))); err != nil {
+		return err
+	}
 	s.upgradeClusterOperations[id] = operation
 	return nil
 }
@@ -375,6 +794,9 @@ func (s *operations) UpdateUpgradeClusterOperation(op internal.UpgradeClusterOpe
 		return nil, dberr.Conflict("unable to update upgradeKyma operation with id %s (for instance id %s) - conflict", op.Operation.ID, op.InstanceID)
 	}
 	op.Version = op.Version + 1
+	if err := s.journal.Apply(put(UpgradeClusterOperationsBucket, op.Operation.ID, newOperationRecord(op.Operation))); err != nil {
+		return nil, err
+	}
 	s.upgradeClusterOperations[op.Operation.ID] = op
 
 	return &op, nil
@@ -387,17 +809,17 @@ func (s *operations) GetLastOperation(instanceID string) (*internal.Operation, e
 	var rows []internal.Operation
 
 	for _, op := range s.operations {
-		if op.InstanceID == instanceID && op.State != orchestration.Pending {
+		if op.InstanceID == instanceID && op.State != orchestration.Pending && op.State != orchestration.Canceled {
 			rows = append(rows, op)
 		}
 	}
 	for _, op := range s.upgradeClusterOperations {
-		if op.InstanceID == instanceID && op.State != orchestration.Pending {
+		if op.InstanceID == instanceID && op.State != orchestration.Pending && op.State != orchestration.Canceled {
 			rows = append(rows, op.Operation)
 		}
 	}
 	for _, op := range s.updateOperations {
-		if op.InstanceID == instanceID && op.State != orchestration.Pending {
+		if op.InstanceID == instanceID && op.State != orchestration.Pending && op.State != orchestration.Canceled {
 			rows = append(rows, op.Operation)
 		}
 	}
@@ -413,6 +835,35 @@ func (s *operations) GetLastOperation(instanceID string) (*internal.Operation, e
 	return &rows[0], nil
 }
 
+func (s *operations) GetOperationByRequestIdentity(requestIdentity string) (*internal.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op, exists := s.findByRequestIdentity(requestIdentity)
+	if !exists {
+		return nil, dberr.NotFound("operation with request identity %s not found", requestIdentity)
+	}
+	return &op, nil
+}
+
+// findByRequestIdentity must be called with the lock held
+func (s *operations) findByRequestIdentity(requestIdentity string) (internal.Operation, bool) {
+	if requestIdentity == "" {
+		return internal.Operation{}, false
+	}
+	for _, op := range s.operations {
+		if op.RequestIdentity == requestIdentity {
+			return op, true
+		}
+	}
+	for _, op := range s.updateOperations {
+		if op.RequestIdentity == requestIdentity {
+			return op.Operation, true
+		}
+	}
+	return internal.Operation{}, false
+}
+
 func (s *operations) GetOperationByID(operationID string) (*internal.Operation, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -597,13 +1048,10 @@ func (s *operations) ListOperations(filter dbmodel.OperationFilter) ([]internal.
 	result := make([]internal.Operation, 0)
 	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
 
-	operations, err := s.filterAll(filter)
-	if err != nil {
-		return nil, 0, 0, fmt.Errorf("while listing operations: %w", err)
-	}
+	operations := s.filterAll(filter)
 	s.sortByCreatedAt(operations)
 
-	for i := offset; (filter.PageSize < 1 || i < offset+filter.PageSize) && i < len(operations)+offset; i++ {
+	for i := offset; (filter.PageSize < 1 || i < offset+filter.PageSize) && i < len(operations); i++ {
 		result = append(result, operations[i])
 	}
 
@@ -722,7 +1170,13 @@ func (s *operations) InsertUpdatingOperation(operation internal.UpdatingOperatio
 	if _, exists := s.updateOperations[id]; exists {
 		return dberr.AlreadyExists("instance operation with id %s already exist", id)
 	}
+	if _, exists := s.findByRequestIdentity(operation.RequestIdentity); exists {
+		return dberr.AlreadyExists("instance operation with request identity %s already exist", operation.RequestIdentity)
+	}
 
+	if err := s.journal.Apply(put(UpdatingOperationsBucket, id, newOperationRecord(operation.Operation))); err != nil {
+		return err
+	}
 	s.updateOperations[id] = operation
 	return nil
 }
@@ -752,6 +1206,9 @@ func (s *operations) UpdateUpdatingOperation(op internal.UpdatingOperation) (*in
 		return nil, dberr.Conflict("unable to update updating operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
 	}
 	op.Version = op.Version + 1
+	if err := s.journal.Apply(put(UpdatingOperationsBucket, op.ID, newOperationRecord(op.Operation))); err != nil {
+		return nil, err
+	}
 	s.updateOperations[op.ID] = op
 
 	return &op, nil
@@ -825,34 +1282,20 @@ func (s *operations) sortByCreatedAt(operations []internal.Operation) {
 	})
 }
 
-func (s *operations) getAll() ([]internal.Operation, error) {
-	ops := make([]internal.Operation, 0)
-	for _, op := range s.upgradeClusterOperations {
-		ops = append(ops, op.Operation)
-	}
-	for _, op := range s.operations {
-		ops = append(ops, op)
-	}
-	if len(ops) == 0 {
-		return nil, dberr.NotFound("operations not found")
-	}
-
-	return ops, nil
-}
-
-func (s *operations) filterAll(filter dbmodel.OperationFilter) ([]internal.Operation, error) {
+func (s *operations) filterAll(filter dbmodel.OperationFilter) []internal.Operation {
 	result := make([]internal.Operation, 0)
-	ops, err := s.getAll()
-	if err != nil {
-		return nil, err
-	}
-	for _, op := range ops {
+	for _, op := range s.operationsOfAllTypes() {
 		if ok := matchFilter(string(op.State), filter.States, s.equalFilter); !ok {
 			continue
 		}
+		if filter.InstanceFilter != nil {
+			if ok := matchFilter(op.InstanceID, filter.InstanceFilter.InstanceIDs, s.equalFilter); !ok {
+				continue
+			}
+		}
 		result = append(result, op)
 	}
-	return result, nil
+	return result
 }
 
 func (s *operations) filterUpgradeKyma(orchestrationID string, filter dbmodel.OperationFilter) []internal.UpgradeKymaOperation {
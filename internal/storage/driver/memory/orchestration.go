@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"fmt"
 	"sort"
 	"sync"
 
@@ -16,17 +17,38 @@ type orchestrations struct {
 	mu sync.Mutex
 
 	orchestrations map[string]internal.Orchestration
+	journal        Journal
 }
 
 func NewOrchestrations() *orchestrations {
 	return &orchestrations{
 		orchestrations: make(map[string]internal.Orchestration, 0),
+		journal:        noJournal{},
 	}
 }
 
+// NewOrchestrationsWithJournal creates in-memory storage for orchestrations which persists all changes in the journal.
+// The orchestrations stored in the journal are restored.
+func NewOrchestrationsWithJournal(journal Journal) (*orchestrations, error) {
+	s := NewOrchestrations()
+	s.journal = journal
+
+	err := restore(journal, OrchestrationsBucket, func(o internal.Orchestration) { s.orchestrations[o.OrchestrationID] = o })
+	if err != nil {
+		return nil, fmt.Errorf("while restoring orchestrations: %w", err)
+	}
+	return s, nil
+}
+
 func (s *orchestrations) Insert(orchestration internal.Orchestration) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if _, exists := s.orchestrations[orchestration.OrchestrationID]; exists {
+		return dberr.AlreadyExists("orchestration with id %s already exist", orchestration.OrchestrationID)
+	}
+	if err := s.journal.Apply(put(OrchestrationsBucket, orchestration.OrchestrationID, orchestration)); err != nil {
+		return err
+	}
 	s.orchestrations[orchestration.OrchestrationID] = orchestration
 
 	return nil
@@ -71,6 +93,9 @@ func (s *orchestrations) Update(orchestration internal.Orchestration) error {
 		return dberr.NotFound("orchestration with id %s not exist", orchestration.OrchestrationID)
 
 	}
+	if err := s.journal.Apply(put(OrchestrationsBucket, orchestration.OrchestrationID, orchestration)); err != nil {
+		return err
+	}
 	s.orchestrations[orchestration.OrchestrationID] = orchestration
 
 	return nil
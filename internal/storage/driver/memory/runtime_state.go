@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"fmt"
 	"sort"
 	"sync"
 
@@ -13,17 +14,35 @@ type runtimeState struct {
 	mu sync.Mutex
 
 	runtimeStates map[string]internal.RuntimeState
+	journal       Journal
 }
 
 func NewRuntimeStates() *runtimeState {
 	return &runtimeState{
 		runtimeStates: make(map[string]internal.RuntimeState, 0),
+		journal:       noJournal{},
 	}
 }
 
+// NewRuntimeStatesWithJournal creates in-memory storage for runtime states which persists all changes in the journal.
+// The runtime states stored in the journal are restored.
+func NewRuntimeStatesWithJournal(journal Journal) (*runtimeState, error) {
+	s := NewRuntimeStates()
+	s.journal = journal
+
+	err := restore(journal, RuntimeStatesBucket, func(state internal.RuntimeState) { s.runtimeStates[state.ID] = state })
+	if err != nil {
+		return nil, fmt.Errorf("while restoring runtime states: %w", err)
+	}
+	return s, nil
+}
+
 func (s *runtimeState) Insert(runtimeState internal.RuntimeState) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if err := s.journal.Apply(put(RuntimeStatesBucket, runtimeState.ID, runtimeState)); err != nil {
+		return err
+	}
 	s.runtimeStates[runtimeState.ID] = runtimeState
 
 	return nil
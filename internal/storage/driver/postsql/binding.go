@@ -0,0 +1,179 @@
+package postsql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type Binding struct {
+	postsql.Factory
+
+	cipher Cipher
+}
+
+func NewBinding(sess postsql.Factory, cipher Cipher) *Binding {
+	return &Binding{
+		Factory: sess,
+		cipher:  cipher,
+	}
+}
+
+func (s *Binding) Insert(binding internal.Binding) error {
+	dto, err := s.toBindingDTO(binding)
+	if err != nil {
+		return err
+	}
+
+	sess := s.NewWriteSession()
+	var lastErr dberr.Error
+	err = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = sess.InsertBinding(dto)
+		if lastErr != nil {
+			if dberr.IsAlreadyExists(lastErr) {
+				return false, lastErr
+			}
+			log.Errorf("while saving binding ID %s for instance ID %s: %v", binding.ID, binding.InstanceID, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil && lastErr != nil {
+		return lastErr
+	}
+
+	return err
+}
+
+func (s *Binding) Get(instanceID string, bindingID string) (*internal.Binding, error) {
+	sess := s.NewReadSession()
+	dto := dbmodel.BindingDTO{}
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		dto, lastErr = sess.GetBinding(instanceID, bindingID)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				return false, dberr.NotFound("Binding with id %s for instance %s not exist", bindingID, instanceID)
+			}
+			log.Errorf("while getting binding %s for instance %s: %v", bindingID, instanceID, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	binding, err := s.toBinding(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	return &binding, nil
+}
+
+func (s *Binding) ListByInstanceID(instanceID string) ([]internal.Binding, error) {
+	sess := s.NewReadSession()
+	var dtos []dbmodel.BindingDTO
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		dtos, lastErr = sess.ListBindings(instanceID)
+		if lastErr != nil {
+			log.Errorf("while listing bindings for instance %s: %v", instanceID, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	return s.toBindings(dtos)
+}
+
+func (s *Binding) ListExpired(before time.Time) ([]internal.Binding, error) {
+	sess := s.NewReadSession()
+	var dtos []dbmodel.BindingDTO
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		dtos, lastErr = sess.ListExpiredBindings(before)
+		if lastErr != nil {
+			log.Errorf("while listing bindings expired before %s: %v", before, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	return s.toBindings(dtos)
+}
+
+func (s *Binding) Delete(instanceID, bindingID string) error {
+	sess := s.NewWriteSession()
+	var lastErr dberr.Error
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		lastErr = sess.DeleteBinding(instanceID, bindingID)
+		if lastErr != nil {
+			log.Errorf("while deleting binding %s for instance %s: %v", bindingID, instanceID, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return lastErr
+	}
+	return nil
+}
+
+func (s *Binding) toBindingDTO(binding internal.Binding) (dbmodel.BindingDTO, error) {
+	encrypted, err := s.cipher.Encrypt([]byte(binding.Kubeconfig))
+	if err != nil {
+		return dbmodel.BindingDTO{}, fmt.Errorf("while encrypting kubeconfig: %w", err)
+	}
+
+	return dbmodel.BindingDTO{
+		ID:                binding.ID,
+		InstanceID:        binding.InstanceID,
+		CreatedAt:         binding.CreatedAt,
+		UpdatedAt:         binding.UpdatedAt,
+		ExpiresAt:         binding.ExpiresAt,
+		Kubeconfig:        string(encrypted),
+		ExpirationSeconds: binding.ExpirationSeconds,
+		ClusterRole:       binding.ClusterRole,
+	}, nil
+}
+
+func (s *Binding) toBinding(dto dbmodel.BindingDTO) (internal.Binding, error) {
+	decrypted, err := s.cipher.Decrypt([]byte(dto.Kubeconfig))
+	if err != nil {
+		return internal.Binding{}, fmt.Errorf("while decrypting kubeconfig: %w", err)
+	}
+
+	return internal.Binding{
+		ID:                dto.ID,
+		InstanceID:        dto.InstanceID,
+		CreatedAt:         dto.CreatedAt,
+		UpdatedAt:         dto.UpdatedAt,
+		ExpiresAt:         dto.ExpiresAt,
+		Kubeconfig:        string(decrypted),
+		ExpirationSeconds: dto.ExpirationSeconds,
+		ClusterRole:       dto.ClusterRole,
+	}, nil
+}
+
+func (s *Binding) toBindings(dtos []dbmodel.BindingDTO) ([]internal.Binding, error) {
+	result := make([]internal.Binding, 0, len(dtos))
+	for _, dto := range dtos {
+		binding, err := s.toBinding(dto)
+		if err != nil {
+			return nil, fmt.Errorf("while converting bindings: %w", err)
+		}
+		result = append(result, binding)
+	}
+	return result, nil
+}
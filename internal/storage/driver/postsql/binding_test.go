@@ -0,0 +1,85 @@
+package postsql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinding(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("should insert, fetch and delete Bindings", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		defer tablesCleanupFunc()
+
+		cipher := storage.NewEncrypter(cfg.SecretKey)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+
+		svc := brokerStorage.Bindings()
+		first := fixBinding("binding-1", "instance-1", time.Now())
+		second := fixBinding("binding-2", "instance-1", time.Now().Add(time.Minute))
+		other := fixBinding("binding-1", "instance-2", time.Now())
+
+		for _, binding := range []internal.Binding{second, first, other} {
+			err = svc.Insert(binding)
+			require.NoError(t, err)
+		}
+
+		err = svc.Insert(first)
+		assert.True(t, dberr.IsAlreadyExists(err))
+
+		got, err := svc.Get("instance-1", "binding-1")
+		require.NoError(t, err)
+		assert.Equal(t, first.Kubeconfig, got.Kubeconfig)
+		assert.Equal(t, first.ExpirationSeconds, got.ExpirationSeconds)
+		assert.Equal(t, first.ClusterRole, got.ClusterRole)
+
+		bindings, err := svc.ListByInstanceID("instance-1")
+		require.NoError(t, err)
+		require.Len(t, bindings, 2)
+		assert.Equal(t, "binding-1", bindings[0].ID)
+		assert.Equal(t, "binding-2", bindings[1].ID)
+
+		expired, err := svc.ListExpired(time.Now().Add(10*time.Minute + 30*time.Second))
+		require.NoError(t, err)
+		assert.Len(t, expired, 2)
+
+		err = svc.Delete("instance-1", "binding-1")
+		require.NoError(t, err)
+
+		_, err = svc.Get("instance-1", "binding-1")
+		assert.True(t, dberr.IsNotFound(err))
+		_, err = svc.Get("instance-2", "binding-1")
+		assert.NoError(t, err)
+	})
+}
+
+func fixBinding(id, instanceID string, createdAt time.Time) internal.Binding {
+	return internal.Binding{
+		ID:                id,
+		InstanceID:        instanceID,
+		CreatedAt:         createdAt,
+		UpdatedAt:         createdAt,
+		ExpiresAt:         createdAt.Add(10 * time.Minute),
+		Kubeconfig:        "kubeconfig of " + id,
+		ExpirationSeconds: 600,
+		ClusterRole:       "view",
+	}
+}
@@ -0,0 +1,44 @@
+package postsql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/conformance"
+	eventstorage "github.com/kyma-project/kyma-environment-broker/internal/storage/driver/postsql/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	ctx := context.Background()
+
+	containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+	require.NoError(t, err)
+	defer containerCleanupFunc()
+
+	conformance.Run(t, func(t *testing.T) storage.BrokerStorage {
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		t.Cleanup(tablesCleanupFunc)
+
+		cipher := storage.NewEncrypter(cfg.SecretKey)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+		return brokerStorage
+	})
+
+	conformance.RunEvents(t, func(t *testing.T) storage.Events {
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		t.Cleanup(tablesCleanupFunc)
+
+		connection, err := postsql.InitializeDatabase(cfg.ConnectionURL(), 3, logrus.StandardLogger())
+		require.NoError(t, err)
+		t.Cleanup(func() { connection.Close() })
+		return eventstorage.New(postsql.NewFactory(connection), logrus.StandardLogger())
+	})
+}
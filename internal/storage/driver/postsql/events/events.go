@@ -1,10 +1,14 @@
 package events
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	eventsapi "github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
 	"github.com/sirupsen/logrus"
 )
@@ -24,15 +28,52 @@ func (e *events) ListEvents(filter eventsapi.EventFilter) ([]eventsapi.EventDTO,
 		return nil, fmt.Errorf("events are disabled")
 	}
 	sess := e.NewReadSession()
-	return sess.ListEvents(filter)
+	dtos, err := sess.ListEvents(filter)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]eventsapi.EventDTO, 0, len(dtos))
+	for _, dto := range dtos {
+		event := eventsapi.EventDTO{
+			ID:          dto.ID,
+			Level:       eventsapi.EventLevel(dto.Level),
+			InstanceID:  dto.InstanceID,
+			OperationID: dto.OperationID,
+			Message:     dto.Message,
+			CreatedAt:   dto.CreatedAt,
+		}
+		if dto.Fields.Valid {
+			if err := json.Unmarshal([]byte(dto.Fields.String), &event.Fields); err != nil {
+				return nil, fmt.Errorf("while unmarshalling fields of event %s: %w", dto.ID, err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
 }
 
-func (e *events) InsertEvent(eventLevel eventsapi.EventLevel, message, instanceID, operationID string) {
+func (e *events) InsertEvent(eventLevel eventsapi.EventLevel, message, instanceID, operationID string, fields eventsapi.Fields) {
 	if e == nil {
 		return
 	}
+	event := dbmodel.EventDTO{
+		ID:          uuid.NewString(),
+		Level:       string(eventLevel),
+		InstanceID:  &instanceID,
+		OperationID: &operationID,
+		Message:     message,
+		CreatedAt:   time.Now(),
+	}
+	if len(fields) > 0 {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			e.log.Errorf("failed to marshal fields of event [%v] %v/%v %q: %v", eventLevel, instanceID, operationID, message, err)
+			return
+		}
+		event.Fields = sql.NullString{String: string(data), Valid: true}
+	}
 	sess := e.NewWriteSession()
-	if err := sess.InsertEvent(eventLevel, message, instanceID, operationID); err != nil {
+	if err := sess.InsertEvent(event); err != nil {
 		e.log.Errorf("failed to insert event [%v] %v/%v %q: %v", eventLevel, instanceID, operationID, message, err)
 	}
 }
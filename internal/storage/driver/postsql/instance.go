@@ -56,6 +56,7 @@ func (s *Instance) InsertWithoutEncryption(instance internal.Instance) error {
 		DeletedAt:              instance.DeletedAt,
 		Version:                instance.Version,
 		Provider:               string(instance.Provider),
+		Labels:                 marshalLabels(instance.Labels),
 	}
 
 	sess := s.NewWriteSession()
@@ -99,6 +100,10 @@ func (s *Instance) ListWithoutDecryption(filter dbmodel.InstanceFilter) ([]inter
 			Version:         dto.Version,
 			Provider:        internal.CloudProvider(dto.Provider),
 		}
+		instance.Labels, err = unmarshalLabels(dto.Labels)
+		if err != nil {
+			return nil, 0, 0, err
+		}
 		instances = append(instances, instance)
 	}
 	return instances, count, totalCount, err
@@ -127,6 +132,7 @@ func (s *Instance) UpdateWithoutEncryption(instance internal.Instance) (*interna
 		DeletedAt:              instance.DeletedAt,
 		Version:                instance.Version,
 		Provider:               string(instance.Provider),
+		Labels:                 marshalLabels(instance.Labels),
 	}
 	var lastErr dberr.Error
 	err = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
@@ -302,6 +308,25 @@ func (s *Instance) GetNumberOfInstancesForGlobalAccountID(globalAccountID string
 	return result, err
 }
 
+func (s *Instance) GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID string) (map[string]int, error) {
+	sess := s.NewReadSession()
+	var entries []dbmodel.InstanceByPlanIDStatEntry
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		var err error
+		entries, err = sess.GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID)
+		return err == nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int)
+	for _, e := range entries {
+		result[e.ServicePlanID] = e.Total
+	}
+	return result, nil
+}
+
 // TODO: Wrap retries in single method WithRetries
 func (s *Instance) GetByID(instanceID string) (*internal.Instance, error) {
 	sess := s.NewReadSession()
@@ -353,6 +378,11 @@ func (s *Instance) toInstance(dto dbmodel.InstanceDTO) (internal.Instance, error
 		log.Warn("decrypting skipped because kubeconfig is in a plain text")
 	}
 
+	labels, err := unmarshalLabels(dto.Labels)
+	if err != nil {
+		return internal.Instance{}, err
+	}
+
 	return internal.Instance{
 		InstanceID:                  dto.InstanceID,
 		RuntimeID:                   dto.RuntimeID,
@@ -370,8 +400,10 @@ func (s *Instance) toInstance(dto dbmodel.InstanceDTO) (internal.Instance, error
 		UpdatedAt:                   dto.UpdatedAt,
 		DeletedAt:                   dto.DeletedAt,
 		ExpiredAt:                   dto.ExpiredAt,
+		CompensationFailedAt:        dto.CompensationFailedAt,
 		Version:                     dto.Version,
 		Provider:                    internal.CloudProvider(dto.Provider),
+		Labels:                      labels,
 	}, nil
 }
 
@@ -397,6 +429,37 @@ func (s *Instance) Insert(instance internal.Instance) error {
 	})
 }
 
+func (s *Instance) InsertWithinQuota(instance internal.Instance, limit int) (bool, error) {
+	dto, err := s.toInstanceDTO(instance)
+	if err != nil {
+		return false, err
+	}
+
+	sess, dbErr := s.NewSessionWithinTransaction()
+	if dbErr != nil {
+		return false, fmt.Errorf("while starting transaction: %w", dbErr)
+	}
+	defer sess.RollbackUnlessCommitted()
+
+	if dbErr := sess.LockGlobalAccountInstances(instance.GlobalAccountID); dbErr != nil {
+		return false, dbErr
+	}
+	count, dbErr := sess.CountInstancesOfPlan(instance.GlobalAccountID, instance.ServicePlanID)
+	if dbErr != nil {
+		return false, dbErr
+	}
+	if count >= limit {
+		return false, nil
+	}
+	if dbErr := sess.InsertInstance(dto); dbErr != nil {
+		return false, dbErr
+	}
+	if dbErr := sess.Commit(); dbErr != nil {
+		return false, dbErr
+	}
+	return true, nil
+}
+
 func (s *Instance) Update(instance internal.Instance) (*internal.Instance, error) {
 	sess := s.NewWriteSession()
 	dto, err := s.toInstanceDTO(instance)
@@ -464,8 +527,10 @@ func (s *Instance) toInstanceDTO(instance internal.Instance) (dbmodel.InstanceDT
 		UpdatedAt:                   instance.UpdatedAt,
 		DeletedAt:                   instance.DeletedAt,
 		ExpiredAt:                   instance.ExpiredAt,
+		CompensationFailedAt:        instance.CompensationFailedAt,
 		Version:                     instance.Version,
 		Provider:                    string(instance.Provider),
+		Labels:                      marshalLabels(instance.Labels),
 	}, nil
 }
 
@@ -529,3 +594,22 @@ func (s *Instance) List(filter dbmodel.InstanceFilter) ([]internal.Instance, int
 	}
 	return instances, count, totalCount, err
 }
+
+func marshalLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "{}"
+	}
+	data, _ := json.Marshal(labels)
+	return string(data)
+}
+
+func unmarshalLabels(data string) (map[string]string, error) {
+	if data == "" || data == "{}" {
+		return nil, nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(data), &labels); err != nil {
+		return nil, fmt.Errorf("while unmarshal labels: %w", err)
+	}
+	return labels, nil
+}
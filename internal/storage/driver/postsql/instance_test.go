@@ -140,7 +140,7 @@ func TestInstance(t *testing.T) {
 		// populate database with samples
 		fixInstances := []internal.Instance{
 			*fixInstance(instanceData{val: "A1", globalAccountID: "A"}),
-			*fixInstance(instanceData{val: "A2", globalAccountID: "A", deletedAt: time.Time{}}),
+			*fixInstance(instanceData{val: "A2", globalAccountID: "A", deletedAt: time.Time{}, trial: true}),
 			*fixInstance(instanceData{val: "C1", globalAccountID: "C"}),
 			*fixInstance(instanceData{val: "C2", globalAccountID: "C", deletedAt: time.Now()}),
 			*fixInstance(instanceData{val: "B1", globalAccountID: "B", deletedAt: time.Now()}),
@@ -160,6 +160,10 @@ func TestInstance(t *testing.T) {
 		require.NoError(t, err)
 		numberOfInstancesB, err := brokerStorage.Instances().GetNumberOfInstancesForGlobalAccountID("B")
 		require.NoError(t, err)
+		numberOfInstancesAByPlan, err := brokerStorage.Instances().GetNumberOfInstancesByPlanForGlobalAccountID("A")
+		require.NoError(t, err)
+		numberOfInstancesBByPlan, err := brokerStorage.Instances().GetNumberOfInstancesByPlanForGlobalAccountID("B")
+		require.NoError(t, err)
 
 		t.Logf("%+v", stats)
 
@@ -171,6 +175,8 @@ func TestInstance(t *testing.T) {
 		assert.Equal(t, 2, numberOfInstancesA)
 		assert.Equal(t, 1, numberOfInstancesC)
 		assert.Equal(t, 0, numberOfInstancesB)
+		assert.Equal(t, map[string]int{fixture.PlanId: 1, broker.TrialPlanID: 1}, numberOfInstancesAByPlan)
+		assert.Empty(t, numberOfInstancesBByPlan)
 	})
 
 	t.Run("Should fetch instances along with their operations", func(t *testing.T) {
@@ -374,6 +380,8 @@ func TestInstance(t *testing.T) {
 			fixture.FixProvisioningOperation("op3", "inst3"),
 			fixture.FixProvisioningOperation("op4", "expiredinstance"),
 		}
+		fixInstances[1].Labels = map[string]string{"team": "payments", "env": "staging"}
+		fixInstances[2].Labels = map[string]string{"team": "payments"}
 		for i, v := range fixInstances {
 			v.InstanceDetails = fixture.FixInstanceDetails(v.InstanceID)
 			fixInstances[i] = v
@@ -454,6 +462,25 @@ func TestInstance(t *testing.T) {
 
 		assert.Equal(t, fixInstances[1].InstanceID, out[0].InstanceID)
 
+		// when
+		out, count, totalCount, err = brokerStorage.Instances().List(dbmodel.InstanceFilter{Labels: map[string]string{"team": "payments", "env": "staging"}})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, 1, totalCount)
+
+		assert.Equal(t, fixInstances[1].InstanceID, out[0].InstanceID)
+		assert.Equal(t, fixInstances[1].Labels, out[0].Labels)
+
+		// when
+		out, count, totalCount, err = brokerStorage.Instances().List(dbmodel.InstanceFilter{Labels: map[string]string{"team": "payments"}})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		require.Equal(t, 2, totalCount)
+
 		// when
 		out, count, totalCount, err = brokerStorage.Instances().List(dbmodel.InstanceFilter{Expired: ptr.Bool(true)})
 		require.NoError(t, err)
@@ -388,6 +388,38 @@ func (s *operations) GetOperationByID(operationID string) (*internal.Operation,
 	return &op, nil
 }
 
+// GetOperationByRequestIdentity returns the operation created by the OSB request with the given X-Broker-API-Request-Identity header
+func (s *operations) GetOperationByRequestIdentity(requestIdentity string) (*internal.Operation, error) {
+	var lastErr dberr.Error
+	session := s.NewReadSession()
+	dto := dbmodel.OperationDTO{}
+	err := wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
+		dto, lastErr = session.GetOperationByRequestIdentity(requestIdentity)
+		if lastErr != nil {
+			if dberr.IsNotFound(lastErr) {
+				lastErr = dberr.NotFound("operation with request identity %s not exist", requestIdentity)
+				return false, lastErr
+			}
+			log.Errorf("while reading operation from the storage: %v", lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := s.toOperation(&dto, internal.Operation{})
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(dto.Data), &op)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshall operation data")
+	}
+	return &op, nil
+}
+
 func (s *operations) GetNotFinishedOperationsByType(operationType internal.OperationType) ([]internal.Operation, error) {
 	session := s.NewReadSession()
 	operations := make([]dbmodel.OperationDTO, 0)
@@ -710,6 +742,212 @@ func (s *operations) ListOperationsInTimeRange(from, to time.Time) ([]internal.O
 	return ret, nil
 }
 
+// InsertStepAttempt stores the step attempt in the operation step history
+func (s *operations) InsertStepAttempt(attempt internal.StepAttempt) error {
+	dto := dbmodel.StepAttemptDTO{
+		OperationID:    attempt.OperationID,
+		Stage:          attempt.Stage,
+		StepName:       attempt.StepName,
+		StartedAt:      attempt.StartedAt,
+		DurationMs:     attempt.Duration.Milliseconds(),
+		BackoffMs:      attempt.Backoff.Milliseconds(),
+		ErrorReason:    attempt.ErrorReason,
+		ErrorComponent: attempt.ErrorComponent,
+		Error:          attempt.Error,
+	}
+	return s.NewWriteSession().InsertStepAttempt(dto)
+}
+
+// ListStepAttemptsByOperationID returns the step history of the operation in the order of execution
+func (s *operations) ListStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error) {
+	dtos, err := s.NewReadSession().ListStepAttempts(operationID)
+	if err != nil {
+		return nil, fmt.Errorf("while listing step attempts of operation %s: %w", operationID, err)
+	}
+	return toStepAttempts(dtos), nil
+}
+
+func toStepAttempts(dtos []dbmodel.StepAttemptDTO) []internal.StepAttempt {
+	attempts := make([]internal.StepAttempt, 0, len(dtos))
+	for _, dto := range dtos {
+		attempts = append(attempts, internal.StepAttempt{
+			OperationID:    dto.OperationID,
+			Stage:          dto.Stage,
+			StepName:       dto.StepName,
+			StartedAt:      dto.StartedAt,
+			Duration:       time.Duration(dto.DurationMs) * time.Millisecond,
+			Backoff:        time.Duration(dto.BackoffMs) * time.Millisecond,
+			ErrorReason:    dto.ErrorReason,
+			ErrorComponent: dto.ErrorComponent,
+			Error:          dto.Error,
+		})
+	}
+	return attempts
+}
+
+func (s *operations) InsertProcessingPause(pause internal.ProcessingPause) error {
+	return s.NewWriteSession().InsertProcessingPause(dbmodel.ProcessingPauseDTO{
+		OperationType: string(pause.OperationType),
+		Reason:        pause.Reason,
+		PausedAt:      pause.PausedAt,
+	})
+}
+
+func (s *operations) GetProcessingPause(operationType internal.OperationType) (*internal.ProcessingPause, error) {
+	dto, err := s.NewReadSession().GetProcessingPause(string(operationType))
+	if err != nil {
+		return nil, err
+	}
+	pause := toProcessingPause(dto)
+	return &pause, nil
+}
+
+func (s *operations) ListProcessingPauses() ([]internal.ProcessingPause, error) {
+	dtos, err := s.NewReadSession().ListProcessingPauses()
+	if err != nil {
+		return nil, fmt.Errorf("while listing processing pauses: %w", err)
+	}
+
+	pauses := make([]internal.ProcessingPause, 0, len(dtos))
+	for _, dto := range dtos {
+		pauses = append(pauses, toProcessingPause(dto))
+	}
+	return pauses, nil
+}
+
+func (s *operations) DeleteProcessingPause(operationType internal.OperationType) error {
+	return s.NewWriteSession().DeleteProcessingPause(string(operationType))
+}
+
+func (s *operations) UpsertOperationPause(pause internal.OperationPause) error {
+	return s.NewWriteSession().UpsertOperationPause(dbmodel.OperationPauseDTO{
+		OperationID: pause.OperationID,
+		Paused:      pause.Paused,
+		PausedAt:    pause.PausedAt,
+		ResumedAt:   pause.ResumedAt,
+	})
+}
+
+func (s *operations) GetOperationPause(operationID string) (*internal.OperationPause, error) {
+	dto, err := s.NewReadSession().GetOperationPause(operationID)
+	if err != nil {
+		return nil, err
+	}
+	pause := toOperationPause(dto)
+	return &pause, nil
+}
+
+func (s *operations) ListPausedOperations() ([]internal.OperationPause, error) {
+	dtos, err := s.NewReadSession().ListPausedOperations()
+	if err != nil {
+		return nil, fmt.Errorf("while listing paused operations: %w", err)
+	}
+
+	pauses := make([]internal.OperationPause, 0, len(dtos))
+	for _, dto := range dtos {
+		pauses = append(pauses, toOperationPause(dto))
+	}
+	return pauses, nil
+}
+
+func (s *operations) AcquireLease(lease internal.Lease) (bool, error) {
+	return s.NewWriteSession().AcquireLease(dbmodel.LeaseDTO{
+		ID:         lease.ID,
+		Kind:       lease.Kind,
+		Owner:      lease.Owner,
+		LeaseUntil: lease.LeaseUntil,
+	}, time.Now())
+}
+
+func (s *operations) ReleaseLease(id, owner string) error {
+	return s.NewWriteSession().ReleaseLease(id, owner)
+}
+
+func (s *operations) ListExpiredLeases(kind string, now time.Time) ([]internal.Lease, error) {
+	dtos, err := s.NewReadSession().ListExpiredLeases(kind, now)
+	if err != nil {
+		return nil, fmt.Errorf("while listing expired leases: %w", err)
+	}
+
+	leases := make([]internal.Lease, 0, len(dtos))
+	for _, dto := range dtos {
+		leases = append(leases, internal.Lease{
+			ID:         dto.ID,
+			Kind:       dto.Kind,
+			Owner:      dto.Owner,
+			LeaseUntil: dto.LeaseUntil,
+		})
+	}
+	return leases, nil
+}
+
+func (s *operations) ListInstancesToArchive(finishedBefore time.Time, limit int) ([]string, error) {
+	instanceIDs, err := s.NewReadSession().ListInstancesToArchive(finishedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("while listing instances to archive: %w", err)
+	}
+	return instanceIDs, nil
+}
+
+func (s *operations) ArchiveOperations(instanceID, summaryOperationID string) error {
+	session, err := s.NewSessionWithinTransaction()
+	if err != nil {
+		return fmt.Errorf("while starting transaction: %w", err)
+	}
+	defer session.RollbackUnlessCommitted()
+
+	if err := session.ArchiveOperations(instanceID, time.Now()); err != nil {
+		return err
+	}
+	if err := session.ArchiveStepAttempts(instanceID, summaryOperationID, time.Now()); err != nil {
+		return err
+	}
+	if err := session.DeleteStepAttemptsOfArchivedOperations(instanceID, summaryOperationID); err != nil {
+		return err
+	}
+	if err := session.DeleteOperationPausesOfArchivedOperations(instanceID, summaryOperationID); err != nil {
+		return err
+	}
+	if err := session.DeleteArchivedOperations(instanceID, summaryOperationID); err != nil {
+		return err
+	}
+	return session.Commit()
+}
+
+func (s *operations) ListArchivedOperationsByInstanceIDs(instanceIDs []string) ([]internal.Operation, error) {
+	dtos, err := s.NewReadSession().ListArchivedOperations(instanceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("while listing archived operations: %w", err)
+	}
+	return s.toOperations(dtos)
+}
+
+// ListArchivedStepAttemptsByOperationID returns the archived step history of the operation in the order of execution
+func (s *operations) ListArchivedStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error) {
+	dtos, err := s.NewReadSession().ListArchivedStepAttempts(operationID)
+	if err != nil {
+		return nil, fmt.Errorf("while listing archived step attempts of operation %s: %w", operationID, err)
+	}
+	return toStepAttempts(dtos), nil
+}
+
+func toProcessingPause(dto dbmodel.ProcessingPauseDTO) internal.ProcessingPause {
+	return internal.ProcessingPause{
+		OperationType: internal.OperationType(dto.OperationType),
+		Reason:        dto.Reason,
+		PausedAt:      dto.PausedAt,
+	}
+}
+
+func toOperationPause(dto dbmodel.OperationPauseDTO) internal.OperationPause {
+	return internal.OperationPause{
+		OperationID: dto.OperationID,
+		Paused:      dto.Paused,
+		PausedAt:    dto.PausedAt,
+		ResumedAt:   dto.ResumedAt,
+	}
+}
+
 func (s *operations) InsertUpdatingOperation(operation internal.UpdatingOperation) error {
 	dto, err := s.updateOperationToDTO(&operation)
 	if err != nil {
@@ -922,6 +1160,7 @@ func (s *operations) operationToDB(op internal.Operation) (dbmodel.OperationDTO,
 		OrchestrationID:        storage.StringToSQLNullString(op.OrchestrationID),
 		ProvisioningParameters: storage.StringToSQLNullString(string(pp)),
 		FinishedStages:         storage.StringToSQLNullString(strings.Join(op.FinishedStages, ",")),
+		RequestIdentity:        op.RequestIdentity,
 	}, nil
 }
 
@@ -952,6 +1191,7 @@ func (s *operations) toOperation(dto *dbmodel.OperationDTO, existingOp internal.
 	}
 
 	existingOp.ID = dto.ID
+	existingOp.RuntimeOperation.ID = dto.ID
 	existingOp.CreatedAt = dto.CreatedAt
 	existingOp.UpdatedAt = dto.UpdatedAt
 	existingOp.Type = dto.Type
@@ -963,6 +1203,7 @@ func (s *operations) toOperation(dto *dbmodel.OperationDTO, existingOp internal.
 	existingOp.OrchestrationID = storage.SQLNullStringToString(dto.OrchestrationID)
 	existingOp.ProvisioningParameters = provisioningParameters
 	existingOp.FinishedStages = stages
+	existingOp.RequestIdentity = dto.RequestIdentity
 
 	return existingOp, nil
 }
@@ -1297,6 +1538,9 @@ func (s *operations) insert(dto dbmodel.OperationDTO) error {
 	_ = wait.PollImmediate(defaultRetryInterval, defaultRetryTimeout, func() (bool, error) {
 		lastErr = session.InsertOperation(dto)
 		if lastErr != nil {
+			if dberr.IsAlreadyExists(lastErr) {
+				return false, lastErr
+			}
 			log.Errorf("while insert operation: %v", lastErr)
 			return false, nil
 		}
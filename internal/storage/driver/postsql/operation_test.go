@@ -12,6 +12,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/sirupsen/logrus"
@@ -123,6 +124,269 @@ func TestOperation(t *testing.T) {
 		assertEmptyResultForNonExistingIds(t, svc)
 	})
 
+	t.Run("Operations - request identity", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		defer tablesCleanupFunc()
+
+		cipher := storage.NewEncrypter(cfg.SecretKey)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+
+		svc := brokerStorage.Operations()
+
+		givenOperation := fixture.FixOperation("operation-id", "inst-id", internal.OperationTypeProvision)
+		givenOperation.InputCreator = nil
+		givenOperation.RequestIdentity = "request-1"
+		duplicatedOperation := fixture.FixOperation("other-operation-id", "inst-id", internal.OperationTypeProvision)
+		duplicatedOperation.InputCreator = nil
+		duplicatedOperation.RequestIdentity = "request-1"
+		withoutIdentity := fixture.FixOperation("without-identity-1", "inst-id", internal.OperationTypeUpdate)
+		withoutIdentity.InputCreator = nil
+		otherWithoutIdentity := fixture.FixOperation("without-identity-2", "inst-id", internal.OperationTypeUpdate)
+		otherWithoutIdentity.InputCreator = nil
+
+		// when
+		err = svc.InsertOperation(givenOperation)
+		require.NoError(t, err)
+		err = svc.InsertOperation(duplicatedOperation)
+
+		// then
+		assert.True(t, dberr.IsAlreadyExists(err))
+		require.NoError(t, svc.InsertOperation(withoutIdentity))
+		require.NoError(t, svc.InsertOperation(otherWithoutIdentity))
+
+		gotOperation, err := svc.GetOperationByRequestIdentity("request-1")
+		require.NoError(t, err)
+		assert.Equal(t, givenOperation.ID, gotOperation.ID)
+		assert.Equal(t, "request-1", gotOperation.RequestIdentity)
+
+		_, err = svc.GetOperationByRequestIdentity("request-2")
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("Operations - step attempts", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		defer tablesCleanupFunc()
+
+		cipher := storage.NewEncrypter(cfg.SecretKey)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+
+		svc := brokerStorage.Operations()
+		startedAt := time.Now().UTC().Truncate(time.Millisecond)
+		first := internal.StepAttempt{
+			OperationID: "operation-id",
+			Stage:       "create_runtime",
+			StepName:    "Create_Runtime",
+			StartedAt:   startedAt,
+			Duration:    20 * time.Millisecond,
+			Backoff:     time.Minute,
+		}
+		second := internal.StepAttempt{
+			OperationID:    "operation-id",
+			Stage:          "create_runtime",
+			StepName:       "Create_Runtime",
+			StartedAt:      startedAt.Add(time.Minute),
+			Duration:       30 * time.Millisecond,
+			ErrorReason:    "err_keb_internal",
+			ErrorComponent: "keb",
+			Error:          "timeout",
+		}
+		other := internal.StepAttempt{OperationID: "other-operation-id", StepName: "Starting", StartedAt: startedAt}
+
+		// when
+		for _, attempt := range []internal.StepAttempt{first, other, second} {
+			require.NoError(t, svc.InsertStepAttempt(attempt))
+		}
+
+		// then
+		attempts, err := svc.ListStepAttemptsByOperationID("operation-id")
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+		assert.Equal(t, first.Backoff, attempts[0].Backoff)
+		assert.True(t, first.StartedAt.Equal(attempts[0].StartedAt))
+		assert.Equal(t, second.Duration, attempts[1].Duration)
+		assert.Equal(t, second.ErrorReason, attempts[1].ErrorReason)
+		assert.Equal(t, second.Error, attempts[1].Error)
+
+		attempts, err = svc.ListStepAttemptsByOperationID("unknown-operation-id")
+		require.NoError(t, err)
+		assert.Empty(t, attempts)
+	})
+
+	t.Run("Operations - processing pauses", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		defer tablesCleanupFunc()
+
+		cipher := storage.NewEncrypter(cfg.SecretKey)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+
+		svc := brokerStorage.Operations()
+		pause := internal.ProcessingPause{
+			OperationType: internal.OperationTypeProvision,
+			Reason:        "incident",
+			PausedAt:      time.Now().UTC().Truncate(time.Millisecond),
+		}
+
+		// when
+		require.NoError(t, svc.InsertProcessingPause(pause))
+		err = svc.InsertProcessingPause(pause)
+
+		// then
+		assert.True(t, dberr.IsAlreadyExists(err))
+		got, err := svc.GetProcessingPause(internal.OperationTypeProvision)
+		require.NoError(t, err)
+		assert.Equal(t, pause.Reason, got.Reason)
+		assert.True(t, pause.PausedAt.Equal(got.PausedAt))
+		pauses, err := svc.ListProcessingPauses()
+		require.NoError(t, err)
+		assert.Len(t, pauses, 1)
+
+		// when
+		require.NoError(t, svc.DeleteProcessingPause(internal.OperationTypeProvision))
+
+		// then
+		_, err = svc.GetProcessingPause(internal.OperationTypeProvision)
+		assert.True(t, dberr.IsNotFound(err))
+		pauses, err = svc.ListProcessingPauses()
+		require.NoError(t, err)
+		assert.Empty(t, pauses)
+	})
+
+	t.Run("Operations - leases", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		defer tablesCleanupFunc()
+
+		cipher := storage.NewEncrypter(cfg.SecretKey)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+
+		svc := brokerStorage.Operations()
+		lease := internal.Lease{ID: "operation-id", Kind: "provision", Owner: "replica-1", LeaseUntil: time.Now().Add(time.Hour)}
+
+		// when
+		acquired, err := svc.AcquireLease(lease)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		// when
+		acquired, err = svc.AcquireLease(internal.Lease{ID: "operation-id", Kind: "provision", Owner: "replica-2", LeaseUntil: time.Now().Add(time.Hour)})
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		// when
+		lease.LeaseUntil = time.Now().Add(-time.Minute)
+		acquired, err = svc.AcquireLease(lease)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		expired, err := svc.ListExpiredLeases("provision", time.Now())
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		assert.Equal(t, "replica-1", expired[0].Owner)
+
+		// when
+		acquired, err = svc.AcquireLease(internal.Lease{ID: "operation-id", Kind: "provision", Owner: "replica-2", LeaseUntil: time.Now().Add(time.Hour)})
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, svc.ReleaseLease("operation-id", "replica-1"))
+		acquired, err = svc.AcquireLease(lease)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		// when
+		require.NoError(t, svc.ReleaseLease("operation-id", "replica-2"))
+		acquired, err = svc.AcquireLease(lease)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, acquired)
+	})
+
+	t.Run("Operations - archive", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		defer tablesCleanupFunc()
+
+		cipher := storage.NewEncrypter(cfg.SecretKey)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+
+		svc := brokerStorage.Operations()
+		old := time.Now().Add(-time.Hour)
+		for _, op := range []internal.Operation{
+			fixture.FixOperation("deprovisioned-provisioning", "deprovisioned", internal.OperationTypeProvision),
+			fixture.FixOperation("deprovisioned-deprovisioning", "deprovisioned", internal.OperationTypeDeprovision),
+			fixture.FixOperation("existing-provisioning", "existing", internal.OperationTypeProvision),
+		} {
+			op.State = domain.Succeeded
+			op.CreatedAt, op.UpdatedAt = old, old
+			require.NoError(t, svc.InsertOperation(op))
+		}
+		require.NoError(t, brokerStorage.Instances().Insert(fixture.FixInstance("existing")))
+
+		// when
+		instanceIDs, err := svc.ListInstancesToArchive(time.Now(), 10)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"deprovisioned"}, instanceIDs)
+
+		// when
+		err = svc.ArchiveOperations("deprovisioned", "deprovisioned-deprovisioning")
+
+		// then
+		require.NoError(t, err)
+		operations, err := svc.ListOperationsByInstanceID("deprovisioned")
+		require.NoError(t, err)
+		require.Len(t, operations, 1)
+		assert.Equal(t, "deprovisioned-deprovisioning", operations[0].ID)
+		archived, err := svc.ListArchivedOperationsByInstanceIDs([]string{"deprovisioned", "existing"})
+		require.NoError(t, err)
+		require.Len(t, archived, 2)
+		assert.Equal(t, fixture.GlobalAccountId, archived[0].ProvisioningParameters.ErsContext.GlobalAccountID)
+		instanceIDs, err = svc.ListInstancesToArchive(time.Now(), 10)
+		require.NoError(t, err)
+		assert.Empty(t, instanceIDs)
+	})
+
 	t.Run("Provisioning", func(t *testing.T) {
 		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
 		require.NoError(t, err)
@@ -0,0 +1,194 @@
+package postsql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+	"github.com/sirupsen/logrus"
+)
+
+// ReencryptionCipher is the cipher which can tell if data must be encrypted again with the active key
+type ReencryptionCipher interface {
+	Cipher
+	NeedsReencryption(obj []byte) bool
+}
+
+// ReencryptionStats describes rows of the table processed by the re-encryption
+type ReencryptionStats struct {
+	Table string
+	// Scanned is the number of rows read, Reencrypted is the number of rows encrypted with the active key
+	// (in the dry run mode - the number of rows which must be encrypted again)
+	Scanned     int
+	Reencrypted int
+	// Changed is the number of rows changed by other processes in the meantime, they are encrypted with the active key by those processes
+	Changed int
+	Failed  int
+}
+
+// Reencryption walks instances, operations, archived operations, runtime states and bindings in batches and encrypts their data with the active key.
+type Reencryption struct {
+	postsql.Factory
+
+	cipher    ReencryptionCipher
+	batchSize int
+	dryRun    bool
+	log       logrus.FieldLogger
+}
+
+type encryptedTable struct {
+	name       string
+	keyColumns []string
+	columns    []string
+	reencrypt  func(value string) (string, bool, error)
+}
+
+func NewReencryption(sess postsql.Factory, cipher ReencryptionCipher, batchSize int, dryRun bool, log logrus.FieldLogger) *Reencryption {
+	return &Reencryption{
+		Factory:   sess,
+		cipher:    cipher,
+		batchSize: batchSize,
+		dryRun:    dryRun,
+		log:       log,
+	}
+}
+
+func (r *Reencryption) Run() ([]ReencryptionStats, error) {
+	tables := []encryptedTable{
+		{name: postsql.InstancesTableName, keyColumns: []string{"instance_id"}, columns: []string{"provisioning_parameters"}, reencrypt: r.reencryptProvisioningParameters},
+		{name: postsql.OperationTableName, keyColumns: []string{"id"}, columns: []string{"provisioning_parameters"}, reencrypt: r.reencryptProvisioningParameters},
+		{name: postsql.OperationsArchiveTable, keyColumns: []string{"id"}, columns: []string{"provisioning_parameters"}, reencrypt: r.reencryptProvisioningParameters},
+		{name: postsql.RuntimeStateTableName, keyColumns: []string{"id"}, columns: []string{"kyma_config", "cluster_setup"}, reencrypt: r.reencryptValue},
+		{name: postsql.BindingsTableName, keyColumns: []string{"instance_id", "id"}, columns: []string{"kubeconfig"}, reencrypt: r.reencryptValue},
+	}
+	var stats []ReencryptionStats
+	for _, table := range tables {
+		tableStats, err := r.reencryptTable(table)
+		stats = append(stats, tableStats)
+		if err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+func (r *Reencryption) reencryptTable(table encryptedTable) (ReencryptionStats, error) {
+	stats := ReencryptionStats{Table: table.name}
+	log := r.log.WithField("table", table.name)
+	var afterKey []string
+	for {
+		rows, err := r.NewReadSession().ListEncryptedColumns(table.name, table.keyColumns, table.columns, afterKey, r.batchSize)
+		if err != nil {
+			return stats, fmt.Errorf("while listing rows of %s table: %w", table.name, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			stats.Scanned++
+			afterKey = row.Key
+			rowID := strings.Join(row.Key, "/")
+
+			values := map[string]string{}
+			var reencryptErr error
+			for _, column := range table.columns {
+				value, changed, err := table.reencrypt(row.Columns[column])
+				if err != nil {
+					reencryptErr = fmt.Errorf("column %s: %w", column, err)
+					break
+				}
+				if changed {
+					values[column] = value
+				}
+			}
+			switch {
+			case reencryptErr != nil:
+				log.Errorf("unable to encrypt row %s with the active key: %s", rowID, reencryptErr)
+				stats.Failed++
+				continue
+			case len(values) == 0:
+				continue
+			case r.dryRun:
+				stats.Reencrypted++
+				continue
+			}
+
+			updated, err := r.NewWriteSession().UpdateEncryptedColumns(table.name, table.keyColumns, row.Key, values, row.Columns)
+			switch {
+			case err != nil:
+				log.Errorf("unable to save row %s encrypted with the active key: %s", rowID, err)
+				stats.Failed++
+			case !updated:
+				log.Infof("row %s was changed in the meantime, skipping", rowID)
+				stats.Changed++
+			default:
+				stats.Reencrypted++
+			}
+		}
+		log.Infof("processed %d rows", stats.Scanned)
+	}
+	return stats, nil
+}
+
+// reencryptValue encrypts the value with the active key, it returns false if the value is already encrypted with the active key
+func (r *Reencryption) reencryptValue(value string) (string, bool, error) {
+	if !r.cipher.NeedsReencryption([]byte(value)) {
+		return value, false, nil
+	}
+	decrypted, err := r.cipher.Decrypt([]byte(value))
+	if err != nil {
+		return value, false, fmt.Errorf("while decrypting: %w", err)
+	}
+	encrypted, err := r.cipher.Encrypt(decrypted)
+	if err != nil {
+		return value, false, fmt.Errorf("while encrypting: %w", err)
+	}
+	return string(encrypted), true, nil
+}
+
+// reencryptProvisioningParameters encrypts SM credentials and the kubeconfig of the provisioning parameters with the active key
+func (r *Reencryption) reencryptProvisioningParameters(value string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+	var params internal.ProvisioningParameters
+	err := json.Unmarshal([]byte(value), &params)
+	if err != nil {
+		return value, false, fmt.Errorf("while unmarshal parameters: %w", err)
+	}
+
+	var encrypted []string
+	if creds := params.ErsContext.SMOperatorCredentials; creds != nil {
+		encrypted = append(encrypted, creds.ClientID, creds.ClientSecret)
+	}
+	encrypted = append(encrypted, params.Parameters.Kubeconfig)
+	needsReencryption := false
+	for _, v := range encrypted {
+		if r.cipher.NeedsReencryption([]byte(v)) {
+			needsReencryption = true
+		}
+	}
+	if !needsReencryption {
+		return value, false, nil
+	}
+
+	if err := r.cipher.DecryptSMCreds(&params); err != nil {
+		return value, false, fmt.Errorf("while decrypting parameters: %w", err)
+	}
+	if err := r.cipher.DecryptKubeconfig(&params); err != nil {
+		return value, false, fmt.Errorf("while decrypting kubeconfig: %w", err)
+	}
+	if err := r.cipher.EncryptSMCreds(&params); err != nil {
+		return value, false, fmt.Errorf("while encrypting parameters: %w", err)
+	}
+	if err := r.cipher.EncryptKubeconfig(&params); err != nil {
+		return value, false, fmt.Errorf("while encrypting kubeconfig: %w", err)
+	}
+	marshalled, err := json.Marshal(params)
+	if err != nil {
+		return value, false, fmt.Errorf("while marshaling parameters: %w", err)
+	}
+	return string(marshalled), true, nil
+}
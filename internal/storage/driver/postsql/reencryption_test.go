@@ -0,0 +1,111 @@
+package postsql_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestReencryption(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("should encrypt data with the active key", func(t *testing.T) {
+		containerCleanupFunc, cfg, err := storage.InitTestDBContainer(t.Logf, ctx, "test_DB_1")
+		require.NoError(t, err)
+		defer containerCleanupFunc()
+
+		tablesCleanupFunc, err := storage.InitTestDBTables(t, cfg.ConnectionURL())
+		require.NoError(t, err)
+		defer tablesCleanupFunc()
+
+		// given
+		oldCipher := storage.NewEncrypter(cfg.SecretKey)
+		oldStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, oldCipher, logrus.StandardLogger())
+		require.NoError(t, err)
+
+		instance := fixture.FixInstance("instance-1")
+		instance.Parameters.ErsContext.SMOperatorCredentials = &internal.ServiceManagerOperatorCredentials{ClientID: "client-id", ClientSecret: "client-secret"}
+		instance.Parameters.Parameters.Kubeconfig = "kubeconfig"
+		require.NoError(t, oldStorage.Instances().Insert(instance))
+		operation := fixture.FixOperation("operation-1", "instance-1", internal.OperationTypeProvision)
+		operation.ProvisioningParameters = instance.Parameters
+		require.NoError(t, oldStorage.Operations().InsertOperation(operation))
+		require.NoError(t, oldStorage.RuntimeStates().Insert(fixture.FixRuntimeState("state-1", "runtime-1", "operation-1")))
+		require.NoError(t, oldStorage.Bindings().Insert(internal.Binding{
+			ID:                "binding-1",
+			InstanceID:        "instance-1",
+			CreatedAt:         time.Now(),
+			UpdatedAt:         time.Now(),
+			ExpiresAt:         time.Now().Add(time.Hour),
+			Kubeconfig:        "binding-kubeconfig",
+			ExpirationSeconds: 3600,
+		}))
+
+		data, err := yaml.Marshal(storage.EncryptionKeys{ActiveKeyID: "key-1", Keys: map[string]string{"key-1": "0123456789abcdef0123456789abcdef"}})
+		require.NoError(t, err)
+		cfg.EncryptionKeysFilePath = filepath.Join(t.TempDir(), "keys.yaml")
+		require.NoError(t, os.WriteFile(cfg.EncryptionKeysFilePath, data, 0600))
+		cipher, err := storage.NewEncrypterFromConfig(cfg)
+		require.NoError(t, err)
+		reencryption, _, err := storage.NewReencryptionFromConfig(cfg, cipher, 1, false, logrus.StandardLogger())
+		require.NoError(t, err)
+
+		// when
+		stats, err := reencryption.Run()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, stats, 5)
+		for _, s := range stats {
+			if s.Table == postsql.OperationsArchiveTable {
+				assert.Zero(t, s.Scanned)
+				continue
+			}
+			assert.Equal(t, 1, s.Scanned, s.Table)
+			assert.Equal(t, 1, s.Reencrypted, s.Table)
+			assert.Zero(t, s.Failed, s.Table)
+		}
+
+		// when
+		stats, err = reencryption.Run()
+
+		// then
+		require.NoError(t, err)
+		for _, s := range stats {
+			assert.Zero(t, s.Reencrypted, s.Table)
+		}
+
+		// data is readable without the secret key
+		cfg.SecretKey = ""
+		cipher, err = storage.NewEncrypterFromConfig(cfg)
+		require.NoError(t, err)
+		brokerStorage, _, err := storage.NewFromConfig(cfg, events.Config{}, cipher, logrus.StandardLogger())
+		require.NoError(t, err)
+
+		gotInstance, err := brokerStorage.Instances().GetByID("instance-1")
+		require.NoError(t, err)
+		assert.Equal(t, "client-secret", gotInstance.Parameters.ErsContext.SMOperatorCredentials.ClientSecret)
+		assert.Equal(t, "kubeconfig", gotInstance.Parameters.Parameters.Kubeconfig)
+		gotOperation, err := brokerStorage.Operations().GetOperationByID("operation-1")
+		require.NoError(t, err)
+		assert.Equal(t, "client-id", gotOperation.ProvisioningParameters.ErsContext.SMOperatorCredentials.ClientID)
+		_, err = brokerStorage.RuntimeStates().GetByOperationID("operation-1")
+		require.NoError(t, err)
+		gotBinding, err := brokerStorage.Bindings().Get("instance-1", "binding-1")
+		require.NoError(t, err)
+		assert.Equal(t, "binding-kubeconfig", gotBinding.Kubeconfig)
+	})
+}
@@ -1,47 +1,170 @@
 package storage
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"os"
+	"strings"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"gopkg.in/yaml.v2"
 )
 
+const (
+	// DefaultKeyID is the ID of the key defined by the SecretKey, the key is also used to decrypt legacy AES-CFB payloads
+	DefaultKeyID = "default"
+
+	// gcmPrefix starts every AES-GCM payload, it is followed by the key ID and a colon. Legacy AES-CFB payloads are
+	// base64 encoded, so they never contain a colon.
+	gcmPrefix = "gcm:"
+)
+
+// EncryptionKeys defines keys used to encrypt data, all of them are used to decrypt data and the active one is used to encrypt data
+type EncryptionKeys struct {
+	ActiveKeyID string            `yaml:"activeKeyID"`
+	Keys        map[string]string `yaml:"keys"`
+}
+
 func NewEncrypter(secretKey string) *Encrypter {
-	return &Encrypter{key: []byte(secretKey)}
+	return &Encrypter{
+		keys:           map[string][]byte{DefaultKeyID: []byte(secretKey)},
+		activeKeyID:    DefaultKeyID,
+		allowLegacyCFB: true,
+	}
+}
+
+// NewEncrypterFromConfig creates the encrypter with keys from the file defined in the configuration.
+// The SecretKey is used if the file is not defined, otherwise it is kept as the key with the default ID.
+// Legacy AES-CFB payloads are rejected unless the AllowLegacyCFB is set.
+func NewEncrypterFromConfig(cfg Config) (*Encrypter, error) {
+	if cfg.EncryptionKeysFilePath == "" {
+		encrypter := NewEncrypter(cfg.SecretKey)
+		encrypter.allowLegacyCFB = cfg.AllowLegacyCFB
+		return encrypter, nil
+	}
+	keys, err := ReadEncryptionKeysFromFile(cfg.EncryptionKeysFilePath)
+	if err != nil {
+		return nil, err
+	}
+	encrypter := &Encrypter{
+		keys:           map[string][]byte{},
+		activeKeyID:    keys.ActiveKeyID,
+		allowLegacyCFB: cfg.AllowLegacyCFB,
+	}
+	if cfg.SecretKey != "" {
+		encrypter.keys[DefaultKeyID] = []byte(cfg.SecretKey)
+	}
+	for id, key := range keys.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid encryption key ID %q: the ID must not be empty and must not contain a colon", id)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("invalid length of encryption key %s: %d bytes, the key must have 16, 24 or 32 bytes", id, len(key))
+		}
+		encrypter.keys[id] = []byte(key)
+	}
+	if _, found := encrypter.keys[encrypter.activeKeyID]; !found {
+		return nil, fmt.Errorf("active encryption key %q is not defined", encrypter.activeKeyID)
+	}
+	return encrypter, nil
 }
 
+func ReadEncryptionKeysFromFile(filename string) (EncryptionKeys, error) {
+	keys := EncryptionKeys{}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return keys, fmt.Errorf("while reading %s file with encryption keys: %w", filename, err)
+	}
+	err = yaml.Unmarshal(data, &keys)
+	if err != nil {
+		return keys, fmt.Errorf("while unmarshalling a file with encryption keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Encrypter encrypts data with AES-GCM using the active key. Every payload is prefixed with the ID of the key, so data
+// encrypted with older keys can be decrypted until it is re-encrypted. Legacy AES-CFB payloads are decrypted only if they are allowed.
 type Encrypter struct {
-	key []byte
+	keys           map[string][]byte
+	activeKeyID    string
+	allowLegacyCFB bool
+}
+
+// ActiveKeyID returns the ID of the key used to encrypt data
+func (e *Encrypter) ActiveKeyID() string {
+	return e.activeKeyID
+}
+
+// NeedsReencryption returns true if the payload is not encrypted with the active key
+func (e *Encrypter) NeedsReencryption(obj []byte) bool {
+	if len(obj) == 0 {
+		return false
+	}
+	return !bytes.HasPrefix(obj, []byte(gcmPrefix+e.activeKeyID+":"))
 }
 
 func (e *Encrypter) Encrypt(obj []byte) ([]byte, error) {
-	block, err := aes.NewCipher(e.key)
+	aead, err := newGCM(e.keys[e.activeKeyID])
 	if err != nil {
 		return nil, err
 	}
-	b := base64.StdEncoding.EncodeToString(obj)
-	bytes := make([]byte, aes.BlockSize+len(b))
-	iv := bytes[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, err
 	}
-	cfb := cipher.NewCFBEncrypter(block, iv)
-	cfb.XORKeyStream(bytes[aes.BlockSize:], []byte(b))
+	// the key ID is authenticated, so the payload cannot be moved to another key
+	sealed := aead.Seal(nonce, nonce, obj, []byte(e.activeKeyID))
 
-	return []byte(base64.StdEncoding.EncodeToString(bytes)), nil
+	return []byte(gcmPrefix + e.activeKeyID + ":" + base64.StdEncoding.EncodeToString(sealed)), nil
 }
 
 func (e *Encrypter) Decrypt(obj []byte) ([]byte, error) {
+	if !bytes.HasPrefix(obj, []byte(gcmPrefix)) {
+		if !e.allowLegacyCFB {
+			return nil, fmt.Errorf("the object is not encrypted with AES-GCM and legacy AES-CFB payloads are not allowed")
+		}
+		return e.decryptCFB(obj)
+	}
+	keyID, payload, found := strings.Cut(string(obj[len(gcmPrefix):]), ":")
+	if !found {
+		return nil, fmt.Errorf("key ID of the encrypted object is missing")
+	}
+	key, found := e.keys[keyID]
+	if !found {
+		return nil, fmt.Errorf("unknown encryption key %s", keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("while decoding input object: %w", err)
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("cipher text is too short")
+	}
+	data, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("while decrypting object with key %s: %w", keyID, err)
+	}
+	return data, nil
+}
+
+// decryptCFB decrypts payloads encrypted with AES-CFB before the AES-GCM encryption was introduced
+func (e *Encrypter) decryptCFB(obj []byte) ([]byte, error) {
 	obj, err := base64.StdEncoding.DecodeString(string(obj))
 	if err != nil {
 		return nil, fmt.Errorf("while decoding input object: %w", err)
 	}
-	block, err := aes.NewCipher(e.key)
+	block, err := aes.NewCipher(e.keys[DefaultKeyID])
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +182,14 @@ func (e *Encrypter) Decrypt(obj []byte) ([]byte, error) {
 	return data, nil
 }
 
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
 func (e *Encrypter) EncryptSMCreds(provisioningParameters *internal.ProvisioningParameters) error {
 	if provisioningParameters.ErsContext.SMOperatorCredentials == nil {
 		return nil
@@ -1,11 +1,20 @@
 package storage
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	cryptorand "crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/util/rand"
 )
 
@@ -70,3 +79,153 @@ func TestNewEncrypter(t *testing.T) {
 	})
 
 }
+
+func TestEncrypterKeyRotation(t *testing.T) {
+	t.Run("should decrypt legacy AES-CFB payload", func(t *testing.T) {
+		// given
+		secretKey := rand.String(32)
+		legacy := encryptCFB(t, []byte(secretKey), []byte("test"))
+		e := NewEncrypter(secretKey)
+
+		// when
+		dec, err := e.Decrypt(legacy)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []byte("test"), dec)
+		assert.True(t, e.NeedsReencryption(legacy))
+	})
+
+	t.Run("should reject legacy AES-CFB payload if it is not allowed", func(t *testing.T) {
+		// given
+		secretKey := rand.String(32)
+		legacy := encryptCFB(t, []byte(secretKey), []byte("test"))
+		e, err := NewEncrypterFromConfig(Config{SecretKey: secretKey, AllowLegacyCFB: false})
+		require.NoError(t, err)
+		enc, err := e.Encrypt([]byte("test"))
+		require.NoError(t, err)
+
+		// when
+		_, err = e.Decrypt(legacy)
+
+		// then
+		require.Error(t, err)
+
+		// when
+		dec, err := e.Decrypt(enc)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []byte("test"), dec)
+	})
+
+	t.Run("should decrypt data encrypted with rotated keys", func(t *testing.T) {
+		// given
+		oldKeys := writeKeysFile(t, EncryptionKeys{ActiveKeyID: "key-1", Keys: map[string]string{"key-1": rand.String(32)}})
+		oldEncrypter, err := NewEncrypterFromConfig(Config{EncryptionKeysFilePath: oldKeys})
+		require.NoError(t, err)
+		enc, err := oldEncrypter.Encrypt([]byte("test"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(enc), "gcm:key-1:"))
+
+		keys, err := ReadEncryptionKeysFromFile(oldKeys)
+		require.NoError(t, err)
+		keys.Keys["key-2"] = rand.String(32)
+		keys.ActiveKeyID = "key-2"
+		e, err := NewEncrypterFromConfig(Config{EncryptionKeysFilePath: writeKeysFile(t, keys)})
+		require.NoError(t, err)
+
+		// when
+		dec, err := e.Decrypt(enc)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []byte("test"), dec)
+		assert.True(t, e.NeedsReencryption(enc))
+
+		// when
+		enc, err = e.Encrypt(dec)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(enc), "gcm:key-2:"))
+		assert.False(t, e.NeedsReencryption(enc))
+	})
+
+	t.Run("should detect tampered payload", func(t *testing.T) {
+		// given
+		e := NewEncrypter(rand.String(32))
+		enc, err := e.Encrypt([]byte("test"))
+		require.NoError(t, err)
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(string(enc), "gcm:default:"))
+		require.NoError(t, err)
+		sealed[len(sealed)-1] ^= 1
+		tampered := []byte("gcm:default:" + base64.StdEncoding.EncodeToString(sealed))
+
+		// when
+		_, err = e.Decrypt(tampered)
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should not decrypt payload moved to another key", func(t *testing.T) {
+		// given
+		key := rand.String(32)
+		e, err := NewEncrypterFromConfig(Config{SecretKey: key, EncryptionKeysFilePath: writeKeysFile(t, EncryptionKeys{ActiveKeyID: "key-1", Keys: map[string]string{"key-1": key}})})
+		require.NoError(t, err)
+		enc, err := e.Encrypt([]byte("test"))
+		require.NoError(t, err)
+
+		// when
+		_, err = e.Decrypt([]byte(strings.Replace(string(enc), "gcm:key-1:", "gcm:default:", 1)))
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error for unknown key", func(t *testing.T) {
+		// given
+		e := NewEncrypter(rand.String(32))
+
+		// when
+		_, err := e.Decrypt([]byte("gcm:unknown:dGVzdA=="))
+
+		// then
+		assert.EqualError(t, err, "unknown encryption key unknown")
+	})
+
+	t.Run("should validate keys", func(t *testing.T) {
+		for tn, keys := range map[string]EncryptionKeys{
+			"missing active key": {ActiveKeyID: "key-2", Keys: map[string]string{"key-1": rand.String(32)}},
+			"invalid key length": {ActiveKeyID: "key-1", Keys: map[string]string{"key-1": rand.String(10)}},
+			"invalid key ID":     {ActiveKeyID: "key:1", Keys: map[string]string{"key:1": rand.String(32)}},
+		} {
+			t.Run(tn, func(t *testing.T) {
+				_, err := NewEncrypterFromConfig(Config{EncryptionKeysFilePath: writeKeysFile(t, keys)})
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func writeKeysFile(t *testing.T, keys EncryptionKeys) string {
+	data, err := yaml.Marshal(keys)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+// encryptCFB encrypts data the way it was encrypted before the AES-GCM encryption was introduced
+func encryptCFB(t *testing.T, key, obj []byte) []byte {
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	b := base64.StdEncoding.EncodeToString(obj)
+	bytes := make([]byte, aes.BlockSize+len(b))
+	iv := bytes[:aes.BlockSize]
+	_, err = io.ReadFull(cryptorand.Reader, iv)
+	require.NoError(t, err)
+	cipher.NewCFBEncrypter(block, iv).XORKeyStream(bytes[aes.BlockSize:], []byte(b))
+	return []byte(base64.StdEncoding.EncodeToString(bytes))
+}
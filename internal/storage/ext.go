@@ -15,11 +15,14 @@ type Instances interface {
 	FindAllInstancesForSubAccounts(subAccountslist []string) ([]internal.Instance, error)
 	GetByID(instanceID string) (*internal.Instance, error)
 	Insert(instance internal.Instance) error
+	// InsertWithinQuota inserts the instance unless the global account already has the limit of instances of the plan, false is returned then
+	InsertWithinQuota(instance internal.Instance, limit int) (bool, error)
 	Update(instance internal.Instance) (*internal.Instance, error)
 	Delete(instanceID string) error
 	GetInstanceStats() (internal.InstanceStats, error)
 	GetERSContextStats() (internal.ERSContextStats, error)
 	GetNumberOfInstancesForGlobalAccountID(globalAccountID string) (int, error)
+	GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID string) (map[string]int, error)
 	List(dbmodel.InstanceFilter) ([]internal.Instance, int, int, error)
 
 	// todo: remove after instances parameters migration is done
@@ -35,9 +38,14 @@ type Operations interface {
 	UpgradeKyma
 	UpgradeCluster
 	Updating
+	StepAttempts
+	ProcessingPauses
+	Leases
+	OperationsArchive
 
 	GetLastOperation(instanceID string) (*internal.Operation, error)
 	GetOperationByID(operationID string) (*internal.Operation, error)
+	GetOperationByRequestIdentity(requestIdentity string) (*internal.Operation, error)
 	GetNotFinishedOperationsByType(operationType internal.OperationType) ([]internal.Operation, error)
 	GetOperationStatsByPlan() (map[string]internal.OperationStats, error)
 	GetOperationsForIDs(operationIDList []string) ([]internal.Operation, error)
@@ -69,6 +77,42 @@ type Deprovisioning interface {
 	ListDeprovisioningOperations() ([]internal.DeprovisioningOperation, error)
 }
 
+type StepAttempts interface {
+	InsertStepAttempt(attempt internal.StepAttempt) error
+	ListStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error)
+}
+
+// ProcessingPauses stores global pauses of the processing of operations of the given type and pauses of single operations
+type ProcessingPauses interface {
+	InsertProcessingPause(pause internal.ProcessingPause) error
+	GetProcessingPause(operationType internal.OperationType) (*internal.ProcessingPause, error)
+	ListProcessingPauses() ([]internal.ProcessingPause, error)
+	DeleteProcessingPause(operationType internal.OperationType) error
+	UpsertOperationPause(pause internal.OperationPause) error
+	GetOperationPause(operationID string) (*internal.OperationPause, error)
+	ListPausedOperations() ([]internal.OperationPause, error)
+}
+
+// Leases stores leases of operations and orchestrations processed by broker replicas
+type Leases interface {
+	// AcquireLease creates the lease, takes over the expired lease or extends the lease held by the same owner.
+	// It returns false if the lease is held by another owner.
+	AcquireLease(lease internal.Lease) (bool, error)
+	ReleaseLease(id, owner string) error
+	ListExpiredLeases(kind string, now time.Time) ([]internal.Lease, error)
+}
+
+// OperationsArchive moves operations of instances deprovisioned long ago out of the operations table.
+// The summary operation of the instance stays in the operations table, so the instance can still be listed as deprovisioned.
+type OperationsArchive interface {
+	ListInstancesToArchive(finishedBefore time.Time, limit int) ([]string, error)
+	// ArchiveOperations copies all operations of the instance to the archive and deletes them from the operations table except the summary operation.
+	// The step history of the deleted operations is moved to the archive too.
+	ArchiveOperations(instanceID, summaryOperationID string) error
+	ListArchivedOperationsByInstanceIDs(instanceIDs []string) ([]internal.Operation, error)
+	ListArchivedStepAttemptsByOperationID(operationID string) ([]internal.StepAttempt, error)
+}
+
 type Orchestrations interface {
 	Insert(orchestration internal.Orchestration) error
 	Update(orchestration internal.Orchestration) error
@@ -111,7 +155,15 @@ type Updating interface {
 	UpdateUpdatingOperation(operation internal.UpdatingOperation) (*internal.UpdatingOperation, error)
 }
 
+type Bindings interface {
+	Insert(binding internal.Binding) error
+	Get(instanceID string, bindingID string) (*internal.Binding, error)
+	ListByInstanceID(instanceID string) ([]internal.Binding, error)
+	ListExpired(before time.Time) ([]internal.Binding, error)
+	Delete(instanceID, bindingID string) error
+}
+
 type Events interface {
-	InsertEvent(level events.EventLevel, message, instanceID, operationID string)
+	InsertEvent(level events.EventLevel, message, instanceID, operationID string, fields events.Fields)
 	ListEvents(filter events.EventFilter) ([]events.EventDTO, error)
 }
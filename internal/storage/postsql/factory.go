@@ -26,6 +26,7 @@ type ReadSession interface {
 	GetInstanceByID(instanceID string) (dbmodel.InstanceDTO, dberr.Error)
 	GetLastOperation(instanceID string) (dbmodel.OperationDTO, dberr.Error)
 	GetOperationByID(opID string) (dbmodel.OperationDTO, dberr.Error)
+	GetOperationByRequestIdentity(requestIdentity string) (dbmodel.OperationDTO, dberr.Error)
 	GetNotFinishedOperationsByType(operationType internal.OperationType) ([]dbmodel.OperationDTO, dberr.Error)
 	CountNotFinishedOperationsByInstanceID(instanceID string) (int, dberr.Error)
 	GetOperationByTypeAndInstanceID(inID string, opType internal.OperationType) (dbmodel.OperationDTO, dberr.Error)
@@ -39,6 +40,7 @@ type ReadSession interface {
 	GetInstanceStats() ([]dbmodel.InstanceByGlobalAccountIDStatEntry, error)
 	GetERSContextStats() ([]dbmodel.InstanceERSContextStatsEntry, error)
 	GetNumberOfInstancesForGlobalAccountID(globalAccountID string) (int, error)
+	GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID string) ([]dbmodel.InstanceByPlanIDStatEntry, error)
 	GetRuntimeStateByOperationID(operationID string) (dbmodel.RuntimeStateDTO, dberr.Error)
 	ListRuntimeStateByRuntimeID(runtimeID string) ([]dbmodel.RuntimeStateDTO, dberr.Error)
 	GetOrchestrationByID(oID string) (dbmodel.OrchestrationDTO, dberr.Error)
@@ -51,12 +53,27 @@ type ReadSession interface {
 	GetLatestRuntimeStateWithReconcilerInputByRuntimeID(runtimeID string) (dbmodel.RuntimeStateDTO, dberr.Error)
 	GetLatestRuntimeStateWithKymaVersionByRuntimeID(runtimeID string) (dbmodel.RuntimeStateDTO, dberr.Error)
 	GetLatestRuntimeStateWithOIDCConfigByRuntimeID(runtimeID string) (dbmodel.RuntimeStateDTO, dberr.Error)
-	ListEvents(filter events.EventFilter) ([]events.EventDTO, error)
+	ListEvents(filter events.EventFilter) ([]dbmodel.EventDTO, error)
+	GetBinding(instanceID, bindingID string) (dbmodel.BindingDTO, dberr.Error)
+	ListBindings(instanceID string) ([]dbmodel.BindingDTO, dberr.Error)
+	ListExpiredBindings(before time.Time) ([]dbmodel.BindingDTO, dberr.Error)
+	ListStepAttempts(operationID string) ([]dbmodel.StepAttemptDTO, dberr.Error)
+	GetProcessingPause(operationType string) (dbmodel.ProcessingPauseDTO, dberr.Error)
+	ListProcessingPauses() ([]dbmodel.ProcessingPauseDTO, dberr.Error)
+	GetOperationPause(operationID string) (dbmodel.OperationPauseDTO, dberr.Error)
+	ListPausedOperations() ([]dbmodel.OperationPauseDTO, dberr.Error)
+	ListExpiredLeases(kind string, now time.Time) ([]dbmodel.LeaseDTO, dberr.Error)
+	ListEncryptedColumns(table string, keyColumns, columns []string, afterKey []string, limit int) ([]dbmodel.EncryptedColumnsDTO, dberr.Error)
+	ListInstancesToArchive(finishedBefore time.Time, limit int) ([]string, dberr.Error)
+	ListArchivedOperations(instanceIDs []string) ([]dbmodel.OperationDTO, dberr.Error)
+	ListArchivedStepAttempts(operationID string) ([]dbmodel.StepAttemptDTO, dberr.Error)
 }
 
 //go:generate mockery --name=WriteSession
 type WriteSession interface {
 	InsertInstance(instance dbmodel.InstanceDTO) dberr.Error
+	LockGlobalAccountInstances(globalAccountID string) dberr.Error
+	CountInstancesOfPlan(globalAccountID, planID string) (int, dberr.Error)
 	UpdateInstance(instance dbmodel.InstanceDTO) dberr.Error
 	DeleteInstance(instanceID string) dberr.Error
 	InsertOperation(dto dbmodel.OperationDTO) dberr.Error
@@ -64,8 +81,22 @@ type WriteSession interface {
 	InsertOrchestration(o dbmodel.OrchestrationDTO) dberr.Error
 	UpdateOrchestration(o dbmodel.OrchestrationDTO) dberr.Error
 	InsertRuntimeState(state dbmodel.RuntimeStateDTO) dberr.Error
-	InsertEvent(level events.EventLevel, message, instanceID, operationID string) dberr.Error
+	InsertEvent(event dbmodel.EventDTO) dberr.Error
 	DeleteEvents(until time.Time) dberr.Error
+	InsertBinding(binding dbmodel.BindingDTO) dberr.Error
+	DeleteBinding(instanceID, bindingID string) dberr.Error
+	InsertStepAttempt(attempt dbmodel.StepAttemptDTO) dberr.Error
+	InsertProcessingPause(pause dbmodel.ProcessingPauseDTO) dberr.Error
+	DeleteProcessingPause(operationType string) dberr.Error
+	UpsertOperationPause(pause dbmodel.OperationPauseDTO) dberr.Error
+	AcquireLease(lease dbmodel.LeaseDTO, now time.Time) (bool, dberr.Error)
+	ReleaseLease(id, owner string) dberr.Error
+	UpdateEncryptedColumns(table string, keyColumns, key []string, values, previous map[string]string) (bool, dberr.Error)
+	ArchiveOperations(instanceID string, archivedAt time.Time) dberr.Error
+	ArchiveStepAttempts(instanceID, summaryOperationID string, archivedAt time.Time) dberr.Error
+	DeleteStepAttemptsOfArchivedOperations(instanceID, summaryOperationID string) dberr.Error
+	DeleteOperationPausesOfArchivedOperations(instanceID, summaryOperationID string) dberr.Error
+	DeleteArchivedOperations(instanceID, summaryOperationID string) dberr.Error
 }
 
 type Transaction interface {
@@ -12,12 +12,20 @@ import (
 )
 
 const (
-	schemaName             = "public"
-	InstancesTableName     = "instances"
-	OperationTableName     = "operations"
-	OrchestrationTableName = "orchestrations"
-	RuntimeStateTableName  = "runtime_states"
-	CreatedAtField         = "created_at"
+	schemaName                   = "public"
+	InstancesTableName           = "instances"
+	OperationTableName           = "operations"
+	OrchestrationTableName       = "orchestrations"
+	RuntimeStateTableName        = "runtime_states"
+	BindingsTableName            = "bindings"
+	StepAttemptsTableName        = "operation_step_attempts"
+	ProcessingPausesTable        = "processing_pauses"
+	OperationPausesTable         = "operation_pauses"
+	LeasesTableName              = "leases"
+	OperationsArchiveTable       = "operations_archive"
+	StepAttemptsArchiveTableName = "operation_step_attempts_archive"
+	EventsTableName              = "events"
+	CreatedAtField               = "created_at"
 )
 
 // InitializeDatabase opens database connection and initializes schema if it does not exist
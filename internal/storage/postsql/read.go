@@ -1,6 +1,8 @@
 package postsql
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -27,7 +29,7 @@ func (r readSession) getInstancesJoinedWithOperationStatement() *dbr.SelectStmt
 		Select("instances.instance_id, instances.runtime_id, instances.global_account_id, instances.subscription_global_account_id, instances.service_id,"+
 			" instances.service_plan_id, instances.dashboard_url, instances.provisioning_parameters, instances.created_at,"+
 			" instances.updated_at, instances.deleted_at, instances.sub_account_id, instances.service_name, instances.service_plan_name,"+
-			" instances.provider_region, instances.provider, operations.state, operations.description, operations.type, operations.created_at AS operation_created_at, operations.data").
+			" instances.provider_region, instances.provider, instances.labels, operations.state, operations.description, operations.type, operations.created_at AS operation_created_at, operations.data").
 		From(InstancesTableName).
 		LeftJoin(OperationTableName, join)
 	return stmt
@@ -147,6 +149,20 @@ func (r readSession) GetOperationByID(opID string) (dbmodel.OperationDTO, dberr.
 	return operation, nil
 }
 
+func (r readSession) GetOperationByRequestIdentity(requestIdentity string) (dbmodel.OperationDTO, dberr.Error) {
+	condition := dbr.Eq("request_identity", requestIdentity)
+	operation, err := r.getOperation(condition)
+	if err != nil {
+		switch {
+		case dberr.IsNotFound(err):
+			return dbmodel.OperationDTO{}, dberr.NotFound("for request identity: %s %s", requestIdentity, err)
+		default:
+			return dbmodel.OperationDTO{}, err
+		}
+	}
+	return operation, nil
+}
+
 func (r readSession) ListOperations(filter dbmodel.OperationFilter) ([]dbmodel.OperationDTO, int, int, error) {
 	var operations []dbmodel.OperationDTO
 
@@ -621,7 +637,7 @@ FROM (
     FROM operations
     INNER JOIN instances
     ON operations.instance_id = instances.instance_id
-    WHERE (operations.state != 'pending' OR operations.state != 'canceled') AND deleted_at = '0001-01-01T00:00:00.000Z'
+    WHERE operations.state NOT IN ('pending', 'canceled') AND deleted_at = '0001-01-01T00:00:00.000Z'
     ORDER BY instance_id, operations.created_at DESC
 ) t
 GROUP BY license_type;
@@ -642,6 +658,18 @@ func (r readSession) GetNumberOfInstancesForGlobalAccountID(globalAccountID stri
 	return res.Total, err
 }
 
+func (r readSession) GetNumberOfInstancesByPlanForGlobalAccountID(globalAccountID string) ([]dbmodel.InstanceByPlanIDStatEntry, error) {
+	var rows []dbmodel.InstanceByPlanIDStatEntry
+	_, err := r.session.Select("service_plan_id", "count(*) as total").
+		From(InstancesTableName).
+		Where(dbr.Eq("global_account_id", globalAccountID)).
+		Where(dbr.Eq("deleted_at", "0001-01-01T00:00:00.000Z")).
+		GroupBy("service_plan_id").
+		Load(&rows)
+
+	return rows, err
+}
+
 func (r readSession) ListInstances(filter dbmodel.InstanceFilter) ([]dbmodel.InstanceDTO, int, int, error) {
 	var instances []dbmodel.InstanceDTO
 
@@ -686,20 +714,312 @@ func (r readSession) ListInstances(filter dbmodel.InstanceFilter) ([]dbmodel.Ins
 		nil
 }
 
-func (r readSession) ListEvents(filter events.EventFilter) ([]events.EventDTO, error) {
-	var events []events.EventDTO
-	stmt := r.session.Select("*").From("events")
+func (r readSession) ListEvents(filter events.EventFilter) ([]dbmodel.EventDTO, error) {
+	var events []dbmodel.EventDTO
+	stmt := r.session.Select("*").From(EventsTableName)
 	if len(filter.InstanceIDs) != 0 {
 		stmt.Where(dbr.Eq("instance_id", filter.InstanceIDs))
 	}
 	if len(filter.OperationIDs) != 0 {
 		stmt.Where(dbr.Eq("operation_id", filter.OperationIDs))
 	}
-	stmt.OrderBy("created_at")
+	if len(filter.Levels) != 0 {
+		levels := make([]string, 0, len(filter.Levels))
+		for _, level := range filter.Levels {
+			levels = append(levels, string(level))
+		}
+		stmt.Where(dbr.Eq("level", levels))
+	}
+	if !filter.From.IsZero() {
+		stmt.Where(dbr.Gte("created_at", filter.From))
+	}
+	if !filter.To.IsZero() {
+		stmt.Where(dbr.Lt("created_at", filter.To))
+	}
+	if filter.MessageContains != "" {
+		stmt.Where("message ILIKE ?", "%"+likeEscaper.Replace(filter.MessageContains)+"%")
+	}
+	if filter.After != nil {
+		stmt.Where(dbr.Or(
+			dbr.Gt("created_at", filter.After.CreatedAt),
+			dbr.And(dbr.Eq("created_at", filter.After.CreatedAt), dbr.Gt("id", filter.After.ID)),
+		))
+	}
+	stmt.OrderBy("created_at").OrderBy("id")
+	if filter.Limit > 0 {
+		stmt.Limit(uint64(filter.Limit))
+	}
 	_, err := stmt.Load(&events)
 	return events, err
 }
 
+// likeEscaper escapes wildcards of the LIKE pattern, so the text is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r readSession) GetBinding(instanceID, bindingID string) (dbmodel.BindingDTO, dberr.Error) {
+	var binding dbmodel.BindingDTO
+
+	err := r.session.
+		Select("*").
+		From(BindingsTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		Where(dbr.Eq("id", bindingID)).
+		LoadOne(&binding)
+
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return dbmodel.BindingDTO{}, dberr.NotFound("Cannot find Binding for instanceID:'%s' bindingID:'%s'", instanceID, bindingID)
+		}
+		return dbmodel.BindingDTO{}, dberr.Internal("Failed to get Binding: %s", err)
+	}
+
+	return binding, nil
+}
+
+func (r readSession) ListBindings(instanceID string) ([]dbmodel.BindingDTO, dberr.Error) {
+	var bindings []dbmodel.BindingDTO
+
+	_, err := r.session.
+		Select("*").
+		From(BindingsTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		OrderBy(CreatedAtField).
+		Load(&bindings)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get bindings: %s", err)
+	}
+
+	return bindings, nil
+}
+
+func (r readSession) ListExpiredBindings(before time.Time) ([]dbmodel.BindingDTO, dberr.Error) {
+	var bindings []dbmodel.BindingDTO
+
+	_, err := r.session.
+		Select("*").
+		From(BindingsTableName).
+		Where(dbr.And(dbr.Gt("expires_at", time.Time{}), dbr.Lt("expires_at", before))).
+		OrderBy("expires_at").
+		Load(&bindings)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get expired bindings: %s", err)
+	}
+
+	return bindings, nil
+}
+
+func (r readSession) ListStepAttempts(operationID string) ([]dbmodel.StepAttemptDTO, dberr.Error) {
+	var attempts []dbmodel.StepAttemptDTO
+
+	_, err := r.session.
+		Select("*").
+		From(StepAttemptsTableName).
+		Where(dbr.Eq("operation_id", operationID)).
+		OrderBy("id").
+		Load(&attempts)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get step attempts: %s", err)
+	}
+
+	return attempts, nil
+}
+
+// ListArchivedStepAttempts returns the archived step history of the operation in the order of execution
+func (r readSession) ListArchivedStepAttempts(operationID string) ([]dbmodel.StepAttemptDTO, dberr.Error) {
+	var attempts []dbmodel.StepAttemptDTO
+
+	_, err := r.session.
+		Select("id", "operation_id", "stage", "step_name", "started_at", "duration_ms", "backoff_ms", "error_reason", "error_component", "error").
+		From(StepAttemptsArchiveTableName).
+		Where(dbr.Eq("operation_id", operationID)).
+		OrderBy("id").
+		Load(&attempts)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get archived step attempts: %s", err)
+	}
+
+	return attempts, nil
+}
+
+func (r readSession) GetProcessingPause(operationType string) (dbmodel.ProcessingPauseDTO, dberr.Error) {
+	var pause dbmodel.ProcessingPauseDTO
+
+	err := r.session.
+		Select("*").
+		From(ProcessingPausesTable).
+		Where(dbr.Eq("operation_type", operationType)).
+		LoadOne(&pause)
+
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return dbmodel.ProcessingPauseDTO{}, dberr.NotFound("processing of %s operations is not paused", operationType)
+		}
+		return dbmodel.ProcessingPauseDTO{}, dberr.Internal("Failed to get processing pause: %s", err)
+	}
+
+	return pause, nil
+}
+
+func (r readSession) ListProcessingPauses() ([]dbmodel.ProcessingPauseDTO, dberr.Error) {
+	var pauses []dbmodel.ProcessingPauseDTO
+
+	_, err := r.session.
+		Select("*").
+		From(ProcessingPausesTable).
+		OrderBy("operation_type").
+		Load(&pauses)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get processing pauses: %s", err)
+	}
+
+	return pauses, nil
+}
+
+func (r readSession) GetOperationPause(operationID string) (dbmodel.OperationPauseDTO, dberr.Error) {
+	var pause dbmodel.OperationPauseDTO
+
+	err := r.session.
+		Select("*").
+		From(OperationPausesTable).
+		Where(dbr.Eq("operation_id", operationID)).
+		LoadOne(&pause)
+
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return dbmodel.OperationPauseDTO{}, dberr.NotFound("operation %s was never paused", operationID)
+		}
+		return dbmodel.OperationPauseDTO{}, dberr.Internal("Failed to get operation pause: %s", err)
+	}
+
+	return pause, nil
+}
+
+func (r readSession) ListPausedOperations() ([]dbmodel.OperationPauseDTO, dberr.Error) {
+	var pauses []dbmodel.OperationPauseDTO
+
+	_, err := r.session.
+		Select("*").
+		From(OperationPausesTable).
+		Where(dbr.Eq("paused", true)).
+		OrderBy("operation_id").
+		Load(&pauses)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get paused operations: %s", err)
+	}
+
+	return pauses, nil
+}
+
+func (r readSession) ListExpiredLeases(kind string, now time.Time) ([]dbmodel.LeaseDTO, dberr.Error) {
+	var leases []dbmodel.LeaseDTO
+
+	_, err := r.session.
+		Select("*").
+		From(LeasesTableName).
+		Where(dbr.Eq("kind", kind)).
+		Where(dbr.Lt("lease_until", now)).
+		OrderBy("lease_until").
+		Load(&leases)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get expired leases: %s", err)
+	}
+
+	return leases, nil
+}
+
+// ListEncryptedColumns returns values of the columns of rows with keys greater than the given one, ordered by keys.
+// All rows from the beginning of the table are returned if the given key is empty.
+func (r readSession) ListEncryptedColumns(table string, keyColumns, columns []string, afterKey []string, limit int) ([]dbmodel.EncryptedColumnsDTO, dberr.Error) {
+	stmt := r.session.
+		Select(append(append([]string{}, keyColumns...), columns...)...).
+		From(table).
+		OrderBy(strings.Join(keyColumns, ", ")).
+		Limit(uint64(limit))
+	if len(afterKey) > 0 {
+		args := make([]interface{}, len(afterKey))
+		for i, value := range afterKey {
+			args[i] = value
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(afterKey)), ", ")
+		stmt = stmt.Where(fmt.Sprintf("(%s) > (%s)", strings.Join(keyColumns, ", "), placeholders), args...)
+	}
+	rows, err := stmt.Rows()
+	if err != nil {
+		return nil, dberr.Internal("Failed to get encrypted columns of %s table: %s", table, err)
+	}
+	defer rows.Close()
+
+	var result []dbmodel.EncryptedColumnsDTO
+	for rows.Next() {
+		key := make([]string, len(keyColumns))
+		values := make([]sql.NullString, len(columns))
+		var dest []interface{}
+		for i := range key {
+			dest = append(dest, &key[i])
+		}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, dberr.Internal("Failed to read encrypted columns of %s table: %s", table, err)
+		}
+		row := dbmodel.EncryptedColumnsDTO{Key: key, Columns: make(map[string]string, len(columns))}
+		for i, column := range columns {
+			row.Columns[column] = values[i].String
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dberr.Internal("Failed to read encrypted columns of %s table: %s", table, err)
+	}
+
+	return result, nil
+}
+
+// ListInstancesToArchive returns IDs of instances which last operation is a succeeded deprovisioning which is not temporary,
+// the operations of the instance must not be archived yet and all of them must be finished before the given time
+func (r readSession) ListInstancesToArchive(finishedBefore time.Time, limit int) ([]string, dberr.Error) {
+	var instanceIDs []string
+
+	lastOperation := fmt.Sprintf("SELECT l.type, l.state, l.data FROM %s l WHERE l.instance_id = o.instance_id ORDER BY l.created_at DESC LIMIT 1", OperationTableName)
+	_, err := r.session.
+		Select("o.instance_id").
+		From(dbr.I(OperationTableName).As("o")).
+		Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s a WHERE a.instance_id = o.instance_id)", OperationsArchiveTable)).
+		GroupBy("o.instance_id").
+		Having("max(o.updated_at) < ?", finishedBefore).
+		Having("bool_and(o.state <> ?)", domain.InProgress).
+		Having(fmt.Sprintf("EXISTS (SELECT 1 FROM (%s) lo WHERE lo.type = ? AND lo.state = ? AND COALESCE((lo.data::json->>'temporary')::boolean, false) = false)", lastOperation),
+			internal.OperationTypeDeprovision, domain.Succeeded).
+		OrderBy("max(o.updated_at)").
+		Limit(uint64(limit)).
+		Load(&instanceIDs)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get instances to archive: %s", err)
+	}
+
+	return instanceIDs, nil
+}
+
+func (r readSession) ListArchivedOperations(instanceIDs []string) ([]dbmodel.OperationDTO, dberr.Error) {
+	var operations []dbmodel.OperationDTO
+	if len(instanceIDs) == 0 {
+		return operations, nil
+	}
+
+	_, err := r.session.
+		Select("*").
+		From(OperationsArchiveTable).
+		Where("instance_id IN ?", instanceIDs).
+		OrderBy(CreatedAtField).
+		Load(&operations)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get archived operations: %s", err)
+	}
+
+	return operations, nil
+}
+
 func (r readSession) getInstanceCount(filter dbmodel.InstanceFilter) (int, error) {
 	var res struct {
 		Total int
@@ -787,6 +1107,9 @@ func addInstanceFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
 	if len(filter.GlobalAccountIDs) > 0 {
 		stmt.Where("instances.global_account_id IN ?", filter.GlobalAccountIDs)
 	}
+	if len(filter.SubscriptionGlobalAccountIDs) > 0 {
+		stmt.Where("instances.subscription_global_account_id IN ?", filter.SubscriptionGlobalAccountIDs)
+	}
 	if len(filter.SubAccountIDs) > 0 {
 		stmt.Where("instances.sub_account_id IN ?", filter.SubAccountIDs)
 	}
@@ -805,6 +1128,10 @@ func addInstanceFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
 	if len(filter.PlanIDs) > 0 {
 		stmt.Where("instances.service_plan_id IN ?", filter.PlanIDs)
 	}
+	if len(filter.Labels) > 0 {
+		labels, _ := json.Marshal(filter.Labels)
+		stmt.Where("instances.labels @> ?::jsonb", string(labels))
+	}
 	if len(filter.Shoots) > 0 {
 		shootNameMatch := fmt.Sprintf(`^(%s)$`, strings.Join(filter.Shoots, "|"))
 		stmt.Where("o1.data::json->>'shoot_name' ~ ?", shootNameMatch)
@@ -827,6 +1154,15 @@ func addInstanceFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
 			stmt.Where("instances.deleted_at = '0001-01-01T00:00:00.000Z'")
 		}
 	}
+
+	if filter.CompensationFailed != nil {
+		if *filter.CompensationFailed {
+			stmt.Where("instances.compensation_failed_at IS NOT NULL")
+		}
+		if !*filter.CompensationFailed {
+			stmt.Where("instances.compensation_failed_at IS NULL")
+		}
+	}
 }
 
 func addOrchestrationFilters(stmt *dbr.SelectStmt, filter dbmodel.OrchestrationFilter) {
@@ -1,10 +1,9 @@
 package postsql
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/google/uuid"
-	"github.com/kyma-project/kyma-environment-broker/common/events"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 
 	"github.com/gocraft/dbr"
@@ -38,6 +37,8 @@ func (ws writeSession) InsertInstance(instance dbmodel.InstanceDTO) dberr.Error
 		Pair("provider", instance.Provider).
 		Pair("deleted_at", instance.DeletedAt).
 		Pair("expired_at", instance.ExpiredAt).
+		Pair("compensation_failed_at", instance.CompensationFailedAt).
+		Pair("labels", instance.Labels).
 		Pair("version", instance.Version).
 		Exec()
 
@@ -64,6 +65,38 @@ func (ws writeSession) DeleteInstance(instanceID string) dberr.Error {
 	return nil
 }
 
+// LockGlobalAccountInstances takes the advisory lock of the global account released at the end of the transaction,
+// so instances of the global account are inserted one by one
+func (ws writeSession) LockGlobalAccountInstances(globalAccountID string) dberr.Error {
+	if ws.transaction == nil {
+		return dberr.Internal("Failed to lock instances of global account %s: the session is not within a transaction", globalAccountID)
+	}
+	_, err := ws.transaction.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", InstancesTableName+"/"+globalAccountID)
+	if err != nil {
+		return dberr.Internal("Failed to lock instances of global account %s: %s", globalAccountID, err)
+	}
+	return nil
+}
+
+func (ws writeSession) CountInstancesOfPlan(globalAccountID, planID string) (int, dberr.Error) {
+	var stmt *dbr.SelectStmt
+	if ws.transaction != nil {
+		stmt = ws.transaction.Select("count(*)")
+	} else {
+		stmt = ws.session.Select("count(*)")
+	}
+	var count int
+	err := stmt.From(InstancesTableName).
+		Where(dbr.Eq("global_account_id", globalAccountID)).
+		Where(dbr.Eq("service_plan_id", planID)).
+		Where(dbr.Eq("deleted_at", "0001-01-01T00:00:00.000Z")).
+		LoadOne(&count)
+	if err != nil {
+		return 0, dberr.Internal("Failed to count instances of global account %s: %s", globalAccountID, err)
+	}
+	return count, nil
+}
+
 func (ws writeSession) UpdateInstance(instance dbmodel.InstanceDTO) dberr.Error {
 	res, err := ws.update(InstancesTableName).
 		Where(dbr.Eq("instance_id", instance.InstanceID)).
@@ -82,6 +115,8 @@ func (ws writeSession) UpdateInstance(instance dbmodel.InstanceDTO) dberr.Error
 		Set("deleted_at", instance.DeletedAt).
 		Set("version", instance.Version+1).
 		Set("expired_at", instance.ExpiredAt).
+		Set("compensation_failed_at", instance.CompensationFailedAt).
+		Set("labels", instance.Labels).
 		Exec()
 	if err != nil {
 		return dberr.Internal("Failed to update record to Instance table: %s", err)
@@ -113,12 +148,13 @@ func (ws writeSession) InsertOperation(op dbmodel.OperationDTO) dberr.Error {
 		Pair("orchestration_id", op.OrchestrationID.String).
 		Pair("provisioning_parameters", op.ProvisioningParameters.String).
 		Pair("finished_stages", op.FinishedStages).
+		Pair("request_identity", op.RequestIdentity).
 		Exec()
 
 	if err != nil {
 		if err, ok := err.(*pq.Error); ok {
 			if err.Code == UniqueViolationErrorCode {
-				return dberr.AlreadyExists("operation with id %s already exist", op.ID)
+				return dberr.AlreadyExists("operation with id %s or request identity %s already exist", op.ID, op.RequestIdentity)
 			}
 		}
 		return dberr.Internal("Failed to insert record to operations table: %s", err)
@@ -240,14 +276,15 @@ func (ws writeSession) UpdateOperation(op dbmodel.OperationDTO) dberr.Error {
 	return nil
 }
 
-func (ws writeSession) InsertEvent(level events.EventLevel, message, instanceID, operationID string) dberr.Error {
-	_, err := ws.insertInto("events").
-		Pair("id", uuid.NewString()).
-		Pair("level", level).
-		Pair("instance_id", instanceID).
-		Pair("operation_id", operationID).
-		Pair("message", message).
-		Pair("created_at", time.Now()).
+func (ws writeSession) InsertEvent(event dbmodel.EventDTO) dberr.Error {
+	_, err := ws.insertInto(EventsTableName).
+		Pair("id", event.ID).
+		Pair("level", event.Level).
+		Pair("instance_id", event.InstanceID).
+		Pair("operation_id", event.OperationID).
+		Pair("message", event.Message).
+		Pair("fields", event.Fields).
+		Pair("created_at", event.CreatedAt).
 		Exec()
 	if err != nil {
 		return dberr.Internal("Failed to insert event: %s", err)
@@ -256,7 +293,7 @@ func (ws writeSession) InsertEvent(level events.EventLevel, message, instanceID,
 }
 
 func (ws writeSession) DeleteEvents(until time.Time) dberr.Error {
-	_, err := ws.deleteFrom("events").
+	_, err := ws.deleteFrom(EventsTableName).
 		Where(dbr.Lte("created_at", until)).
 		Exec()
 	if err != nil {
@@ -265,6 +302,243 @@ func (ws writeSession) DeleteEvents(until time.Time) dberr.Error {
 	return nil
 }
 
+func (ws writeSession) InsertBinding(binding dbmodel.BindingDTO) dberr.Error {
+	_, err := ws.insertInto(BindingsTableName).
+		Pair("id", binding.ID).
+		Pair("instance_id", binding.InstanceID).
+		Pair("created_at", binding.CreatedAt).
+		Pair("updated_at", binding.UpdatedAt).
+		Pair("expires_at", binding.ExpiresAt).
+		Pair("kubeconfig", binding.Kubeconfig).
+		Pair("expiration_seconds", binding.ExpirationSeconds).
+		Pair("cluster_role", binding.ClusterRole).
+		Exec()
+
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("binding with id %s already exist", binding.ID)
+			}
+		}
+		return dberr.Internal("Failed to insert record to Binding table: %s", err)
+	}
+
+	return nil
+}
+
+func (ws writeSession) InsertStepAttempt(attempt dbmodel.StepAttemptDTO) dberr.Error {
+	_, err := ws.insertInto(StepAttemptsTableName).
+		Pair("operation_id", attempt.OperationID).
+		Pair("stage", attempt.Stage).
+		Pair("step_name", attempt.StepName).
+		Pair("started_at", attempt.StartedAt).
+		Pair("duration_ms", attempt.DurationMs).
+		Pair("backoff_ms", attempt.BackoffMs).
+		Pair("error_reason", attempt.ErrorReason).
+		Pair("error_component", attempt.ErrorComponent).
+		Pair("error", attempt.Error).
+		Exec()
+
+	if err != nil {
+		return dberr.Internal("Failed to insert record to step attempts table: %s", err)
+	}
+
+	return nil
+}
+
+func (ws writeSession) InsertProcessingPause(pause dbmodel.ProcessingPauseDTO) dberr.Error {
+	_, err := ws.insertInto(ProcessingPausesTable).
+		Pair("operation_type", pause.OperationType).
+		Pair("reason", pause.Reason).
+		Pair("paused_at", pause.PausedAt).
+		Exec()
+
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("processing of %s operations is already paused", pause.OperationType)
+			}
+		}
+		return dberr.Internal("Failed to insert record to processing pauses table: %s", err)
+	}
+
+	return nil
+}
+
+func (ws writeSession) DeleteProcessingPause(operationType string) dberr.Error {
+	_, err := ws.deleteFrom(ProcessingPausesTable).
+		Where(dbr.Eq("operation_type", operationType)).
+		Exec()
+
+	if err != nil {
+		return dberr.Internal("Failed to delete record from processing pauses table: %s", err)
+	}
+	return nil
+}
+
+// UpsertOperationPause inserts the pause of the operation or replaces the existing one
+func (ws writeSession) UpsertOperationPause(pause dbmodel.OperationPauseDTO) dberr.Error {
+	query := fmt.Sprintf(`INSERT INTO %s (operation_id, paused, paused_at, resumed_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (operation_id) DO UPDATE SET paused = EXCLUDED.paused, paused_at = EXCLUDED.paused_at, resumed_at = EXCLUDED.resumed_at`, OperationPausesTable)
+	args := []interface{}{pause.OperationID, pause.Paused, pause.PausedAt, pause.ResumedAt}
+
+	var stmt *dbr.InsertStmt
+	if ws.transaction != nil {
+		stmt = ws.transaction.InsertBySql(query, args...)
+	} else {
+		stmt = ws.session.InsertBySql(query, args...)
+	}
+	_, err := stmt.Exec()
+	if err != nil {
+		return dberr.Internal("Failed to upsert record to operation pauses table: %s", err)
+	}
+	return nil
+}
+
+// AcquireLease inserts the lease or updates it if it is expired or held by the same owner, in one statement,
+// so only one replica can get the lease
+func (ws writeSession) AcquireLease(lease dbmodel.LeaseDTO, now time.Time) (bool, dberr.Error) {
+	query := fmt.Sprintf(`INSERT INTO %s (id, kind, owner, lease_until) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET kind = EXCLUDED.kind, owner = EXCLUDED.owner, lease_until = EXCLUDED.lease_until
+		WHERE %s.owner = EXCLUDED.owner OR %s.lease_until < ?`, LeasesTableName, LeasesTableName, LeasesTableName)
+	args := []interface{}{lease.ID, lease.Kind, lease.Owner, lease.LeaseUntil, now}
+
+	var stmt *dbr.InsertStmt
+	if ws.transaction != nil {
+		stmt = ws.transaction.InsertBySql(query, args...)
+	} else {
+		stmt = ws.session.InsertBySql(query, args...)
+	}
+	res, err := stmt.Exec()
+	if err != nil {
+		return false, dberr.Internal("Failed to acquire lease %s: %s", lease.ID, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, dberr.Internal("Failed to get number of rows affected: %s", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (ws writeSession) ReleaseLease(id, owner string) dberr.Error {
+	_, err := ws.deleteFrom(LeasesTableName).
+		Where(dbr.Eq("id", id)).
+		Where(dbr.Eq("owner", owner)).
+		Exec()
+
+	if err != nil {
+		return dberr.Internal("Failed to delete record from leases table: %s", err)
+	}
+	return nil
+}
+
+// UpdateEncryptedColumns sets the values of the columns only if the columns still have the previous values,
+// so changes made after the row was read are not overwritten. It returns false if the row was not updated.
+func (ws writeSession) UpdateEncryptedColumns(table string, keyColumns, key []string, values, previous map[string]string) (bool, dberr.Error) {
+	stmt := ws.update(table)
+	for i, column := range keyColumns {
+		stmt = stmt.Where(dbr.Eq(column, key[i]))
+	}
+	for column, value := range values {
+		stmt = stmt.Set(column, value).Where(dbr.Eq(column, previous[column]))
+	}
+	res, err := stmt.Exec()
+	if err != nil {
+		return false, dberr.Internal("Failed to update encrypted columns of %s table: %s", table, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, dberr.Internal("Failed to get number of rows affected: %s", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (ws writeSession) DeleteBinding(instanceID, bindingID string) dberr.Error {
+	_, err := ws.deleteFrom(BindingsTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		Where(dbr.Eq("id", bindingID)).
+		Exec()
+
+	if err != nil {
+		return dberr.Internal("Failed to delete record from Binding table: %s", err)
+	}
+	return nil
+}
+
+// ArchiveOperations copies all operations of the instance to the archive
+func (ws writeSession) ArchiveOperations(instanceID string, archivedAt time.Time) dberr.Error {
+	columns := "id, instance_id, target_operation_id, version, state, description, type, data, created_at, updated_at, orchestration_id, provisioning_parameters, finished_stages, request_identity"
+	query := fmt.Sprintf("INSERT INTO %s (%s, archived_at) SELECT %s, ? FROM %s WHERE instance_id = ? ON CONFLICT (id) DO NOTHING",
+		OperationsArchiveTable, columns, columns, OperationTableName)
+
+	var stmt *dbr.InsertStmt
+	if ws.transaction != nil {
+		stmt = ws.transaction.InsertBySql(query, archivedAt, instanceID)
+	} else {
+		stmt = ws.session.InsertBySql(query, archivedAt, instanceID)
+	}
+	_, err := stmt.Exec()
+	if err != nil {
+		return dberr.Internal("Failed to archive operations of instance %s: %s", instanceID, err)
+	}
+	return nil
+}
+
+// ArchiveStepAttempts copies the step history of operations which are deleted by DeleteArchivedOperations to the archive
+func (ws writeSession) ArchiveStepAttempts(instanceID, summaryOperationID string, archivedAt time.Time) dberr.Error {
+	columns := "id, operation_id, stage, step_name, started_at, duration_ms, backoff_ms, error_reason, error_component, error"
+	query := fmt.Sprintf("INSERT INTO %s (%s, archived_at) SELECT %s, ? FROM %s WHERE operation_id IN (SELECT id FROM %s WHERE instance_id = ? AND id <> ?) ON CONFLICT (id) DO NOTHING",
+		StepAttemptsArchiveTableName, columns, columns, StepAttemptsTableName, OperationTableName)
+
+	var stmt *dbr.InsertStmt
+	if ws.transaction != nil {
+		stmt = ws.transaction.InsertBySql(query, archivedAt, instanceID, summaryOperationID)
+	} else {
+		stmt = ws.session.InsertBySql(query, archivedAt, instanceID, summaryOperationID)
+	}
+	_, err := stmt.Exec()
+	if err != nil {
+		return dberr.Internal("Failed to archive step attempts of instance %s: %s", instanceID, err)
+	}
+	return nil
+}
+
+// DeleteStepAttemptsOfArchivedOperations deletes the step history of operations which are deleted by DeleteArchivedOperations
+func (ws writeSession) DeleteStepAttemptsOfArchivedOperations(instanceID, summaryOperationID string) dberr.Error {
+	_, err := ws.deleteFrom(StepAttemptsTableName).
+		Where(fmt.Sprintf("operation_id IN (SELECT id FROM %s WHERE instance_id = ? AND id <> ?)", OperationTableName), instanceID, summaryOperationID).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to delete step attempts of archived operations of instance %s: %s", instanceID, err)
+	}
+	return nil
+}
+
+// DeleteOperationPausesOfArchivedOperations deletes pauses of operations which are deleted by DeleteArchivedOperations
+func (ws writeSession) DeleteOperationPausesOfArchivedOperations(instanceID, summaryOperationID string) dberr.Error {
+	_, err := ws.deleteFrom(OperationPausesTable).
+		Where(fmt.Sprintf("operation_id IN (SELECT id FROM %s WHERE instance_id = ? AND id <> ?)", OperationTableName), instanceID, summaryOperationID).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to delete pauses of archived operations of instance %s: %s", instanceID, err)
+	}
+	return nil
+}
+
+// DeleteArchivedOperations deletes operations of the instance from the operations table except the summary operation
+func (ws writeSession) DeleteArchivedOperations(instanceID, summaryOperationID string) dberr.Error {
+	_, err := ws.deleteFrom(OperationTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		Where(dbr.Neq("id", summaryOperationID)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to delete archived operations of instance %s: %s", instanceID, err)
+	}
+	return nil
+}
+
 func (ws writeSession) Commit() dberr.Error {
 	err := ws.transaction.Commit()
 	if err != nil {
@@ -1,14 +1,13 @@
 package storage
 
 import (
-	"log"
-	"time"
+	"fmt"
 
 	"github.com/gocraft/dbr"
 	"github.com/sirupsen/logrus"
 
-	eventsapi "github.com/kyma-project/kyma-environment-broker/common/events"
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/bolt"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
 	postgres "github.com/kyma-project/kyma-environment-broker/internal/storage/driver/postsql"
 	eventstorage "github.com/kyma-project/kyma-environment-broker/internal/storage/driver/postsql/events"
@@ -23,6 +22,7 @@ type BrokerStorage interface {
 	Orchestrations() Orchestrations
 	RuntimeStates() RuntimeStates
 	Events() Events
+	Bindings() Bindings
 }
 
 const (
@@ -51,9 +51,21 @@ func NewFromConfig(cfg Config, evcfg events.Config, cipher postgres.Cipher, log
 		orchestrations: postgres.NewOrchestrations(fact),
 		runtimeStates:  postgres.NewRuntimeStates(fact, cipher),
 		events:         events.New(evcfg, eventstorage.New(fact, log)),
+		bindings:       postgres.NewBinding(fact, cipher),
 	}, connection, nil
 }
 
+// NewReencryptionFromConfig creates the re-encryption of data stored in the database with the active key of the cipher
+func NewReencryptionFromConfig(cfg Config, cipher postgres.ReencryptionCipher, batchSize int, dryRun bool, log logrus.FieldLogger) (*postgres.Reencryption, *dbr.Connection, error) {
+	connection, err := postsql.InitializeDatabase(cfg.ConnectionURL(), connectionRetries, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	connection.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	return postgres.NewReencryption(postsql.NewFactory(connection), cipher, batchSize, dryRun, log), connection, nil
+}
+
 func NewMemoryStorage() BrokerStorage {
 	op := memory.NewOperation()
 	return storage{
@@ -61,56 +73,62 @@ func NewMemoryStorage() BrokerStorage {
 		instance:       memory.NewInstance(op),
 		orchestrations: memory.NewOrchestrations(),
 		runtimeStates:  memory.NewRuntimeStates(),
-		events:         events.New(events.Config{}, NewInMemoryEvents()),
+		events:         events.New(events.Config{}, memory.NewEvents()),
+		bindings:       memory.NewBinding(),
 	}
 }
 
-type inMemoryEvents struct {
-	events []eventsapi.EventDTO
-}
-
-func NewInMemoryEvents() *inMemoryEvents {
-	return &inMemoryEvents{
-		events: make([]eventsapi.EventDTO, 0),
+// NewEmbeddedStorage creates the in-memory storage which keeps all data in the database file, so it is not lost on restart.
+// Suitable for development purposes, the file can be used by a single broker instance only.
+func NewEmbeddedStorage(path string, evcfg events.Config, cipher bolt.Cipher, log logrus.FieldLogger) (BrokerStorage, *bolt.Journal, error) {
+	log.Infof("Opening embedded database file %s", path)
+	journal, err := bolt.Open(path, cipher)
+	if err != nil {
+		return nil, nil, err
 	}
-}
-
-func (_ inMemoryEvents) RunGarbageCollection(pollingPeriod, retention time.Duration) {
-	return
-}
-
-func (e *inMemoryEvents) InsertEvent(eventLevel eventsapi.EventLevel, message, instanceID, operationID string) {
-	e.events = append(e.events, eventsapi.EventDTO{Level: eventLevel, InstanceID: &instanceID, OperationID: &operationID, Message: message})
-	log.Printf("EVENT [%v/%v] %v: %v\n", instanceID, operationID, eventLevel, message)
-}
 
-func (e *inMemoryEvents) ListEvents(filter eventsapi.EventFilter) ([]eventsapi.EventDTO, error) {
-	var events []eventsapi.EventDTO
-	for _, ev := range e.events {
-		if !requiredContains(ev.InstanceID, filter.InstanceIDs) {
-			continue
-		}
-		if !requiredContains(ev.OperationID, filter.OperationIDs) {
-			continue
-		}
-		events = append(events, ev)
+	s, err := newJournaledStorage(journal, evcfg)
+	if err != nil {
+		journal.Close()
+		return nil, nil, fmt.Errorf("while restoring storage from %s: %w", path, err)
 	}
-	return events, nil
+	return s, journal, nil
 }
 
-func requiredContains[T comparable](el *T, sl []T) bool {
-	if len(sl) == 0 {
-		return true
+func newJournaledStorage(journal memory.Journal, evcfg events.Config) (BrokerStorage, error) {
+	op, err := memory.NewOperationWithJournal(journal)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := memory.NewInstanceWithJournal(op, journal)
+	if err != nil {
+		return nil, err
+	}
+	orchestrations, err := memory.NewOrchestrationsWithJournal(journal)
+	if err != nil {
+		return nil, err
+	}
+	runtimeStates, err := memory.NewRuntimeStatesWithJournal(journal)
+	if err != nil {
+		return nil, err
 	}
-	if el == nil {
-		return false
+	bindings, err := memory.NewBindingWithJournal(journal)
+	if err != nil {
+		return nil, err
 	}
-	for _, x := range sl {
-		if *el == x {
-			return true
-		}
+	ev, err := memory.NewEventsWithJournal(journal)
+	if err != nil {
+		return nil, err
 	}
-	return false
+
+	return storage{
+		operation:      op,
+		instance:       instance,
+		orchestrations: orchestrations,
+		runtimeStates:  runtimeStates,
+		events:         events.New(evcfg, ev),
+		bindings:       bindings,
+	}, nil
 }
 
 type storage struct {
@@ -119,6 +137,7 @@ type storage struct {
 	orchestrations Orchestrations
 	runtimeStates  RuntimeStates
 	events         Events
+	bindings       Bindings
 }
 
 func (s storage) Instances() Instances {
@@ -148,3 +167,7 @@ func (s storage) RuntimeStates() RuntimeStates {
 func (s storage) Events() Events {
 	return s.events
 }
+
+func (s storage) Bindings() Bindings {
+	return s.bindings
+}
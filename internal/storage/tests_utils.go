@@ -322,11 +322,19 @@ func isDBContainerAvailable(hostname, port string) (isAvailable bool, dbCfg Conf
 }
 
 func clearDBQuery() string {
-	return fmt.Sprintf("TRUNCATE TABLE %s, %s, %s, %s RESTART IDENTITY CASCADE",
+	return fmt.Sprintf("TRUNCATE TABLE %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s RESTART IDENTITY CASCADE",
 		postsql.InstancesTableName,
 		postsql.OperationTableName,
 		postsql.OrchestrationTableName,
 		postsql.RuntimeStateTableName,
+		postsql.BindingsTableName,
+		postsql.StepAttemptsTableName,
+		postsql.ProcessingPausesTable,
+		postsql.OperationPausesTable,
+		postsql.LeasesTableName,
+		postsql.OperationsArchiveTable,
+		postsql.StepAttemptsArchiveTableName,
+		postsql.EventsTableName,
 	)
 }
 
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS instances_labels_idx;
+
+ALTER TABLE instances
+    DROP COLUMN labels;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE instances
+    ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX instances_labels_idx ON instances USING GIN (labels);
+
+COMMIT;
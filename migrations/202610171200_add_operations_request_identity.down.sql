@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS operations_request_identity_idx;
+
+ALTER TABLE operations
+    DROP COLUMN request_identity;
@@ -0,0 +1,4 @@
+ALTER TABLE operations
+    ADD COLUMN request_identity varchar(255) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX operations_request_identity_idx ON operations (request_identity) WHERE request_identity <> '';
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS operation_step_attempts (
+    id              bigserial PRIMARY KEY,
+    operation_id    varchar(255) NOT NULL,
+    stage           varchar(255) NOT NULL DEFAULT '',
+    step_name       varchar(255) NOT NULL,
+    started_at      timestamp with time zone NOT NULL,
+    duration_ms     bigint NOT NULL DEFAULT 0,
+    backoff_ms      bigint NOT NULL DEFAULT 0,
+    error_reason    varchar(255) NOT NULL DEFAULT '',
+    error_component varchar(255) NOT NULL DEFAULT '',
+    error           text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS operation_step_attempts_operation_id ON operation_step_attempts (operation_id);
+
+COMMIT;
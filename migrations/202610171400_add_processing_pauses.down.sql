@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE processing_pauses;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS processing_pauses (
+    operation_type varchar(32) PRIMARY KEY,
+    reason         text NOT NULL DEFAULT '',
+    paused_at      timestamp with time zone NOT NULL
+);
+
+COMMIT;
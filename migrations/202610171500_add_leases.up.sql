@@ -0,0 +1,12 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS leases (
+    id          varchar(255) PRIMARY KEY,
+    kind        varchar(64) NOT NULL,
+    owner       varchar(255) NOT NULL,
+    lease_until timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS leases_kind_lease_until ON leases (kind, lease_until);
+
+COMMIT;
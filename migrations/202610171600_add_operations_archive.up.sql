@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS operations_archive (
+    id                      varchar(255) PRIMARY KEY,
+    instance_id             varchar(255) NOT NULL,
+    target_operation_id     varchar(255) NOT NULL,
+    version                 integer NOT NULL,
+    state                   varchar(32) NOT NULL,
+    description             text NOT NULL,
+    type                    varchar(32) NOT NULL,
+    data                    json NOT NULL,
+    created_at              timestamp with time zone NOT NULL,
+    updated_at              timestamp with time zone NOT NULL,
+    orchestration_id        varchar(64),
+    provisioning_parameters json NOT NULL,
+    finished_stages         text,
+    request_identity        varchar(255) NOT NULL DEFAULT '',
+    archived_at             timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS operations_archive_by_instance_id ON operations_archive (instance_id);
+
+COMMIT;
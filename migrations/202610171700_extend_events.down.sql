@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS events_created_at_id;
+
+ALTER TABLE events DROP COLUMN IF EXISTS fields;
+DELETE FROM events WHERE level NOT IN ('info', 'error');
+ALTER TABLE events ALTER COLUMN level TYPE event_level USING level::event_level;
+
+COMMIT;
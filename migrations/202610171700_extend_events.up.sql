@@ -0,0 +1,9 @@
+BEGIN;
+
+-- levels are not kept in the enum anymore, so adding a level does not require altering the type
+ALTER TABLE events ALTER COLUMN level TYPE varchar(32);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS fields json;
+
+CREATE INDEX IF NOT EXISTS events_created_at_id ON events (created_at, id);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE bindings DROP COLUMN IF EXISTS cluster_role;
+
+COMMIT;
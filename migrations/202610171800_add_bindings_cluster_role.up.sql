@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE bindings ADD COLUMN IF NOT EXISTS cluster_role varchar(255) NOT NULL DEFAULT 'cluster-admin';
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS operation_pauses (
+    operation_id varchar(255) PRIMARY KEY,
+    paused       boolean NOT NULL DEFAULT false,
+    paused_at    timestamp with time zone NOT NULL DEFAULT '0001-01-01 00:00:00+00',
+    resumed_at   timestamp with time zone NOT NULL DEFAULT '0001-01-01 00:00:00+00'
+);
+
+COMMIT;
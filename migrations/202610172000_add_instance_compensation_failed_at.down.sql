@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE instances
+    DROP COLUMN compensation_failed_at;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE instances
+    ADD COLUMN compensation_failed_at timestamp with time zone;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE operation_step_attempts_archive;
+
+COMMIT;